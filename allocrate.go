@@ -0,0 +1,60 @@
+package autotune
+
+const (
+	// allocRateRiseThreshold is how far above its baseline the allocation
+	// rate must be to count as rising
+	allocRateRiseThreshold = 1.2
+	// allocRateBaselineSamples is how many preceding samples the allocation
+	// rate baseline averages
+	allocRateBaselineSamples = 5
+)
+
+// allocRate returns the bytes allocated per second between two samples
+func allocRate(prev, cur Metrics) uint64 {
+	timeDiff := cur.Timestamp.Sub(prev.Timestamp).Seconds()
+	if timeDiff <= 0 || cur.TotalAlloc < prev.TotalAlloc {
+		return 0
+	}
+	return uint64(float64(cur.TotalAlloc-prev.TotalAlloc) / timeDiff)
+}
+
+// allocRateBaseline averages the allocation rates of the samples preceding
+// metrics in the history, up to allocRateBaselineSamples of them. Samples
+// without an allocation rate are skipped; zero means there is no baseline.
+func (t *Tuner) allocRateBaseline(metrics Metrics) float64 {
+	var sum float64
+	count := 0
+	history := t.metricsHistory.items()
+	for i := len(history) - 1; i >= 0 && count < allocRateBaselineSamples; i-- {
+		sample := history[i]
+		if !sample.Timestamp.Before(metrics.Timestamp) || sample.AllocRate == 0 {
+			continue
+		}
+		sum += float64(sample.AllocRate)
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// allocRateRise returns how far, relative to its baseline, the allocation
+// rate has risen, or zero when it isn't rising or raising GOGC for it isn't
+// safe: pauses above TargetLatency are left to the latency factor, and high
+// memory pressure to the memory factor.
+func (t *Tuner) allocRateRise(metrics Metrics) float64 {
+	if metrics.AllocRate == 0 || t.tuningPause(metrics) > t.config.TargetLatency || metrics.MemoryPressure > 0.8 {
+		return 0
+	}
+
+	baseline := t.allocRateBaseline(metrics)
+	if baseline == 0 {
+		return 0
+	}
+	ratio := float64(metrics.AllocRate) / baseline
+	if ratio < allocRateRiseThreshold {
+		return 0
+	}
+	return ratio - 1
+}
@@ -0,0 +1,68 @@
+package autotune
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAllocRate tests computing the allocation rate between samples
+func TestAllocRate(t *testing.T) {
+	base := time.Now()
+	prev := Metrics{TotalAlloc: 1000, Timestamp: base}
+	assert.Equal(t, uint64(500), allocRate(prev, Metrics{TotalAlloc: 6000, Timestamp: base.Add(10 * time.Second)}))
+	assert.Zero(t, allocRate(prev, Metrics{TotalAlloc: 6000, Timestamp: base}))
+	assert.Zero(t, allocRate(prev, Metrics{TotalAlloc: 500, Timestamp: base.Add(time.Second)}))
+}
+
+// TestAllocRateFactor tests that a rising allocation rate raises GOGC while
+// pauses are acceptable
+func TestAllocRateFactor(t *testing.T) {
+	config := DefaultConfig()
+	config.TuningAggressiveness = 1
+	config.FactorSmoothingAlpha = 1
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	tuner.qosClass = QoSClassUnknown
+
+	base := time.Now()
+	sample := func(i int, rate uint64, pause time.Duration) Metrics {
+		return Metrics{
+			Timestamp:      base.Add(time.Duration(i) * time.Minute),
+			AllocRate:      rate,
+			GCPauseTime:    pause,
+			GCFrequency:    1,
+			MemoryPressure: 0.5,
+			CurrentGOGC:    100,
+		}
+	}
+	for i := 0; i < 5; i++ {
+		tuner.metricsHistory.push(sample(i, 100e6, 10*time.Millisecond))
+	}
+
+	steady := sample(5, 100e6, 10*time.Millisecond)
+	steadyTarget, _ := tuner.calculateTarget(steady)
+	assert.Zero(t, tuner.allocRateRise(steady))
+
+	// Doubling the allocation rate raises the target and explains why
+	rising := sample(5, 200e6, 10*time.Millisecond)
+	tuner.metricsHistory.push(rising)
+	assert.InDelta(t, 1.0, tuner.allocRateRise(rising), 1e-9)
+	risingTarget, category := tuner.calculateTarget(rising)
+	assert.Greater(t, risingTarget, steadyTarget)
+	assert.Equal(t, CategoryFrequency, category)
+	assert.Contains(t, tuner.reasonFactors(rising), "Allocation rate rising to 200.0MB/s (+100%)")
+
+	// Pauses above the target are left to the latency factor
+	assert.Zero(t, tuner.allocRateRise(sample(5, 200e6, 20*time.Millisecond)))
+
+	// So is high memory pressure to the memory factor
+	pressured := rising
+	pressured.MemoryPressure = 0.9
+	assert.Zero(t, tuner.allocRateRise(pressured))
+
+	// Small increases don't count
+	assert.Zero(t, tuner.allocRateRise(sample(5, 110e6, 10*time.Millisecond)))
+}
@@ -8,15 +8,20 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"math/rand"
+	"os"
 	"runtime"
 	"runtime/debug"
+	"strconv"
 	"sync"
 	"time"
 )
 
 // Config holds configuration for the autotune package
 type Config struct {
-	// MonitorInterval is how often to collect metrics and evaluate tuning
+	// MonitorInterval is how often to collect metrics and evaluate tuning.
+	// The minimum is 1s; 5s or more is recommended in production since each
+	// cycle stops the world briefly to read memory statistics.
 	MonitorInterval time.Duration
 	// MinGOGC is the minimum GOGC value allowed
 	MinGOGC int
@@ -24,18 +29,176 @@ type Config struct {
 	MaxGOGC int
 	// TargetLatency is the target GC pause time in nanoseconds
 	TargetLatency time.Duration
+	// TargetLatencyTolerance is the dead-band around TargetLatency within
+	// which pauses count as on target, so the latency factor proposes no
+	// change (zero means 20% of TargetLatency, negative disables the
+	// dead-band)
+	TargetLatencyTolerance time.Duration
 	// MemoryLimitPercent is the percentage of container memory limit to use as threshold
 	MemoryLimitPercent float64
+	// UseMemoryHigh computes memory pressure against the cgroup v2
+	// memory.high threshold when it is below the memory limit, since the
+	// kernel throttles and reclaims the cgroup above it. MemoryPressureRaw
+	// stays relative to the hard limit.
+	UseMemoryHigh bool
 	// TuningAggressiveness controls how quickly GOGC is adjusted (0.1 = conservative, 1.0 = aggressive)
 	TuningAggressiveness float64
 	// StabilizationWindow is the time window for anti-oscillation logic
 	StabilizationWindow time.Duration
+	// DisableAntiOscillation turns off the anti-oscillation check, so
+	// decisions may reverse direction every cycle. This is meant for studying
+	// the raw algorithm and for workloads that genuinely need rapid,
+	// bidirectional changes; elsewhere it risks GOGC flapping, with the GC
+	// CPU and heap size swings that come with it.
+	DisableAntiOscillation bool
 	// MaxChangePerInterval limits how much GOGC can change in one interval
 	MaxChangePerInterval int
+	// RevertThreshold is the relative degradation of the metric a decision
+	// targeted, averaged over the two cycles after it and compared with the
+	// metrics at decision time, that reverts the decision, e.g. 0.25 for 25%
+	// worse. A reverted change isn't retried in the same direction for the
+	// stabilization window (zero disables reverts).
+	RevertThreshold float64
+	// GOGCRounding rounds each computed GOGC to the nearest multiple of this
+	// increment after clamping, e.g. 10 or 25, to reduce churn from small
+	// changes (zero disables rounding). The rounded value stays within
+	// bounds and may exceed MaxChangePerInterval by half an increment.
+	GOGCRounding int
+	// BoundProximityMargin is the fraction of the GOGC bound range, in
+	// [0, 0.5), within which GOGC counts as near a bound. Decisions made
+	// near either bound get a lower confidence (zero means 0.1).
+	BoundProximityMargin float64
+	// FactorSmoothingAlpha is the weight of each cycle's combined factor in
+	// the exponential smoothing of GOGC adjustments, in (0, 1]. Lower values
+	// make the tuner smoother and slower, higher values more reactive. It
+	// compounds with TuningAggressiveness, which scales the factors being
+	// smoothed (zero means 0.3).
+	FactorSmoothingAlpha float64
+	// CPUAwareness is the weight, in [0, 1], of the CPU factor in the
+	// combined GOGC adjustment. The factor raises GOGC when both CPU usage
+	// and the share of CPU spent in GC are high, trading memory for less GC
+	// overhead (zero disables it).
+	CPUAwareness float64
+	// Strategy proposes each cycle's target GOGC (nil means the built-in
+	// heuristic combining pause time, memory pressure, GC frequency and CPU
+	// overhead, see Tuner.DefaultStrategy). See also PIDStrategy.
+	Strategy Strategy
+	// MaxAggressivenessBoost caps the temporary boost to TuningAggressiveness
+	// applied while consecutive decisions keep moving GOGC in the same
+	// direction, as after a regime change (zero means 2, 1 disables the ramp)
+	MaxAggressivenessBoost float64
+	// RecommendationWeight is how far the local target moves towards the
+	// cluster-wide recommendation, see SetRecommendationSource: 0.5 lands
+	// halfway between them, 1 adopts the recommendation (zero means 0.5)
+	RecommendationWeight float64
+	// MemoryLimitSources is the order memory limit sources are tried in, the
+	// first one that yields a limit wins (empty means
+	// DefaultMemoryLimitSources: cgroup v2, cgroup v1, /proc/meminfo)
+	MemoryLimitSources []MemoryLimitSource
+	// MemoryLimitFile is the file read by MemoryLimitSourceFile
+	MemoryLimitFile string
+	// MemoryRequestBytes is the container memory request. When zero it is read
+	// from the AUTOTUNE_MEMORY_REQUEST environment variable, which can be
+	// populated with the Kubernetes downward API (requests.memory). Together
+	// with the detected limit it determines the QoS class, see QoSClass.
+	MemoryRequestBytes uint64
+	// LiveHeapSource selects the heap statistic used as the live heap for memory
+	// pressure calculations (empty means LiveHeapSourceAuto)
+	LiveHeapSource LiveHeapSource
+	// EnableBallast enables a memory ballast that is sized from the memory
+	// headroom and adjusted alongside GOGC. On Go 1.19+ prefer a soft memory
+	// limit (GOMEMLIMIT) instead; the ballast is meant for cases where one
+	// can't be used.
+	EnableBallast bool
+	// EnableMemoryLimit lets the tuner set the soft memory limit
+	// (GOMEMLIMIT) to MemoryLimitTarget of the detected container memory
+	// limit, raising it towards the container limit when the live heap
+	// leaves too little headroom. The memory limit then acts as the
+	// backstop against OOM kills, so memory pressure no longer lowers GOGC.
+	// It can't be combined with EnableBallast and has no effect without a
+	// container memory limit. The previous limit is restored on Stop.
+	EnableMemoryLimit bool
+	// MemoryLimitTarget is the fraction of the container memory limit
+	// GOMEMLIMIT is set to, in [0.5, 1] (zero means 0.9)
+	MemoryLimitTarget float64
+	// DisableGC turns the garbage collector off (GOGC=off) once the tuner
+	// has set the soft memory limit, so the heap grows until the limit
+	// triggers a collection. When memory pressure reaches the
+	// MemoryLimitPercent threshold, GC is turned back on at MaxGOGC and
+	// tuned as usual until pressure falls well below it again. It requires
+	// EnableMemoryLimit; without a container memory limit GC stays on. GOGC
+	// is turned back on when the tuner stops.
+	DisableGC bool
+	// MaxBallastBytes caps the ballast size (zero means no cap). Without a
+	// detected container memory limit the ballast is only used when this is
+	// set, and is then sized to exactly this value.
+	MaxBallastBytes uint64
+	// GOGCBand enables cooperative mode for processes shared with other
+	// components: the tuner only sets GOGC within [GOGCBand[0], GOGCBand[1]],
+	// which must lie within MinGOGC/MaxGOGC, and restores the band center on
+	// Stop. External GOGC changes inside the band are adopted as advisory
+	// baselines. The zero value disables cooperative mode.
+	GOGCBand [2]int
+	// PauseTarget selects the GC pause statistic compared with
+	// TargetLatency when deciding and evaluating changes: the average of the
+	// last 10 pauses, or their 95th or 99th percentile so tail pauses drive
+	// GOGC up (empty means PauseStatisticAverage)
+	PauseTarget PauseStatistic
+	// LeakAction selects the response to a suspected memory leak (empty
+	// means LeakActionAlert)
+	LeakAction LeakAction
+	// LeakDetectionWindow is the number of consecutive samples the live heap
+	// must grow over before a leak is suspected (zero means 20)
+	LeakDetectionWindow int
+	// MetricsHistorySize is the number of metrics samples kept for decisions
+	// and GetMetricsHistory; it must hold the leak detection window (zero
+	// means 100). It only takes effect in a new tuner.
+	MetricsHistorySize int
+	// MaxPauseTime is a hard ceiling on GC pause time: a cycle whose pause
+	// exceeds it raises GOGC by MaxChangePerInterval right away, bypassing
+	// confidence gating, anti-oscillation and SLO mode dormancy but not the
+	// GOGC bounds (zero disables the ceiling). Unlike TargetLatency, which
+	// is a goal, it is an emergency threshold and should be well above it.
+	MaxPauseTime time.Duration
+	// SLOMode keeps the tuner dormant until the GC pause SLO is breached,
+	// see SLOConfig (zero value disables it)
+	SLOMode SLOConfig
+	// RandSeed seeds randomized behavior such as retry jitter, so runs with
+	// the same seed and inputs are reproducible (zero means seeded from the
+	// current time)
+	RandSeed int64
+	// ExternalMetrics disables internal metrics collection: tuning cycles
+	// run when metrics are fed with IngestMetrics instead of on a timer
+	ExternalMetrics bool
+	// DisablePanicRecovery lets panics in user-supplied functions such as
+	// providers, hooks, filters and callbacks propagate instead of being
+	// logged and counted, e.g. to get a stack trace while debugging
+	DisablePanicRecovery bool
 	// Logger for debugging and observability
 	Logger Logger
 }
 
+// defaultBoundProximityMargin is the near-bound margin used when
+// Config.BoundProximityMargin is zero
+const defaultBoundProximityMargin = 0.1
+
+// defaultTargetLatencyTolerance is the fraction of TargetLatency used as the
+// latency dead-band when Config.TargetLatencyTolerance is zero
+const defaultTargetLatencyTolerance = 0.2
+
+// defaultFactorSmoothingAlpha is the smoothing weight used when
+// Config.FactorSmoothingAlpha is zero
+const defaultFactorSmoothingAlpha = 0.3
+
+const (
+	// cpuHighUsage is the CPU utilization, as a fraction of the CPU limit,
+	// above which GC CPU overhead is worth trading memory for
+	cpuHighUsage = 0.8
+	// gcCPUFractionThreshold is the share of CPU time spent in GC above
+	// which the CPU factor raises GOGC
+	gcCPUFractionThreshold = 0.05
+)
+
 // DefaultConfig returns a production-ready default configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -47,10 +210,37 @@ func DefaultConfig() *Config {
 		TuningAggressiveness: 0.3,
 		StabilizationWindow:  5 * time.Minute,
 		MaxChangePerInterval: 50,
+		RevertThreshold:      0.25,
+		FactorSmoothingAlpha: defaultFactorSmoothingAlpha,
+		CPUAwareness:         1.0,
+		LiveHeapSource:       LiveHeapSourceAuto,
 		Logger:               &defaultLogger{},
 	}
 }
 
+// LiveHeapSource identifies where the live heap size is read from.
+//
+// HeapSys and HeapInuse include spans that are reserved or partially used,
+// which on workloads with large arenas makes them a poor proxy for the live
+// set. The runtime/metrics live heap is the most accurate source; HeapAlloc
+// also counts garbage allocated since the last GC but excludes span overhead.
+type LiveHeapSource string
+
+const (
+	// LiveHeapSourceAuto uses the runtime/metrics live heap when the runtime
+	// exposes it and falls back to HeapAlloc otherwise
+	LiveHeapSourceAuto LiveHeapSource = "auto"
+	// LiveHeapSourceRuntimeMetrics uses /gc/heap/live:bytes, the heap marked
+	// live by the last GC cycle, falling back to HeapAlloc if unavailable
+	LiveHeapSourceRuntimeMetrics LiveHeapSource = "runtime_metrics"
+	// LiveHeapSourceHeapAlloc uses MemStats.HeapAlloc, the bytes of allocated
+	// heap objects including unswept garbage
+	LiveHeapSourceHeapAlloc LiveHeapSource = "heap_alloc"
+	// LiveHeapSourceHeapInuse uses MemStats.HeapInuse, the bytes in in-use
+	// spans including fragmentation (the historical behavior)
+	LiveHeapSourceHeapInuse LiveHeapSource = "heap_inuse"
+)
+
 // Logger interface for customizable logging
 type Logger interface {
 	Debug(msg string, fields ...interface{})
@@ -73,27 +263,79 @@ func (l *defaultLogger) Error(msg string, fields ...interface{}) {
 // Metrics holds runtime metrics for GC tuning decisions
 type Metrics struct {
 	// GC metrics
-	GCPauseTime time.Duration
+	GCPauseTime time.Duration // average of the last 10 pauses
+	// GCPauseP95 and GCPauseP99 are percentiles of the last 256 pauses,
+	// which show tail pauses the average washes out. Unlike GCFrequency, the
+	// pause statistics include forced GCs: MemStats doesn't record which
+	// pauses in its ring buffer were forced.
+	GCPauseP95  time.Duration
+	GCPauseP99  time.Duration
 	GCFrequency float64 // GCs per second
 	HeapSize    uint64
 	HeapAlloc   uint64
 	HeapInuse   uint64
+	LiveHeap    uint64 // live heap as selected by Config.LiveHeapSource
 	NextGC      uint64
 	LastGC      time.Time
 	NumGC       uint32
+	NumForcedGC uint32 // GCs forced by runtime.GC, included in NumGC
+	TotalAlloc  uint64 // cumulative bytes allocated
+	AllocRate   uint64 // bytes allocated per second since the previous sample
+	// GCCPUSeconds and TotalCPUSeconds are the cumulative CPU time spent in
+	// the GC and available to the process, from runtime/metrics; zero on
+	// runtimes without them
+	GCCPUSeconds    float64
+	TotalCPUSeconds float64
+	// Pauses are the individual GC pauses since the previous sample, oldest
+	// first (at most 256). They are only passed to this cycle's callbacks
+	// and observers, not kept in the history.
+	Pauses []time.Duration `json:"-"`
 
 	// Memory metrics
-	MemoryLimit    uint64
-	MemoryUsage    uint64
-	MemoryPressure float64 // 0.0 to 1.0
+	MemoryLimit    uint64  // the pressure threshold, see Tuner.memoryBaseline
+	MemoryUsage    uint64  // cgroup working set in a container, else the live heap, see Tuner.memoryUsage
+	MemoryPressure float64 // same as MemoryPressureAdjusted
+
+	// MemoryPressureRaw is MemoryUsage relative to the container memory
+	// limit, how close the process is to being OOM killed. Alerts and the
+	// /health check use it.
+	MemoryPressureRaw float64
+	// MemoryPressureAdjusted is MemoryUsage relative to the pressure
+	// threshold MemoryLimit, derived from MemoryLimitPercent; 1.0 means the
+	// threshold is reached. Tuning decisions use it.
+	MemoryPressureAdjusted float64
+
+	// HeapFragmentation is the share of the heap obtained from the OS that
+	// isn't in in-use spans, (HeapSize - HeapInuse) / HeapSize: free spans
+	// not yet returned to the OS and fragmentation. When it is high, RSS is
+	// dominated by memory GOGC can't reclaim.
+	HeapFragmentation float64
+
+	// Memory pressure stall information (cgroup v2): the percentage of time
+	// over the last 10s some tasks were stalled on memory, see PSIStats
+	MemoryPSI          float64
+	MemoryPSIAvailable bool
+
+	// Live heap growth trend, see Config.LeakAction
+	HeapGrowthSlope float64 // bytes per second over the leak detection window
+	LeakSuspected   bool
 
 	// Performance metrics
-	CPUUsage   float64
-	Throughput float64 // requests per second (app-specific)
+	CPUUsage       float64       // CPU utilization since the previous cycle as a fraction of the CPU limit plus burst allowance (0-1)
+	GCCPUFraction  float64       // share of available CPU time used by the GC since the previous cycle (since the program started for the first)
+	Throughput     float64       // requests per second (app-specific)
+	RequestLatency time.Duration // from the latency provider, see SetLatencyProvider
+
+	// Cluster-wide GOGC recommendation, zero when none (see SetRecommendationSource)
+	RecommendedGOGC int
+
+	// Application metrics collected by hooks, see Tuner.AddMetricHook
+	Custom map[string]float64 `json:",omitempty"`
 
 	// Container metrics
 	ContainerMemLimit uint64
 	ContainerCPULimit float64
+	ContainerCPUBurst float64 // cgroup v2 burst allowance in cores on top of the limit
 
 	// Current GOGC value
 	CurrentGOGC int
@@ -103,14 +345,65 @@ type Metrics struct {
 
 // TuningDecision represents a decision made by the tuning algorithm
 type TuningDecision struct {
-	OldGOGC    int
-	NewGOGC    int
+	// ID identifies the decision: recorded decisions are numbered from 1
+	// in the order they were applied, see AnnotateDecision
+	ID      uint64
+	OldGOGC int
+	NewGOGC int
+	// DesiredGOGC is the target computed by the algorithm before the
+	// per-interval change limit and bounds were applied
+	DesiredGOGC int
+	// ClampedBy records what held NewGOGC back from DesiredGOGC, if anything
+	ClampedBy ClampType
+	// BoundHit records which GOGC bound NewGOGC was clamped to, if any
+	BoundHit BoundHit
+	// OldMemLimit and NewMemLimit are the soft memory limit (GOMEMLIMIT)
+	// before and after decisions that set it, see Config.EnableMemoryLimit;
+	// zero for decisions that only change GOGC
+	OldMemLimit int64 `json:",omitempty"`
+	NewMemLimit int64 `json:",omitempty"`
+	// Category records which factor drove the decision
+	Category DecisionCategory
+	// Priority is PriorityHigh for emergency responses to MaxPauseTime
+	Priority   DecisionPriority
 	Reason     string
 	Confidence float64 // 0.0 to 1.0
 	Timestamp  time.Time
-	Metrics    *Metrics
+	Metrics    *Metrics // metrics at decision time, before the change
+	Outcome    string   // application-supplied outcome, see AnnotateDecision
+
+	// generation is the GOGC generation the decision was proposed under, see
+	// Tuner.gogcGeneration; zero means it applies unconditionally
+	generation uint64
+	// reverted is set once a later decision reverted this one
+	reverted bool
 }
 
+// ClampType identifies the limit a decision was clamped by
+type ClampType string
+
+const (
+	// ClampNone means the decision moved GOGC all the way to its target
+	ClampNone ClampType = ""
+	// ClampRateLimit means MaxChangePerInterval limited the step
+	ClampRateLimit ClampType = "rate_limit"
+	// ClampBounds means the target was outside MinGOGC/MaxGOGC (or the
+	// cooperative GOGC band)
+	ClampBounds ClampType = "bounds"
+)
+
+// BoundHit identifies the GOGC bound a decision was clamped to
+type BoundHit string
+
+const (
+	// BoundHitNone means the target was within bounds
+	BoundHitNone BoundHit = ""
+	// BoundHitMin means the target was below MinGOGC (or the band's low end)
+	BoundHitMin BoundHit = "min"
+	// BoundHitMax means the target was above MaxGOGC (or the band's high end)
+	BoundHitMax BoundHit = "max"
+)
+
 // Tuner manages automatic GC tuning
 type Tuner struct {
 	config  *Config
@@ -118,31 +411,130 @@ type Tuner struct {
 	ctx     context.Context
 	cancel  context.CancelFunc
 	running bool
+	// stopAfter unregisters the Stop call on the StartWithContext context
+	stopAfter func() bool
+
+	// now returns the current time, replaceable in tests to simulate clock
+	// adjustments
+	now func() time.Time
+	// setGCPercent applies GOGC, replaced by Simulate to replay decisions
+	// without touching the process
+	setGCPercent func(int) int
 
-	// Metrics history for decision-making
-	metricsHistory []Metrics
-	maxHistory     int
+	// Metrics history for decision-making, the last
+	// Config.MetricsHistorySize samples
+	metricsHistory *ring[Metrics]
+	historyVersion uint64 // incremented whenever metricsHistory changes
 
 	// Decision history for anti-oscillation
-	decisionHistory []TuningDecision
-	maxDecisions    int
+	decisionHistory *ring[TuningDecision]
+	lastDecisionID  uint64 // ID of the last recorded decision
 
 	// Container resource detection
 	containerResources *ContainerResources
+	cpuSampler         *CPUSampler
+
+	// GC-relevant GODEBUG settings detected at startup
+	gcDebug GCDebugSettings
+
+	// How promptly freed memory is returned to the OS, detected at startup
+	memoryReturn MemoryReturnSettings
+
+	// Memory request and the QoS class derived from it
+	memoryRequest uint64
+	qosClass      QoSClass
+
+	// Computations over metricsHistory cached per history version, so extra
+	// evaluations within a cycle (such as Recommend) don't repeat them
+	cacheMu             sync.Mutex
+	latencyBands        []LatencyBand
+	latencyBandsVersion uint64
+
+	// Memory ballast, see Config.EnableBallast
+	ballast []byte
+
+	// Soft memory limit set by the tuner (zero when unmanaged) and the one
+	// it replaced, see Config.EnableMemoryLimit
+	memLimit         int64
+	originalMemLimit int64
+	setMemoryLimit   func(limit int64) int64
 
 	// Callbacks
-	onTuningDecision func(decision TuningDecision)
-	onMetricsUpdate  func(metrics Metrics)
+	decisionFilter       func(proposed TuningDecision) (TuningDecision, bool)
+	onRateLimited        func(decision TuningDecision)
+	latencyProvider      func() time.Duration
+	recommendationSource func() (gogc int, ok bool)
+	metricHooks          []metricHook
+
+	// SLO mode state: when the current breach started and whether the tuner
+	// is acting on it
+	sloBreachStart time.Time
+	sloActing      bool
+
+	// Config passed to UpdateConfig, applied at the start of the next cycle
+	pendingConfig *Config
+
+	// Panics recovered from user-supplied functions
+	userFuncFailures userFuncFailures
+	decisionObs      []DecisionObserver
+	metricsSubs      []*metricsSubscriber
+
+	// rand seeds the random sources of components, see newRand
+	rand *rand.Rand
+
+	// gogcGeneration is incremented whenever GOGC is set outside the tuning
+	// loop (SetGOGC or an external change), so decisions proposed before
+	// that can be discarded instead of clobbering it. It starts at 1 so that
+	// a zero decision generation means unchecked.
+	gogcGeneration uint64
+
+	// paused holds tuning until Resume; overrideUntil holds it until then,
+	// see SetGOGCOverride (zero without an override)
+	paused        bool
+	overrideUntil time.Time
 
 	// Internal state
 	lastGOGC       int
 	stabilityCount int
+	stableCh       chan struct{} // closed once the tuner has converged
+	desiredGOGC    int           // unclamped target of the last applied decision
+
+	// Monitor loop progress, see metricsStaleLocked
+	startTime     time.Time
+	lastCycleTime time.Time
+
+	// Aggressiveness ramp, see aggressivenessBoost
+	lastDirection       int // -1, 0 or 1
+	sameDirectionStreak int
+
+	// Memory leak detection, see Config.LeakAction
+	leakSuspected   bool
+	heapGrowthSlope float64
+	safeMode        bool
+
+	// External GOGC change detection
+	externalChangeDetected bool
+	externalChanges        int64
+
+	// Evaluation of the last decision and the hold after a revert, see
+	// Config.RevertThreshold
+	evaluation          *decisionEvaluation
+	revertHoldUntil     time.Time
+	revertHoldDirection int
 
 	// Metrics for observability
-	totalDecisions  int64
-	successfulTunes int64
-	revertedTunes   int64
-	avgImprovement  float64
+	totalDecisions       int64
+	successfulTunes      int64
+	evaluatedDecisions   int64
+	revertedTunes        int64
+	vetoedDecisions      int64
+	rateLimitedDecisions int64
+	minBoundHits         int64
+	categoryCounts       map[DecisionCategory]int64
+	maxBoundHits         int64
+	staleDecisions       int64
+	pauseCeilingHits     int64
+	avgImprovement       float64
 }
 
 // NewTuner creates a new GC tuner with the given configuration
@@ -157,45 +549,117 @@ func NewTuner(config *Config) (*Tuner, error) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	containerResources, err := DetectContainerResources()
+	containerResources, err := detectContainerResources(config.MemoryLimitSources, config.MemoryLimitFile)
 	if err != nil {
 		config.Logger.Warn("Failed to detect container resources: %v", err)
+	} else if containerResources.MemoryLimitSource != "" {
+		config.Logger.Info("Detected memory limit of %d bytes from %s",
+			containerResources.MemoryLimit, containerResources.MemoryLimitSource)
+	}
+	if containerResources != nil && containerResources.MemoryHigh > 0 {
+		config.Logger.Info("Detected memory.high of %d bytes", containerResources.MemoryHigh)
 	}
 
 	tuner := &Tuner{
 		config:             config,
+		now:                time.Now,
+		setGCPercent:       debug.SetGCPercent,
+		setMemoryLimit:     debug.SetMemoryLimit,
 		ctx:                ctx,
 		cancel:             cancel,
-		maxHistory:         100,
-		maxDecisions:       50,
+		metricsHistory:     newRing[Metrics](metricsHistorySize(config)),
+		decisionHistory:    newRing[TuningDecision](maxDecisions),
 		containerResources: containerResources,
-		lastGOGC:           debug.SetGCPercent(-1), // Get current GOGC
+		gcDebug:            ParseGCDebug(os.Getenv("GODEBUG")),
+		lastGOGC:           readGOGC(),
+		stableCh:           make(chan struct{}),
+		gogcGeneration:     1,
+		rand:               newRand(config.RandSeed),
+	}
+
+	tuner.cpuSampler = newContainerCPUSampler(containerResources)
+
+	tuner.memoryReturn = newMemoryReturnSettings(tuner.gcDebug, detectTHPMode())
+
+	tuner.memoryRequest = config.MemoryRequestBytes
+	if tuner.memoryRequest == 0 {
+		tuner.memoryRequest = memoryRequestFromEnv()
+	}
+	var memoryLimit uint64
+	if containerResources != nil {
+		memoryLimit = containerResources.MemoryLimit
+	}
+	tuner.qosClass = detectQoSClass(tuner.memoryRequest, memoryLimit)
+	if tuner.qosClass != QoSClassUnknown {
+		config.Logger.Info("Detected %s memory QoS (request %d, limit %d bytes)",
+			tuner.qosClass, tuner.memoryRequest, memoryLimit)
 	}
 
-	// Restore original GOGC
-	debug.SetGCPercent(tuner.lastGOGC)
+	runtimeCollector.logSources(config.Logger)
+
+	if len(tuner.gcDebug.Raw) > 0 {
+		config.Logger.Info("Detected GC-relevant GODEBUG settings: %v", tuner.gcDebug.Raw)
+	}
+	if !tuner.gcDebug.PauseTuningMeaningful() {
+		config.Logger.Warn("GODEBUG disables concurrent GC or enables checkmark mode, pause-time based tuning is disabled")
+	}
+	thp := tuner.memoryReturn.THP
+	if thp == THPUnknown {
+		thp = "unknown"
+	}
+	config.Logger.Info("Detected memory return mode: madvise %s, transparent huge pages %s",
+		tuner.memoryReturn.Madvise, thp)
+	if tuner.memoryReturn.Delayed {
+		config.Logger.Warn("Freed memory may stay resident, GOGC reductions for memory pressure are damped")
+	}
 
 	return tuner, nil
 }
 
 // Start begins the automatic tuning process
 func (t *Tuner) Start() error {
+	return t.StartWithContext(context.Background())
+}
+
+// StartWithContext begins the automatic tuning process like Start, and
+// stops the tuner as Stop does when ctx is done, for tying it to an
+// application's shutdown context
+func (t *Tuner) StartWithContext(ctx context.Context) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
 	if t.running {
 		return fmt.Errorf("tuner is already running")
 	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context is already done: %w", err)
+	}
+
+	// Restarting after Stop needs a fresh context for the workers
+	if t.ctx.Err() != nil {
+		t.ctx, t.cancel = context.WithCancel(context.Background())
+		t.restartMetricsSubscribersLocked()
+	}
 
 	t.running = true
+	t.stopAfter = context.AfterFunc(ctx, func() {
+		if err := t.Stop(); err == nil {
+			t.config.Logger.Info("Stopped GC autotuner: %v", context.Cause(ctx))
+		}
+	})
+	t.startTime = t.now()
 	t.config.Logger.Info("Starting GC autotuner")
 
-	go t.monitorLoop()
+	// With external metrics, cycles run in IngestMetrics instead
+	if !t.config.ExternalMetrics {
+		go t.monitorLoop(t.ctx)
+	}
 
 	return nil
 }
 
-// Stop stops the automatic tuning process
+// Stop stops the automatic tuning process. The tuner can be started again
+// afterwards; its history is kept.
 func (t *Tuner) Stop() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -205,60 +669,431 @@ func (t *Tuner) Stop() error {
 	}
 
 	t.running = false
+	if t.stopAfter != nil {
+		t.stopAfter()
+		t.stopAfter = nil
+	}
 	t.cancel()
+	t.setBallast(0)
+	t.restoreGCLocked()
+	t.restoreMemoryLimitLocked()
+
+	if t.cooperative() {
+		center := (t.config.GOGCBand[0] + t.config.GOGCBand[1]) / 2
+		debug.SetGCPercent(center)
+		t.lastGOGC = center
+		t.config.Logger.Info("Restored GOGC to band center %d", center)
+	}
 	t.config.Logger.Info("Stopping GC autotuner")
 
 	return nil
 }
 
-// GetMetrics returns the current metrics
+// GetMetrics returns the current metrics. The request latency, cluster
+// recommendation, custom metrics and CPU usage are those of the last tuning
+// cycle, since their sources are only consulted once per cycle.
 func (t *Tuner) GetMetrics() Metrics {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
-	return t.collectMetrics()
+	return t.currentMetricsLocked()
+}
+
+// Decisions returns a copy of the decision history, oldest first. The
+// decisions' metrics are copied too, so callers may modify the result.
+func (t *Tuner) Decisions() []TuningDecision {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	decisions := make([]TuningDecision, t.decisionHistory.len())
+	for i, decision := range t.decisionHistory.items() {
+		if decision.Metrics != nil {
+			metrics := copyMetrics(*decision.Metrics)
+			decision.Metrics = &metrics
+		}
+		decisions[i] = decision
+	}
+	return decisions
+}
+
+// GetDecisionHistory returns a copy of the decision history, oldest first.
+// It is the same as Decisions, named like GetMetricsHistory.
+func (t *Tuner) GetDecisionHistory() []TuningDecision {
+	return t.Decisions()
+}
+
+// GetMetricsHistory returns a copy of the retained metrics samples, oldest
+// first, one per tuning cycle
+func (t *Tuner) GetMetricsHistory() []Metrics {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	history := make([]Metrics, t.metricsHistory.len())
+	for i, metrics := range t.metricsHistory.items() {
+		history[i] = copyMetrics(metrics)
+	}
+	return history
 }
 
-// SetOnTuningDecision sets a callback for when tuning decisions are made
+// copyMetrics returns metrics with its own copies of the custom metrics and
+// pauses
+func copyMetrics(metrics Metrics) Metrics {
+	if metrics.Custom != nil {
+		custom := make(map[string]float64, len(metrics.Custom))
+		for name, value := range metrics.Custom {
+			custom[name] = value
+		}
+		metrics.Custom = custom
+	}
+	if metrics.Pauses != nil {
+		metrics.Pauses = append([]time.Duration(nil), metrics.Pauses...)
+	}
+	return metrics
+}
+
+// RangeDecisions calls fn for each decision in the history, oldest first,
+// until fn returns false. The tuner's read lock is held throughout, so fn
+// must not call methods that modify the tuner and should return quickly:
+// tuning cycles wait for the iteration to finish.
+func (t *Tuner) RangeDecisions(fn func(TuningDecision) bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, decision := range t.decisionHistory.items() {
+		if !fn(decision) {
+			return
+		}
+	}
+}
+
+// SetOnTuningDecision sets a callback for when tuning decisions are made. It
+// is the first decision observer, replaced by the next call and removed by
+// nil; observers added with AddDecisionObserver are kept.
 func (t *Tuner) SetOnTuningDecision(callback func(TuningDecision)) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.onTuningDecision = callback
+
+	observers := make([]DecisionObserver, 0, len(t.decisionObs)+1)
+	if callback != nil {
+		observers = append(observers, decisionCallback(callback))
+	}
+	for _, observer := range t.decisionObs {
+		if _, ok := observer.(decisionCallback); !ok {
+			observers = append(observers, observer)
+		}
+	}
+	t.decisionObs = observers
 }
 
-// SetOnMetricsUpdate sets a callback for when metrics are updated
+// SetOnMetricsUpdate sets a callback for when metrics are updated. It is the
+// first metrics observer, replaced by the next call and removed by nil;
+// observers added with AddMetricsObserver are kept. Unlike them it is called
+// by the tuning cycle itself, so it should return quickly.
 func (t *Tuner) SetOnMetricsUpdate(callback func(Metrics)) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.onMetricsUpdate = callback
+
+	// Copy the list, dispatchMetrics reads it without the lock
+	subscribers := make([]*metricsSubscriber, 0, len(t.metricsSubs)+1)
+	if callback != nil {
+		subscribers = append(subscribers, &metricsSubscriber{
+			name:     "on_metrics_update",
+			observer: MetricsObserverFunc(callback),
+			inline:   true,
+		})
+	}
+	for _, sub := range t.metricsSubs {
+		if !sub.inline {
+			subscribers = append(subscribers, sub)
+		}
+	}
+	t.metricsSubs = subscribers
+}
+
+// SetOnRateLimited sets a callback invoked for every applied decision that
+// MaxChangePerInterval held back from its target, after the
+// SetOnTuningDecision callback. A tuner that is frequently rate limited is
+// moving too slowly rather than being held back by its bounds.
+func (t *Tuner) SetOnRateLimited(callback func(TuningDecision)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onRateLimited = callback
+}
+
+// DecisionObserver defines the interface for decision observers
+type DecisionObserver interface {
+	OnDecision(decision TuningDecision)
+}
+
+// DecisionObserverFunc adapts a function to a DecisionObserver
+type DecisionObserverFunc func(TuningDecision)
+
+// OnDecision calls f(decision)
+func (f DecisionObserverFunc) OnDecision(decision TuningDecision) {
+	f(decision)
+}
+
+// decisionCallback is the decision observer set by SetOnTuningDecision
+type decisionCallback func(TuningDecision)
+
+func (c decisionCallback) OnDecision(decision TuningDecision) {
+	c(decision)
+}
+
+// AddDecisionObserver adds an observer that is notified of every applied
+// decision, after the SetOnTuningDecision callback
+func (t *Tuner) AddDecisionObserver(observer DecisionObserver) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.decisionObs = append(t.decisionObs, observer)
+}
+
+// MetricsObserver defines the interface for observers of the metrics
+// collected every cycle
+type MetricsObserver interface {
+	OnMetrics(metrics Metrics)
+}
+
+// MetricsObserverFunc adapts a function to a MetricsObserver
+type MetricsObserverFunc func(Metrics)
+
+// OnMetrics calls f(metrics)
+func (f MetricsObserverFunc) OnMetrics(metrics Metrics) {
+	f(metrics)
+}
+
+// AddMetricsObserver adds an observer that is notified of the metrics
+// collected every tuning cycle. Each observer is called in order from its
+// own goroutine with a queue of 64 samples, so a slow observer never blocks
+// the tuning cycle; samples arriving while its queue is full are dropped and
+// counted in the metrics_observer_dropped stat.
+func (t *Tuner) AddMetricsObserver(observer MetricsObserver) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.metricsSubs = append(t.metricsSubs, newMetricsSubscriber(t.metricsObserverCountLocked(), observer))
+}
+
+// SetDecisionFilter sets a hook that is called with each proposed decision
+// before it is applied. Returning false vetoes the decision; otherwise the
+// returned decision, which may be modified, is applied. The new GOGC is still
+// clamped to MinGOGC/MaxGOGC and rounded to Config.GOGCRounding after
+// filtering. A panicking filter skips the decision.
+func (t *Tuner) SetDecisionFilter(filter func(proposed TuningDecision) (TuningDecision, bool)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.decisionFilter = filter
+}
+
+// AnnotateDecision attaches an application-known outcome (for example "p99
+// SLO met") to a past decision, identified by its ID as returned by
+// /decisions. Decisions that have already rotated out of the history can no
+// longer be annotated.
+func (t *Tuner) AnnotateDecision(id uint64, outcome string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if id == 0 || id > t.lastDecisionID {
+		return fmt.Errorf("unknown decision %d", id)
+	}
+	for i, decision := range t.decisionHistory.items() {
+		if decision.ID == id {
+			t.decisionHistory.update(i, func(decision *TuningDecision) { decision.Outcome = outcome })
+			return nil
+		}
+	}
+	return fmt.Errorf("decision %d has rotated out of the history", id)
+}
+
+// Recommend returns the decision the tuner would make right now, based on a
+// fresh metrics sample and the existing history, without applying it. The
+// sample doesn't run the user callbacks or the CPU sampler; their values are
+// those of the last cycle, see GetMetrics. Metrics and decision history,
+// GOGC and all counters are left untouched, so it can back a
+// manual-approval workflow. A nil decision with a nil error means no change
+// is recommended.
+func (t *Tuner) Recommend() (*TuningDecision, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.metricsHistory.len() < 2 {
+		return nil, fmt.Errorf("not enough metrics history for a recommendation: have %d samples, need 2",
+			t.metricsHistory.len())
+	}
+
+	metrics := t.currentMetricsLocked()
+	decision, _ := t.proposeTuningDecision(metrics)
+	return decision, nil
+}
+
+// GCDebug returns the GC-relevant GODEBUG settings detected at startup
+func (t *Tuner) GCDebug() GCDebugSettings {
+	return t.gcDebug
 }
 
 // GetStats returns statistics about the tuner's performance
 func (t *Tuner) GetStats() map[string]interface{} {
+	return t.stats(0)
+}
+
+// stats returns the tuner's statistics, with metrics_stale computed against
+// staleThreshold (zero means three monitor intervals)
+func (t *Tuner) stats(staleThreshold time.Duration) map[string]interface{} {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
+	sloStatus, sloBreach := t.sloStatusLocked()
+
 	return map[string]interface{}{
-		"total_decisions":  t.totalDecisions,
-		"successful_tunes": t.successfulTunes,
-		"reverted_tunes":   t.revertedTunes,
-		"avg_improvement":  t.avgImprovement,
-		"current_gogc":     debug.SetGCPercent(-1), // Get current without changing
-		"stability_count":  t.stabilityCount,
-		"metrics_history":  len(t.metricsHistory),
-		"decision_history": len(t.decisionHistory),
-		"running":          t.running,
+		"total_decisions":    t.totalDecisions,
+		"successful_tunes":   t.successfulTunes,
+		"evaluated_tunes":    t.evaluatedDecisions,
+		"reverted_tunes":     t.revertedTunes,
+		"vetoed_decisions":   t.vetoedDecisions,
+		"rate_limited":       t.rateLimitedDecisions,
+		"bound_hits_min":     t.minBoundHits,
+		"bound_hits_max":     t.maxBoundHits,
+		"stale_decisions":    t.staleDecisions,
+		"pause_ceiling_hits": t.pauseCeilingHits,
+		"user_func_failures": t.userFuncFailures.snapshot(),
+		"avg_improvement":    t.avgImprovement,
+		"current_gogc":       readGOGC(),
+		"desired_gogc":       t.latestDesiredGOGC(),
+		"stability_count":    t.stabilityCount,
+		"metrics_history":    t.metricsHistory.len(),
+		"decision_history":   t.decisionHistory.len(),
+		"running":            t.running,
+
+		"external_change_detected":    t.externalChangeDetected,
+		"external_changes":            t.externalChanges,
+		"health_score":                t.healthScore(),
+		"qos_class":                   t.qosClass,
+		"ballast_bytes":               t.ballastSize(),
+		"memory_limit_bytes":          t.memLimit,
+		"leak_suspected":              t.leakSuspected,
+		"heap_growth_slope":           t.heapGrowthSlope,
+		"safe_mode":                   t.safeMode,
+		"effective_aggressiveness":    t.aggressiveness(),
+		"last_cycle_time":             t.lastCycleTime,
+		"metrics_stale":               t.metricsStaleLocked(staleThreshold),
+		"seconds_since_last_decision": t.secondsSinceLastDecisionLocked(),
+		"forced_gc_total":             t.forcedGCTotal(),
+		"heap_fragmentation_ratio":    t.latestFragmentation(),
+		"cluster_recommendation":      t.lastRecommendation(),
+		"trend":                       t.pauseTrend(),
+		"decision_categories":         t.categoryCountsLocked(),
+		"metrics_observer_dropped":    t.metricsObserverDroppedLocked(),
+		"slo_status":                  sloStatus,
+		"slo_breach_seconds":          sloBreach.Seconds(),
+		"paused":                      t.paused,
+		"gogc_overridden":             t.overriddenLocked(),
+		"gogc_override_until":         t.overrideUntil,
+	}
+}
+
+// StatusLine returns a one-line summary of the tuner state for CLI output,
+// for example "gogc=180 pause=4.2ms pressure=52% decisions=12 reverts=1
+// stable=3". Pause and pressure come from the latest recorded sample, so it
+// doesn't collect fresh metrics.
+func (t *Tuner) StatusLine() string {
+	t.mu.RLock()
+	latest, _ := t.metricsHistory.last()
+	decisions, reverts, stable := t.totalDecisions, t.revertedTunes, t.stabilityCount
+	t.mu.RUnlock()
+
+	buf := make([]byte, 0, 80)
+	buf = append(buf, "gogc="...)
+	buf = strconv.AppendInt(buf, int64(readGOGC()), 10)
+	buf = append(buf, " pause="...)
+	buf = strconv.AppendFloat(buf, float64(latest.GCPauseTime)/float64(time.Millisecond), 'f', 1, 64)
+	buf = append(buf, "ms pressure="...)
+	buf = strconv.AppendFloat(buf, latest.MemoryPressure*100, 'f', 0, 64)
+	buf = append(buf, "% decisions="...)
+	buf = strconv.AppendInt(buf, decisions, 10)
+	buf = append(buf, " reverts="...)
+	buf = strconv.AppendInt(buf, reverts, 10)
+	buf = append(buf, " stable="...)
+	buf = strconv.AppendInt(buf, int64(stable), 10)
+	return string(buf)
+}
+
+// latestDesiredGOGC returns the unclamped target of the last applied
+// decision, or the current GOGC if no decision has recorded one
+func (t *Tuner) latestDesiredGOGC() int {
+	if t.desiredGOGC == 0 {
+		return readGOGC()
+	}
+	return t.desiredGOGC
+}
+
+// latestFragmentation returns the heap fragmentation of the latest sample
+func (t *Tuner) latestFragmentation() float64 {
+	latest, _ := t.metricsHistory.last()
+	return latest.HeapFragmentation
+}
+
+// forcedGCTotal returns the number of forced GCs as of the latest sample
+func (t *Tuner) forcedGCTotal() uint32 {
+	latest, _ := t.metricsHistory.last()
+	return latest.NumForcedGC
+}
+
+// HealthScore summarizes how well tuning is going as a single value between
+// 0 (struggling) and 1 (healthy), suitable for fleet-wide alerting.
+//
+// The score is a weighted average of three components:
+//   - 40%: mean confidence of the last 10 decisions (1.0 with no decisions)
+//   - 30%: one minus the fraction of decisions that were reverted
+//   - 30%: distance of the current GOGC from the nearest bound, reaching
+//     1.0 once GOGC is at least a quarter of the bound range away
+func (t *Tuner) HealthScore() float64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.healthScore()
+}
+
+// healthScore computes HealthScore; callers must hold the lock
+func (t *Tuner) healthScore() float64 {
+	confidenceScore := 1.0
+	if n := t.decisionHistory.len(); n > 0 {
+		recent := t.decisionHistory.items()
+		if n > 10 {
+			recent = recent[n-10:]
+		}
+		total := 0.0
+		for _, d := range recent {
+			total += d.Confidence
+		}
+		confidenceScore = total / float64(len(recent))
 	}
+
+	revertScore := 1.0
+	if t.totalDecisions > 0 {
+		revertScore = 1.0 - float64(t.revertedTunes)/float64(t.totalDecisions)
+	}
+
+	boundScore := 1.0
+	minGOGC, maxGOGC := t.bounds()
+	if span := maxGOGC - minGOGC; span > 0 {
+		current := readGOGC()
+		distance := current - minGOGC
+		if d := maxGOGC - current; d < distance {
+			distance = d
+		}
+		boundScore = math.Max(0, math.Min(1, 4*float64(distance)/float64(span)))
+	}
+
+	return 0.4*confidenceScore + 0.3*revertScore + 0.3*boundScore
 }
 
-// monitorLoop is the main monitoring and tuning loop
-func (t *Tuner) monitorLoop() {
+// monitorLoop is the main monitoring and tuning loop, running until ctx is
+// done
+func (t *Tuner) monitorLoop(ctx context.Context) {
 	ticker := time.NewTicker(t.config.MonitorInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-t.ctx.Done():
+		case <-ctx.Done():
 			return
 		case <-ticker.C:
 			t.performTuningCycle()
@@ -274,201 +1109,661 @@ func (t *Tuner) performTuningCycle() {
 		}
 	}()
 
+	// Adopt GOGC changes made outside the tuner before deciding
+	t.detectExternalGOGCChange()
+
+	// This cycle's decision is discarded if GOGC is set manually before it
+	// is applied
+	t.mu.RLock()
+	generation := t.gogcGeneration
+	t.mu.RUnlock()
+
 	// Collect current metrics
 	metrics := t.collectMetrics()
 
+	t.runTuningCycle(metrics, generation)
+}
+
+// runTuningCycle records a metrics sample and makes and applies a decision
+// from it. generation is the GOGC generation the sample was taken in.
+func (t *Tuner) runTuningCycle(metrics Metrics, generation uint64) {
 	t.mu.Lock()
-	// Store metrics history
-	t.metricsHistory = append(t.metricsHistory, metrics)
-	if len(t.metricsHistory) > t.maxHistory {
-		t.metricsHistory = t.metricsHistory[1:]
-	}
+	t.applyPendingConfigLocked()
+
+	// Store metrics history, without the pauses only this cycle needs
+	stored := metrics
+	stored.Pauses = nil
+	sloActing, held := t.recordCycleLocked(stored)
 	t.mu.Unlock()
 
-	// Trigger metrics callback
-	if t.onMetricsUpdate != nil {
-		t.onMetricsUpdate(metrics)
+	// Notify the metrics observers
+	t.dispatchMetrics(metrics)
+
+	if held {
+		t.config.Logger.Debug("Skipping tuning while paused")
+		t.markCycleComplete()
+		return
+	}
+
+	t.tune(metrics, sloActing, generation)
+	t.completeCycle(metrics)
+}
+
+// recordCycleLocked stores a cycle's metrics in the history and returns
+// whether SLO mode is acting and whether tuning is held. t.mu must be held
+// for writing.
+func (t *Tuner) recordCycleLocked(metrics Metrics) (sloActing, held bool) {
+	t.metricsHistory.push(metrics)
+	t.historyVersion++
+	return t.updateSLOLocked(metrics), t.tuningHeldLocked()
+}
+
+// tune runs a cycle's metrics through safe mode, the evaluation of the last
+// decision, the SLO and pause ceiling gates and the decision logic, and
+// processes the resulting decision. Both live cycles and simulations use it.
+func (t *Tuner) tune(metrics Metrics, sloActing bool, generation uint64) {
+	if t.handleLeak(metrics) {
+		t.config.Logger.Debug("Skipping tuning in safe mode")
+		return
+	}
+
+	if revert := t.evaluateLastDecision(metrics); revert != nil {
+		t.processDecision(*revert)
+		return
+	}
+
+	if toggle, off := t.gcOffDecision(metrics); toggle != nil || off {
+		if toggle != nil {
+			toggle.generation = generation
+			t.processDecision(*toggle)
+		}
+		return
+	}
+
+	if !sloActing && !t.pauseCeilingBreached(metrics) {
+		t.config.Logger.Debug("Skipping tuning while the GC pause SLO is met")
+		return
 	}
 
 	// Make tuning decision
 	decision := t.makeTuningDecision(metrics)
 
 	if decision != nil {
-		t.applyTuningDecision(*decision)
+		decision.generation = generation
+		t.processDecision(*decision)
+	}
+}
+
+// completeCycle adjusts the ballast and soft memory limit for a cycle's
+// metrics, after any GOGC change, and records the cycle's completion
+func (t *Tuner) completeCycle(metrics Metrics) {
+	t.adjustBallast(metrics)
+	t.adjustMemoryLimit(metrics)
+	t.markCycleComplete()
+}
+
+// markCycleComplete records the completion time of a tuning cycle
+func (t *Tuner) markCycleComplete() {
+	t.mu.Lock()
+	t.lastCycleTime = t.now()
+	t.mu.Unlock()
+}
+
+// processDecision runs a proposed decision through the decision filter and
+// applies it unless it was vetoed
+func (t *Tuner) processDecision(decision TuningDecision) {
+	t.mu.RLock()
+	filter := t.decisionFilter
+	t.mu.RUnlock()
+
+	if filter != nil {
+		generation := decision.generation
+		var filtered TuningDecision
+		var ok bool
+		if !t.safeCall("decision_filter", func() { filtered, ok = filter(decision) }) {
+			t.config.Logger.Warn("Skipped GC tuning after the decision filter panicked: %s", decision.Reason)
+			return
+		}
+		if !ok {
+			t.mu.Lock()
+			t.vetoedDecisions++
+			t.mu.Unlock()
+			t.config.Logger.Info("Skipped GC tuning vetoed by decision filter: %s", decision.Reason)
+			return
+		}
+		decision = filtered
+		decision.generation = generation
+	}
+
+	if bounded := t.clampGOGC(decision.NewGOGC); bounded != decision.NewGOGC && !decision.memoryLimitChange() && decision.NewGOGC != gcOff {
+		decision.BoundHit = t.boundHit(decision.NewGOGC)
+		decision.NewGOGC = bounded
+		decision.ClampedBy = ClampBounds
+	}
+
+	// Snap the filtered target to the configured increment, unless that
+	// would cancel or reverse the change. Reverts restore the exact value.
+	if !decision.memoryLimitChange() && decision.Category != CategoryRevert && decision.NewGOGC != gcOff {
+		rounded := t.roundGOGC(decision.NewGOGC)
+		if rounded != decision.OldGOGC && (rounded > decision.OldGOGC) == (decision.NewGOGC > decision.OldGOGC) {
+			decision.NewGOGC = rounded
+		}
+	}
+
+	t.applyTuningDecision(decision)
+}
+
+// cooperative reports whether the tuner is restricted to Config.GOGCBand
+func (t *Tuner) cooperative() bool {
+	return t.config.GOGCBand != [2]int{}
+}
+
+// bounds returns the range of GOGC values the tuner may set: the GOGC band
+// in cooperative mode, MinGOGC/MaxGOGC otherwise
+func (t *Tuner) bounds() (int, int) {
+	if t.cooperative() {
+		return t.config.GOGCBand[0], t.config.GOGCBand[1]
+	}
+	return t.config.MinGOGC, t.config.MaxGOGC
+}
+
+// clampGOGC clamps a GOGC value into the tuner's bounds
+func (t *Tuner) clampGOGC(gogc int) int {
+	minGOGC, maxGOGC := t.bounds()
+	if gogc < minGOGC {
+		return minGOGC
+	}
+	if gogc > maxGOGC {
+		return maxGOGC
+	}
+	return gogc
+}
+
+// roundGOGC rounds a GOGC value within bounds to the nearest multiple of
+// Config.GOGCRounding that is also within bounds, or returns it unchanged
+// when rounding is disabled or no multiple is within bounds
+func (t *Tuner) roundGOGC(gogc int) int {
+	step := t.config.GOGCRounding
+	if step <= 1 {
+		return gogc
+	}
+
+	minGOGC, maxGOGC := t.bounds()
+	rounded := (gogc + step/2) / step * step
+	if rounded > maxGOGC {
+		rounded -= step
+	}
+	if rounded < minGOGC {
+		rounded += step
+	}
+	if rounded < minGOGC || rounded > maxGOGC {
+		return gogc
+	}
+	return rounded
+}
+
+// boundHit returns the bound clampGOGC would clamp a GOGC value to
+func (t *Tuner) boundHit(gogc int) BoundHit {
+	minGOGC, maxGOGC := t.bounds()
+	if gogc < minGOGC {
+		return BoundHitMin
+	}
+	if gogc > maxGOGC {
+		return BoundHitMax
+	}
+	return BoundHitNone
+}
+
+// detectExternalGOGCChange compares the live GOGC with the value the tuner
+// last set. A mismatch means another actor changed GOGC, so the live value
+// becomes the new baseline for subsequent decisions.
+func (t *Tuner) detectExternalGOGCChange() {
+	current := readGOGC()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if current == t.lastGOGC {
+		return
+	}
+
+	if minGOGC, maxGOGC := t.bounds(); t.cooperative() && (current < minGOGC || current > maxGOGC) {
+		t.config.Logger.Warn("GOGC changed externally from %d to %d, outside the granted band [%d, %d]; "+
+			"the next decision will move it back into the band", t.lastGOGC, current, minGOGC, maxGOGC)
+	} else {
+		t.config.Logger.Warn("GOGC changed externally from %d to %d, adopting as new baseline",
+			t.lastGOGC, current)
 	}
+
+	t.lastGOGC = current
+	t.gogcGeneration++
+	t.externalChangeDetected = true
+	t.externalChanges++
+	t.resetStabilityLocked()
 }
 
-// collectMetrics gathers all relevant metrics for tuning decisions
+// collectMetrics gathers all relevant metrics for tuning decisions. It runs
+// the user callbacks and advances the CPU sampler, so only the tuning cycle
+// calls it; readers sample without them, see currentMetricsLocked.
 func (t *Tuner) collectMetrics() Metrics {
+	return t.sampleMetrics(true)
+}
+
+// sampleMetrics reads the runtime and container metrics. The latency
+// provider, recommendation source, metric hooks and CPU sampler only run
+// for a tuning cycle.
+func (t *Tuner) sampleMetrics(cycle bool) Metrics {
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
 
-	var gcStats debug.GCStats
-	debug.ReadGCStats(&gcStats)
-
 	metrics := Metrics{
-		HeapSize:    m.HeapSys,
-		HeapAlloc:   m.HeapAlloc,
-		HeapInuse:   m.HeapInuse,
-		NextGC:      m.NextGC,
-		NumGC:       m.NumGC,
-		CurrentGOGC: debug.SetGCPercent(-1), // Get current without changing
-		Timestamp:   time.Now(),
+		HeapSize:      m.HeapSys,
+		HeapAlloc:     m.HeapAlloc,
+		HeapInuse:     m.HeapInuse,
+		NextGC:        m.NextGC,
+		NumGC:         m.NumGC,
+		NumForcedGC:   m.NumForcedGC,
+		TotalAlloc:    m.TotalAlloc,
+		CurrentGOGC:   readGOGC(),
+		GCCPUFraction: m.GCCPUFraction,
+		Timestamp:     t.now(),
 	}
+	metrics.HeapFragmentation = heapFragmentation(m.HeapSys, m.HeapInuse)
+	metrics.GCCPUSeconds, metrics.TotalCPUSeconds = readRuntimeCPUSeconds()
 
-	// Calculate GC pause time (average of recent pauses)
-	if len(gcStats.Pause) > 0 {
-		var totalPause time.Duration
-		count := len(gcStats.Pause)
-		if count > 10 {
-			count = 10 // Use last 10 pauses
-		}
-		for i := 0; i < count; i++ {
-			totalPause += gcStats.Pause[i]
+	runtimeLive, runtimeOK := readRuntimeLiveHeap()
+	metrics.LiveHeap = liveHeapBytes(t.config.LiveHeapSource, &m, runtimeLive, runtimeOK)
+
+	// The ballast is never touched, so it doesn't count as memory usage
+	if ballast := t.ballastSize(); metrics.LiveHeap > ballast {
+		metrics.LiveHeap -= ballast
+	}
+
+	if cycle {
+		t.mu.RLock()
+		provider := t.latencyProvider
+		t.mu.RUnlock()
+		if provider != nil {
+			t.safeCall("latency_provider", func() { metrics.RequestLatency = provider() })
 		}
-		metrics.GCPauseTime = totalPause / time.Duration(count)
+		metrics.RecommendedGOGC = t.readRecommendation()
+		metrics.Custom = t.collectCustomMetrics()
 	}
 
-	// Calculate GC frequency
-	if len(t.metricsHistory) > 0 {
-		prev := t.metricsHistory[len(t.metricsHistory)-1]
-		timeDiff := metrics.Timestamp.Sub(prev.Timestamp).Seconds()
-		if timeDiff > 0 {
-			gcDiff := float64(metrics.NumGC - prev.NumGC)
-			metrics.GCFrequency = gcDiff / timeDiff
+	// Calculate GC pause time (average of the last 10 pauses) and the tail
+	metrics.GCPauseTime = recentPauseAverage(&m, 10)
+	metrics.GCPauseP95, metrics.GCPauseP99 = recentPausePercentiles(&m)
+
+	// Calculate GC frequency and allocation rate
+	if prev, ok := t.metricsHistory.last(); ok {
+		metrics.GCFrequency = gcFrequency(prev, metrics)
+		metrics.AllocRate = allocRate(prev, metrics)
+		if fraction, ok := gcCPUFraction(prev, metrics); ok {
+			metrics.GCCPUFraction = fraction
 		}
+		metrics.Pauses = newPauses(&m, prev.NumGC, true)
+	} else {
+		metrics.Pauses = newPauses(&m, 0, false)
 	}
 
+	t.updateLeakMetrics(&metrics)
+
 	// Add container resource information
 	if t.containerResources != nil {
 		metrics.ContainerMemLimit = t.containerResources.MemoryLimit
 		metrics.ContainerCPULimit = t.containerResources.CPULimit
-		if t.containerResources.MemoryLimit > 0 {
-			metrics.MemoryPressure = float64(metrics.HeapInuse) / float64(t.containerResources.MemoryLimit)
+		metrics.ContainerCPUBurst = t.containerResources.CPUBurst
+	}
+
+	// The first cycle only takes the initial CPU usage reading
+	if cycle {
+		if usage, _, err := t.cpuSampler.Sample(); err == nil {
+			metrics.CPUUsage = usage
 		}
 	}
 
-	// Calculate memory usage and pressure
+	if psi, err := readCgroupV2PSI(t.cgroupV2Paths(), "memory"); err == nil {
+		metrics.MemoryPSI = psi.SomeAvg10
+		metrics.MemoryPSIAvailable = true
+	}
+
+	// Calculate memory usage and pressure against both the container limit
+	// and the MemoryLimitPercent threshold
 	if metrics.ContainerMemLimit > 0 {
-		metrics.MemoryUsage = metrics.HeapInuse
-		metrics.MemoryLimit = uint64(float64(metrics.ContainerMemLimit) * t.config.MemoryLimitPercent)
-		metrics.MemoryPressure = float64(metrics.MemoryUsage) / float64(metrics.MemoryLimit)
+		metrics.MemoryUsage = t.memoryUsage(metrics.LiveHeap)
+		metrics.MemoryLimit = t.memoryBaseline(t.memoryCeiling(metrics.ContainerMemLimit))
+		metrics.MemoryPressureRaw = float64(metrics.MemoryUsage) / float64(metrics.ContainerMemLimit)
+		if metrics.MemoryLimit > 0 {
+			metrics.MemoryPressureAdjusted = float64(metrics.MemoryUsage) / float64(metrics.MemoryLimit)
+		}
+		metrics.MemoryPressure = metrics.MemoryPressureAdjusted
 	}
 
 	return metrics
 }
 
+// memoryUsage returns the memory counted against the container limit. In a
+// container this is the cgroup's working set (memory.current, or
+// memory.usage_in_bytes on cgroup v1, minus the inactive page cache), which
+// unlike the heap includes stacks, off-heap allocations and mapped files,
+// all of which count towards OOM kills. Without cgroup data it falls back
+// to liveHeap.
+func (t *Tuner) memoryUsage(liveHeap uint64) uint64 {
+	if t.containerResources == nil || !t.containerResources.IsContainer {
+		return liveHeap
+	}
+	if usage, err := getCurrentMemoryUsage(t.containerResources.cgroup); err == nil && usage > 0 {
+		return usage
+	}
+	return liveHeap
+}
+
+// cgroupV2Paths returns the cgroup v2 location resolved when the container
+// resources were detected
+func (t *Tuner) cgroupV2Paths() cgroupV2Paths {
+	if t.containerResources == nil {
+		return cgroupV2Paths{}
+	}
+	return t.containerResources.cgroup
+}
+
+// memoryCeiling returns the container memory limit pressure is computed
+// against: memory.high when Config.UseMemoryHigh is set and it is lower than
+// the hard limit, otherwise limit
+func (t *Tuner) memoryCeiling(limit uint64) uint64 {
+	if t.config.UseMemoryHigh && t.containerResources != nil {
+		if high := t.containerResources.MemoryHigh; high > 0 && high < limit {
+			return high
+		}
+	}
+	return limit
+}
+
+// memoryBaseline returns the memory level that counts as full pressure.
+//
+// For Guaranteed and unknown QoS this is MemoryLimitPercent of the limit.
+// Burstable pods are guaranteed only their request, and everything between
+// request and limit is opportunistic, so the percentage is applied to that
+// burst headroom on top of the request. This lets a Burstable pod use more of
+// its limit before the tuner reacts to pressure.
+func (t *Tuner) memoryBaseline(limit uint64) uint64 {
+	if t.qosClass == QoSClassBurstable && t.memoryRequest < limit {
+		headroom := float64(limit - t.memoryRequest)
+		return t.memoryRequest + uint64(headroom*t.config.MemoryLimitPercent)
+	}
+	return uint64(float64(limit) * t.config.MemoryLimitPercent)
+}
+
+// aggressiveness returns the tuning aggressiveness in effect: the configured
+// value times the ramp boost, see aggressivenessBoost. Guaranteed pods are
+// tuned more conservatively since they shouldn't run close to the edge of
+// their limit.
+func (t *Tuner) aggressiveness() float64 {
+	aggressiveness := t.config.TuningAggressiveness * t.aggressivenessBoost() * t.sloBoost()
+	if t.qosClass == QoSClassGuaranteed {
+		return aggressiveness * 0.75
+	}
+	return aggressiveness
+}
+
 // makeTuningDecision analyzes metrics and decides whether to adjust GOGC
 func (t *Tuner) makeTuningDecision(metrics Metrics) *TuningDecision {
+	decision, stable := t.proposeTuningDecision(metrics)
+	if stable {
+		t.mu.Lock()
+		t.recordStableCycleLocked()
+		t.mu.Unlock()
+	}
+	return decision
+}
+
+// proposeTuningDecision runs the decision logic without modifying any tuner
+// state. stable reports whether the target was within the minimum change
+// threshold of the current GOGC.
+func (t *Tuner) proposeTuningDecision(metrics Metrics) (decision *TuningDecision, stable bool) {
 	currentGOGC := metrics.CurrentGOGC
 
+	// A pause above the ceiling is an emergency, act without the usual checks
+	if t.pauseCeilingBreached(metrics) {
+		return t.pauseCeilingDecision(metrics), false
+	}
+
 	// Check if we have enough data to make a decision
-	if len(t.metricsHistory) < 2 {
-		return nil
+	if t.metricsHistory.len() < 2 {
+		return nil, false
 	}
 
 	// Anti-oscillation check
 	if t.shouldSkipDueToOscillation() {
 		t.config.Logger.Debug("Skipping tuning due to oscillation prevention")
-		return nil
+		return nil, false
 	}
 
 	// Calculate target GOGC based on multiple factors
-	targetGOGC := t.calculateTargetGOGC(metrics)
+	proposal, ok := t.propose(metrics)
+	if !ok {
+		t.config.Logger.Warn("Skipped GC tuning after the tuning strategy panicked")
+		return nil, false
+	}
+	targetGOGC, category := proposal.GOGC, proposal.Category
+	targetGOGC, latencyBand := t.applyLatencyBias(targetGOGC, currentGOGC)
+	localGOGC := targetGOGC
+	targetGOGC = t.applyRecommendationBias(targetGOGC, metrics)
+	desiredGOGC := targetGOGC
+
+	// Lowering GOGC doesn't help against a leak, it only burns CPU
+	if metrics.LeakSuspected && targetGOGC < currentGOGC {
+		t.config.Logger.Debug("Not lowering GOGC while a memory leak is suspected")
+		return nil, false
+	}
+
+	// Don't redo a change that was just reverted
+	if t.revertHeld(currentGOGC, targetGOGC) {
+		t.config.Logger.Debug("Not retrying a reverted GOGC change within the stabilization window")
+		return nil, false
+	}
 
 	// Check if change is significant enough
 	change := targetGOGC - currentGOGC
 	if abs(change) < 10 { // Minimum change threshold
-		t.stabilityCount++
-		return nil
+		return nil, true
 	}
 
 	// Limit the change per interval
+	clampedBy := ClampNone
 	if abs(change) > t.config.MaxChangePerInterval {
 		if change > 0 {
 			targetGOGC = currentGOGC + t.config.MaxChangePerInterval
 		} else {
 			targetGOGC = currentGOGC - t.config.MaxChangePerInterval
 		}
+		clampedBy = ClampRateLimit
 	}
 
 	// Ensure bounds
-	if targetGOGC < t.config.MinGOGC {
-		targetGOGC = t.config.MinGOGC
-	}
-	if targetGOGC > t.config.MaxGOGC {
-		targetGOGC = t.config.MaxGOGC
+	boundHit := t.boundHit(targetGOGC)
+	if bounded := t.clampGOGC(targetGOGC); bounded != targetGOGC {
+		targetGOGC = bounded
+		clampedBy = ClampBounds
 	}
 
-	// Calculate confidence based on metrics stability and clarity
-	confidence := t.calculateConfidence(metrics)
+	// Snap to the configured increment, which may leave nothing to change
+	targetGOGC = t.roundGOGC(targetGOGC)
+	if targetGOGC == currentGOGC {
+		return nil, true
+	}
 
 	// Only proceed if confidence is high enough
+	confidence := proposal.Confidence
 	if confidence < 0.6 {
 		t.config.Logger.Debug("Skipping tuning due to low confidence: %.2f", confidence)
-		return nil
+		return nil, false
 	}
 
-	reason := t.buildReasonString(metrics, currentGOGC, targetGOGC)
+	reason := describeChange(currentGOGC, targetGOGC, proposal.Reason)
+	if latencyBand != nil {
+		reason += fmt.Sprintf(" (request latency lowest at GOGC %d-%d: %.2fms)",
+			latencyBand.Low, latencyBand.High, float64(latencyBand.MeanLatency)/1e6)
+	}
+	if desiredGOGC != localGOGC {
+		reason += fmt.Sprintf(" (local target %d biased towards cluster recommendation %d)", localGOGC, metrics.RecommendedGOGC)
+	}
+	switch clampedBy {
+	case ClampRateLimit:
+		reason += fmt.Sprintf(" (target %d limited by MaxChangePerInterval %d)", desiredGOGC, t.config.MaxChangePerInterval)
+	case ClampBounds:
+		reason += fmt.Sprintf(" (target %d clamped to bounds)", desiredGOGC)
+	}
 
-	decision := &TuningDecision{
-		OldGOGC:    currentGOGC,
-		NewGOGC:    targetGOGC,
-		Reason:     reason,
-		Confidence: confidence,
-		Timestamp:  time.Now(),
-		Metrics:    &metrics,
+	decision = &TuningDecision{
+		OldGOGC:     currentGOGC,
+		NewGOGC:     targetGOGC,
+		DesiredGOGC: desiredGOGC,
+		ClampedBy:   clampedBy,
+		BoundHit:    boundHit,
+		Category:    category,
+		Reason:      reason,
+		Confidence:  confidence,
+		Timestamp:   t.now(),
+		Metrics:     &metrics,
 	}
 
-	return decision
+	return decision, false
 }
 
 // calculateTargetGOGC computes the optimal GOGC value based on current metrics
 func (t *Tuner) calculateTargetGOGC(metrics Metrics) int {
+	target, _ := t.calculateTarget(metrics)
+	return target
+}
+
+// calculateTarget computes the optimal GOGC value and the category of the
+// factor that moved it most
+func (t *Tuner) calculateTarget(metrics Metrics) (int, DecisionCategory) {
 	currentGOGC := metrics.CurrentGOGC
+	aggressiveness := t.aggressiveness()
 
 	// Factor 1: Latency-based adjustment
+	// (skipped when GODEBUG settings dominate pause times rather than GOGC,
+	// or before any pause has been recorded)
 	latencyFactor := 1.0
-	if metrics.GCPauseTime > t.config.TargetLatency {
-		// Pause time too high, increase GOGC to reduce GC frequency
-		ratio := float64(metrics.GCPauseTime) / float64(t.config.TargetLatency)
-		latencyFactor = 1.0 + (ratio-1.0)*t.config.TuningAggressiveness
-	} else {
-		// Pause time acceptable, might be able to decrease GOGC for better memory usage
-		ratio := float64(t.config.TargetLatency) / float64(metrics.GCPauseTime)
-		latencyFactor = 1.0 - (ratio-1.0)*t.config.TuningAggressiveness*0.5
+	pause := t.tuningPause(metrics)
+	if t.gcDebug.PauseTuningMeaningful() && pause > 0 && !t.pauseOnTarget(pause) {
+		if pause > t.config.TargetLatency {
+			// Pause time too high, increase GOGC to reduce GC frequency
+			ratio := float64(pause) / float64(t.config.TargetLatency)
+			latencyFactor = 1.0 + (ratio-1.0)*aggressiveness
+		} else {
+			// Pause time acceptable, might be able to decrease GOGC for better memory usage
+			ratio := float64(t.config.TargetLatency) / float64(pause)
+			latencyFactor = 1.0 - (ratio-1.0)*aggressiveness*0.5
+		}
 	}
 
 	// Factor 2: Memory pressure adjustment
 	memoryFactor := 1.0
 	if metrics.MemoryPressure > 0.8 {
 		// High memory pressure, decrease GOGC to collect more frequently
-		memoryFactor = 1.0 - (metrics.MemoryPressure-0.8)*2.0*t.config.TuningAggressiveness
+		memoryFactor = 1.0 - (metrics.MemoryPressure-0.8)*2.0*aggressiveness
 	} else if metrics.MemoryPressure < 0.4 {
 		// Low memory pressure, can increase GOGC for better performance
-		memoryFactor = 1.0 + (0.4-metrics.MemoryPressure)*1.5*t.config.TuningAggressiveness
+		memoryFactor = 1.0 + (0.4-metrics.MemoryPressure)*1.5*aggressiveness
+	}
+
+	// When freed memory isn't promptly returned to the OS, collecting more
+	// often barely lowers RSS, so only a fraction of the reduction is kept
+	if t.memoryReturn.Delayed && memoryFactor < 1.0 {
+		memoryFactor = 1.0 - (1.0-memoryFactor)*lazyReturnDamping
+	}
+
+	// With a soft memory limit as the backstop, memory pressure no longer
+	// lowers GOGC: the runtime collects harder as the limit nears
+	if t.config.EnableMemoryLimit && metrics.ContainerMemLimit > 0 && memoryFactor < 1.0 {
+		memoryFactor = 1.0
+	}
+
+	// Memory PSI, when available, is authoritative for whether memory is
+	// under pressure: stalls trigger a prompt reduction that bypasses the
+	// other factors and smoothing, and without stalls the usage ratio may
+	// only raise GOGC
+	if metrics.MemoryPSIAvailable {
+		if metrics.MemoryPSI >= psiStallThreshold {
+			return int(float64(currentGOGC) * psiReductionFactor(metrics.MemoryPSI)), CategoryMemory
+		}
+		if memoryFactor < 1.0 {
+			memoryFactor = 1.0
+		}
 	}
 
 	// Factor 3: GC frequency adjustment
 	frequencyFactor := 1.0
 	if metrics.GCFrequency > 2.0 {
 		// Too frequent GCs, increase GOGC
-		frequencyFactor = 1.0 + (metrics.GCFrequency-2.0)*0.1*t.config.TuningAggressiveness
+		frequencyFactor = 1.0 + (metrics.GCFrequency-2.0)*0.1*aggressiveness
 	} else if metrics.GCFrequency < 0.1 {
 		// Very infrequent GCs, might decrease GOGC
-		frequencyFactor = 1.0 - (0.1-metrics.GCFrequency)*0.5*t.config.TuningAggressiveness
+		frequencyFactor = 1.0 - (0.1-metrics.GCFrequency)*0.5*aggressiveness
+	}
+
+	// Factor 4: CPU overhead adjustment
+	// When the process is short on CPU and much of it goes to the GC,
+	// increase GOGC to collect less often
+	cpuFactor, cpuWeight := 1.0, 0.0
+	if t.config.CPUAwareness > 0 && metrics.CPUUsage > cpuHighUsage && metrics.GCCPUFraction > gcCPUFractionThreshold {
+		ratio := metrics.GCCPUFraction / gcCPUFractionThreshold
+		cpuFactor = 1.0 + (ratio-1.0)*aggressiveness
+		cpuWeight = t.config.CPUAwareness
 	}
 
-	// Combine factors
-	combinedFactor := (latencyFactor + memoryFactor + frequencyFactor) / 3.0
+	// Factor 5: Allocation rate adjustment
+	// Allocation drives GC frequency, so while pauses are acceptable a
+	// rising allocation rate raises GOGC before the GCs pile up
+	allocFactor, allocWeight := 1.0, 0.0
+	if rise := t.allocRateRise(metrics); rise > 0 {
+		allocFactor = 1.0 + math.Min(rise, 1.0)*0.5*aggressiveness
+		allocWeight = 1.0
+	}
+
+	// Combine factors, the CPU factor weighted by CPUAwareness and the
+	// allocation factor by 1, each only while active
+	combinedFactor := (latencyFactor + memoryFactor + frequencyFactor + cpuFactor*cpuWeight + allocFactor*allocWeight) /
+		(3.0 + cpuWeight + allocWeight)
 
 	// Apply exponential smoothing to avoid rapid changes
-	alpha := 0.3 // Smoothing factor
-	smoothedFactor := alpha*combinedFactor + (1-alpha)*1.0
+	alpha := t.config.FactorSmoothingAlpha
+	if alpha == 0 {
+		alpha = defaultFactorSmoothingAlpha
+	}
+	smoothedFactor := sanitizeFactor(alpha*combinedFactor + (1-alpha)*1.0)
 
 	targetGOGC := int(float64(currentGOGC) * smoothedFactor)
 
-	return targetGOGC
+	// GC CPU overhead and allocation come from or cause collecting often,
+	// so the CPU and allocation factors count towards the frequency category
+	return targetGOGC, dominantCategory(latencyFactor, memoryFactor, frequencyFactor*cpuFactor*allocFactor)
+}
+
+const (
+	// minFactor and maxFactor bound the multiplicative GOGC adjustment of a
+	// single cycle
+	minFactor = 0.1
+	maxFactor = 10.0
+)
+
+// sanitizeFactor clamps a GOGC adjustment factor to [minFactor, maxFactor].
+// Extreme metrics, such as a zero pause time, can make the factor infinite
+// or NaN, and converting those to int is implementation-defined; NaN leaves
+// GOGC unchanged.
+func sanitizeFactor(factor float64) float64 {
+	switch {
+	case math.IsNaN(factor):
+		return 1
+	case factor < minFactor:
+		return minFactor
+	case factor > maxFactor:
+		return maxFactor
+	}
+	return factor
 }
 
 // calculateConfidence determines confidence in the tuning decision
@@ -476,15 +1771,15 @@ func (t *Tuner) calculateConfidence(metrics Metrics) float64 {
 	confidence := 1.0
 
 	// Reduce confidence if we don't have enough history
-	if len(t.metricsHistory) < 5 {
+	if t.metricsHistory.len() < 5 {
 		confidence *= 0.7
 	}
 
 	// Reduce confidence if metrics are unstable
-	if len(t.metricsHistory) >= 3 {
-		recent := t.metricsHistory[len(t.metricsHistory)-3:]
+	if history := t.metricsHistory.items(); len(history) >= 3 {
+		recent := history[len(history)-3:]
 		pauseVariation := calculateVariation(recent, func(m Metrics) float64 {
-			return float64(m.GCPauseTime)
+			return float64(t.tuningPause(m))
 		})
 
 		if pauseVariation > 0.3 {
@@ -493,7 +1788,7 @@ func (t *Tuner) calculateConfidence(metrics Metrics) float64 {
 	}
 
 	// Reduce confidence if we're near limits
-	if metrics.CurrentGOGC <= t.config.MinGOGC+20 || metrics.CurrentGOGC >= t.config.MaxGOGC-20 {
+	if t.nearBound(metrics.CurrentGOGC) {
 		confidence *= 0.9
 	}
 
@@ -505,34 +1800,87 @@ func (t *Tuner) calculateConfidence(metrics Metrics) float64 {
 	return confidence
 }
 
+// pauseOnTarget reports whether pause is within
+// Config.TargetLatencyTolerance of TargetLatency
+func (t *Tuner) pauseOnTarget(pause time.Duration) bool {
+	tolerance := t.config.TargetLatencyTolerance
+	switch {
+	case tolerance == 0:
+		tolerance = time.Duration(float64(t.config.TargetLatency) * defaultTargetLatencyTolerance)
+	case tolerance < 0:
+		tolerance = 0
+	}
+	deviation := pause - t.config.TargetLatency
+	return deviation <= tolerance && deviation >= -tolerance
+}
+
+// nearBound reports whether gogc is within Config.BoundProximityMargin of
+// the range from either bound
+func (t *Tuner) nearBound(gogc int) bool {
+	fraction := t.config.BoundProximityMargin
+	if fraction == 0 {
+		fraction = defaultBoundProximityMargin
+	}
+	minGOGC, maxGOGC := t.bounds()
+	margin := float64(maxGOGC-minGOGC) * fraction
+	return float64(gogc) <= float64(minGOGC)+margin || float64(gogc) >= float64(maxGOGC)-margin
+}
+
 // buildReasonString creates a human-readable reason for the tuning decision
 func (t *Tuner) buildReasonString(metrics Metrics, oldGOGC, newGOGC int) string {
+	return describeChange(oldGOGC, newGOGC, joinStrings(t.reasonFactors(metrics), ", "))
+}
+
+// reasonFactors lists the conditions in metrics that call for a GOGC change
+func (t *Tuner) reasonFactors(metrics Metrics) []string {
 	reasons := []string{}
 
-	if metrics.GCPauseTime > t.config.TargetLatency {
-		reasons = append(reasons, fmt.Sprintf("GC pause %.2fms > target %.2fms",
-			float64(metrics.GCPauseTime)/1e6, float64(t.config.TargetLatency)/1e6))
+	if pause := t.tuningPause(metrics); pause > t.config.TargetLatency && !t.pauseOnTarget(pause) {
+		label := "GC pause"
+		if t.config.PauseTarget == PauseStatisticP95 || t.config.PauseTarget == PauseStatisticP99 {
+			label += " " + string(t.config.PauseTarget)
+		}
+		reasons = append(reasons, fmt.Sprintf("%s %.2fms > target %.2fms",
+			label, float64(pause)/1e6, float64(t.config.TargetLatency)/1e6))
 	}
 
 	if metrics.MemoryPressure > 0.8 {
 		reasons = append(reasons, fmt.Sprintf("High memory pressure %.1f%%", metrics.MemoryPressure*100))
 	}
 
+	if metrics.MemoryPSIAvailable && metrics.MemoryPSI >= psiStallThreshold {
+		reasons = append(reasons, fmt.Sprintf("Memory stalls %.1f%% (PSI)", metrics.MemoryPSI))
+	}
+
 	if metrics.GCFrequency > 2.0 {
 		reasons = append(reasons, fmt.Sprintf("High GC frequency %.1f/sec", metrics.GCFrequency))
 	}
 
+	if t.config.CPUAwareness > 0 && metrics.CPUUsage > cpuHighUsage && metrics.GCCPUFraction > gcCPUFractionThreshold {
+		reasons = append(reasons, fmt.Sprintf("GC using %.1f%% of CPU at %.1f%% CPU usage",
+			metrics.GCCPUFraction*100, metrics.CPUUsage*100))
+	}
+
+	if rise := t.allocRateRise(metrics); rise > 0 {
+		reasons = append(reasons, fmt.Sprintf("Allocation rate rising to %.1fMB/s (+%.0f%%)",
+			float64(metrics.AllocRate)/1e6, rise*100))
+	}
+
+	return reasons
+}
+
+// describeChange describes a GOGC change and what drove it, if known
+func describeChange(oldGOGC, newGOGC int, because string) string {
 	direction := "increasing"
 	if newGOGC < oldGOGC {
 		direction = "decreasing"
 	}
 
-	if len(reasons) == 0 {
+	if because == "" {
 		return fmt.Sprintf("Optimizing performance by %s GOGC %d -> %d", direction, oldGOGC, newGOGC)
 	}
 
-	return fmt.Sprintf("%s GOGC %d -> %d due to: %s",
-		direction, oldGOGC, newGOGC, joinStrings(reasons, ", "))
+	return fmt.Sprintf("%s GOGC %d -> %d due to: %s", direction, oldGOGC, newGOGC, because)
 }
 
 // applyTuningDecision applies the tuning decision and records it
@@ -540,37 +1888,93 @@ func (t *Tuner) applyTuningDecision(decision TuningDecision) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	// Apply the GOGC change
-	oldGOGC := debug.SetGCPercent(decision.NewGOGC)
-	decision.OldGOGC = oldGOGC // Ensure we have the actual old value
+	if decision.generation != 0 && decision.generation != t.gogcGeneration {
+		t.staleDecisions++
+		t.config.Logger.Info("Discarded GC tuning decision proposed before GOGC was set manually: %s", decision.Reason)
+		return
+	}
+	if t.tuningHeldLocked() {
+		t.config.Logger.Info("Discarded GC tuning decision proposed before tuning was paused: %s", decision.Reason)
+		return
+	}
 
-	// Record the decision
-	t.decisionHistory = append(t.decisionHistory, decision)
-	if len(t.decisionHistory) > t.maxDecisions {
-		t.decisionHistory = t.decisionHistory[1:]
+	// Apply the GOGC and memory limit changes
+	limitOnly := decision.memoryLimitChange()
+	oldGOGC := decision.OldGOGC
+	if !limitOnly {
+		oldGOGC = t.setGCPercent(decision.NewGOGC)
+		decision.OldGOGC = oldGOGC // Ensure we have the actual old value
 	}
+	if decision.NewMemLimit != 0 {
+		decision.OldMemLimit = t.memLimit
+		t.applyMemoryLimitLocked(decision.NewMemLimit)
+	}
+
+	// Record the decision
+	t.lastDecisionID++
+	decision.ID = t.lastDecisionID
+	t.decisionHistory.push(decision)
 
 	t.totalDecisions++
-	t.lastGOGC = decision.NewGOGC
-	t.stabilityCount = 0
+	if decision.ClampedBy == ClampRateLimit {
+		t.rateLimitedDecisions++
+	}
+	if decision.Priority == PriorityHigh {
+		t.pauseCeilingHits++
+	}
+	if decision.Category == CategoryRevert {
+		t.recordRevertLocked(decision)
+	}
+	t.startEvaluationLocked(decision)
+	t.recordCategoryLocked(decision.Category)
+	switch decision.BoundHit {
+	case BoundHitMin:
+		t.minBoundHits++
+	case BoundHitMax:
+		t.maxBoundHits++
+	}
+	if !limitOnly {
+		t.recordDecisionDirectionLocked(oldGOGC, decision.NewGOGC)
+		t.lastGOGC = decision.NewGOGC
+		t.desiredGOGC = decision.DesiredGOGC
+		t.resetStabilityLocked()
+	}
 
 	t.config.Logger.Info("Applied GC tuning: %s (confidence: %.2f)",
 		decision.Reason, decision.Confidence)
 
-	// Trigger callback
-	if t.onTuningDecision != nil {
-		t.onTuningDecision(decision)
+	// Notify the decision observers, the SetOnTuningDecision callback first
+	for _, observer := range t.decisionObs {
+		name := "decision_observer"
+		if _, ok := observer.(decisionCallback); ok {
+			name = "on_tuning_decision"
+		}
+		t.safeCall(name, func() { observer.OnDecision(decision) })
+	}
+	if t.onRateLimited != nil && decision.ClampedBy == ClampRateLimit {
+		t.safeCall("on_rate_limited", func() { t.onRateLimited(decision) })
 	}
 }
 
 // shouldSkipDueToOscillation checks if we should skip tuning to prevent oscillation
 func (t *Tuner) shouldSkipDueToOscillation() bool {
-	if len(t.decisionHistory) < 4 {
+	if t.config.DisableAntiOscillation {
 		return false
 	}
 
-	// Check for rapid back-and-forth changes
-	recent := t.decisionHistory[len(t.decisionHistory)-4:]
+	// Check for rapid back-and-forth changes. Reverts and the decisions
+	// they undid cancel out, and memory limit changes leave GOGC alone, so
+	// they don't count as oscillation.
+	var recent []TuningDecision
+	history := t.decisionHistory.items()
+	for i := len(history) - 1; i >= 0 && len(recent) < 4; i-- {
+		if d := history[i]; !d.reverted && d.Category != CategoryRevert && !d.memoryLimitChange() {
+			recent = append([]TuningDecision{d}, recent...)
+		}
+	}
+	if len(recent) < 4 {
+		return false
+	}
 
 	// Look for alternating increase/decrease pattern
 	increaseCount := 0
@@ -587,10 +1991,7 @@ func (t *Tuner) shouldSkipDueToOscillation() bool {
 	// If we have both increases and decreases in recent history, we might be oscillating
 	if increaseCount > 0 && decreaseCount > 0 {
 		// Check if decisions are within the stabilization window
-		now := time.Now()
-		oldestDecision := recent[0].Timestamp
-
-		if now.Sub(oldestDecision) < t.config.StabilizationWindow {
+		if clockDistance(t.now(), recent[0].Timestamp) < t.config.StabilizationWindow {
 			t.config.Logger.Debug("Detected potential oscillation, skipping tuning")
 			return true
 		}
@@ -614,12 +2015,173 @@ func validateConfig(config *Config) error {
 	if config.TuningAggressiveness < 0.1 || config.TuningAggressiveness > 2.0 {
 		return fmt.Errorf("tuning aggressiveness must be between 0.1 and 2.0")
 	}
+	if config.TargetLatency <= 0 {
+		return fmt.Errorf("target latency must be positive")
+	}
+	if config.TargetLatencyTolerance >= config.TargetLatency {
+		return fmt.Errorf("target latency tolerance %v must be below target latency %v",
+			config.TargetLatencyTolerance, config.TargetLatency)
+	}
+	if config.BoundProximityMargin < 0 || config.BoundProximityMargin >= 0.5 {
+		return fmt.Errorf("bound proximity margin must be in [0, 0.5)")
+	}
+	if config.RevertThreshold < 0 {
+		return fmt.Errorf("revert threshold must not be negative")
+	}
+	if config.FactorSmoothingAlpha < 0 || config.FactorSmoothingAlpha > 1 {
+		return fmt.Errorf("factor smoothing alpha must be between 0 and 1")
+	}
+	if config.CPUAwareness < 0 || config.CPUAwareness > 1 {
+		return fmt.Errorf("CPU awareness must be between 0 and 1")
+	}
+	if config.MaxAggressivenessBoost != 0 && config.MaxAggressivenessBoost < 1 {
+		return fmt.Errorf("max aggressiveness boost must be at least 1")
+	}
+	if config.RecommendationWeight < 0 || config.RecommendationWeight > 1 {
+		return fmt.Errorf("recommendation weight must be between 0 and 1")
+	}
+	if err := config.SLOMode.validate(); err != nil {
+		return err
+	}
+	if config.MaxPauseTime < 0 {
+		return fmt.Errorf("max pause time must not be negative")
+	}
+	if config.MaxPauseTime > 0 && config.MaxPauseTime < config.TargetLatency {
+		return fmt.Errorf("max pause time %v must not be below target latency %v",
+			config.MaxPauseTime, config.TargetLatency)
+	}
+	if config.GOGCRounding < 0 {
+		return fmt.Errorf("GOGC rounding must not be negative")
+	}
 	if config.MemoryLimitPercent < 0.1 || config.MemoryLimitPercent > 1.0 {
 		return fmt.Errorf("memory limit percent must be between 0.1 and 1.0")
 	}
+	if config.MemoryLimitTarget != 0 && (config.MemoryLimitTarget < 0.5 || config.MemoryLimitTarget > 1) {
+		return fmt.Errorf("memory limit target must be between 0.5 and 1")
+	}
+	if config.EnableMemoryLimit && config.EnableBallast {
+		return fmt.Errorf("memory limit tuning and the memory ballast can't be enabled together")
+	}
+	if config.DisableGC && !config.EnableMemoryLimit {
+		return fmt.Errorf("turning GC off requires memory limit tuning to bound the heap")
+	}
+	if config.DisableGC && config.GOGCBand != [2]int{} {
+		return fmt.Errorf("GC can't be turned off in cooperative mode")
+	}
+	if config.GOGCBand != [2]int{} {
+		if config.GOGCBand[0] > config.GOGCBand[1] {
+			return fmt.Errorf("GOGC band lower bound %d is above upper bound %d",
+				config.GOGCBand[0], config.GOGCBand[1])
+		}
+		if config.GOGCBand[0] < config.MinGOGC || config.GOGCBand[1] > config.MaxGOGC {
+			return fmt.Errorf("GOGC band [%d, %d] must be within min/max GOGC [%d, %d]",
+				config.GOGCBand[0], config.GOGCBand[1], config.MinGOGC, config.MaxGOGC)
+		}
+	}
+	switch config.PauseTarget {
+	case "", PauseStatisticAverage, PauseStatisticP95, PauseStatisticP99:
+	default:
+		return fmt.Errorf("unknown pause target %q", config.PauseTarget)
+	}
+	switch config.LeakAction {
+	case "", LeakActionAlert, LeakActionSafeMode, LeakActionIgnore:
+	default:
+		return fmt.Errorf("unknown leak action %q", config.LeakAction)
+	}
+	if config.LeakDetectionWindow < 0 || config.LeakDetectionWindow == 1 {
+		return fmt.Errorf("leak detection window must be at least 2 samples")
+	}
+	if config.MetricsHistorySize < 0 || (config.MetricsHistorySize > 0 && config.MetricsHistorySize < pauseTrendWindow) {
+		return fmt.Errorf("metrics history size must be at least %d samples", pauseTrendWindow)
+	}
+	if window := leakWindowSize(config); config.LeakAction != LeakActionIgnore && window > metricsHistorySize(config) {
+		return fmt.Errorf("leak detection window %d exceeds metrics history size %d", window, metricsHistorySize(config))
+	}
+	for _, source := range config.MemoryLimitSources {
+		if err := source.validate(); err != nil {
+			return err
+		}
+		if source == MemoryLimitSourceFile && config.MemoryLimitFile == "" {
+			return fmt.Errorf("memory limit source %q requires a memory limit file", source)
+		}
+	}
+	switch config.LiveHeapSource {
+	case "", LiveHeapSourceAuto, LiveHeapSourceRuntimeMetrics, LiveHeapSourceHeapAlloc, LiveHeapSourceHeapInuse:
+	default:
+		return fmt.Errorf("unknown live heap source %q", config.LiveHeapSource)
+	}
 	return nil
 }
 
+// readGOGC returns the current GOGC value without changing it
+func readGOGC() int {
+	if gogc, ok := runtimeCollector.readUint64(rtGOGCKey); ok {
+		return int(gogc)
+	}
+
+	// Older runtimes only expose GOGC through SetGCPercent
+	current := debug.SetGCPercent(-1)
+	debug.SetGCPercent(current)
+	return current
+}
+
+// readRuntimeLiveHeap reads the live heap size from runtime/metrics
+func readRuntimeLiveHeap() (uint64, bool) {
+	return runtimeCollector.readUint64(rtLiveHeapKey)
+}
+
+// readRuntimeCPUSeconds reads the cumulative GC and total CPU time, or
+// zeros when runtime/metrics doesn't provide both
+func readRuntimeCPUSeconds() (gc, total float64) {
+	gc, gcOK := runtimeCollector.readFloat64(rtGCCPUKey)
+	total, totalOK := runtimeCollector.readFloat64(rtTotalCPUKey)
+	if !gcOK || !totalOK {
+		return 0, 0
+	}
+	return gc, total
+}
+
+// liveHeapBytes picks the live heap size for the given source
+func liveHeapBytes(source LiveHeapSource, m *runtime.MemStats, runtimeLive uint64, runtimeOK bool) uint64 {
+	switch source {
+	case LiveHeapSourceHeapInuse:
+		return m.HeapInuse
+	case LiveHeapSourceHeapAlloc:
+		return m.HeapAlloc
+	default:
+		// Auto and runtime_metrics prefer the runtime's live heap. A zero value
+		// means no GC has completed yet, so HeapAlloc is the better estimate.
+		if runtimeOK && runtimeLive > 0 {
+			return runtimeLive
+		}
+		return m.HeapAlloc
+	}
+}
+
+// heapFragmentation returns the share of heapSys not in in-use spans, zero
+// for an empty heap
+func heapFragmentation(heapSys, heapInuse uint64) float64 {
+	if heapSys == 0 || heapInuse >= heapSys {
+		return 0
+	}
+	return float64(heapSys-heapInuse) / float64(heapSys)
+}
+
+// clockDistance returns how far apart two timestamps are. Timestamps taken
+// with time.Now in this process are compared on the monotonic clock, but
+// others (decoded, or taken after the monotonic reading was stripped) use the
+// wall clock, which can step backwards on NTP corrections. Comparing the
+// absolute distance keeps a backward step from making old timestamps look
+// like they are in the future and count as recent indefinitely: at worst a
+// step shorter than a window is mistaken for elapsed time.
+func clockDistance(now, then time.Time) time.Duration {
+	d := now.Sub(then)
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
 func abs(x int) int {
 	if x < 0 {
 		return -x
@@ -627,6 +2189,66 @@ func abs(x int) int {
 	return x
 }
 
+// gcFrequency returns the number of GCs per second between two samples.
+// Forced GCs (runtime.GC calls) aren't triggered by heap growth, so GOGC has
+// no influence on them and they are excluded; otherwise an application
+// calling runtime.GC in a loop would have the tuner chase a phantom.
+func gcFrequency(prev, cur Metrics) float64 {
+	timeDiff := cur.Timestamp.Sub(prev.Timestamp).Seconds()
+	if timeDiff <= 0 {
+		return 0
+	}
+
+	gcDiff := cur.NumGC - prev.NumGC
+	if forced := cur.NumForcedGC - prev.NumForcedGC; cur.NumForcedGC >= prev.NumForcedGC && forced <= gcDiff {
+		gcDiff -= forced
+	}
+	return float64(gcDiff) / timeDiff
+}
+
+// gcCPUFraction returns the share of available CPU time the GC used between
+// two samples, reporting false when the CPU counters are missing. The
+// runtime only updates them at the end of a GC, so without a GC in between
+// the share is zero.
+func gcCPUFraction(prev, cur Metrics) (float64, bool) {
+	if cur.TotalCPUSeconds <= 0 {
+		return 0, false
+	}
+	total := cur.TotalCPUSeconds - prev.TotalCPUSeconds
+	gc := cur.GCCPUSeconds - prev.GCCPUSeconds
+	switch {
+	case total < 0 || gc < 0:
+		return 0, false
+	case total == 0:
+		return 0, true
+	}
+	return gc / total, true
+}
+
+// recentPauseAverage averages the last n GC pauses from the MemStats pause
+// ring buffer. Reading it from the MemStats already collected avoids a
+// separate debug.ReadGCStats call, which allocates the full pause history on
+// every cycle.
+func recentPauseAverage(m *runtime.MemStats, n int) time.Duration {
+	count := int(m.NumGC)
+	if count > n {
+		count = n
+	}
+	if count > len(m.PauseNs) {
+		count = len(m.PauseNs)
+	}
+	if count == 0 {
+		return 0
+	}
+
+	var total uint64
+	for i := 0; i < count; i++ {
+		// The most recent pause is at PauseNs[(NumGC+255)%256]
+		total += m.PauseNs[(int(m.NumGC)-1-i+len(m.PauseNs))%len(m.PauseNs)]
+	}
+	return time.Duration(total / uint64(count))
+}
+
 func calculateVariation(metrics []Metrics, extractor func(Metrics) float64) float64 {
 	if len(metrics) < 2 {
 		return 0
@@ -1,8 +1,13 @@
 package autotune
 
 import (
+	"context"
+	"math"
+	"net/http/httptest"
+	"runtime"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -96,6 +101,59 @@ func TestConfigValidation(t *testing.T) {
 	}
 }
 
+// TestHeapFragmentation tests the fragmentation ratio from MemStats values
+func TestHeapFragmentation(t *testing.T) {
+	m := &runtime.MemStats{
+		HeapSys:   800 << 20,
+		HeapInuse: 200 << 20,
+		HeapAlloc: 150 << 20,
+	}
+	assert.InDelta(t, 0.75, heapFragmentation(m.HeapSys, m.HeapInuse), 1e-9)
+	assert.Equal(t, 0.0, heapFragmentation(m.HeapInuse, m.HeapInuse), "fully in use")
+	assert.Equal(t, 0.0, heapFragmentation(0, 0), "empty heap")
+
+	// Ingested samples derive the ratio and it shows up in stats
+	config := DefaultConfig()
+	config.ExternalMetrics = true
+	config.Logger = &mockLogger{}
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	tuner.setGCPercent = func(value int) int { return 100 }
+
+	require.NoError(t, tuner.IngestMetrics(Metrics{HeapSize: m.HeapSys, HeapInuse: m.HeapInuse, HeapAlloc: m.HeapAlloc}))
+	assert.InDelta(t, 0.75, tuner.GetMetrics().HeapFragmentation, 1e-9)
+	assert.InDelta(t, 0.75, tuner.GetStats()["heap_fragmentation_ratio"], 1e-9)
+}
+
+// TestLiveHeapSource tests memory pressure under each live heap source
+func TestLiveHeapSource(t *testing.T) {
+	m := &runtime.MemStats{
+		HeapAlloc: 200 << 20,
+		HeapInuse: 600 << 20, // Inflated by partially used spans
+	}
+	runtimeLive := uint64(100 << 20)
+	limit := float64(1 << 30)
+
+	pressure := func(source LiveHeapSource, runtimeOK bool) float64 {
+		return float64(liveHeapBytes(source, m, runtimeLive, runtimeOK)) / limit
+	}
+
+	assert.InDelta(t, 0.0977, pressure(LiveHeapSourceAuto, true), 0.001)
+	assert.InDelta(t, 0.0977, pressure(LiveHeapSourceRuntimeMetrics, true), 0.001)
+	assert.InDelta(t, 0.1953, pressure(LiveHeapSourceHeapAlloc, true), 0.001)
+	assert.InDelta(t, 0.5859, pressure(LiveHeapSourceHeapInuse, true), 0.001)
+
+	// Without runtime/metrics the runtime sources fall back to HeapAlloc
+	assert.InDelta(t, 0.1953, pressure(LiveHeapSourceAuto, false), 0.001)
+	assert.InDelta(t, 0.1953, pressure(LiveHeapSourceRuntimeMetrics, false), 0.001)
+	assert.InDelta(t, 0.1953, pressure("", false), 0.001)
+
+	// Unknown sources are rejected
+	config := DefaultConfig()
+	config.LiveHeapSource = "heap_sys"
+	assert.Error(t, validateConfig(config))
+}
+
 // TestNewTuner tests tuner creation
 func TestNewTuner(t *testing.T) {
 	tuner, err := NewTuner(nil)
@@ -144,6 +202,91 @@ func TestTunerStartStop(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// TestStartWithContext tests that cancelling the start context stops the
+// tuner
+func TestStartWithContext(t *testing.T) {
+	config := DefaultConfig()
+	config.Logger = silentLogger{}
+	config.MonitorInterval = time.Hour
+
+	isRunning := func(tuner *Tuner) bool {
+		tuner.mu.RLock()
+		defer tuner.mu.RUnlock()
+		return tuner.running
+	}
+
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, tuner.StartWithContext(ctx))
+	assert.Error(t, tuner.StartWithContext(ctx))
+	assert.True(t, isRunning(tuner))
+
+	cancel()
+	assert.Eventually(t, func() bool { return !isRunning(tuner) }, 5*time.Second, 10*time.Millisecond)
+	assert.Error(t, tuner.ctx.Err(), "the monitor loop's context is cancelled")
+	assert.Error(t, tuner.Stop())
+
+	// An explicit Stop releases the context
+	tuner, err = NewTuner(config)
+	require.NoError(t, err)
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, tuner.StartWithContext(ctx))
+	require.NoError(t, tuner.Stop())
+	assert.Nil(t, tuner.stopAfter)
+
+	// A context that is already done is rejected
+	tuner, err = NewTuner(config)
+	require.NoError(t, err)
+	done, cancelDone := context.WithCancel(context.Background())
+	cancelDone()
+	assert.ErrorIs(t, tuner.StartWithContext(done), context.Canceled)
+	assert.False(t, isRunning(tuner))
+}
+
+// TestRestart tests that a stopped tuner can be started again and its
+// monitor loop and metrics observers resume
+func TestRestart(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	config := DefaultConfig()
+	config.Logger = silentLogger{}
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	// Below the configurable minimum to keep the test fast
+	tuner.config.MonitorInterval = 10 * time.Millisecond
+
+	var observed int64
+	tuner.AddMetricsObserver(MetricsObserverFunc(func(Metrics) { atomic.AddInt64(&observed, 1) }))
+
+	samples := func() int {
+		tuner.mu.RLock()
+		defer tuner.mu.RUnlock()
+		return tuner.metricsHistory.len()
+	}
+	waitForCycles := func() {
+		collected, notified := samples(), atomic.LoadInt64(&observed)
+		assert.Eventually(t, func() bool {
+			return samples() > collected && atomic.LoadInt64(&observed) > notified
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	require.NoError(t, tuner.Start())
+	waitForCycles()
+	require.NoError(t, tuner.Stop())
+
+	// No cycles run while stopped, once an in-flight one has finished
+	time.Sleep(20 * time.Millisecond)
+	stopped := samples()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, stopped, samples())
+
+	require.NoError(t, tuner.Start())
+	waitForCycles()
+	require.NoError(t, tuner.Stop())
+}
+
 // TestMetricsCollection tests metrics collection
 func TestMetricsCollection(t *testing.T) {
 	tuner, err := NewTuner(DefaultConfig())
@@ -155,6 +298,13 @@ func TestMetricsCollection(t *testing.T) {
 	assert.NotZero(t, metrics.HeapSize)
 	assert.NotZero(t, metrics.HeapAlloc)
 	assert.GreaterOrEqual(t, metrics.CurrentGOGC, 0)
+
+	// Readers sample live rather than returning the last cycle's metrics
+	tuner.metricsHistory.push(Metrics{Timestamp: time.Now().Add(-time.Hour), NumGC: 0})
+	runtime.GC()
+	metrics = tuner.GetMetrics()
+	assert.WithinDuration(t, time.Now(), metrics.Timestamp, time.Minute)
+	assert.NotZero(t, metrics.NumGC)
 }
 
 // TestTuningDecision tests tuning decision making
@@ -177,7 +327,7 @@ func TestTuningDecision(t *testing.T) {
 			CurrentGOGC:    100,
 			Timestamp:      time.Now(),
 		}
-		tuner.metricsHistory = append(tuner.metricsHistory, metrics)
+		tuner.metricsHistory.push(metrics)
 	}
 
 	// Test decision making
@@ -217,23 +367,67 @@ func TestAntiOscillation(t *testing.T) {
 		{OldGOGC: 150, NewGOGC: 100, Timestamp: now.Add(-200 * time.Millisecond)},
 	}
 
-	tuner.decisionHistory = decisions
+	tuner.decisionHistory.reset(decisions)
 
 	// Should skip due to oscillation
 	shouldSkip := tuner.shouldSkipDueToOscillation()
 	assert.True(t, shouldSkip)
 
+	// Unless anti-oscillation is disabled
+	config.DisableAntiOscillation = true
+	assert.False(t, tuner.shouldSkipDueToOscillation())
+	config.DisableAntiOscillation = false
+
 	// Test with older decisions (outside window)
 	oldDecisions := []TuningDecision{
 		{OldGOGC: 100, NewGOGC: 150, Timestamp: now.Add(-2 * time.Second)},
 		{OldGOGC: 150, NewGOGC: 100, Timestamp: now.Add(-3 * time.Second)},
 	}
 
-	tuner.decisionHistory = oldDecisions
+	tuner.decisionHistory.reset(oldDecisions)
 	shouldSkip = tuner.shouldSkipDueToOscillation()
 	assert.False(t, shouldSkip)
 }
 
+// TestAntiOscillationClockJump tests that a wall clock stepping backwards
+// doesn't make old decisions count as recent indefinitely
+func TestAntiOscillationClockJump(t *testing.T) {
+	config := DefaultConfig()
+	config.StabilizationWindow = time.Minute
+
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	// Wall clock readings, as after decoding, without monotonic readings
+	decided := time.Now().Round(0)
+	tuner.decisionHistory.reset([]TuningDecision{
+		{OldGOGC: 100, NewGOGC: 150, Timestamp: decided.Add(-30 * time.Second)},
+		{OldGOGC: 150, NewGOGC: 100, Timestamp: decided.Add(-20 * time.Second)},
+		{OldGOGC: 100, NewGOGC: 150, Timestamp: decided.Add(-10 * time.Second)},
+		{OldGOGC: 150, NewGOGC: 100, Timestamp: decided},
+	})
+
+	now := decided
+	tuner.now = func() time.Time { return now }
+	assert.True(t, tuner.shouldSkipDueToOscillation())
+
+	// A small backward step keeps suppressing within the window
+	now = decided.Add(-50 * time.Second)
+	assert.True(t, tuner.shouldSkipDueToOscillation())
+
+	// A large backward step must not suppress tuning until the clock
+	// catches up
+	now = decided.Add(-time.Hour)
+	assert.False(t, tuner.shouldSkipDueToOscillation())
+
+	// Forward time keeps working as before
+	now = decided.Add(2 * time.Minute)
+	assert.False(t, tuner.shouldSkipDueToOscillation())
+
+	assert.Equal(t, 5*time.Second, clockDistance(decided, decided.Add(5*time.Second)))
+	assert.Equal(t, 5*time.Second, clockDistance(decided.Add(5*time.Second), decided))
+}
+
 // TestCalculateTargetGOGC tests GOGC calculation
 func TestCalculateTargetGOGC(t *testing.T) {
 	config := DefaultConfig()
@@ -276,7 +470,7 @@ func TestCalculateConfidence(t *testing.T) {
 
 	// Test with stable history
 	for i := 0; i < 10; i++ {
-		tuner.metricsHistory = append(tuner.metricsHistory, Metrics{
+		tuner.metricsHistory.push(Metrics{
 			GCPauseTime: 10 * time.Millisecond,
 			CurrentGOGC: 100,
 		})
@@ -286,6 +480,97 @@ func TestCalculateConfidence(t *testing.T) {
 	assert.Greater(t, confidence, 0.5)
 }
 
+// TestBoundProximityMargin tests that the near-bound confidence penalty
+// scales with the bound range
+func TestBoundProximityMargin(t *testing.T) {
+	config := DefaultConfig()
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	// Wide range: 10% of 1950 is 195 from either bound
+	config.MinGOGC, config.MaxGOGC = 50, 2000
+	assert.True(t, tuner.nearBound(240))
+	assert.False(t, tuner.nearBound(250))
+	assert.False(t, tuner.nearBound(1800))
+	assert.True(t, tuner.nearBound(1810))
+
+	// Narrow range: 10% of 50 is 5 from either bound
+	config.MinGOGC, config.MaxGOGC = 50, 100
+	assert.True(t, tuner.nearBound(55))
+	assert.False(t, tuner.nearBound(56))
+	assert.False(t, tuner.nearBound(94))
+	assert.True(t, tuner.nearBound(95))
+
+	// A larger margin widens both sides equally
+	config.BoundProximityMargin = 0.25
+	assert.True(t, tuner.nearBound(62))
+	assert.False(t, tuner.nearBound(63))
+	assert.False(t, tuner.nearBound(87))
+	assert.True(t, tuner.nearBound(88))
+
+	// The penalty applies to confidence
+	for i := 0; i < 10; i++ {
+		tuner.metricsHistory.push(Metrics{GCPauseTime: time.Millisecond, CurrentGOGC: 75})
+	}
+	metrics := Metrics{MemoryPressure: 0.5, CurrentGOGC: 75}
+	assert.Equal(t, 1.0, tuner.calculateConfidence(metrics))
+	metrics.CurrentGOGC = 90
+	assert.Equal(t, 0.9, tuner.calculateConfidence(metrics))
+
+	config.BoundProximityMargin = -0.1
+	assert.Error(t, validateConfig(config))
+	config.BoundProximityMargin = 0.5
+	assert.Error(t, validateConfig(config))
+}
+
+// TestTargetLatencyTolerance tests that pauses within the dead-band around
+// the target latency don't move GOGC
+func TestTargetLatencyTolerance(t *testing.T) {
+	config := DefaultConfig()
+	config.FactorSmoothingAlpha = 1
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	tuner.qosClass = QoSClassUnknown
+
+	target := func(pause time.Duration) int {
+		return tuner.calculateTargetGOGC(Metrics{
+			GCPauseTime:    pause,
+			GCFrequency:    1.0,
+			MemoryPressure: 0.5,
+			CurrentGOGC:    100,
+		})
+	}
+
+	// The default dead-band is 20% of the 10ms target
+	onTarget := target(10 * time.Millisecond)
+	assert.Equal(t, onTarget, target(11*time.Millisecond))
+	assert.Equal(t, onTarget, target(8*time.Millisecond))
+	assert.Greater(t, target(13*time.Millisecond), onTarget)
+	assert.Less(t, target(7*time.Millisecond), onTarget)
+	assert.NotContains(t, joinStrings(tuner.reasonFactors(Metrics{GCPauseTime: 11 * time.Millisecond}), ", "), "GC pause")
+	assert.Contains(t, joinStrings(tuner.reasonFactors(Metrics{GCPauseTime: 13 * time.Millisecond}), ", "), "GC pause")
+
+	// A narrower band reacts to smaller deviations
+	config.TargetLatencyTolerance = 500 * time.Microsecond
+	assert.Greater(t, target(11*time.Millisecond), onTarget)
+	assert.Less(t, target(9*time.Millisecond), onTarget)
+
+	// A negative tolerance disables the dead-band
+	config.TargetLatencyTolerance = -1
+	assert.NoError(t, validateConfig(config))
+	assert.Greater(t, target(11*time.Millisecond), onTarget)
+	assert.Less(t, target(9*time.Millisecond), onTarget)
+	assert.Equal(t, onTarget, target(10*time.Millisecond))
+
+	config.TargetLatencyTolerance = config.TargetLatency
+	assert.Error(t, validateConfig(config))
+
+	// A missing target is reported as such, not as a tolerance error
+	config.TargetLatencyTolerance = 0
+	config.TargetLatency = 0
+	assert.EqualError(t, validateConfig(config), "target latency must be positive")
+}
+
 // TestCallbacks tests callback functionality
 func TestCallbacks(t *testing.T) {
 	tuner, err := NewTuner(DefaultConfig())
@@ -307,16 +592,11 @@ func TestCallbacks(t *testing.T) {
 	})
 
 	// Trigger metrics callback
-	if tuner.onMetricsUpdate != nil {
-		testMetrics := Metrics{CurrentGOGC: 100}
-		tuner.onMetricsUpdate(testMetrics)
-	}
+	tuner.dispatchMetrics(Metrics{CurrentGOGC: 100})
 
 	// Trigger decision callback
-	if tuner.onTuningDecision != nil {
-		testDecision := TuningDecision{OldGOGC: 100, NewGOGC: 150}
-		tuner.onTuningDecision(testDecision)
-	}
+	tuner.setGCPercent = func(value int) int { return 100 }
+	tuner.applyTuningDecision(TuningDecision{OldGOGC: 100, NewGOGC: 150})
 
 	assert.True(t, metricsCallbackCalled)
 	assert.True(t, decisionCallbackCalled)
@@ -363,6 +643,88 @@ func TestThreadSafety(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// TestRangeDecisions tests iterating over the decision history while
+// decisions are being recorded
+func TestRangeDecisions(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	config := DefaultConfig()
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	require.NoError(t, tuner.Start())
+	defer tuner.Stop()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			tuner.processDecision(TuningDecision{OldGOGC: 100 + i, NewGOGC: 101 + i, Timestamp: time.Now()})
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		previous := 0
+		tuner.RangeDecisions(func(decision TuningDecision) bool {
+			assert.Greater(t, decision.NewGOGC, previous)
+			previous = decision.NewGOGC
+			return true
+		})
+		for _, decision := range tuner.Decisions() {
+			assert.NotZero(t, decision.NewGOGC)
+		}
+	}
+	wg.Wait()
+
+	// Stops early when fn returns false
+	visited := 0
+	tuner.RangeDecisions(func(TuningDecision) bool {
+		visited++
+		return visited < 3
+	})
+	assert.Equal(t, 3, visited)
+
+	// Decisions returns a copy
+	decisions := tuner.Decisions()
+	require.Len(t, decisions, maxDecisions)
+	decisions[0].Outcome = "modified"
+	assert.Empty(t, tuner.Decisions()[0].Outcome)
+}
+
+// TestHistoryAccessors tests that the history accessors return copies
+// callers can't use to modify the tuner
+func TestHistoryAccessors(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	sample := Metrics{CurrentGOGC: 150, Custom: map[string]float64{"queue_depth": 4}}
+	tuner.mu.Lock()
+	tuner.metricsHistory.reset([]Metrics{{CurrentGOGC: 100}, sample})
+	tuner.decisionHistory.reset([]TuningDecision{{OldGOGC: 100, NewGOGC: 150, Metrics: &sample}})
+	tuner.mu.Unlock()
+
+	history := tuner.GetMetricsHistory()
+	require.Len(t, history, 2)
+	assert.Equal(t, 100, history[0].CurrentGOGC)
+	assert.Equal(t, 4.0, history[1].Custom["queue_depth"])
+	history[0].CurrentGOGC = 1
+	history[1].Custom["queue_depth"] = 99
+	assert.Equal(t, 100, tuner.GetMetricsHistory()[0].CurrentGOGC)
+	assert.Equal(t, 4.0, tuner.GetMetricsHistory()[1].Custom["queue_depth"])
+
+	decisions := tuner.GetDecisionHistory()
+	require.Len(t, decisions, 1)
+	assert.Equal(t, 150, decisions[0].NewGOGC)
+	decisions[0].NewGOGC = 1
+	decisions[0].Metrics.CurrentGOGC = 1
+	decisions[0].Metrics.Custom["queue_depth"] = 99
+	assert.Equal(t, tuner.Decisions(), tuner.GetDecisionHistory())
+	assert.Equal(t, 150, tuner.GetDecisionHistory()[0].NewGOGC)
+	assert.Equal(t, 150, tuner.GetDecisionHistory()[0].Metrics.CurrentGOGC)
+	assert.Equal(t, 4.0, tuner.GetDecisionHistory()[0].Metrics.Custom["queue_depth"])
+}
+
 // TestStatistics tests statistics collection
 func TestStatistics(t *testing.T) {
 	tuner, err := NewTuner(DefaultConfig())
@@ -382,6 +744,22 @@ func TestStatistics(t *testing.T) {
 	assert.Equal(t, false, stats["running"])
 }
 
+// TestStatusLine tests the one-line status summary
+func TestStatusLine(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(180))
+
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	assert.Equal(t, "gogc=180 pause=0.0ms pressure=0% decisions=0 reverts=0 stable=0", tuner.StatusLine())
+
+	setMetricsHistory(tuner, []Metrics{{GCPauseTime: 4200 * time.Microsecond, MemoryPressure: 0.523}})
+	tuner.totalDecisions = 12
+	tuner.revertedTunes = 1
+	tuner.stabilityCount = 3
+	assert.Equal(t, "gogc=180 pause=4.2ms pressure=52% decisions=12 reverts=1 stable=3", tuner.StatusLine())
+}
+
 // TestRealGOGCApplication tests that GOGC is actually applied
 func TestRealGOGCApplication(t *testing.T) {
 	originalGOGC := debug.SetGCPercent(-1)
@@ -406,10 +784,521 @@ func TestRealGOGCApplication(t *testing.T) {
 	assert.Equal(t, 200, currentGOGC)
 
 	// Check that decision was recorded
-	assert.Len(t, tuner.decisionHistory, 1)
+	assert.Len(t, tuner.decisionHistory.items(), 1)
 	assert.Equal(t, int64(1), tuner.totalDecisions)
 }
 
+// TestExternalGOGCChange tests that GOGC changes made outside the tuner are adopted
+func TestExternalGOGCChange(t *testing.T) {
+	originalGOGC := debug.SetGCPercent(-1)
+	debug.SetGCPercent(originalGOGC)
+	defer debug.SetGCPercent(originalGOGC)
+
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	// Reading GOGC must not change it
+	assert.Equal(t, originalGOGC, readGOGC())
+	assert.Equal(t, originalGOGC, tuner.GetMetrics().CurrentGOGC)
+	assert.Equal(t, originalGOGC, readGOGC())
+
+	// No change between cycles
+	tuner.detectExternalGOGCChange()
+	assert.Equal(t, false, tuner.GetStats()["external_change_detected"])
+
+	// Another actor changes GOGC between cycles
+	debug.SetGCPercent(originalGOGC + 77)
+	tuner.stabilityCount = 5
+	tuner.detectExternalGOGCChange()
+
+	stats := tuner.GetStats()
+	assert.Equal(t, true, stats["external_change_detected"])
+	assert.Equal(t, int64(1), stats["external_changes"])
+	assert.Equal(t, originalGOGC+77, tuner.lastGOGC)
+	assert.Equal(t, 0, tuner.stabilityCount)
+
+	// The adopted value is the baseline for the next decision
+	decision := TuningDecision{NewGOGC: originalGOGC + 100, Timestamp: time.Now()}
+	tuner.applyTuningDecision(decision)
+	assert.Equal(t, originalGOGC+77, tuner.decisionHistory.items()[0].OldGOGC)
+
+	tuner.detectExternalGOGCChange()
+	assert.Equal(t, int64(1), tuner.GetStats()["external_changes"])
+}
+
+// TestHealthScore tests the tuning health score components
+func TestHealthScore(t *testing.T) {
+	originalGOGC := debug.SetGCPercent(175)
+	defer debug.SetGCPercent(originalGOGC)
+
+	config := DefaultConfig()
+	config.MinGOGC = 50
+	config.MaxGOGC = 300
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	// No decisions, GOGC in the middle of the range
+	assert.InDelta(t, 1.0, tuner.HealthScore(), 0.001)
+
+	// Low confidence and reverts lower the score
+	tuner.decisionHistory.reset([]TuningDecision{
+		{Confidence: 0.6},
+		{Confidence: 0.8},
+	})
+	tuner.totalDecisions = 4
+	tuner.revertedTunes = 2
+	assert.InDelta(t, 0.4*0.7+0.3*0.5+0.3*1.0, tuner.HealthScore(), 0.001)
+
+	// GOGC pinned at a bound gives no bound credit
+	debug.SetGCPercent(300)
+	assert.InDelta(t, 0.4*0.7+0.3*0.5, tuner.HealthScore(), 0.001)
+
+	// Halfway to the quarter-range threshold
+	debug.SetGCPercent(50 + 250/8)
+	score := tuner.GetStats()["health_score"].(float64)
+	assert.InDelta(t, 0.4*0.7+0.3*0.5+0.3*0.496, score, 0.001)
+}
+
+// TestDecisionFilter tests vetoing and modifying proposed decisions
+func TestDecisionFilter(t *testing.T) {
+	originalGOGC := debug.SetGCPercent(100)
+	defer debug.SetGCPercent(originalGOGC)
+
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	var applied []TuningDecision
+	tuner.SetOnTuningDecision(func(decision TuningDecision) {
+		applied = append(applied, decision)
+	})
+
+	// Veto every increase
+	tuner.SetDecisionFilter(func(proposed TuningDecision) (TuningDecision, bool) {
+		return proposed, proposed.NewGOGC <= proposed.OldGOGC
+	})
+
+	tuner.processDecision(TuningDecision{OldGOGC: 100, NewGOGC: 150, Reason: "increase", Timestamp: time.Now()})
+	assert.Equal(t, 100, readGOGC())
+	assert.Empty(t, applied)
+	assert.Equal(t, int64(1), tuner.GetStats()["vetoed_decisions"])
+	assert.Equal(t, int64(0), tuner.GetStats()["total_decisions"])
+
+	assert.Equal(t, 100, tuner.GetStats()["desired_gogc"], "defaults to the current GOGC")
+
+	tuner.processDecision(TuningDecision{OldGOGC: 100, NewGOGC: 80, DesiredGOGC: 20, Reason: "decrease", Timestamp: time.Now()})
+	assert.Equal(t, 80, readGOGC())
+	assert.Equal(t, 20, tuner.GetStats()["desired_gogc"])
+	require.Len(t, applied, 1)
+	assert.Equal(t, 80, applied[0].NewGOGC)
+
+	// A modified decision is applied, but still within bounds
+	tuner.SetDecisionFilter(func(proposed TuningDecision) (TuningDecision, bool) {
+		proposed.NewGOGC = 5000
+		proposed.Reason = "modified"
+		return proposed, true
+	})
+	tuner.processDecision(TuningDecision{OldGOGC: 80, NewGOGC: 90, Timestamp: time.Now()})
+	assert.Equal(t, tuner.config.MaxGOGC, readGOGC())
+	require.Len(t, applied, 2)
+	assert.Equal(t, "modified", applied[1].Reason)
+}
+
+// TestRecommend tests that recommendations leave tuner state untouched
+func TestRecommend(t *testing.T) {
+	originalGOGC := debug.SetGCPercent(100)
+	defer debug.SetGCPercent(originalGOGC)
+
+	config := DefaultConfig()
+	config.TargetLatency = time.Nanosecond // Any real pause exceeds the target
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	_, err = tuner.Recommend()
+	assert.Error(t, err)
+
+	runtime.GC()
+	lastCycle := time.Now().Add(-time.Minute)
+	for i := 0; i < 5; i++ {
+		tuner.metricsHistory.push(Metrics{
+			GCPauseTime: time.Millisecond,
+			CurrentGOGC: 100,
+			Timestamp:   lastCycle,
+		})
+	}
+	statsBefore := tuner.GetStats()
+
+	for i := 0; i < 3; i++ {
+		decision, err := tuner.Recommend()
+		require.NoError(t, err)
+		require.NotNil(t, decision)
+		// Decided on a fresh sample, not a replay of the last cycle
+		require.NotNil(t, decision.Metrics)
+		assert.True(t, decision.Metrics.Timestamp.After(lastCycle))
+		assert.NotZero(t, decision.Metrics.NumGC)
+		assert.Equal(t, 100, decision.OldGOGC)
+		assert.Greater(t, decision.NewGOGC, 100)
+		assert.LessOrEqual(t, decision.NewGOGC, config.MaxGOGC)
+		assert.Greater(t, decision.DesiredGOGC, decision.NewGOGC, "target is rate limited")
+		assert.NotEmpty(t, decision.Reason)
+	}
+
+	assert.Equal(t, 100, readGOGC())
+	assert.Len(t, tuner.metricsHistory.items(), 5)
+	assert.Empty(t, tuner.decisionHistory.items())
+	statsAfter := tuner.GetStats()
+	for _, key := range []string{"total_decisions", "stability_count", "current_gogc", "external_changes"} {
+		assert.Equal(t, statsBefore[key], statsAfter[key], key)
+	}
+}
+
+// TestRateLimitedDecisions tests recording decisions clamped by the rate limit
+func TestRateLimitedDecisions(t *testing.T) {
+	originalGOGC := debug.SetGCPercent(100)
+	defer debug.SetGCPercent(originalGOGC)
+
+	config := DefaultConfig()
+	config.TargetLatency = time.Nanosecond // Any real pause exceeds the target
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	var rateLimited []TuningDecision
+	tuner.SetOnRateLimited(func(decision TuningDecision) {
+		rateLimited = append(rateLimited, decision)
+	})
+
+	runtime.GC()
+	for i := 0; i < 5; i++ {
+		tuner.metricsHistory.push(Metrics{
+			GCPauseTime: time.Millisecond,
+			CurrentGOGC: 100,
+			Timestamp:   time.Now(),
+		})
+	}
+
+	decision, err := tuner.Recommend()
+	require.NoError(t, err)
+	require.NotNil(t, decision)
+	assert.Equal(t, ClampRateLimit, decision.ClampedBy)
+	assert.Equal(t, 100+config.MaxChangePerInterval, decision.NewGOGC)
+	assert.Contains(t, decision.Reason, "limited by MaxChangePerInterval")
+
+	tuner.processDecision(*decision)
+	assert.Equal(t, int64(1), tuner.GetStats()["rate_limited"])
+	require.Len(t, rateLimited, 1)
+	assert.Equal(t, decision.DesiredGOGC, rateLimited[0].DesiredGOGC)
+
+	// Decisions that reach their target don't count
+	tuner.processDecision(TuningDecision{OldGOGC: 150, NewGOGC: 160, DesiredGOGC: 160, Timestamp: time.Now()})
+	assert.Equal(t, int64(1), tuner.GetStats()["rate_limited"])
+	assert.Len(t, rateLimited, 1)
+
+	// Bounds take precedence when they clamp further
+	config.MaxChangePerInterval = 1000
+	config.MaxGOGC = 200
+	tuner.decisionHistory.reset(nil)
+	decision, err = tuner.Recommend()
+	require.NoError(t, err)
+	require.NotNil(t, decision)
+	assert.Equal(t, ClampBounds, decision.ClampedBy)
+	assert.Equal(t, 200, decision.NewGOGC)
+
+	tuner.processDecision(*decision)
+	assert.Equal(t, int64(1), tuner.GetStats()["rate_limited"])
+}
+
+// TestGOGCRounding tests that targets snap to the configured increment
+// within bounds
+func TestGOGCRounding(t *testing.T) {
+	config := DefaultConfig()
+	config.GOGCRounding = 25
+	config.MinGOGC = 60
+	config.MaxGOGC = 790
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	assert.Equal(t, 175, tuner.roundGOGC(173))
+	assert.Equal(t, 175, tuner.roundGOGC(181))
+	assert.Equal(t, 200, tuner.roundGOGC(188))
+	assert.Equal(t, 75, tuner.roundGOGC(60), "nearest multiple below MinGOGC")
+	assert.Equal(t, 775, tuner.roundGOGC(790), "nearest multiple above MaxGOGC")
+
+	config.MinGOGC, config.MaxGOGC = 101, 110
+	assert.Equal(t, 105, tuner.roundGOGC(105), "no multiple within bounds")
+	config.MinGOGC, config.MaxGOGC = 60, 790
+
+	config.GOGCRounding = 0
+	assert.Equal(t, 173, tuner.roundGOGC(173))
+
+	config.GOGCRounding = -5
+	assert.Error(t, validateConfig(config))
+
+	// Decisions snap to the increment
+	originalGOGC := debug.SetGCPercent(100)
+	defer debug.SetGCPercent(originalGOGC)
+	config.GOGCRounding = 25
+	config.TargetLatency = time.Nanosecond // Any real pause exceeds the target
+	config.MaxChangePerInterval = 33
+	runtime.GC()
+	for i := 0; i < 5; i++ {
+		tuner.metricsHistory.push(Metrics{
+			GCPauseTime: time.Millisecond,
+			CurrentGOGC: 100,
+			Timestamp:   time.Now(),
+		})
+	}
+
+	decision, err := tuner.Recommend()
+	require.NoError(t, err)
+	require.NotNil(t, decision)
+	assert.Equal(t, 125, decision.NewGOGC)
+
+	// Targets changed by the decision filter are rounded too
+	tuner.SetDecisionFilter(func(proposed TuningDecision) (TuningDecision, bool) {
+		proposed.NewGOGC = 142
+		return proposed, true
+	})
+	tuner.processDecision(*decision)
+	assert.Equal(t, 150, readGOGC())
+
+	// Rounding never cancels the change
+	tuner.SetDecisionFilter(func(proposed TuningDecision) (TuningDecision, bool) {
+		proposed.OldGOGC, proposed.NewGOGC = 150, 155
+		return proposed, true
+	})
+	tuner.processDecision(*decision)
+	assert.Equal(t, 155, readGOGC())
+}
+
+// TestBoundHit tests recording which bound a decision was clamped to
+func TestBoundHit(t *testing.T) {
+	originalGOGC := debug.SetGCPercent(100)
+	defer debug.SetGCPercent(originalGOGC)
+
+	config := DefaultConfig()
+	config.TargetLatency = time.Nanosecond // Any real pause exceeds the target
+	config.MaxChangePerInterval = 1000
+	config.MaxGOGC = 200
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	runtime.GC()
+	for i := 0; i < 5; i++ {
+		tuner.metricsHistory.push(Metrics{
+			GCPauseTime: time.Millisecond,
+			CurrentGOGC: 100,
+			Timestamp:   time.Now(),
+		})
+	}
+
+	decision, err := tuner.Recommend()
+	require.NoError(t, err)
+	require.NotNil(t, decision)
+	assert.Greater(t, decision.DesiredGOGC, 200)
+	assert.Equal(t, BoundHitMax, decision.BoundHit)
+	assert.Equal(t, 200, decision.NewGOGC)
+
+	tuner.processDecision(*decision)
+	stats := tuner.GetStats()
+	assert.Equal(t, int64(1), stats["bound_hits_max"])
+	assert.Equal(t, int64(0), stats["bound_hits_min"])
+
+	// Decisions clamped after the filter record the bound too
+	tuner.processDecision(TuningDecision{OldGOGC: 200, NewGOGC: 10, DesiredGOGC: 10, Timestamp: time.Now()})
+	assert.Equal(t, int64(1), tuner.GetStats()["bound_hits_min"])
+	assert.Equal(t, BoundHitMin, tuner.Decisions()[1].BoundHit)
+
+	// Within bounds nothing is recorded
+	tuner.processDecision(TuningDecision{OldGOGC: 50, NewGOGC: 100, DesiredGOGC: 100, Timestamp: time.Now()})
+	assert.Equal(t, BoundHitNone, tuner.Decisions()[2].BoundHit)
+
+	// The counter is exported per bound and the field shows in /decisions
+	obs := NewObservabilityServer(DefaultObservabilityConfig(), tuner)
+	w := httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics?format=prometheus", nil))
+	assert.Contains(t, w.Body.String(), `autotune_bound_hits_total{bound="max"} 1`)
+	assert.Contains(t, w.Body.String(), `autotune_bound_hits_total{bound="min"} 1`)
+
+	w = httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/decisions", nil))
+	assert.Contains(t, w.Body.String(), `"BoundHit":"max"`)
+}
+
+// TestGOGCBand tests cooperative mode band validation and enforcement
+func TestGOGCBand(t *testing.T) {
+	originalGOGC := debug.SetGCPercent(150)
+	defer debug.SetGCPercent(originalGOGC)
+
+	config := DefaultConfig()
+	config.GOGCBand = [2]int{40, 300}
+	assert.Error(t, validateConfig(config), "band below MinGOGC")
+	config.GOGCBand = [2]int{300, 200}
+	assert.Error(t, validateConfig(config), "inverted band")
+	config.GOGCBand = [2]int{100, 200}
+	require.NoError(t, validateConfig(config))
+
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	// Decisions never leave the band
+	tuner.processDecision(TuningDecision{OldGOGC: 150, NewGOGC: 600, Timestamp: time.Now()})
+	assert.Equal(t, 200, readGOGC())
+	tuner.processDecision(TuningDecision{OldGOGC: 200, NewGOGC: 60, Timestamp: time.Now()})
+	assert.Equal(t, 100, readGOGC())
+	assert.Equal(t, 100, tuner.clampGOGC(80))
+	assert.Equal(t, 170, tuner.clampGOGC(170))
+
+	// External changes within the band are adopted as a baseline
+	debug.SetGCPercent(120)
+	tuner.detectExternalGOGCChange()
+	assert.Equal(t, 120, tuner.lastGOGC)
+
+	// Stop restores the band center
+	require.NoError(t, tuner.Start())
+	require.NoError(t, tuner.Stop())
+	assert.Equal(t, 150, readGOGC())
+}
+
+// TestQoSAwareTuning tests the pressure baseline and aggressiveness per QoS class
+func TestQoSAwareTuning(t *testing.T) {
+	config := DefaultConfig()
+	config.MemoryRequestBytes = 256 << 20
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	limit := uint64(512 << 20)
+
+	// Burstable: the percentage applies to the headroom above the request
+	tuner.qosClass = detectQoSClass(tuner.memoryRequest, limit)
+	assert.Equal(t, QoSClassBurstable, tuner.qosClass)
+	assert.Equal(t, uint64(256<<20+214748364), tuner.memoryBaseline(limit)) // request + 80% of 256Mi
+	assert.Equal(t, config.TuningAggressiveness, tuner.aggressiveness())
+
+	// Guaranteed: the percentage applies to the whole limit and tuning is damped
+	tuner.memoryRequest = limit
+	tuner.qosClass = detectQoSClass(tuner.memoryRequest, limit)
+	assert.Equal(t, QoSClassGuaranteed, tuner.qosClass)
+	assert.Equal(t, uint64(float64(limit)*0.8), tuner.memoryBaseline(limit))
+	assert.InDelta(t, config.TuningAggressiveness*0.75, tuner.aggressiveness(), 1e-9)
+	assert.Equal(t, QoSClassGuaranteed, tuner.GetStats()["qos_class"])
+
+	metrics := Metrics{
+		GCPauseTime:    50 * time.Millisecond,
+		GCFrequency:    1.0,
+		MemoryPressure: 0.5,
+		CurrentGOGC:    100,
+	}
+	guaranteedTarget := tuner.calculateTargetGOGC(metrics)
+
+	tuner.qosClass = QoSClassUnknown
+	assert.Equal(t, uint64(float64(limit)*0.8), tuner.memoryBaseline(limit))
+	assert.Greater(t, tuner.calculateTargetGOGC(metrics), guaranteedTarget)
+}
+
+// TestMemoryPressureBasis tests that raw pressure is against the container
+// limit and adjusted pressure against the MemoryLimitPercent threshold
+func TestMemoryPressureBasis(t *testing.T) {
+	config := DefaultConfig()
+	config.MemoryLimitPercent = 0.5
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	limit := uint64(8 << 30)
+	tuner.containerResources = &ContainerResources{MemoryLimit: limit}
+	tuner.qosClass = QoSClassUnknown
+
+	metrics := tuner.collectMetrics()
+	require.NotZero(t, metrics.LiveHeap)
+	assert.Equal(t, limit/2, metrics.MemoryLimit)
+	assert.Equal(t, float64(metrics.LiveHeap)/float64(limit), metrics.MemoryPressureRaw)
+	assert.Equal(t, float64(metrics.LiveHeap)/float64(limit/2), metrics.MemoryPressureAdjusted)
+	assert.InDelta(t, 2*metrics.MemoryPressureRaw, metrics.MemoryPressureAdjusted, 1e-12)
+	assert.Equal(t, metrics.MemoryPressureAdjusted, metrics.MemoryPressure)
+
+	// Burstable: the threshold is the request plus a share of the headroom
+	tuner.memoryRequest = 4 << 30
+	tuner.qosClass = QoSClassBurstable
+	metrics = tuner.collectMetrics()
+	assert.Equal(t, uint64(6<<30), metrics.MemoryLimit)
+	assert.Equal(t, float64(metrics.LiveHeap)/float64(limit), metrics.MemoryPressureRaw)
+	assert.Equal(t, float64(metrics.LiveHeap)/float64(6<<30), metrics.MemoryPressureAdjusted)
+
+	// Without a limit there is no pressure
+	tuner.containerResources = nil
+	metrics = tuner.collectMetrics()
+	assert.Zero(t, metrics.MemoryPressureRaw)
+	assert.Zero(t, metrics.MemoryPressureAdjusted)
+	assert.Zero(t, metrics.MemoryPressure)
+}
+
+// TestMemoryUsageFromCgroup tests that in a container memory pressure is
+// computed from the cgroup's working set, falling back to the live heap
+func TestMemoryUsageFromCgroup(t *testing.T) {
+	originalReadFile := readFile
+	defer func() { readFile = originalReadFile }()
+	files := map[string]string{"/sys/fs/cgroup/memory.current": "3221225472\n"}
+	readFile = fixtureFileReader(files)
+
+	config := DefaultConfig()
+	config.MemoryLimitPercent = 0.5
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	tuner.qosClass = QoSClassUnknown
+
+	limit := uint64(8 << 30)
+	tuner.containerResources = &ContainerResources{MemoryLimit: limit, IsContainer: true}
+
+	metrics := tuner.collectMetrics()
+	assert.Equal(t, uint64(3<<30), metrics.MemoryUsage)
+	assert.Equal(t, 0.375, metrics.MemoryPressureRaw)
+	assert.Equal(t, 0.75, metrics.MemoryPressureAdjusted)
+
+	// Inactive page cache isn't counted
+	files["/sys/fs/cgroup/memory.current"] = "4294967296\n"
+	files["/sys/fs/cgroup/memory.stat"] = "anon 2147483648\nfile 2147483648\nactive_file 1073741824\ninactive_file 1073741824\n"
+	metrics = tuner.collectMetrics()
+	assert.Equal(t, uint64(3<<30), metrics.MemoryUsage)
+	assert.Equal(t, 0.375, metrics.MemoryPressureRaw)
+
+	// Without cgroup data the live heap is used
+	delete(files, "/sys/fs/cgroup/memory.current")
+	metrics = tuner.collectMetrics()
+	require.NotZero(t, metrics.LiveHeap)
+	assert.Equal(t, metrics.LiveHeap, metrics.MemoryUsage)
+
+	// Outside a container the cgroup isn't the process's own
+	files["/sys/fs/cgroup/memory.current"] = "3221225472\n"
+	tuner.containerResources.IsContainer = false
+	metrics = tuner.collectMetrics()
+	assert.Equal(t, metrics.LiveHeap, metrics.MemoryUsage)
+}
+
+// TestUseMemoryHigh tests computing adjusted pressure against memory.high
+// when it is below the memory limit
+func TestUseMemoryHigh(t *testing.T) {
+	config := DefaultConfig()
+	config.MemoryLimitPercent = 0.5
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	tuner.qosClass = QoSClassUnknown
+
+	limit := uint64(8 << 30)
+	tuner.containerResources = &ContainerResources{MemoryLimit: limit, MemoryHigh: 4 << 30}
+
+	// memory.high is ignored unless enabled
+	metrics := tuner.collectMetrics()
+	assert.Equal(t, limit/2, metrics.MemoryLimit)
+
+	config.UseMemoryHigh = true
+	metrics = tuner.collectMetrics()
+	assert.Equal(t, uint64(2<<30), metrics.MemoryLimit)
+	assert.Equal(t, float64(metrics.LiveHeap)/float64(2<<30), metrics.MemoryPressureAdjusted)
+	assert.Equal(t, float64(metrics.LiveHeap)/float64(limit), metrics.MemoryPressureRaw)
+
+	// A memory.high above the limit never applies
+	tuner.containerResources.MemoryHigh = 16 << 30
+	assert.Equal(t, limit, tuner.memoryCeiling(limit))
+}
+
 // TestHelperFunctions tests helper functions
 func TestHelperFunctions(t *testing.T) {
 	// Test abs function
@@ -461,7 +1350,7 @@ func TestBoundaryConditions(t *testing.T) {
 
 	// Add some history
 	for i := 0; i < 5; i++ {
-		tuner.metricsHistory = append(tuner.metricsHistory, metrics)
+		tuner.metricsHistory.push(metrics)
 	}
 
 	decision := tuner.makeTuningDecision(metrics)
@@ -515,7 +1404,7 @@ func BenchmarkTuningDecision(b *testing.B) {
 			CurrentGOGC:    100,
 			Timestamp:      time.Now(),
 		}
-		tuner.metricsHistory = append(tuner.metricsHistory, metrics)
+		tuner.metricsHistory.push(metrics)
 	}
 
 	testMetrics := Metrics{
@@ -532,6 +1421,236 @@ func BenchmarkTuningDecision(b *testing.B) {
 	}
 }
 
+// BenchmarkTuningCycle benchmarks a full tuning cycle at the minimum 1s
+// interval with a full metrics history and latency correlation enabled. The
+// per-cycle cost should not grow with the history size.
+func BenchmarkTuningCycle(b *testing.B) {
+	config := DefaultConfig()
+	config.MonitorInterval = time.Second
+	config.Logger = &mockLogger{}
+	tuner, err := NewTuner(config)
+	require.NoError(b, err)
+	tuner.SetLatencyProvider(func() time.Duration { return time.Millisecond })
+
+	for i := 0; i < tuner.metricsHistory.capacity(); i++ {
+		tuner.performTuningCycle()
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tuner.performTuningCycle()
+	}
+}
+
+// TestRecentPauseAverage tests reading recent pauses from the MemStats ring buffer
+func TestRecentPauseAverage(t *testing.T) {
+	var m runtime.MemStats
+	assert.Equal(t, time.Duration(0), recentPauseAverage(&m, 10))
+
+	m.NumGC = 3
+	m.PauseNs[0], m.PauseNs[1], m.PauseNs[2] = 100, 200, 600
+	assert.Equal(t, time.Duration(300), recentPauseAverage(&m, 10))
+	assert.Equal(t, time.Duration(400), recentPauseAverage(&m, 2))
+
+	// Wrapped ring buffer: the most recent pause is at (NumGC+255)%256
+	m = runtime.MemStats{NumGC: 257}
+	m.PauseNs[0], m.PauseNs[255] = 1000, 3000
+	assert.Equal(t, time.Duration(2000), recentPauseAverage(&m, 2))
+
+	// Matches the pause history reported by the runtime
+	runtime.GC()
+	runtime.ReadMemStats(&m)
+	var gcStats debug.GCStats
+	debug.ReadGCStats(&gcStats)
+	assert.Equal(t, gcStats.Pause[0], recentPauseAverage(&m, 1))
+}
+
+// TestFactorSmoothingAlpha tests that a higher alpha moves further towards
+// the target in a single step
+func TestFactorSmoothingAlpha(t *testing.T) {
+	metrics := Metrics{
+		CurrentGOGC:    100,
+		GCPauseTime:    30 * time.Millisecond,
+		MemoryPressure: 0.5,
+		GCFrequency:    1,
+	}
+
+	target := func(alpha float64) int {
+		config := DefaultConfig()
+		config.FactorSmoothingAlpha = alpha
+		tuner, err := NewTuner(config)
+		require.NoError(t, err)
+		tuner.qosClass = QoSClassUnknown
+		return tuner.calculateTargetGOGC(metrics)
+	}
+
+	smooth, reactive := target(0.1), target(0.9)
+	assert.Greater(t, smooth, 100)
+	assert.Greater(t, reactive, smooth)
+	assert.Equal(t, target(defaultFactorSmoothingAlpha), target(0))
+
+	for _, alpha := range []float64{-0.1, 1.1} {
+		config := DefaultConfig()
+		config.FactorSmoothingAlpha = alpha
+		assert.Error(t, validateConfig(config))
+	}
+}
+
+// TestCPUAwareness tests that high CPU usage together with high GC CPU
+// overhead raises GOGC, weighted by Config.CPUAwareness
+func TestCPUAwareness(t *testing.T) {
+	metrics := Metrics{
+		CurrentGOGC:    100,
+		GCPauseTime:    10 * time.Millisecond,
+		MemoryPressure: 0.5,
+		GCFrequency:    1,
+		CPUUsage:       0.9,
+		GCCPUFraction:  0.2,
+	}
+
+	target := func(awareness float64, metrics Metrics) (int, DecisionCategory) {
+		config := DefaultConfig()
+		config.CPUAwareness = awareness
+		tuner, err := NewTuner(config)
+		require.NoError(t, err)
+		tuner.qosClass = QoSClassUnknown
+		return tuner.calculateTarget(metrics)
+	}
+
+	disabled, _ := target(0, metrics)
+	half, _ := target(0.5, metrics)
+	full, category := target(1, metrics)
+	assert.Equal(t, 100, disabled)
+	assert.Greater(t, half, disabled)
+	assert.Greater(t, full, half)
+	assert.Equal(t, CategoryFrequency, category)
+
+	// An inactive CPU factor doesn't dilute the other factors
+	for _, inactive := range []Metrics{
+		{CurrentGOGC: 100, GCPauseTime: 30 * time.Millisecond, MemoryPressure: 0.5, GCFrequency: 1, CPUUsage: 0.5, GCCPUFraction: 0.2},
+		{CurrentGOGC: 100, GCPauseTime: 30 * time.Millisecond, MemoryPressure: 0.5, GCFrequency: 1, CPUUsage: 0.9, GCCPUFraction: 0.01},
+	} {
+		enabled, _ := target(1, inactive)
+		off, _ := target(0, inactive)
+		assert.Greater(t, off, 100)
+		assert.Equal(t, off, enabled)
+	}
+
+	for _, awareness := range []float64{-0.1, 1.1} {
+		config := DefaultConfig()
+		config.CPUAwareness = awareness
+		assert.Error(t, validateConfig(config))
+	}
+}
+
+// FuzzCalculateTargetGOGC tests that extreme metrics always produce a
+// finite target within the adjustment range and the GOGC bounds
+func FuzzCalculateTargetGOGC(f *testing.F) {
+	f.Add(100, int64(time.Hour), 1000.0, 0.5, 0.0, 1.0)
+	f.Add(100, int64(0), 0.0, 0.0, 0.0, 1.0)
+	f.Add(800, int64(time.Nanosecond), -1.0, 5.0, 100.0, 2.0)
+	f.Add(-1, int64(time.Millisecond), math.NaN(), math.Inf(1), math.NaN(), 0.1)
+	f.Add(1<<30, int64(math.MaxInt64), math.MaxFloat64, -math.MaxFloat64, 0.0, 1.0)
+
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(f, err)
+	minGOGC, maxGOGC := tuner.bounds()
+
+	f.Fuzz(func(t *testing.T, gogc int, pause int64, frequency, pressure, psi, aggressiveness float64) {
+		if gogc < 0 || gogc > 1<<30 || aggressiveness < 0.1 || aggressiveness > 2 {
+			t.Skip()
+		}
+		tuner.config.TuningAggressiveness = aggressiveness
+
+		metrics := Metrics{
+			CurrentGOGC:        gogc,
+			GCPauseTime:        time.Duration(pause),
+			GCFrequency:        frequency,
+			MemoryPressure:     pressure,
+			MemoryPSI:          psi,
+			MemoryPSIAvailable: psi > 0,
+		}
+
+		target := tuner.calculateTargetGOGC(metrics)
+		assert.GreaterOrEqual(t, target, int(float64(gogc)*minFactor))
+		assert.LessOrEqual(t, target, int(float64(gogc)*maxFactor))
+
+		clamped := tuner.clampGOGC(target)
+		assert.GreaterOrEqual(t, clamped, minGOGC)
+		assert.LessOrEqual(t, clamped, maxGOGC)
+	})
+}
+
+// TestSanitizeFactor tests clamping of non-finite and extreme factors
+func TestSanitizeFactor(t *testing.T) {
+	assert.Equal(t, 1.0, sanitizeFactor(math.NaN()))
+	assert.Equal(t, maxFactor, sanitizeFactor(math.Inf(1)))
+	assert.Equal(t, minFactor, sanitizeFactor(math.Inf(-1)))
+	assert.Equal(t, minFactor, sanitizeFactor(-3))
+	assert.Equal(t, 1.5, sanitizeFactor(1.5))
+}
+
+// TestGCFrequencyExcludesForcedGCs tests that forced GCs don't trigger the
+// frequency factor
+func TestGCFrequencyExcludesForcedGCs(t *testing.T) {
+	config := DefaultConfig()
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	base := time.Now()
+	prev := Metrics{NumGC: 100, NumForcedGC: 10, Timestamp: base}
+	cur := Metrics{NumGC: 200, NumForcedGC: 105, Timestamp: base.Add(10 * time.Second)}
+
+	// 100 GCs in 10s, of which 95 were forced
+	assert.InDelta(t, 0.5, gcFrequency(prev, cur), 1e-9)
+
+	// Neutral pause and pressure, so only the frequency factor could move the target
+	cur.CurrentGOGC = 100
+	cur.GCPauseTime = config.TargetLatency
+	cur.MemoryPressure = 0.5
+	cur.GCFrequency = gcFrequency(prev, cur)
+	assert.Equal(t, 100, tuner.calculateTargetGOGC(cur))
+
+	cur.GCFrequency = 10
+	assert.Greater(t, tuner.calculateTargetGOGC(cur), 100)
+
+	// Inconsistent counters fall back to counting every GC
+	assert.InDelta(t, 10.0, gcFrequency(Metrics{NumForcedGC: 5, Timestamp: base}, Metrics{NumGC: 100, Timestamp: base.Add(10 * time.Second)}), 1e-9)
+	assert.Equal(t, 0.0, gcFrequency(cur, prev))
+}
+
+// TestGCCPUFraction tests measuring the GC's CPU share per interval rather
+// than over the process lifetime
+func TestGCCPUFraction(t *testing.T) {
+	prev := Metrics{GCCPUSeconds: 1, TotalCPUSeconds: 100}
+
+	fraction, ok := gcCPUFraction(prev, Metrics{GCCPUSeconds: 3, TotalCPUSeconds: 110})
+	assert.True(t, ok)
+	assert.InDelta(t, 0.2, fraction, 1e-9)
+
+	fraction, ok = gcCPUFraction(prev, prev)
+	assert.True(t, ok, "no GC since the previous sample")
+	assert.Equal(t, 0.0, fraction)
+
+	_, ok = gcCPUFraction(prev, Metrics{})
+	assert.False(t, ok, "counters missing")
+	_, ok = gcCPUFraction(prev, Metrics{GCCPUSeconds: 0.5, TotalCPUSeconds: 90})
+	assert.False(t, ok, "counters went backwards")
+
+	// Collected metrics use the counters once there is a previous sample
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+	runtime.GC()
+	first := tuner.collectMetrics()
+	tuner.metricsHistory.push(first)
+	runtime.GC()
+	second := tuner.collectMetrics()
+	require.Greater(t, second.TotalCPUSeconds, first.TotalCPUSeconds)
+	expected := (second.GCCPUSeconds - first.GCCPUSeconds) / (second.TotalCPUSeconds - first.TotalCPUSeconds)
+	assert.InDelta(t, expected, second.GCCPUFraction, 1e-12)
+}
+
 // TestEdgeCases tests various edge cases
 func TestEdgeCases(t *testing.T) {
 	tuner, err := NewTuner(DefaultConfig())
@@ -553,7 +1672,7 @@ func TestEdgeCases(t *testing.T) {
 
 	// Add some history
 	for i := 0; i < 5; i++ {
-		tuner.metricsHistory = append(tuner.metricsHistory, metrics)
+		tuner.metricsHistory.push(metrics)
 	}
 
 	decision = tuner.makeTuningDecision(metrics)
@@ -0,0 +1,98 @@
+package autotune
+
+// Memory ballast support.
+//
+// A ballast is a large allocation that is never touched. It counts towards
+// the heap size the GC paces against, so the heap can grow further before the
+// next collection without the pages ever becoming resident. This was the usual
+// way to reduce GC frequency before GOMEMLIMIT existed; on Go 1.19 and later
+// a soft memory limit achieves the same without the trick and should be
+// preferred. Ballast support is kept for runtimes and deployments where a
+// memory limit cannot be used.
+
+// ballastResizeThreshold is the relative size difference below which the
+// ballast is left alone, so that small fluctuations don't cause reallocation
+const ballastResizeThreshold = 0.1
+
+// ballastTarget returns the ballast size that keeps the next GC target within
+// the memory baseline.
+//
+// With a ballast of size B and a live heap L (excluding the ballast), the
+// runtime triggers the next GC at about (L+B)*(1+GOGC/100). Solving for B so
+// that this stays at or below the baseline gives the largest safe ballast.
+// The result is capped at maxBytes when that is non-zero. When the baseline
+// is unknown only an explicit maxBytes allows a ballast at all, and there is
+// no ballast while GC is off.
+func ballastTarget(baseline, live uint64, gogc int, maxBytes uint64) uint64 {
+	if gogc < 0 {
+		// GC is off, a ballast has no effect
+		return 0
+	}
+	if baseline == 0 {
+		return maxBytes
+	}
+
+	maxHeap := float64(baseline) / (1 + float64(gogc)/100)
+	if maxHeap <= float64(live) {
+		return 0
+	}
+
+	target := uint64(maxHeap - float64(live))
+	if maxBytes > 0 && target > maxBytes {
+		target = maxBytes
+	}
+	return target
+}
+
+// ballastSize returns the current ballast size in bytes
+func (t *Tuner) ballastSize() uint64 {
+	return uint64(len(t.ballast))
+}
+
+// adjustBallast resizes the ballast for the current metrics. It must be
+// called after any GOGC change for the cycle so the new GOGC is accounted for.
+func (t *Tuner) adjustBallast(metrics Metrics) {
+	if !t.config.EnableBallast {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	target := ballastTarget(metrics.MemoryLimit, metrics.LiveHeap, readGOGC(), t.config.MaxBallastBytes)
+	current := t.ballastSize()
+
+	// Always shrink when the ballast would push towards the memory limit,
+	// otherwise only resize on significant changes
+	if target < current || absDiffRatio(target, current) >= ballastResizeThreshold {
+		t.setBallast(target)
+	}
+}
+
+// setBallast replaces the ballast with one of the given size. The previous
+// ballast becomes garbage and is released by the next GC cycle.
+func (t *Tuner) setBallast(size uint64) {
+	if size == t.ballastSize() {
+		return
+	}
+
+	old := t.ballastSize()
+	if size == 0 {
+		t.ballast = nil
+	} else {
+		t.ballast = make([]byte, size)
+	}
+
+	t.config.Logger.Debug("Resized memory ballast from %d to %d bytes", old, size)
+}
+
+// absDiffRatio returns |a-b| relative to the larger of the two
+func absDiffRatio(a, b uint64) float64 {
+	if a == b {
+		return 0
+	}
+	if a < b {
+		a, b = b, a
+	}
+	return float64(a-b) / float64(a)
+}
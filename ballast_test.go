@@ -0,0 +1,71 @@
+package autotune
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBallastTarget tests ballast sizing against the memory headroom
+func TestBallastTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseline uint64
+		live     uint64
+		gogc     int
+		maxBytes uint64
+		expected uint64
+	}{
+		{"headroom at GOGC 100", 400 << 20, 100 << 20, 100, 0, 100 << 20},
+		{"capped", 400 << 20, 100 << 20, 100, 50 << 20, 50 << 20},
+		{"no headroom", 400 << 20, 250 << 20, 100, 0, 0},
+		{"higher GOGC leaves less room", 400 << 20, 50 << 20, 300, 0, 50 << 20},
+		{"unknown limit uses cap", 0, 100 << 20, 100, 64 << 20, 64 << 20},
+		{"unknown limit without cap", 0, 100 << 20, 100, 0, 0},
+		{"GC off", 400 << 20, 0, -1, 64 << 20, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ballastTarget(tt.baseline, tt.live, tt.gogc, tt.maxBytes))
+		})
+	}
+}
+
+// TestAdjustBallast tests ballast resizing and reporting
+func TestAdjustBallast(t *testing.T) {
+	originalGOGC := debug.SetGCPercent(100)
+	defer debug.SetGCPercent(originalGOGC)
+
+	config := DefaultConfig()
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	// Disabled by default
+	tuner.adjustBallast(Metrics{MemoryLimit: 400 << 20, LiveHeap: 100 << 20})
+	assert.Equal(t, uint64(0), tuner.GetStats()["ballast_bytes"])
+
+	config.EnableBallast = true
+	tuner.adjustBallast(Metrics{MemoryLimit: 400 << 20, LiveHeap: 100 << 20})
+	assert.Equal(t, uint64(100<<20), tuner.GetStats()["ballast_bytes"])
+
+	// Small changes are ignored
+	tuner.adjustBallast(Metrics{MemoryLimit: 400 << 20, LiveHeap: 99 << 20})
+	assert.Equal(t, uint64(100<<20), tuner.ballastSize())
+
+	// Shrinks as soon as headroom drops
+	tuner.adjustBallast(Metrics{MemoryLimit: 400 << 20, LiveHeap: 101 << 20})
+	assert.Equal(t, uint64(99<<20), tuner.ballastSize())
+
+	// Released entirely when there is no headroom
+	tuner.adjustBallast(Metrics{MemoryLimit: 400 << 20, LiveHeap: 300 << 20})
+	assert.Equal(t, uint64(0), tuner.ballastSize())
+
+	// Released on stop
+	tuner.adjustBallast(Metrics{MemoryLimit: 400 << 20, LiveHeap: 100 << 20})
+	require.NoError(t, tuner.Start())
+	require.NoError(t, tuner.Stop())
+	assert.Equal(t, uint64(0), tuner.ballastSize())
+}
@@ -0,0 +1,188 @@
+package autotune
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// binaryMetricsVersion is the current version of the binary metrics layout.
+// Bump it and add a new record type when the layout changes so that older
+// recordings can still be decoded.
+const binaryMetricsVersion byte = 2
+
+// binaryMetricsV1 is the fixed little-endian layout of the core numeric
+// metrics. Every field has a fixed size so records can be written and read
+// without any per-field framing.
+type binaryMetricsV1 struct {
+	Timestamp         int64 // Unix nanoseconds
+	GCPauseTime       int64 // Nanoseconds
+	GCFrequency       float64
+	HeapSize          uint64
+	HeapAlloc         uint64
+	HeapInuse         uint64
+	LiveHeap          uint64
+	NextGC            uint64
+	LastGC            int64 // Unix nanoseconds, 0 if never
+	NumGC             uint32
+	CurrentGOGC       int32
+	MemoryLimit       uint64
+	MemoryUsage       uint64
+	MemoryPressure    float64
+	CPUUsage          float64
+	Throughput        float64
+	ContainerMemLimit uint64
+	ContainerCPULimit float64
+}
+
+// binaryMetricsV2 extends the v1 layout with the remaining inputs of the
+// decision logic, so a recorded trace replays into Simulate without
+// silently dropping them
+type binaryMetricsV2 struct {
+	binaryMetricsV1
+	NumForcedGC        uint32
+	MemoryPSIAvailable bool
+	MemoryPSI          float64
+	RequestLatency     int64 // Nanoseconds
+	GCPauseP95         int64 // Nanoseconds
+	GCPauseP99         int64 // Nanoseconds
+	AllocRate          uint64
+}
+
+// binaryRecordSizes maps each binary metrics version to its encoded record
+// size in bytes, including the version byte
+var binaryRecordSizes = map[byte]int{
+	1: 1 + binary.Size(binaryMetricsV1{}),
+	2: 1 + binary.Size(binaryMetricsV2{}),
+}
+
+// BinaryMetricsSize is the encoded size in bytes of one metrics record in
+// the current version
+var BinaryMetricsSize = binaryRecordSizes[binaryMetricsVersion]
+
+// EncodeBinaryMetrics encodes the numeric metrics into the compact
+// versioned binary layout. The pause list, the cumulative CPU and
+// allocation counters and the custom metrics are not encoded.
+func EncodeBinaryMetrics(metrics Metrics) []byte {
+	core := binaryMetricsV1{
+		Timestamp:         unixNanoOrZero(metrics.Timestamp),
+		GCPauseTime:       int64(metrics.GCPauseTime),
+		GCFrequency:       metrics.GCFrequency,
+		HeapSize:          metrics.HeapSize,
+		HeapAlloc:         metrics.HeapAlloc,
+		HeapInuse:         metrics.HeapInuse,
+		LiveHeap:          metrics.LiveHeap,
+		NextGC:            metrics.NextGC,
+		LastGC:            unixNanoOrZero(metrics.LastGC),
+		NumGC:             metrics.NumGC,
+		CurrentGOGC:       int32(metrics.CurrentGOGC),
+		MemoryLimit:       metrics.MemoryLimit,
+		MemoryUsage:       metrics.MemoryUsage,
+		MemoryPressure:    metrics.MemoryPressure,
+		CPUUsage:          metrics.CPUUsage,
+		Throughput:        metrics.Throughput,
+		ContainerMemLimit: metrics.ContainerMemLimit,
+		ContainerCPULimit: metrics.ContainerCPULimit,
+	}
+	record := binaryMetricsV2{
+		binaryMetricsV1:    core,
+		NumForcedGC:        metrics.NumForcedGC,
+		MemoryPSIAvailable: metrics.MemoryPSIAvailable,
+		MemoryPSI:          metrics.MemoryPSI,
+		RequestLatency:     int64(metrics.RequestLatency),
+		GCPauseP95:         int64(metrics.GCPauseP95),
+		GCPauseP99:         int64(metrics.GCPauseP99),
+		AllocRate:          metrics.AllocRate,
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(BinaryMetricsSize)
+	buf.WriteByte(binaryMetricsVersion)
+	// Writing fixed-size fields to a bytes.Buffer cannot fail
+	_ = binary.Write(&buf, binary.LittleEndian, &record)
+
+	return buf.Bytes()
+}
+
+// DecodeBinaryMetrics decodes a record produced by EncodeBinaryMetrics or
+// MetricsExporter.ExportBinary, in the current or an earlier version.
+// Fields an earlier version didn't record are left zero.
+func DecodeBinaryMetrics(data []byte) (Metrics, error) {
+	if len(data) == 0 {
+		return Metrics{}, fmt.Errorf("empty binary metrics record")
+	}
+
+	version := data[0]
+	size, ok := binaryRecordSizes[version]
+	if !ok {
+		return Metrics{}, fmt.Errorf("unsupported binary metrics version %d", version)
+	}
+	if len(data) != size {
+		return Metrics{}, fmt.Errorf("binary metrics v%d record is %d bytes, expected %d", version, len(data), size)
+	}
+
+	var record binaryMetricsV2
+	var err error
+	switch version {
+	case 1:
+		err = binary.Read(bytes.NewReader(data[1:]), binary.LittleEndian, &record.binaryMetricsV1)
+	default:
+		err = binary.Read(bytes.NewReader(data[1:]), binary.LittleEndian, &record)
+	}
+	if err != nil {
+		return Metrics{}, fmt.Errorf("failed to decode binary metrics: %w", err)
+	}
+
+	return Metrics{
+		GCPauseTime:        time.Duration(record.GCPauseTime),
+		GCPauseP95:         time.Duration(record.GCPauseP95),
+		GCPauseP99:         time.Duration(record.GCPauseP99),
+		GCFrequency:        record.GCFrequency,
+		HeapSize:           record.HeapSize,
+		HeapAlloc:          record.HeapAlloc,
+		HeapInuse:          record.HeapInuse,
+		LiveHeap:           record.LiveHeap,
+		NextGC:             record.NextGC,
+		LastGC:             timeFromUnixNano(record.LastGC),
+		NumGC:              record.NumGC,
+		NumForcedGC:        record.NumForcedGC,
+		AllocRate:          record.AllocRate,
+		MemoryLimit:        record.MemoryLimit,
+		MemoryUsage:        record.MemoryUsage,
+		MemoryPressure:     record.MemoryPressure,
+		MemoryPSI:          record.MemoryPSI,
+		MemoryPSIAvailable: record.MemoryPSIAvailable,
+		CPUUsage:           record.CPUUsage,
+		Throughput:         record.Throughput,
+		RequestLatency:     time.Duration(record.RequestLatency),
+		ContainerMemLimit:  record.ContainerMemLimit,
+		ContainerCPULimit:  record.ContainerCPULimit,
+		CurrentGOGC:        int(record.CurrentGOGC),
+		Timestamp:          timeFromUnixNano(record.Timestamp),
+	}, nil
+}
+
+// binaryRecordSize returns the size of the record starting data, from its
+// version byte, or false when data is empty or the version unknown
+func binaryRecordSize(data []byte) (int, bool) {
+	if len(data) == 0 {
+		return 0, false
+	}
+	size, ok := binaryRecordSizes[data[0]]
+	return size, ok
+}
+
+func unixNanoOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func timeFromUnixNano(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
@@ -0,0 +1,127 @@
+package autotune
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBinaryMetricsRoundTrip tests encoding and decoding of binary metrics
+func TestBinaryMetricsRoundTrip(t *testing.T) {
+	now := time.Now()
+	metrics := Metrics{
+		GCPauseTime:        3 * time.Millisecond,
+		GCPauseP95:         5 * time.Millisecond,
+		GCPauseP99:         9 * time.Millisecond,
+		GCFrequency:        1.5,
+		HeapSize:           64 << 20,
+		HeapAlloc:          40 << 20,
+		HeapInuse:          48 << 20,
+		LiveHeap:           30 << 20,
+		NextGC:             80 << 20,
+		LastGC:             now.Add(-time.Second),
+		NumGC:              42,
+		NumForcedGC:        3,
+		AllocRate:          64 << 20,
+		MemoryLimit:        400 << 20,
+		MemoryUsage:        30 << 20,
+		MemoryPressure:     0.075,
+		MemoryPSI:          12.5,
+		MemoryPSIAvailable: true,
+		CPUUsage:           0.5,
+		Throughput:         1200,
+		RequestLatency:     8 * time.Millisecond,
+		ContainerMemLimit:  512 << 20,
+		ContainerCPULimit:  2,
+		CurrentGOGC:        150,
+		Timestamp:          now,
+	}
+
+	data := EncodeBinaryMetrics(metrics)
+	assert.Len(t, data, BinaryMetricsSize)
+
+	decoded, err := DecodeBinaryMetrics(data)
+	require.NoError(t, err)
+
+	assert.True(t, metrics.Timestamp.Equal(decoded.Timestamp))
+	assert.True(t, metrics.LastGC.Equal(decoded.LastGC))
+	decoded.Timestamp, metrics.Timestamp = time.Time{}, time.Time{}
+	decoded.LastGC, metrics.LastGC = time.Time{}, time.Time{}
+	assert.Equal(t, metrics, decoded)
+
+	// Zero times stay zero
+	decoded, err = DecodeBinaryMetrics(EncodeBinaryMetrics(Metrics{CurrentGOGC: -1}))
+	require.NoError(t, err)
+	assert.True(t, decoded.Timestamp.IsZero())
+	assert.Equal(t, -1, decoded.CurrentGOGC)
+}
+
+// encodeBinaryMetricsV1 encodes metrics in the version 1 layout, as
+// recorded before the decision inputs were added
+func encodeBinaryMetricsV1(metrics Metrics) []byte {
+	data := EncodeBinaryMetrics(metrics)
+	data[0] = 1
+	return data[:binaryRecordSizes[1]]
+}
+
+// TestDecodeBinaryMetricsV1 tests that version 1 records still decode, with
+// the fields they didn't record left zero
+func TestDecodeBinaryMetricsV1(t *testing.T) {
+	data := encodeBinaryMetricsV1(Metrics{GCPauseTime: time.Millisecond, NumForcedGC: 2, CurrentGOGC: 150, ContainerCPULimit: 2})
+
+	decoded, err := DecodeBinaryMetrics(data)
+	require.NoError(t, err)
+	assert.Equal(t, Metrics{GCPauseTime: time.Millisecond, CurrentGOGC: 150, ContainerCPULimit: 2}, decoded)
+
+	_, err = DecodeBinaryMetrics(data[:len(data)-1])
+	assert.Error(t, err)
+}
+
+// TestDecodeBinaryMetricsErrors tests rejection of malformed records
+func TestDecodeBinaryMetricsErrors(t *testing.T) {
+	_, err := DecodeBinaryMetrics(nil)
+	assert.Error(t, err)
+
+	data := EncodeBinaryMetrics(Metrics{})
+	_, err = DecodeBinaryMetrics(data[:len(data)-1])
+	assert.Error(t, err)
+
+	data[0] = 99
+	_, err = DecodeBinaryMetrics(data)
+	assert.Error(t, err)
+}
+
+// TestExportBinary tests binary export through the metrics exporter
+func TestExportBinary(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	data, err := NewMetricsExporter(tuner).ExportBinary()
+	require.NoError(t, err)
+
+	decoded, err := DecodeBinaryMetrics(data)
+	require.NoError(t, err)
+	assert.NotZero(t, decoded.HeapAlloc)
+	assert.False(t, decoded.Timestamp.IsZero())
+}
+
+// BenchmarkBinaryVsJSONSize compares the binary and JSON encodings of one sample
+func BenchmarkBinaryVsJSONSize(b *testing.B) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(b, err)
+	metrics := tuner.GetMetrics()
+
+	jsonData, err := json.Marshal(metrics)
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = EncodeBinaryMetrics(metrics)
+	}
+
+	b.ReportMetric(float64(BinaryMetricsSize), "binary-bytes")
+	b.ReportMetric(float64(len(jsonData)), "json-bytes")
+}
@@ -0,0 +1,68 @@
+package autotune
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// minBoundsSamples is the number of decisions RecommendBounds needs
+	// before it suggests anything other than the current bounds
+	minBoundsSamples = 10
+	// boundsLowPercentile and boundsHighPercentile are the percentiles of
+	// desired targets the recommended bounds contain
+	boundsLowPercentile  = 0.05
+	boundsHighPercentile = 0.95
+)
+
+// RecommendBounds suggests MinGOGC/MaxGOGC values from the targets the
+// algorithm wanted over the decision history, including those clamped by
+// the current bounds: the result contains the 5th to 95th percentile of
+// desired targets, so outliers don't widen it. It returns the configured
+// bounds unchanged until 10 decisions have been recorded. Targets that were
+// clamped only show how far beyond a bound the algorithm wanted to go, so
+// after widening a bound it is worth calling it again later.
+func (t *Tuner) RecommendBounds() (min, max int) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.decisionHistory.len() < minBoundsSamples {
+		return t.config.MinGOGC, t.config.MaxGOGC
+	}
+
+	desired := make([]int, 0, t.decisionHistory.len())
+	for _, decision := range t.decisionHistory.items() {
+		target := decision.DesiredGOGC
+		if target <= 0 {
+			target = decision.NewGOGC
+		}
+		desired = append(desired, target)
+	}
+	sort.Ints(desired)
+
+	// Stay within the limits validateConfig accepts
+	min = clampInt(percentileInt(desired, boundsLowPercentile), 10, 1000)
+	max = clampInt(percentileInt(desired, boundsHighPercentile), min, 2000)
+	return min, max
+}
+
+// percentileInt returns the q-th quantile of sorted values using the
+// nearest-rank method
+func percentileInt(sorted []int, q float64) int {
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// clampInt clamps value to [low, high]
+func clampInt(value, low, high int) int {
+	if value < low {
+		return low
+	}
+	if value > high {
+		return high
+	}
+	return value
+}
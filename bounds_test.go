@@ -0,0 +1,50 @@
+package autotune
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecommendBounds tests suggesting bounds from desired targets
+func TestRecommendBounds(t *testing.T) {
+	config := DefaultConfig()
+	config.MinGOGC = 50
+	config.MaxGOGC = 200
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	// Not enough history yet
+	min, max := tuner.RecommendBounds()
+	assert.Equal(t, 50, min)
+	assert.Equal(t, 200, max)
+
+	// Desired targets 100, 105, ..., 595 with one outlier at each end; the
+	// targets above 200 were clamped to MaxGOGC, in a history large enough
+	// to hold all 102
+	tuner.decisionHistory = newRing[TuningDecision](102)
+	tuner.decisionHistory.push(TuningDecision{NewGOGC: 50, DesiredGOGC: 12})
+	for target := 100; target < 600; target += 5 {
+		tuner.decisionHistory.push(TuningDecision{
+			NewGOGC:     clampInt(target, 50, 200),
+			DesiredGOGC: target,
+			Timestamp:   time.Now(),
+		})
+	}
+	tuner.decisionHistory.push(TuningDecision{NewGOGC: 200, DesiredGOGC: 1900})
+
+	min, max = tuner.RecommendBounds()
+	assert.Equal(t, 120, min) // 5th percentile of 102 targets
+	assert.Equal(t, 575, max) // 95th percentile
+
+	// Results stay within the valid range
+	tuner.decisionHistory.reset(nil)
+	for i := 0; i < minBoundsSamples; i++ {
+		tuner.decisionHistory.push(TuningDecision{NewGOGC: 50, DesiredGOGC: 5})
+	}
+	min, max = tuner.RecommendBounds()
+	assert.Equal(t, 10, min)
+	assert.Equal(t, 10, max)
+}
@@ -0,0 +1,109 @@
+package autotune
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// DecisionCategory classifies why GOGC changed, for breakdowns of the
+// tuner's activity
+type DecisionCategory string
+
+const (
+	// CategoryLatency means GC pause time moved the target most
+	CategoryLatency DecisionCategory = "latency"
+	// CategoryMemory means memory pressure (or memory PSI) moved the target
+	// most
+	CategoryMemory DecisionCategory = "memory"
+	// CategoryFrequency means GC frequency moved the target most
+	CategoryFrequency DecisionCategory = "frequency"
+	// CategoryMixed means no single factor dominated
+	CategoryMixed DecisionCategory = "mixed"
+	// CategoryRevert means the decision undid an earlier one that regressed
+	// the metrics, see Config.RevertThreshold
+	CategoryRevert DecisionCategory = "revert"
+	// CategoryManual means GOGC was set with SetGOGC
+	CategoryManual DecisionCategory = "manual"
+	// CategorySchedule is reserved for scheduled GOGC changes
+	CategorySchedule DecisionCategory = "schedule"
+)
+
+// decisionCategories lists the categories in export order
+var decisionCategories = []DecisionCategory{
+	CategoryLatency,
+	CategoryMemory,
+	CategoryFrequency,
+	CategoryMixed,
+	CategoryRevert,
+	CategoryManual,
+	CategorySchedule,
+}
+
+// dominanceRatio is how many times further from 1 than every other factor
+// the largest factor must be to dominate a decision
+const dominanceRatio = 2.0
+
+// dominantCategory returns the category of the factor that moved the target
+// most, or CategoryMixed when none moved it at least dominanceRatio times as
+// far as each of the others
+func dominantCategory(latencyFactor, memoryFactor, frequencyFactor float64) DecisionCategory {
+	factors := []struct {
+		category DecisionCategory
+		effect   float64
+	}{
+		{CategoryLatency, math.Abs(latencyFactor - 1)},
+		{CategoryMemory, math.Abs(memoryFactor - 1)},
+		{CategoryFrequency, math.Abs(frequencyFactor - 1)},
+	}
+
+	best := 0
+	for i := range factors {
+		if factors[i].effect > factors[best].effect {
+			best = i
+		}
+	}
+	if factors[best].effect == 0 {
+		return CategoryMixed
+	}
+	for i := range factors {
+		if i != best && factors[i].effect*dominanceRatio > factors[best].effect {
+			return CategoryMixed
+		}
+	}
+	return factors[best].category
+}
+
+// recordCategoryLocked counts a GOGC change by category. t.mu must be held
+// for writing.
+func (t *Tuner) recordCategoryLocked(category DecisionCategory) {
+	if category == "" {
+		return
+	}
+	if t.categoryCounts == nil {
+		t.categoryCounts = make(map[DecisionCategory]int64)
+	}
+	t.categoryCounts[category]++
+}
+
+// categoryCountsLocked returns the GOGC changes per category, with every
+// category present. t.mu must be held.
+func (t *Tuner) categoryCountsLocked() map[DecisionCategory]int64 {
+	counts := make(map[DecisionCategory]int64, len(decisionCategories))
+	for _, category := range decisionCategories {
+		counts[category] = t.categoryCounts[category]
+	}
+	return counts
+}
+
+// writeCategoryMetric writes autotune_decisions_by_category_total with one
+// sample per category
+func writeCategoryMetric(w io.Writer, labels map[string]string, counts map[DecisionCategory]int64) {
+	const name = "autotune_decisions_by_category_total"
+
+	fmt.Fprintf(w, "# HELP %s %s\n", name, metricDescriptors[name].Help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricDescriptors[name].Type)
+	for _, category := range decisionCategories {
+		fmt.Fprintf(w, "%s%s %d\n", name, withLabel(labels, "category", string(category)), counts[category])
+	}
+}
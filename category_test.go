@@ -0,0 +1,86 @@
+package autotune
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDecisionCategory tests classifying decisions by their dominant factor
+func TestDecisionCategory(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+	tuner.memoryReturn = MemoryReturnSettings{}
+
+	neutral := Metrics{
+		GCPauseTime:    10 * time.Millisecond, // at target
+		GCFrequency:    1.0,
+		MemoryPressure: 0.6,
+		CurrentGOGC:    100,
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(m *Metrics)
+		expected DecisionCategory
+	}{
+		{"latency", func(m *Metrics) { m.GCPauseTime = 50 * time.Millisecond }, CategoryLatency},
+		{"memory", func(m *Metrics) { m.MemoryPressure = 0.98 }, CategoryMemory},
+		{"memory PSI", func(m *Metrics) { m.MemoryPSIAvailable, m.MemoryPSI = true, 50 }, CategoryMemory},
+		{"frequency", func(m *Metrics) { m.GCFrequency = 20 }, CategoryFrequency},
+		{"mixed", func(m *Metrics) {
+			m.GCPauseTime = 20 * time.Millisecond // latency factor 1.3
+			m.MemoryPressure = 0.2                // memory factor 1.09
+			m.GCFrequency = 12                    // frequency factor 1.3
+		}, CategoryMixed},
+		{"no change", func(m *Metrics) {}, CategoryMixed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := neutral
+			tt.modify(&metrics)
+			_, category := tuner.calculateTarget(metrics)
+			assert.Equal(t, tt.expected, category)
+		})
+	}
+}
+
+// TestDecisionCategoryExport tests counting and exporting decision categories
+func TestDecisionCategoryExport(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	tuner.processDecision(TuningDecision{OldGOGC: 100, NewGOGC: 150, Category: CategoryLatency, Timestamp: time.Now()})
+	tuner.processDecision(TuningDecision{OldGOGC: 150, NewGOGC: 120, Category: CategoryMemory, Timestamp: time.Now()})
+	tuner.processDecision(TuningDecision{OldGOGC: 120, NewGOGC: 160, Category: CategoryLatency, Timestamp: time.Now()})
+	require.NoError(t, tuner.SetGOGC(200))
+
+	counts := tuner.GetStats()["decision_categories"].(map[DecisionCategory]int64)
+	assert.Equal(t, int64(2), counts[CategoryLatency])
+	assert.Equal(t, int64(1), counts[CategoryMemory])
+	assert.Equal(t, int64(1), counts[CategoryManual])
+	assert.Len(t, counts, len(decisionCategories))
+
+	data, err := json.Marshal(tuner.Decisions()[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Category":"latency"`)
+
+	obs := NewObservabilityServer(DefaultObservabilityConfig(), tuner)
+	w := httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics?format=prometheus", nil))
+	assert.Contains(t, w.Body.String(), `autotune_decisions_by_category_total{category="latency"} 2`)
+	assert.Contains(t, w.Body.String(), `autotune_decisions_by_category_total{category="manual"} 1`)
+	assert.Contains(t, w.Body.String(), `autotune_decisions_by_category_total{category="schedule"} 0`)
+
+	output, err := NewMetricsExporter(tuner).ExportToPrometheus()
+	require.NoError(t, err)
+	assert.Contains(t, output, `autotune_decisions_by_category_total{category="memory"} 1`)
+}
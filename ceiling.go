@@ -0,0 +1,66 @@
+package autotune
+
+import "fmt"
+
+// DecisionPriority ranks how urgently a decision was made
+type DecisionPriority string
+
+const (
+	// PriorityNormal is a regular decision from the tuning algorithm
+	PriorityNormal DecisionPriority = ""
+	// PriorityHigh is an emergency response to a GC pause above
+	// Config.MaxPauseTime
+	PriorityHigh DecisionPriority = "high"
+)
+
+// pauseCeilingBreached reports whether the sample's GC pause exceeds
+// Config.MaxPauseTime
+func (t *Tuner) pauseCeilingBreached(metrics Metrics) bool {
+	return t.config.MaxPauseTime > 0 && metrics.GCPauseTime > t.config.MaxPauseTime
+}
+
+// pauseCeilingDecision returns a high priority decision raising GOGC by
+// MaxChangePerInterval in response to a pause above Config.MaxPauseTime.
+// Confidence gating, anti-oscillation and the history requirement don't
+// apply, but bounds and rounding do. It returns nil when GOGC is already at
+// its upper bound.
+func (t *Tuner) pauseCeilingDecision(metrics Metrics) *TuningDecision {
+	currentGOGC := metrics.CurrentGOGC
+	desiredGOGC := currentGOGC + t.config.MaxChangePerInterval
+
+	targetGOGC := desiredGOGC
+	clampedBy := ClampNone
+	boundHit := t.boundHit(targetGOGC)
+	if bounded := t.clampGOGC(targetGOGC); bounded != targetGOGC {
+		targetGOGC = bounded
+		clampedBy = ClampBounds
+	}
+	// Rounding must not cancel the emergency step
+	if rounded := t.roundGOGC(targetGOGC); rounded > currentGOGC {
+		targetGOGC = rounded
+	}
+
+	if targetGOGC <= currentGOGC {
+		t.config.Logger.Warn("GC pause %v exceeds the ceiling of %v but GOGC %d is already at its upper bound",
+			metrics.GCPauseTime, t.config.MaxPauseTime, currentGOGC)
+		return nil
+	}
+
+	t.config.Logger.Warn("GC pause %v exceeds the ceiling of %v, raising GOGC immediately",
+		metrics.GCPauseTime, t.config.MaxPauseTime)
+
+	return &TuningDecision{
+		OldGOGC:     currentGOGC,
+		NewGOGC:     targetGOGC,
+		DesiredGOGC: desiredGOGC,
+		ClampedBy:   clampedBy,
+		BoundHit:    boundHit,
+		Category:    CategoryLatency,
+		Priority:    PriorityHigh,
+		Reason: fmt.Sprintf("Emergency increasing GOGC %d -> %d due to: GC pause %.2fms > ceiling %.2fms",
+			currentGOGC, targetGOGC, float64(metrics.GCPauseTime)/1e6, float64(t.config.MaxPauseTime)/1e6),
+		Confidence: t.calculateConfidence(metrics),
+		Timestamp:  t.now(),
+		Metrics:    &metrics,
+	}
+}
@@ -0,0 +1,85 @@
+package autotune
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPauseCeiling tests that a pause above MaxPauseTime raises GOGC on the
+// first cycle despite low confidence
+func TestPauseCeiling(t *testing.T) {
+	newTuner := func() (*Tuner, *[]int) {
+		config := DefaultConfig()
+		config.ExternalMetrics = true
+		config.Logger = &mockLogger{}
+		config.MaxPauseTime = 20 * time.Millisecond
+		tuner, err := NewTuner(config)
+		require.NoError(t, err)
+
+		var applied []int
+		tuner.setGCPercent = func(value int) int {
+			applied = append(applied, value)
+			return 100
+		}
+		return tuner, &applied
+	}
+	// Extreme memory pressure and a single sample keep confidence below the
+	// 0.6 gate
+	sample := func(pause time.Duration, gogc int) Metrics {
+		return Metrics{GCPauseTime: pause, GCFrequency: 1, MemoryPressure: 0.99, CurrentGOGC: gogc}
+	}
+
+	// Below the ceiling the usual checks apply
+	tuner, applied := newTuner()
+	require.NoError(t, tuner.IngestMetrics(sample(15*time.Millisecond, 100)))
+	assert.Empty(t, *applied)
+
+	// Above it GOGC rises by the max step right away
+	tuner, applied = newTuner()
+	require.NoError(t, tuner.IngestMetrics(sample(50*time.Millisecond, 100)))
+	assert.Equal(t, []int{150}, *applied)
+
+	decisions := tuner.Decisions()
+	require.Len(t, decisions, 1)
+	assert.Equal(t, PriorityHigh, decisions[0].Priority)
+	assert.Equal(t, CategoryLatency, decisions[0].Category)
+	assert.Less(t, decisions[0].Confidence, 0.6)
+	assert.Contains(t, decisions[0].Reason, "ceiling")
+	assert.Equal(t, int64(1), tuner.GetStats()["pause_ceiling_hits"])
+
+	// Bounds still apply
+	tuner, applied = newTuner()
+	require.NoError(t, tuner.IngestMetrics(sample(50*time.Millisecond, 780)))
+	assert.Equal(t, []int{800}, *applied)
+	require.NoError(t, tuner.IngestMetrics(sample(50*time.Millisecond, 800)))
+	assert.Equal(t, []int{800}, *applied, "no decision at the upper bound")
+
+	config := DefaultConfig()
+	config.MaxPauseTime = -time.Millisecond
+	assert.Error(t, validateConfig(config))
+	config.MaxPauseTime = config.TargetLatency / 2
+	assert.Error(t, validateConfig(config), "ceiling below the target latency")
+}
+
+// TestPauseCeilingSLOMode tests that the ceiling acts while SLO mode is dormant
+func TestPauseCeilingSLOMode(t *testing.T) {
+	config := DefaultConfig()
+	config.ExternalMetrics = true
+	config.Logger = &mockLogger{}
+	config.MaxPauseTime = 20 * time.Millisecond
+	config.SLOMode = SLOConfig{PauseTarget: 10 * time.Millisecond, BreachDuration: time.Hour}
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	var applied []int
+	tuner.setGCPercent = func(value int) int {
+		applied = append(applied, value)
+		return 100
+	}
+
+	require.NoError(t, tuner.IngestMetrics(Metrics{GCPauseTime: 50 * time.Millisecond, GCFrequency: 1, MemoryPressure: 0.5, CurrentGOGC: 100}))
+	assert.Equal(t, []int{150}, applied)
+}
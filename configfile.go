@@ -0,0 +1,293 @@
+package autotune
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// configDuration is a duration in a config file, written as a string such
+// as "30s" or as a number of nanoseconds
+type configDuration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *configDuration) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*d = configDuration(parsed)
+	case float64:
+		*d = configDuration(time.Duration(v))
+	default:
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	return nil
+}
+
+// configFile is the JSON layout of a config file. Fields left out keep
+// their current value.
+type configFile struct {
+	MonitorInterval        *configDuration `json:"monitor_interval"`
+	MinGOGC                *int            `json:"min_gogc"`
+	MaxGOGC                *int            `json:"max_gogc"`
+	TargetLatency          *configDuration `json:"target_latency"`
+	TargetLatencyTolerance *configDuration `json:"target_latency_tolerance"`
+	MaxPauseTime           *configDuration `json:"max_pause_time"`
+	MemoryLimitPercent     *float64        `json:"memory_limit_percent"`
+	UseMemoryHigh          *bool           `json:"use_memory_high"`
+	TuningAggressiveness   *float64        `json:"tuning_aggressiveness"`
+	StabilizationWindow    *configDuration `json:"stabilization_window"`
+	DisableAntiOscillation *bool           `json:"disable_anti_oscillation"`
+	MaxChangePerInterval   *int            `json:"max_change_per_interval"`
+	RevertThreshold        *float64        `json:"revert_threshold"`
+	GOGCRounding           *int            `json:"gogc_rounding"`
+	BoundProximityMargin   *float64        `json:"bound_proximity_margin"`
+	FactorSmoothingAlpha   *float64        `json:"factor_smoothing_alpha"`
+	CPUAwareness           *float64        `json:"cpu_awareness"`
+	MaxAggressivenessBoost *float64        `json:"max_aggressiveness_boost"`
+	RecommendationWeight   *float64        `json:"recommendation_weight"`
+	LeakAction             *LeakAction     `json:"leak_action"`
+	PauseTarget            *PauseStatistic `json:"pause_target"`
+	MetricsHistorySize     *int            `json:"metrics_history_size"`
+}
+
+// apply overlays the fields set in the file onto config
+func (f *configFile) apply(config *Config) {
+	if f.MonitorInterval != nil {
+		config.MonitorInterval = time.Duration(*f.MonitorInterval)
+	}
+	if f.MinGOGC != nil {
+		config.MinGOGC = *f.MinGOGC
+	}
+	if f.MaxGOGC != nil {
+		config.MaxGOGC = *f.MaxGOGC
+	}
+	if f.TargetLatency != nil {
+		config.TargetLatency = time.Duration(*f.TargetLatency)
+	}
+	if f.TargetLatencyTolerance != nil {
+		config.TargetLatencyTolerance = time.Duration(*f.TargetLatencyTolerance)
+	}
+	if f.MaxPauseTime != nil {
+		config.MaxPauseTime = time.Duration(*f.MaxPauseTime)
+	}
+	if f.MemoryLimitPercent != nil {
+		config.MemoryLimitPercent = *f.MemoryLimitPercent
+	}
+	if f.UseMemoryHigh != nil {
+		config.UseMemoryHigh = *f.UseMemoryHigh
+	}
+	if f.TuningAggressiveness != nil {
+		config.TuningAggressiveness = *f.TuningAggressiveness
+	}
+	if f.StabilizationWindow != nil {
+		config.StabilizationWindow = time.Duration(*f.StabilizationWindow)
+	}
+	if f.DisableAntiOscillation != nil {
+		config.DisableAntiOscillation = *f.DisableAntiOscillation
+	}
+	if f.MaxChangePerInterval != nil {
+		config.MaxChangePerInterval = *f.MaxChangePerInterval
+	}
+	if f.RevertThreshold != nil {
+		config.RevertThreshold = *f.RevertThreshold
+	}
+	if f.GOGCRounding != nil {
+		config.GOGCRounding = *f.GOGCRounding
+	}
+	if f.BoundProximityMargin != nil {
+		config.BoundProximityMargin = *f.BoundProximityMargin
+	}
+	if f.FactorSmoothingAlpha != nil {
+		config.FactorSmoothingAlpha = *f.FactorSmoothingAlpha
+	}
+	if f.CPUAwareness != nil {
+		config.CPUAwareness = *f.CPUAwareness
+	}
+	if f.MaxAggressivenessBoost != nil {
+		config.MaxAggressivenessBoost = *f.MaxAggressivenessBoost
+	}
+	if f.RecommendationWeight != nil {
+		config.RecommendationWeight = *f.RecommendationWeight
+	}
+	if f.LeakAction != nil {
+		config.LeakAction = *f.LeakAction
+	}
+	if f.PauseTarget != nil {
+		config.PauseTarget = *f.PauseTarget
+	}
+	if f.MetricsHistorySize != nil {
+		config.MetricsHistorySize = *f.MetricsHistorySize
+	}
+}
+
+// LoadConfigFile reads a JSON config file such as
+//
+//	{"min_gogc": 100, "max_gogc": 400, "target_latency": "5ms"}
+//
+// on top of DefaultConfig and validates the result. Durations are strings
+// such as "30s". Unknown fields are rejected to catch typos.
+func LoadConfigFile(path string) (*Config, error) {
+	return loadConfigFile(path, DefaultConfig())
+}
+
+// loadConfigFile reads a config file on top of a copy of base and validates
+// the result
+func loadConfigFile(path string, base *Config) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file configFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	config := *base
+	file.apply(&config)
+	if err := validateConfig(&config); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// Config returns a copy of the tuner's current config, including updates
+// applied by UpdateConfig
+func (t *Tuner) Config() Config {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return *t.config
+}
+
+// UpdateConfig validates config and applies its tuning parameters at the
+// start of the next tuning cycle: the GOGC bounds, target latency, pause
+// ceiling, memory limit percent, aggressiveness and smoothing, stabilization
+// window, anti-oscillation, change limit, revert threshold, rounding, bound
+// proximity margin, recommendation weight and leak action. Other fields,
+// such as MonitorInterval, the logger and container detection settings, only
+// take effect when a new tuner is created. An invalid config is rejected and
+// the current one kept.
+func (t *Tuner) UpdateConfig(config *Config) error {
+	if config == nil {
+		return fmt.Errorf("config is nil")
+	}
+	if err := validateConfig(config); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	pending := *config
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if pending.MonitorInterval != t.config.MonitorInterval {
+		t.config.Logger.Warn("Monitor interval change to %v requires a restart, keeping %v",
+			pending.MonitorInterval, t.config.MonitorInterval)
+	}
+	t.pendingConfig = &pending
+	return nil
+}
+
+// applyPendingConfigLocked copies the tuning parameters of a config passed
+// to UpdateConfig into the live config. Caller must hold t.mu.
+func (t *Tuner) applyPendingConfigLocked() {
+	pending := t.pendingConfig
+	if pending == nil {
+		return
+	}
+	t.pendingConfig = nil
+
+	t.config.MinGOGC = pending.MinGOGC
+	t.config.MaxGOGC = pending.MaxGOGC
+	t.config.TargetLatency = pending.TargetLatency
+	t.config.TargetLatencyTolerance = pending.TargetLatencyTolerance
+	t.config.MaxPauseTime = pending.MaxPauseTime
+	t.config.MemoryLimitPercent = pending.MemoryLimitPercent
+	t.config.UseMemoryHigh = pending.UseMemoryHigh
+	t.config.TuningAggressiveness = pending.TuningAggressiveness
+	t.config.StabilizationWindow = pending.StabilizationWindow
+	t.config.DisableAntiOscillation = pending.DisableAntiOscillation
+	t.config.MaxChangePerInterval = pending.MaxChangePerInterval
+	t.config.RevertThreshold = pending.RevertThreshold
+	t.config.GOGCRounding = pending.GOGCRounding
+	t.config.BoundProximityMargin = pending.BoundProximityMargin
+	t.config.FactorSmoothingAlpha = pending.FactorSmoothingAlpha
+	t.config.CPUAwareness = pending.CPUAwareness
+	t.config.MaxAggressivenessBoost = pending.MaxAggressivenessBoost
+	t.config.RecommendationWeight = pending.RecommendationWeight
+	t.config.LeakAction = pending.LeakAction
+	t.config.PauseTarget = pending.PauseTarget
+
+	t.config.Logger.Info("Applied updated config: GOGC bounds [%d, %d], target latency %v",
+		t.config.MinGOGC, t.config.MaxGOGC, t.config.TargetLatency)
+}
+
+// reloadConfigFile reads a config file on top of the current config and
+// passes it to UpdateConfig, logging the outcome. A missing, malformed or
+// invalid file keeps the current config.
+func (t *Tuner) reloadConfigFile(path string) error {
+	t.mu.RLock()
+	base := *t.config
+	if t.pendingConfig != nil {
+		base = *t.pendingConfig
+	}
+	t.mu.RUnlock()
+
+	config, err := loadConfigFile(path, &base)
+	if err == nil {
+		err = t.UpdateConfig(config)
+	}
+	if err != nil {
+		t.config.Logger.Error("Config reload failed, keeping the current config: %v", err)
+		return err
+	}
+
+	t.config.Logger.Info("Reloaded config from %s, applying at the next tuning cycle", path)
+	return nil
+}
+
+// WatchConfigFile reloads the config file at path (see LoadConfigFile) on
+// every SIGHUP, applying it through UpdateConfig, until ctx is done. The
+// watcher doesn't depend on the tuner running: it keeps watching across
+// Stop and Start, and reloads into a stopped tuner apply at the first cycle
+// after the next start. Fields left out of the file keep their current
+// value. A missing, malformed or invalid file is logged and the current
+// config kept.
+func (t *Tuner) WatchConfigFile(ctx context.Context, path string) error {
+	if path == "" {
+		return fmt.Errorf("config file path is required")
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				t.reloadConfigFile(path)
+			}
+		}
+	}()
+
+	t.config.Logger.Info("Watching %s for config reloads on SIGHUP", path)
+	return nil
+}
@@ -0,0 +1,77 @@
+package autotune
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadConfigFile tests reading a config file on top of the defaults
+func TestLoadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "autotune.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"min_gogc": 100,
+		"max_gogc": 400,
+		"target_latency": "5ms",
+		"stabilization_window": 60000000000
+	}`), 0o644))
+
+	config, err := LoadConfigFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 100, config.MinGOGC)
+	assert.Equal(t, 400, config.MaxGOGC)
+	assert.Equal(t, 5*time.Millisecond, config.TargetLatency)
+	assert.Equal(t, time.Minute, config.StabilizationWindow)
+	assert.Equal(t, DefaultConfig().MonitorInterval, config.MonitorInterval)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"min_gocg": 100}`), 0o644))
+	_, err = LoadConfigFile(path)
+	assert.Error(t, err, "unknown field")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"target_latency": "soon"}`), 0o644))
+	_, err = LoadConfigFile(path)
+	assert.Error(t, err, "bad duration")
+
+	_, err = LoadConfigFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+// TestReloadConfigFile tests reloading, including keeping the current config
+// when the file is deleted, malformed or invalid
+func TestReloadConfigFile(t *testing.T) {
+	config := DefaultConfig()
+	logger := &mockLogger{}
+	config.Logger = logger
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "autotune.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"max_gogc": 300}`), 0o644))
+
+	require.NoError(t, tuner.reloadConfigFile(path))
+	assert.Equal(t, 800, config.MaxGOGC, "applied at the next cycle")
+	tuner.runTuningCycle(Metrics{CurrentGOGC: 100, Timestamp: time.Now()}, 0)
+	assert.Equal(t, 300, config.MaxGOGC)
+	assert.Equal(t, 50, config.MinGOGC, "fields left out keep their value")
+
+	// Config returns a copy
+	live := tuner.Config()
+	assert.Equal(t, 300, live.MaxGOGC)
+	live.MaxGOGC = 400
+	assert.Equal(t, 300, config.MaxGOGC)
+
+	for _, content := range []string{`{"max_gogc": `, `{"max_gogc": 20}`} {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		assert.Error(t, tuner.reloadConfigFile(path))
+	}
+	require.NoError(t, os.Remove(path))
+	assert.Error(t, tuner.reloadConfigFile(path))
+
+	tuner.runTuningCycle(Metrics{CurrentGOGC: 100, Timestamp: time.Now()}, 0)
+	assert.Equal(t, 300, config.MaxGOGC)
+	assert.Nil(t, tuner.pendingConfig)
+}
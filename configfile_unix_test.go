@@ -0,0 +1,53 @@
+//go:build unix
+
+package autotune
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWatchConfigFile tests reloading on SIGHUP, including across a restart
+// of the tuner
+func TestWatchConfigFile(t *testing.T) {
+	config := DefaultConfig()
+	config.Logger = silentLogger{}
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	defer tuner.cancel()
+
+	path := filepath.Join(t.TempDir(), "autotune.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"max_gogc": 250}`), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.Error(t, tuner.WatchConfigFile(ctx, ""))
+	require.NoError(t, tuner.WatchConfigFile(ctx, path))
+
+	reloaded := func(maxGOGC int) bool {
+		tuner.mu.RLock()
+		defer tuner.mu.RUnlock()
+		return tuner.pendingConfig != nil && tuner.pendingConfig.MaxGOGC == maxGOGC
+	}
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	assert.Eventually(t, func() bool { return reloaded(250) }, 2*time.Second, 10*time.Millisecond)
+
+	// The watcher outlives a stop and start of the tuner
+	require.NoError(t, tuner.Start())
+	require.NoError(t, tuner.Stop())
+	require.NoError(t, tuner.Start())
+	defer tuner.Stop()
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"max_gogc": 300}`), 0o644))
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	assert.Eventually(t, func() bool {
+		return reloaded(300) || tuner.Config().MaxGOGC == 300
+	}, 2*time.Second, 10*time.Millisecond)
+}
@@ -2,37 +2,207 @@ package autotune
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
 // ContainerResources holds detected container resource limits
 type ContainerResources struct {
-	MemoryLimit uint64  // Memory limit in bytes
-	CPULimit    float64 // CPU limit in cores
-	IsContainer bool    // Whether running in a container
+	MemoryLimit       uint64            // Memory limit in bytes
+	MemoryLimitSource MemoryLimitSource // Where the memory limit was detected
+	MemoryHigh        uint64            // cgroup v2 memory.high throttling threshold in bytes, 0 if none
+	SwapLimit         uint64            // cgroup v2 memory.swap.max in bytes, 0 if disabled, UnlimitedSwap if unlimited
+	SwapLimitKnown    bool              // Whether SwapLimit was read from memory.swap.max
+	CPULimit          float64           // CPU limit in cores
+	CPUBurst          float64           // cgroup v2 cpu.max.burst in cores, 0 if none
+	CPUWeight         uint64            // cgroup v2 cpu.weight (1-10000, default 100), 0 if unknown
+	IsContainer       bool              // Whether running in a container
+
+	cgroup cgroupV2Paths // cgroup v2 location resolved at detection
+}
+
+// UnlimitedSwap is the SwapLimit of a cgroup whose memory.swap.max is "max"
+const UnlimitedSwap = math.MaxUint64
+
+// BurstCPULimit returns the CPU available for short spikes, such as GC
+// work, in cores: the quota plus any cgroup v2 burst allowance. Zero means
+// no limit.
+func (r *ContainerResources) BurstCPULimit() float64 {
+	if r.CPULimit == 0 {
+		return 0
+	}
+	return r.CPULimit + r.CPUBurst
+}
+
+// readFile reads a file in one attempt. It is a variable so tests can inject
+// failures.
+var readFile = os.ReadFile
+
+// FileReadPolicy controls retries of cgroup and proc file reads. Some
+// overlay and virtualized filesystems occasionally fail reads with EINTR or
+// EAGAIN, and without a retry a single transient failure would make
+// detection report no limit for the lifetime of the process.
+type FileReadPolicy struct {
+	// Attempts is the maximum number of reads per file
+	Attempts int
+	// Timeout bounds the total time spent retrying one file
+	Timeout time.Duration
+	// Logger receives a warning when retries are exhausted
+	Logger Logger
+}
+
+// DefaultFileReadPolicy returns the default file read retry policy
+func DefaultFileReadPolicy() FileReadPolicy {
+	return FileReadPolicy{
+		Attempts: 3,
+		Timeout:  time.Second,
+		Logger:   &defaultLogger{},
+	}
+}
+
+var (
+	fileReadMu     sync.RWMutex
+	fileReadPolicy = DefaultFileReadPolicy()
+)
+
+// SetFileReadPolicy sets the retry policy used for container file reads
+// during detection and stats collection. Zero fields keep their defaults.
+func SetFileReadPolicy(policy FileReadPolicy) {
+	defaults := DefaultFileReadPolicy()
+	if policy.Attempts <= 0 {
+		policy.Attempts = defaults.Attempts
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = defaults.Timeout
+	}
+	if policy.Logger == nil {
+		policy.Logger = defaults.Logger
+	}
+
+	fileReadMu.Lock()
+	defer fileReadMu.Unlock()
+	fileReadPolicy = policy
+}
+
+// readContainerFile reads a file, retrying transient errors according to the
+// file read policy. Other errors, such as a missing file, are returned
+// immediately.
+func readContainerFile(path string) ([]byte, error) {
+	fileReadMu.RLock()
+	policy := fileReadPolicy
+	fileReadMu.RUnlock()
+
+	deadline := time.Now().Add(policy.Timeout)
+	// File reads aren't owned by a tuner, so the jitter isn't seeded by
+	// Config.RandSeed
+	delay := newBackoff(time.Millisecond, 50*time.Millisecond, nil)
+
+	for attempt := 1; ; attempt++ {
+		data, err := readFile(path)
+		if err == nil || !isTransientReadError(err) {
+			return data, err
+		}
+
+		wait := delay.next()
+		if attempt >= policy.Attempts || time.Now().Add(wait).After(deadline) {
+			policy.Logger.Warn("Failed to read %s after %d attempts: %v", path, attempt, err)
+			return nil, err
+		}
+		time.Sleep(wait)
+	}
+}
+
+// isTransientReadError reports whether a read error is worth retrying
+func isTransientReadError(err error) bool {
+	return errors.Is(err, syscall.EINTR) || errors.Is(err, syscall.EAGAIN)
+}
+
+// QoSClass is the Kubernetes memory quality-of-service class inferred from
+// the container's memory request and limit
+type QoSClass string
+
+const (
+	// QoSClassUnknown means the request or limit could not be determined
+	QoSClassUnknown QoSClass = "unknown"
+	// QoSClassGuaranteed means the request equals the limit
+	QoSClassGuaranteed QoSClass = "Guaranteed"
+	// QoSClassBurstable means the request is below the limit
+	QoSClassBurstable QoSClass = "Burstable"
+)
+
+// detectQoSClass infers the QoS class from a memory request and limit
+func detectQoSClass(request, limit uint64) QoSClass {
+	if request == 0 || limit == 0 {
+		return QoSClassUnknown
+	}
+	if request >= limit {
+		return QoSClassGuaranteed
+	}
+	return QoSClassBurstable
+}
+
+// memoryRequestFromEnv reads the memory request in bytes from the
+// AUTOTUNE_MEMORY_REQUEST environment variable
+func memoryRequestFromEnv() uint64 {
+	value := strings.TrimSpace(os.Getenv("AUTOTUNE_MEMORY_REQUEST"))
+	if value == "" {
+		return 0
+	}
+
+	request, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return request
 }
 
 // DetectContainerResources attempts to detect container resource limits
 func DetectContainerResources() (*ContainerResources, error) {
-	resources := &ContainerResources{}
+	return detectContainerResources(nil, "")
+}
+
+// detectContainerResources detects container resource limits, trying the
+// given memory limit sources in order (empty means the default order)
+func detectContainerResources(memoryLimitSources []MemoryLimitSource, memoryLimitFile string) (*ContainerResources, error) {
+	cg := resolveCgroupV2Paths()
+	resources := &ContainerResources{cgroup: cg}
 
 	// Check if we're running in a container
 	if isRunningInContainer() {
 		resources.IsContainer = true
 
 		// Try to detect memory limit
-		if memLimit, err := detectMemoryLimit(); err == nil {
+		if memLimit, source, err := detectMemoryLimitFrom(cg, memoryLimitSources, memoryLimitFile); err == nil {
 			resources.MemoryLimit = memLimit
+			resources.MemoryLimitSource = source
+		}
+
+		if high, err := readCgroupV2MemoryValue(cg, "memory.high"); err == nil {
+			resources.MemoryHigh = high
+		}
+		if swap, err := readCgroupV2SwapLimit(cg); err == nil {
+			resources.SwapLimit = swap
+			resources.SwapLimitKnown = true
 		}
 
 		// Try to detect CPU limit
-		if cpuLimit, err := detectCPULimit(); err == nil {
+		if cpuLimit, err := detectCPULimit(cg); err == nil {
 			resources.CPULimit = cpuLimit
 		}
+		if burst, err := readCgroupV2CPUBurst(cg); err == nil {
+			resources.CPUBurst = burst
+		}
+		if weight, err := readCgroupV2CPUWeight(cg); err == nil {
+			resources.CPUWeight = weight
+		}
 	}
 
 	return resources, nil
@@ -46,7 +216,7 @@ func isRunningInContainer() bool {
 	}
 
 	// Method 2: Check cgroup information
-	if data, err := os.ReadFile("/proc/1/cgroup"); err == nil {
+	if data, err := readContainerFile("/proc/1/cgroup"); err == nil {
 		content := string(data)
 		if strings.Contains(content, "docker") ||
 			strings.Contains(content, "kubepods") ||
@@ -68,36 +238,109 @@ func isRunningInContainer() bool {
 	return false
 }
 
-// detectMemoryLimit attempts to detect the container memory limit
+// detectMemoryLimit attempts to detect the container memory limit from the
+// default sources
 func detectMemoryLimit() (uint64, error) {
-	// Try cgroup v2 first
-	if limit, err := readCgroupV2MemoryLimit(); err == nil {
-		return limit, nil
+	limit, _, err := detectMemoryLimitFrom(cgroupV2Paths{}, nil, "")
+	return limit, err
+}
+
+// defaultCgroupV2Mount is the usual mount point of the cgroup v2 unified
+// hierarchy, used when /proc/mounts doesn't list it
+const defaultCgroupV2Mount = "/sys/fs/cgroup"
+
+// cgroupV2Mount returns the mount point of the cgroup v2 unified hierarchy
+func cgroupV2Mount() string {
+	data, err := readContainerFile("/proc/mounts")
+	if err != nil {
+		return defaultCgroupV2Mount
 	}
 
-	// Try cgroup v1
-	if limit, err := readCgroupV1MemoryLimit(); err == nil {
-		return limit, nil
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 3 && fields[2] == "cgroup2" {
+			return fields[1]
+		}
 	}
+	return defaultCgroupV2Mount
+}
 
-	// Try /proc/meminfo as fallback
-	if limit, err := readProcMemInfo(); err == nil {
-		return limit, nil
+// cgroupV2Dir returns the directory of the process's cgroup v2 below mount,
+// from the unified "0::" line of /proc/self/cgroup. Inside a cgroup
+// namespace the line is "0::/" and the mount is the process's cgroup;
+// without one, as in many Kubernetes pods, it's the full path from the
+// hierarchy root. Paths that can't be resolved below the mount give the
+// mount.
+func cgroupV2Dir(mount string) string {
+	data, err := readContainerFile("/proc/self/cgroup")
+	if err != nil {
+		return mount
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		if path, ok := strings.CutPrefix(scanner.Text(), "0::"); ok {
+			dir := filepath.Join(mount, path)
+			if !strings.HasPrefix(dir, strings.TrimSuffix(mount, "/")+"/") {
+				return mount
+			}
+			return dir
+		}
+	}
+	return mount
+}
+
+// cgroupV2Paths is the location of the process's cgroup v2: the mount
+// point of the unified hierarchy and the cgroup directory below it. The
+// zero value resolves them again on every read.
+type cgroupV2Paths struct {
+	mount string
+	dir   string
+}
+
+// resolveCgroupV2Paths locates the process's cgroup v2 from /proc/mounts
+// and /proc/self/cgroup
+func resolveCgroupV2Paths() cgroupV2Paths {
+	mount := cgroupV2Mount()
+	return cgroupV2Paths{mount: mount, dir: cgroupV2Dir(mount)}
+}
+
+// readFile reads an interface file of the cgroup, falling back to the
+// mount root when the file isn't found there, e.g. when /proc/self/cgroup
+// is relative to another cgroup namespace than the mount
+func (p cgroupV2Paths) readFile(name string) ([]byte, error) {
+	if p.mount == "" {
+		p = resolveCgroupV2Paths()
+	}
+	if p.dir != p.mount {
+		if data, err := readContainerFile(filepath.Join(p.dir, name)); err == nil {
+			return data, nil
+		}
 	}
+	return readContainerFile(filepath.Join(p.mount, name))
+}
 
-	return 0, fmt.Errorf("unable to detect memory limit")
+// readOwnFile reads an interface file of the cgroup without falling back
+// to the mount root. Usage counters at the root cover the whole host, so
+// they can't stand in for the cgroup's.
+func (p cgroupV2Paths) readOwnFile(name string) ([]byte, error) {
+	if p.mount == "" {
+		p = resolveCgroupV2Paths()
+	}
+	return readContainerFile(filepath.Join(p.dir, name))
 }
 
 // readCgroupV2MemoryLimit reads memory limit from cgroup v2
-func readCgroupV2MemoryLimit() (uint64, error) {
+func readCgroupV2MemoryLimit(cg cgroupV2Paths) (uint64, error) {
 	// Try unified hierarchy first
-	paths := []string{
-		"/sys/fs/cgroup/memory.max",
-		"/sys/fs/cgroup/memory/memory.limit_in_bytes",
+	names := []string{
+		"memory.max",
+		"memory/memory.limit_in_bytes",
 	}
 
-	for _, path := range paths {
-		if data, err := os.ReadFile(path); err == nil {
+	for _, name := range names {
+		if data, err := cg.readFile(name); err == nil {
 			content := strings.TrimSpace(string(data))
 			if content == "max" {
 				continue // No limit set
@@ -115,6 +358,44 @@ func readCgroupV2MemoryLimit() (uint64, error) {
 	return 0, fmt.Errorf("cgroup v2 memory limit not found")
 }
 
+// readCgroupV2MemoryValue reads a cgroup v2 memory interface file holding
+// a byte count or "max", such as memory.high
+func readCgroupV2MemoryValue(cg cgroupV2Paths, name string) (uint64, error) {
+	data, err := cg.readFile(name)
+	if err != nil {
+		return 0, err
+	}
+
+	content := strings.TrimSpace(string(data))
+	if content == "max" {
+		return 0, fmt.Errorf("no %s set", name)
+	}
+	value, err := strconv.ParseUint(content, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// readCgroupV2SwapLimit reads memory.swap.max in bytes, returning
+// UnlimitedSwap for "max"
+func readCgroupV2SwapLimit(cg cgroupV2Paths) (uint64, error) {
+	data, err := cg.readFile("memory.swap.max")
+	if err != nil {
+		return 0, err
+	}
+
+	content := strings.TrimSpace(string(data))
+	if content == "max" {
+		return UnlimitedSwap, nil
+	}
+	swap, err := strconv.ParseUint(content, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory.swap.max: %w", err)
+	}
+	return swap, nil
+}
+
 // readCgroupV1MemoryLimit reads memory limit from cgroup v1
 func readCgroupV1MemoryLimit() (uint64, error) {
 	// First, find the memory cgroup path
@@ -125,7 +406,7 @@ func readCgroupV1MemoryLimit() (uint64, error) {
 
 	limitPath := filepath.Join(cgroupPath, "memory.limit_in_bytes")
 
-	data, err := os.ReadFile(limitPath)
+	data, err := readContainerFile(limitPath)
 	if err != nil {
 		return 0, err
 	}
@@ -146,7 +427,7 @@ func readCgroupV1MemoryLimit() (uint64, error) {
 
 // readProcMemInfo reads total memory from /proc/meminfo
 func readProcMemInfo() (uint64, error) {
-	data, err := os.ReadFile("/proc/meminfo")
+	data, err := readContainerFile("/proc/meminfo")
 	if err != nil {
 		return 0, err
 	}
@@ -168,9 +449,9 @@ func readProcMemInfo() (uint64, error) {
 }
 
 // detectCPULimit attempts to detect the container CPU limit
-func detectCPULimit() (float64, error) {
+func detectCPULimit(cg cgroupV2Paths) (float64, error) {
 	// Try cgroup v2 first
-	if limit, err := readCgroupV2CPULimit(); err == nil {
+	if limit, err := readCgroupV2CPULimit(cg); err == nil {
 		return limit, nil
 	}
 
@@ -183,12 +464,21 @@ func detectCPULimit() (float64, error) {
 }
 
 // readCgroupV2CPULimit reads CPU limit from cgroup v2
-func readCgroupV2CPULimit() (float64, error) {
-	// Try cpu.max first
-	if data, err := os.ReadFile("/sys/fs/cgroup/cpu.max"); err == nil {
+func readCgroupV2CPULimit(cg cgroupV2Paths) (float64, error) {
+	quota, period, err := readCgroupV2CPUMax(cg)
+	if err != nil {
+		return 0, err
+	}
+	return quota / period, nil
+}
+
+// readCgroupV2CPUMax reads the quota and period from cgroup v2 cpu.max, in
+// microseconds
+func readCgroupV2CPUMax(cg cgroupV2Paths) (quota, period float64, err error) {
+	if data, err := cg.readFile("cpu.max"); err == nil {
 		content := strings.TrimSpace(string(data))
-		if content == "max" {
-			return 0, fmt.Errorf("no CPU limit set")
+		if content == "max" || strings.HasPrefix(content, "max ") {
+			return 0, 0, fmt.Errorf("no CPU limit set")
 		}
 
 		fields := strings.Fields(content)
@@ -196,12 +486,48 @@ func readCgroupV2CPULimit() (float64, error) {
 			quota, err1 := strconv.ParseFloat(fields[0], 64)
 			period, err2 := strconv.ParseFloat(fields[1], 64)
 			if err1 == nil && err2 == nil && period > 0 {
-				return quota / period, nil
+				return quota, period, nil
 			}
 		}
 	}
 
-	return 0, fmt.Errorf("cgroup v2 CPU limit not found")
+	return 0, 0, fmt.Errorf("cgroup v2 CPU limit not found")
+}
+
+// readCgroupV2CPUBurst reads cpu.max.burst, the time per period a cgroup
+// v2 may run beyond its quota using runtime it left unused earlier, and
+// returns it in cores
+func readCgroupV2CPUBurst(cg cgroupV2Paths) (float64, error) {
+	_, period, err := readCgroupV2CPUMax(cg)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := cg.readFile("cpu.max.burst")
+	if err != nil {
+		return 0, err
+	}
+
+	burst, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cpu.max.burst: %w", err)
+	}
+	return burst / period, nil
+}
+
+// readCgroupV2CPUWeight reads cpu.weight, the cgroup v2 proportional CPU
+// share under contention
+func readCgroupV2CPUWeight(cg cgroupV2Paths) (uint64, error) {
+	data, err := cg.readFile("cpu.weight")
+	if err != nil {
+		return 0, err
+	}
+
+	weight, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cpu.weight: %w", err)
+	}
+	return weight, nil
 }
 
 // readCgroupV1CPULimit reads CPU limit from cgroup v1
@@ -216,12 +542,12 @@ func readCgroupV1CPULimit() (float64, error) {
 	quotaPath := filepath.Join(cgroupPath, "cpu.cfs_quota_us")
 	periodPath := filepath.Join(cgroupPath, "cpu.cfs_period_us")
 
-	quotaData, err := os.ReadFile(quotaPath)
+	quotaData, err := readContainerFile(quotaPath)
 	if err != nil {
 		return 0, err
 	}
 
-	periodData, err := os.ReadFile(periodPath)
+	periodData, err := readContainerFile(periodPath)
 	if err != nil {
 		return 0, err
 	}
@@ -249,7 +575,7 @@ func readCgroupV1CPULimit() (float64, error) {
 // findCgroupPath finds the cgroup path for a given subsystem
 func findCgroupPath(subsystem string) (string, error) {
 	// First, try to find the cgroup mount point
-	mountData, err := os.ReadFile("/proc/mounts")
+	mountData, err := readContainerFile("/proc/mounts")
 	if err != nil {
 		return "", err
 	}
@@ -270,7 +596,7 @@ func findCgroupPath(subsystem string) (string, error) {
 	}
 
 	// Read the current process's cgroup
-	cgroupData, err := os.ReadFile("/proc/self/cgroup")
+	cgroupData, err := readContainerFile("/proc/self/cgroup")
 	if err != nil {
 		return "", err
 	}
@@ -292,18 +618,30 @@ func findCgroupPath(subsystem string) (string, error) {
 	return "", fmt.Errorf("cgroup path for %s not found", subsystem)
 }
 
-// GetContainerStats returns current container resource usage statistics
+// GetContainerStats returns current container resource usage statistics.
+// CPU usage is measured since the previous call; the first call samples the
+// CPU usage counters twice, cpuSampleInterval apart.
 func GetContainerStats() (*ContainerStats, error) {
 	stats := &ContainerStats{}
+	cg := resolveCgroupV2Paths()
 
 	// Get memory usage
-	if memUsage, err := getCurrentMemoryUsage(); err == nil {
+	if memUsage, err := getCurrentMemoryUsage(cg); err == nil {
 		stats.MemoryUsage = memUsage
 	}
 
-	// Get CPU usage
-	if cpuUsage, err := getCurrentCPUUsage(); err == nil {
-		stats.CPUUsage = cpuUsage
+	// Get CPU usage since the previous call
+	if usage, cores, err := defaultCPUSampler().sampleOrWait(); err == nil {
+		stats.CPUUsage = usage
+		stats.CPUCores = cores
+	}
+
+	// Get pressure stall information (cgroup v2 only)
+	if psi, err := readCgroupV2PSI(cg, "memory"); err == nil {
+		stats.MemoryPSI = psi
+	}
+	if psi, err := readCgroupV2PSI(cg, "cpu"); err == nil {
+		stats.CPUPSI = psi
 	}
 
 	return stats, nil
@@ -311,14 +649,19 @@ func GetContainerStats() (*ContainerStats, error) {
 
 // ContainerStats holds current container resource usage
 type ContainerStats struct {
-	MemoryUsage uint64  // Current memory usage in bytes
-	CPUUsage    float64 // Current CPU usage percentage
+	MemoryUsage uint64    // Memory working set in bytes, excluding inactive page cache
+	CPUUsage    float64   // CPU utilization as a fraction of the CPU limit (0-1)
+	CPUCores    float64   // CPU used in cores
+	MemoryPSI   *PSIStats // Memory pressure stall information, nil if unavailable
+	CPUPSI      *PSIStats // CPU pressure stall information, nil if unavailable
 }
 
-// getCurrentMemoryUsage gets current memory usage from cgroup
-func getCurrentMemoryUsage() (uint64, error) {
+// getCurrentMemoryUsage gets the cgroup's memory working set: its usage
+// minus the inactive page cache the kernel reclaims before OOM killing,
+// as the kubelet computes it for eviction
+func getCurrentMemoryUsage(cg cgroupV2Paths) (uint64, error) {
 	// Try cgroup v2
-	if usage, err := readCgroupV2MemoryUsage(); err == nil {
+	if usage, err := readCgroupV2MemoryUsage(cg); err == nil {
 		return usage, nil
 	}
 
@@ -330,9 +673,10 @@ func getCurrentMemoryUsage() (uint64, error) {
 	return 0, fmt.Errorf("unable to get memory usage")
 }
 
-// readCgroupV2MemoryUsage reads current memory usage from cgroup v2
-func readCgroupV2MemoryUsage() (uint64, error) {
-	data, err := os.ReadFile("/sys/fs/cgroup/memory.current")
+// readCgroupV2MemoryUsage reads the memory working set from cgroup v2,
+// memory.current minus inactive_file from memory.stat
+func readCgroupV2MemoryUsage(cg cgroupV2Paths) (uint64, error) {
+	data, err := cg.readFile("memory.current")
 	if err != nil {
 		return 0, err
 	}
@@ -343,10 +687,15 @@ func readCgroupV2MemoryUsage() (uint64, error) {
 		return 0, err
 	}
 
-	return usage, nil
+	stat, err := cg.readFile("memory.stat")
+	if err != nil {
+		return usage, nil
+	}
+	return workingSet(usage, stat, "inactive_file"), nil
 }
 
-// readCgroupV1MemoryUsage reads current memory usage from cgroup v1
+// readCgroupV1MemoryUsage reads the memory working set from cgroup v1,
+// memory.usage_in_bytes minus total_inactive_file from memory.stat
 func readCgroupV1MemoryUsage() (uint64, error) {
 	cgroupPath, err := findCgroupPath("memory")
 	if err != nil {
@@ -355,7 +704,7 @@ func readCgroupV1MemoryUsage() (uint64, error) {
 
 	usagePath := filepath.Join(cgroupPath, "memory.usage_in_bytes")
 
-	data, err := os.ReadFile(usagePath)
+	data, err := readContainerFile(usagePath)
 	if err != nil {
 		return 0, err
 	}
@@ -366,16 +715,39 @@ func readCgroupV1MemoryUsage() (uint64, error) {
 		return 0, err
 	}
 
-	return usage, nil
+	stat, err := readContainerFile(filepath.Join(cgroupPath, "memory.stat"))
+	if err != nil {
+		return usage, nil
+	}
+	return workingSet(usage, stat, "total_inactive_file"), nil
 }
 
-// getCurrentCPUUsage gets current CPU usage percentage
-func getCurrentCPUUsage() (float64, error) {
-	// This is a simplified CPU usage calculation
-	// In a real implementation, you'd want to sample over time
+// workingSet subtracts the inactive file cache, the inactiveKey entry of
+// the memory.stat contents stat, from usage. Usage is returned unchanged
+// when the entry is missing.
+func workingSet(usage uint64, stat []byte, inactiveKey string) uint64 {
+	scanner := bufio.NewScanner(strings.NewReader(string(stat)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != inactiveKey {
+			continue
+		}
+		inactive, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return usage
+		}
+		if inactive >= usage {
+			return 0
+		}
+		return usage - inactive
+	}
+	return usage
+}
 
+// readCPUUsageCounter reads the cumulative CPU time used by the cgroup
+func readCPUUsageCounter(cg cgroupV2Paths) (time.Duration, error) {
 	// Try cgroup v2
-	if usage, err := readCgroupV2CPUUsage(); err == nil {
+	if usage, err := readCgroupV2CPUUsage(cg); err == nil {
 		return usage, nil
 	}
 
@@ -387,24 +759,20 @@ func getCurrentCPUUsage() (float64, error) {
 	return 0, fmt.Errorf("unable to get CPU usage")
 }
 
-// readCgroupV2CPUUsage reads current CPU usage from cgroup v2
-func readCgroupV2CPUUsage() (float64, error) {
-	data, err := os.ReadFile("/sys/fs/cgroup/cpu.stat")
+// readCgroupV2CPUUsage reads the cumulative CPU time from usage_usec in
+// cgroup v2 cpu.stat
+func readCgroupV2CPUUsage(cg cgroupV2Paths) (time.Duration, error) {
+	data, err := cg.readOwnFile("cpu.stat")
 	if err != nil {
 		return 0, err
 	}
 
 	scanner := bufio.NewScanner(strings.NewReader(string(data)))
 	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "usage_usec") {
-			fields := strings.Fields(line)
-			if len(fields) >= 2 {
-				if usec, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
-					// Convert microseconds to a percentage (this is simplified)
-					// In reality, you'd need to track changes over time
-					return float64(usec) / 1000000.0, nil
-				}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "usage_usec" {
+			if usec, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+				return time.Duration(usec) * time.Microsecond, nil
 			}
 		}
 	}
@@ -412,8 +780,9 @@ func readCgroupV2CPUUsage() (float64, error) {
 	return 0, fmt.Errorf("CPU usage not found in cgroup v2")
 }
 
-// readCgroupV1CPUUsage reads current CPU usage from cgroup v1
-func readCgroupV1CPUUsage() (float64, error) {
+// readCgroupV1CPUUsage reads the cumulative CPU time from cgroup v1
+// cpuacct.usage
+func readCgroupV1CPUUsage() (time.Duration, error) {
 	cgroupPath, err := findCgroupPath("cpuacct")
 	if err != nil {
 		return 0, err
@@ -421,7 +790,7 @@ func readCgroupV1CPUUsage() (float64, error) {
 
 	usagePath := filepath.Join(cgroupPath, "cpuacct.usage")
 
-	data, err := os.ReadFile(usagePath)
+	data, err := readContainerFile(usagePath)
 	if err != nil {
 		return 0, err
 	}
@@ -432,7 +801,5 @@ func readCgroupV1CPUUsage() (float64, error) {
 		return 0, err
 	}
 
-	// Convert nanoseconds to a percentage (this is simplified)
-	// In reality, you'd need to track changes over time
-	return float64(usage) / 1000000000.0, nil
+	return time.Duration(usage), nil
 }
@@ -3,7 +3,10 @@ package autotune
 import (
 	"os"
 	"path/filepath"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -21,6 +24,20 @@ func TestDetectContainerResources(t *testing.T) {
 	assert.GreaterOrEqual(t, resources.CPULimit, float64(0))
 }
 
+// TestDetectQoSClass tests QoS class inference from request and limit
+func TestDetectQoSClass(t *testing.T) {
+	assert.Equal(t, QoSClassUnknown, detectQoSClass(0, 512<<20))
+	assert.Equal(t, QoSClassUnknown, detectQoSClass(256<<20, 0))
+	assert.Equal(t, QoSClassGuaranteed, detectQoSClass(512<<20, 512<<20))
+	assert.Equal(t, QoSClassBurstable, detectQoSClass(256<<20, 512<<20))
+
+	t.Setenv("AUTOTUNE_MEMORY_REQUEST", "268435456")
+	assert.Equal(t, uint64(256<<20), memoryRequestFromEnv())
+
+	t.Setenv("AUTOTUNE_MEMORY_REQUEST", "256Mi")
+	assert.Equal(t, uint64(0), memoryRequestFromEnv())
+}
+
 // TestIsRunningInContainer tests container detection
 func TestIsRunningInContainer(t *testing.T) {
 	isContainer := isRunningInContainer()
@@ -43,7 +60,7 @@ func TestDetectMemoryLimit(t *testing.T) {
 func TestDetectCPULimit(t *testing.T) {
 	// This will likely fail in a non-container environment
 	// but should not panic
-	limit, err := detectCPULimit()
+	limit, err := detectCPULimit(resolveCgroupV2Paths())
 	if err == nil {
 		assert.Greater(t, limit, float64(0))
 	}
@@ -173,7 +190,7 @@ func TestContainerDetectionMethods(t *testing.T) {
 // TestResourceLimitEdgeCases tests edge cases in resource limit detection
 func TestResourceLimitEdgeCases(t *testing.T) {
 	// Test with non-existent files
-	_, err := readCgroupV2MemoryLimit()
+	_, err := readCgroupV2MemoryLimit(resolveCgroupV2Paths())
 	// Should handle missing files gracefully
 	if err != nil {
 		assert.Error(t, err)
@@ -185,7 +202,7 @@ func TestResourceLimitEdgeCases(t *testing.T) {
 		assert.Error(t, err)
 	}
 
-	_, err = readCgroupV2CPULimit()
+	_, err = readCgroupV2CPULimit(resolveCgroupV2Paths())
 	// Should handle missing files gracefully
 	if err != nil {
 		assert.Error(t, err)
@@ -224,7 +241,7 @@ func TestContainerStatsStruct(t *testing.T) {
 
 // TestMemoryUsageDetection tests memory usage detection
 func TestMemoryUsageDetection(t *testing.T) {
-	usage, err := getCurrentMemoryUsage()
+	usage, err := getCurrentMemoryUsage(resolveCgroupV2Paths())
 	// May fail in non-container environment
 	if err == nil {
 		assert.Greater(t, usage, uint64(0))
@@ -243,14 +260,14 @@ func TestCPUUsageDetection(t *testing.T) {
 // TestCgroupV2Detection tests cgroup v2 specific detection
 func TestCgroupV2Detection(t *testing.T) {
 	// Test cgroup v2 memory usage
-	_, err := readCgroupV2MemoryUsage()
+	_, err := readCgroupV2MemoryUsage(resolveCgroupV2Paths())
 	// Expected to fail in most test environments
 	if err == nil {
 		// If it succeeds, that's fine too
 	}
 
 	// Test cgroup v2 CPU usage
-	_, err = readCgroupV2CPUUsage()
+	_, err = readCgroupV2CPUUsage(resolveCgroupV2Paths())
 	// Expected to fail in most test environments
 	if err == nil {
 		// If it succeeds, that's fine too
@@ -323,3 +340,273 @@ func BenchmarkIsRunningInContainer(b *testing.B) {
 		isRunningInContainer()
 	}
 }
+
+// fakeFileReader returns a reader that fails the first failures reads with
+// err and then returns content
+func fakeFileReader(failures int, err error, content string) (func(string) ([]byte, error), *int) {
+	calls := 0
+	return func(path string) ([]byte, error) {
+		calls++
+		if calls <= failures {
+			return nil, &os.PathError{Op: "read", Path: path, Err: err}
+		}
+		return []byte(content), nil
+	}, &calls
+}
+
+// TestReadContainerFileRetry tests retrying transient cgroup read errors
+func TestReadContainerFileRetry(t *testing.T) {
+	originalReadFile := readFile
+	defer func() {
+		readFile = originalReadFile
+		SetFileReadPolicy(DefaultFileReadPolicy())
+	}()
+
+	logger := &mockLogger{}
+	SetFileReadPolicy(FileReadPolicy{Attempts: 3, Timeout: time.Second, Logger: logger})
+
+	// A transient failure followed by a successful read
+	var calls *int
+	readFile, calls = fakeFileReader(1, syscall.EINTR, "536870912\n")
+	limit, err := readCgroupV2MemoryLimit(resolveCgroupV2Paths())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(512*1024*1024), limit)
+	// Two reads of /proc/mounts, then /proc/self/cgroup and memory.max at
+	// the root
+	assert.Equal(t, 4, *calls)
+	assert.Equal(t, 0, logger.warnCalls)
+
+	// Persistent transient failures give up after the configured attempts
+	readFile, calls = fakeFileReader(10, syscall.EAGAIN, "")
+	_, err = readContainerFile("/sys/fs/cgroup/memory.max")
+	assert.ErrorIs(t, err, syscall.EAGAIN)
+	assert.Equal(t, 3, *calls)
+	assert.Equal(t, 1, logger.warnCalls)
+
+	// Other errors are not retried
+	readFile, calls = fakeFileReader(10, syscall.ENOENT, "")
+	_, err = readContainerFile("/sys/fs/cgroup/memory.max")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, 1, logger.warnCalls)
+}
+
+// TestCgroupV2CPUBurst tests reading the cgroup v2 CPU burst and weight
+func TestCgroupV2CPUBurst(t *testing.T) {
+	originalReadFile := readFile
+	defer func() { readFile = originalReadFile }()
+
+	files := map[string]string{
+		"/sys/fs/cgroup/cpu.max":       "200000 100000\n",
+		"/sys/fs/cgroup/cpu.max.burst": "50000\n",
+		"/sys/fs/cgroup/cpu.weight":    "250\n",
+	}
+	readFile = fixtureFileReader(files)
+
+	limit, err := readCgroupV2CPULimit(resolveCgroupV2Paths())
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, limit)
+
+	burst, err := readCgroupV2CPUBurst(resolveCgroupV2Paths())
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, burst)
+
+	weight, err := readCgroupV2CPUWeight(resolveCgroupV2Paths())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(250), weight)
+
+	resources := &ContainerResources{CPULimit: limit, CPUBurst: burst}
+	assert.Equal(t, 2.5, resources.BurstCPULimit())
+	assert.Equal(t, 0.0, (&ContainerResources{CPUBurst: burst}).BurstCPULimit())
+
+	// Burst is meaningless without a quota
+	files["/sys/fs/cgroup/cpu.max"] = "max 100000\n"
+	_, err = readCgroupV2CPUBurst(resolveCgroupV2Paths())
+	assert.Error(t, err)
+
+	// Kernels without burst support don't have the file
+	files["/sys/fs/cgroup/cpu.max"] = "200000 100000\n"
+	delete(files, "/sys/fs/cgroup/cpu.max.burst")
+	_, err = readCgroupV2CPUBurst(resolveCgroupV2Paths())
+	assert.Error(t, err)
+
+	files["/sys/fs/cgroup/cpu.max.burst"] = "lots\n"
+	_, err = readCgroupV2CPUBurst(resolveCgroupV2Paths())
+	assert.ErrorContains(t, err, "invalid cpu.max.burst")
+}
+
+// TestCgroupV2MemoryValue tests reading memory.high and memory.swap.max
+func TestCgroupV2MemoryValue(t *testing.T) {
+	originalReadFile := readFile
+	defer func() { readFile = originalReadFile }()
+
+	files := map[string]string{
+		"/sys/fs/cgroup/memory.high":     "402653184\n",
+		"/sys/fs/cgroup/memory.swap.max": "max\n",
+	}
+	readFile = fixtureFileReader(files)
+
+	high, err := readCgroupV2MemoryValue(resolveCgroupV2Paths(), "memory.high")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(384<<20), high)
+
+	_, err = readCgroupV2MemoryValue(resolveCgroupV2Paths(), "memory.swap.max")
+	assert.ErrorContains(t, err, "no memory.swap.max set")
+
+	files["/sys/fs/cgroup/memory.high"] = "lots\n"
+	_, err = readCgroupV2MemoryValue(resolveCgroupV2Paths(), "memory.high")
+	assert.ErrorContains(t, err, "invalid memory.high")
+}
+
+// TestCgroupV2SwapLimit tests telling unlimited, disabled and unknown swap
+// apart
+func TestCgroupV2SwapLimit(t *testing.T) {
+	originalReadFile := readFile
+	defer func() { readFile = originalReadFile }()
+
+	files := map[string]string{
+		"/sys/fs/cgroup/memory.swap.max": "max\n",
+	}
+	readFile = fixtureFileReader(files)
+
+	swap, err := readCgroupV2SwapLimit(resolveCgroupV2Paths())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(UnlimitedSwap), swap)
+
+	files["/sys/fs/cgroup/memory.swap.max"] = "0\n"
+	swap, err = readCgroupV2SwapLimit(resolveCgroupV2Paths())
+	require.NoError(t, err)
+	assert.Zero(t, swap)
+
+	files["/sys/fs/cgroup/memory.swap.max"] = "1073741824\n"
+	swap, err = readCgroupV2SwapLimit(resolveCgroupV2Paths())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1<<30), swap)
+
+	files["/sys/fs/cgroup/memory.swap.max"] = "lots\n"
+	_, err = readCgroupV2SwapLimit(resolveCgroupV2Paths())
+	assert.ErrorContains(t, err, "invalid memory.swap.max")
+
+	delete(files, "/sys/fs/cgroup/memory.swap.max")
+	_, err = readCgroupV2SwapLimit(resolveCgroupV2Paths())
+	assert.Error(t, err)
+}
+
+// TestCgroupV2NestedPath tests reading cgroup v2 limits from the process's
+// cgroup rather than the hierarchy root
+func TestCgroupV2NestedPath(t *testing.T) {
+	originalReadFile := readFile
+	defer func() { readFile = originalReadFile }()
+
+	pod := "/sys/fs/cgroup/kubepods.slice/kubepods-pod1.slice/cri-containerd-abc.scope"
+	files := map[string]string{
+		"/proc/self/cgroup":         "0::/kubepods.slice/kubepods-pod1.slice/cri-containerd-abc.scope\n",
+		"/sys/fs/cgroup/cpu.max":    "max 100000\n",
+		"/sys/fs/cgroup/memory.max": "max\n",
+		pod + "/cpu.max":            "150000 100000\n",
+		pod + "/memory.max":         "268435456\n",
+		pod + "/memory.current":     "134217728\n",
+		"/sys/fs/cgroup/cpu.weight": "100\n",
+	}
+	readFile = fixtureFileReader(files)
+
+	assert.Equal(t, pod, cgroupV2Dir("/sys/fs/cgroup"))
+	cg := resolveCgroupV2Paths()
+	assert.Equal(t, cgroupV2Paths{mount: "/sys/fs/cgroup", dir: pod}, cg)
+
+	cpuLimit, err := readCgroupV2CPULimit(cg)
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, cpuLimit)
+
+	memoryLimit, err := readCgroupV2MemoryLimit(cg)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(268435456), memoryLimit)
+
+	usage, err := readCgroupV2MemoryUsage(cg)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(134217728), usage)
+
+	// Files missing from the nested cgroup are read at the root
+	weight, err := readCgroupV2CPUWeight(cg)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), weight)
+
+	// Inside a cgroup namespace the root is the process's cgroup
+	files["/proc/self/cgroup"] = "0::/\n"
+	assert.Equal(t, "/sys/fs/cgroup", cgroupV2Dir("/sys/fs/cgroup"))
+	_, err = readCgroupV2CPULimit(resolveCgroupV2Paths())
+	assert.Error(t, err)
+
+	// Paths outside the mount and hybrid hierarchies without a unified
+	// line resolve to the root
+	files["/proc/self/cgroup"] = "0::/../../escape\n"
+	assert.Equal(t, "/sys/fs/cgroup", cgroupV2Dir("/sys/fs/cgroup"))
+	files["/proc/self/cgroup"] = "4:memory:/kubepods/pod1\n"
+	assert.Equal(t, "/sys/fs/cgroup", cgroupV2Dir("/sys/fs/cgroup"))
+}
+
+// TestCgroupV2PathsResolvedOnce tests that reads through resolved cgroup v2
+// paths don't parse /proc/mounts and /proc/self/cgroup again
+func TestCgroupV2PathsResolvedOnce(t *testing.T) {
+	originalReadFile := readFile
+	defer func() { readFile = originalReadFile }()
+
+	pod := "/sys/fs/cgroup/kubepods.slice/pod1.slice"
+	files := map[string]string{
+		"/proc/self/cgroup":     "0::/kubepods.slice/pod1.slice\n",
+		pod + "/memory.current": "134217728\n",
+		pod + "/cpu.stat":       "usage_usec 2000000\n",
+	}
+	fixture := fixtureFileReader(files)
+	procReads := 0
+	readFile = func(path string) ([]byte, error) {
+		if strings.HasPrefix(path, "/proc/") {
+			procReads++
+		}
+		return fixture(path)
+	}
+
+	cg := resolveCgroupV2Paths()
+	resolved := procReads
+
+	usage, err := readCgroupV2MemoryUsage(cg)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(134217728), usage)
+	cpu, err := readCgroupV2CPUUsage(cg)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, cpu)
+	assert.Equal(t, resolved, procReads)
+
+	// The zero value resolves on every read
+	_, err = readCgroupV2MemoryUsage(cgroupV2Paths{})
+	require.NoError(t, err)
+	assert.Greater(t, procReads, resolved)
+}
+
+// TestMemoryWorkingSet tests subtracting the inactive file cache from the
+// cgroup memory usage
+func TestMemoryWorkingSet(t *testing.T) {
+	stat := []byte("anon 104857600\ninactive_file 52428800\nactive_file 10485760\n")
+	assert.Equal(t, uint64(150<<20), workingSet(200<<20, stat, "inactive_file"))
+
+	// Inactive cache above the usage, as racy reads can give, clamps to 0
+	assert.Zero(t, workingSet(40<<20, stat, "inactive_file"))
+
+	// A missing or invalid entry leaves the usage unchanged
+	assert.Equal(t, uint64(200<<20), workingSet(200<<20, stat, "total_inactive_file"))
+	assert.Equal(t, uint64(200<<20), workingSet(200<<20, []byte("inactive_file lots\n"), "inactive_file"))
+
+	originalReadFile := readFile
+	defer func() { readFile = originalReadFile }()
+	files := map[string]string{
+		"/proc/mounts":      "cgroup /sys/fs/cgroup cgroup rw,memory 0 0\n",
+		"/proc/self/cgroup": "4:memory:/kubepods/pod1\n",
+		"/sys/fs/cgroup/memory/kubepods/pod1/memory.usage_in_bytes": "209715200\n",
+		"/sys/fs/cgroup/memory/kubepods/pod1/memory.stat":           "cache 62914560\ninactive_file 1048576\ntotal_inactive_file 52428800\n",
+	}
+	readFile = fixtureFileReader(files)
+
+	usage, err := readCgroupV1MemoryUsage()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(150<<20), usage)
+}
@@ -0,0 +1,169 @@
+package autotune
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// cpuSampleInterval is the minimum time between two readings of the CPU
+// usage counters. Calls within it return the previous result, so repeated
+// calls are cheap and the utilization isn't computed over a tiny, noisy
+// interval.
+const cpuSampleInterval = 100 * time.Millisecond
+
+// CPUSampler computes CPU utilization from a cumulative CPU time counter by
+// keeping the previous reading and dividing the CPU time used since by the
+// elapsed wall time. In a container the counter is the cgroup's
+// (usage_usec in cgroup v2, cpuacct.usage in cgroup v1); elsewhere it is
+// the CPU time of the process. It is safe for concurrent use.
+type CPUSampler struct {
+	mu    sync.Mutex
+	limit float64
+
+	lastUsage time.Duration
+	lastTime  time.Time
+	hasLast   bool
+	usage     float64
+	cores     float64
+	sampled   bool
+
+	now       func() time.Time
+	readUsage func() (time.Duration, error)
+}
+
+// NewCPUSampler creates a CPU sampler reading the usage counters of the
+// process's cgroup and reporting utilization against limit cores. A limit
+// of zero uses the number of CPUs.
+func NewCPUSampler(limit float64) *CPUSampler {
+	return newCPUSampler(limit, resolveCgroupV2Paths())
+}
+
+// newCPUSampler creates a CPU sampler reading the usage counters of the
+// cgroup v2 at cg, or of cgroup v1
+func newCPUSampler(limit float64, cg cgroupV2Paths) *CPUSampler {
+	if limit <= 0 {
+		limit = float64(runtime.NumCPU())
+	}
+	return &CPUSampler{
+		limit: limit,
+		now:   time.Now,
+		readUsage: func() (time.Duration, error) {
+			return readCPUUsageCounter(cg)
+		},
+	}
+}
+
+// newProcessCPUSampler creates a CPU sampler reading the CPU time of the
+// process against the number of CPUs. Outside a container the enclosing
+// cgroup is shared with other processes, such as a systemd slice, so its
+// counters don't tell how much CPU the process uses.
+func newProcessCPUSampler() *CPUSampler {
+	return &CPUSampler{
+		limit:     float64(runtime.NumCPU()),
+		now:       time.Now,
+		readUsage: processCPUTime,
+	}
+}
+
+// newContainerCPUSampler creates the sampler a tuner measures CPUUsage with.
+// Its ceiling is the CPU quota plus any cgroup v2 burst allowance: GC work
+// comes in short spikes the burst absorbs, so usage near the quota alone
+// doesn't mean there is no headroom for it.
+func newContainerCPUSampler(resources *ContainerResources) *CPUSampler {
+	if resources == nil || !resources.IsContainer {
+		return newProcessCPUSampler()
+	}
+	return newCPUSampler(resources.BurstCPULimit(), resources.cgroup)
+}
+
+var (
+	cpuSamplerOnce sync.Once
+	cpuSampler     *CPUSampler
+)
+
+// defaultCPUSampler returns the sampler used by GetContainerStats, created
+// with the detected CPU limit on first use
+func defaultCPUSampler() *CPUSampler {
+	cpuSamplerOnce.Do(func() {
+		if !isRunningInContainer() {
+			cpuSampler = newProcessCPUSampler()
+			return
+		}
+		cg := resolveCgroupV2Paths()
+		limit, _ := detectCPULimit(cg)
+		cpuSampler = newCPUSampler(limit, cg)
+	})
+	return cpuSampler
+}
+
+// Sample returns the CPU used since the previous reading, both as a
+// fraction of the CPU limit (0-1) and in cores. The first call only records
+// a reading and returns an error, as does a call after the counter went
+// backwards. Calls less than cpuSampleInterval after the previous reading
+// return its result.
+func (s *CPUSampler) Sample() (usage, cores float64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sampleLocked()
+}
+
+// sampleOrWait is Sample, except that without a previous reading it takes
+// one and samples again cpuSampleInterval later
+func (s *CPUSampler) sampleOrWait() (usage, cores float64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.hasLast {
+		if _, _, err := s.sampleLocked(); !s.hasLast {
+			return 0, 0, err
+		}
+		time.Sleep(cpuSampleInterval)
+	}
+	return s.sampleLocked()
+}
+
+// sampleLocked implements Sample. s.mu must be held.
+func (s *CPUSampler) sampleLocked() (usage, cores float64, err error) {
+	now := s.now()
+	if s.hasLast && now.Sub(s.lastTime) < cpuSampleInterval {
+		if !s.sampled {
+			return 0, 0, fmt.Errorf("no previous CPU usage reading")
+		}
+		return s.usage, s.cores, nil
+	}
+
+	total, err := s.readUsage()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	previous, previousTime, hadLast := s.lastUsage, s.lastTime, s.hasLast
+	s.lastUsage, s.lastTime, s.hasLast = total, now, true
+
+	if !hadLast {
+		return 0, 0, fmt.Errorf("no previous CPU usage reading")
+	}
+	elapsed := now.Sub(previousTime)
+	if total < previous || elapsed <= 0 {
+		s.sampled = false
+		return 0, 0, fmt.Errorf("CPU usage counter reset")
+	}
+
+	s.cores = float64(total-previous) / float64(elapsed)
+	s.usage = s.cores / s.limit
+	if s.usage > 1 {
+		// Burst allowances let usage briefly exceed the quota
+		s.usage = 1
+	}
+	s.sampled = true
+	return s.usage, s.cores, nil
+}
+
+// getCurrentCPUUsage returns the CPU utilization since the previous call as
+// a fraction of the CPU limit
+func getCurrentCPUUsage() (float64, error) {
+	usage, _, err := defaultCPUSampler().sampleOrWait()
+	return usage, err
+}
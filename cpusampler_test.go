@@ -0,0 +1,152 @@
+package autotune
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCPUSampler tests CPU utilization computed from counter deltas
+func TestCPUSampler(t *testing.T) {
+	now := time.Unix(0, 0)
+	var counter time.Duration
+	reads := 0
+
+	sampler := NewCPUSampler(2)
+	sampler.now = func() time.Time { return now }
+	sampler.readUsage = func() (time.Duration, error) {
+		reads++
+		return counter, nil
+	}
+
+	_, _, err := sampler.Sample()
+	assert.Error(t, err, "the first call only takes a reading")
+
+	// One core busy for a second out of a two-core limit
+	now = now.Add(time.Second)
+	counter += time.Second
+	usage, cores, err := sampler.Sample()
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, usage, 1e-9)
+	assert.InDelta(t, 1.0, cores, 1e-9)
+
+	// Calls within the sample interval reuse the previous result
+	now = now.Add(10 * time.Millisecond)
+	counter += time.Second
+	usage, _, err = sampler.Sample()
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, usage, 1e-9)
+	assert.Equal(t, 2, reads)
+
+	// Usage above the limit is capped
+	now = now.Add(990 * time.Millisecond)
+	counter += 2 * time.Second
+	usage, cores, err = sampler.Sample()
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, usage)
+	assert.InDelta(t, 3.0, cores, 1e-9)
+
+	// A counter reset takes a new baseline
+	now = now.Add(time.Second)
+	counter = 0
+	_, _, err = sampler.Sample()
+	assert.Error(t, err)
+	now = now.Add(time.Second)
+	counter = 500 * time.Millisecond
+	usage, _, err = sampler.Sample()
+	require.NoError(t, err)
+	assert.InDelta(t, 0.25, usage, 1e-9)
+}
+
+// TestCPUSamplerCgroupV2 tests reading usage_usec from cgroup v2 cpu.stat
+func TestCPUSamplerCgroupV2(t *testing.T) {
+	originalReadFile := readFile
+	defer func() { readFile = originalReadFile }()
+	readFile = fixtureFileReader(map[string]string{
+		"/sys/fs/cgroup/cpu.stat": "usage_usec 2500000\nuser_usec 2000000\nsystem_usec 500000\n",
+	})
+
+	usage, err := readCgroupV2CPUUsage(resolveCgroupV2Paths())
+	require.NoError(t, err)
+	assert.Equal(t, 2500*time.Millisecond, usage)
+
+	sampler := NewCPUSampler(1)
+	_, _, err = sampler.sampleOrWait()
+	require.NoError(t, err, "samples twice without a previous reading")
+}
+
+// TestCPUSamplerOutsideContainer tests that outside a container the CPU time
+// of the process is sampled rather than the counters of the enclosing cgroup
+// or, failing that, of the root cgroup
+func TestCPUSamplerOutsideContainer(t *testing.T) {
+	originalReadFile := readFile
+	defer func() { readFile = originalReadFile }()
+	readFile = fixtureFileReader(map[string]string{
+		"/proc/self/cgroup":       "0::/user.slice/user-1000.slice/session-1.scope\n",
+		"/sys/fs/cgroup/cpu.stat": "usage_usec 2500000\n",
+	})
+
+	_, err := readCgroupV2CPUUsage(resolveCgroupV2Paths())
+	assert.Error(t, err, "host-wide usage at the root isn't the cgroup's")
+
+	for _, resources := range []*ContainerResources{nil, {CPULimit: 2}} {
+		sampler := newContainerCPUSampler(resources)
+		assert.Equal(t, float64(runtime.NumCPU()), sampler.limit)
+
+		before, err := sampler.readUsage()
+		require.NoError(t, err)
+		for end := time.Now().Add(20 * time.Millisecond); time.Now().Before(end); {
+		}
+		after, err := sampler.readUsage()
+		require.NoError(t, err)
+		assert.Greater(t, after, before, "reads the CPU time of the process")
+	}
+}
+
+// TestCPUBurstHeadroom tests that a burst allowance counts as CPU headroom
+// for GC work, so the CPU factor doesn't raise GOGC while it is available
+func TestCPUBurstHeadroom(t *testing.T) {
+	// 1.7 cores used over a second
+	usage := func(resources *ContainerResources) float64 {
+		now := time.Unix(0, 0)
+		var counter time.Duration
+		sampler := newContainerCPUSampler(resources)
+		sampler.now = func() time.Time { return now }
+		sampler.readUsage = func() (time.Duration, error) { return counter, nil }
+
+		_, _, err := sampler.Sample()
+		require.Error(t, err)
+		now = now.Add(time.Second)
+		counter += 1700 * time.Millisecond
+		usage, _, err := sampler.Sample()
+		require.NoError(t, err)
+		return usage
+	}
+
+	quotaOnly := usage(&ContainerResources{IsContainer: true, CPULimit: 2})
+	withBurst := usage(&ContainerResources{IsContainer: true, CPULimit: 2, CPUBurst: 0.5})
+	assert.InDelta(t, 0.85, quotaOnly, 1e-9)
+	assert.InDelta(t, 0.68, withBurst, 1e-9)
+
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+	tuner.qosClass = QoSClassUnknown
+	target := func(cpuUsage float64) int {
+		gogc, _ := tuner.calculateTarget(Metrics{
+			CurrentGOGC:    100,
+			GCPauseTime:    10 * time.Millisecond,
+			MemoryPressure: 0.5,
+			GCFrequency:    1,
+			CPUUsage:       cpuUsage,
+			GCCPUFraction:  0.2,
+		})
+		return gogc
+	}
+
+	// Near the quota the CPU factor raises GOGC; the burst leaves room
+	assert.Greater(t, target(quotaOnly), 100)
+	assert.Equal(t, 100, target(withBurst))
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package autotune
+
+import (
+	"fmt"
+	"time"
+)
+
+// processCPUTime returns the user and system CPU time used by the process.
+// It isn't available on this platform.
+func processCPUTime() (time.Duration, error) {
+	return 0, fmt.Errorf("process CPU time not supported")
+}
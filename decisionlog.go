@@ -0,0 +1,126 @@
+package autotune
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FileDecisionObserver appends every decision to a file as a JSON line,
+// giving a durable audit trail independent of the in-memory history cap.
+// When a write would grow the file beyond maxBytes, the file is rotated to
+// path.1 (replacing any previous rotation) and a new file is started.
+type FileDecisionObserver struct {
+	path     string
+	maxBytes int64
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	err  error
+}
+
+// NewFileDecisionObserver creates a decision observer writing to path. A
+// maxBytes of zero or less disables rotation.
+func NewFileDecisionObserver(path string, maxBytes int64) (*FileDecisionObserver, error) {
+	fdo := &FileDecisionObserver{path: path, maxBytes: maxBytes}
+	if err := fdo.open(); err != nil {
+		return nil, err
+	}
+	return fdo, nil
+}
+
+// OnDecision writes the decision as one JSON line. Lines are written
+// unbuffered so each decision is on disk as soon as it has been applied.
+func (fdo *FileDecisionObserver) OnDecision(decision TuningDecision) {
+	line, err := json.Marshal(decision)
+	if err != nil {
+		fdo.setErr(fmt.Errorf("failed to encode decision: %w", err))
+		return
+	}
+	line = append(line, '\n')
+
+	fdo.mu.Lock()
+	defer fdo.mu.Unlock()
+
+	if fdo.file == nil {
+		fdo.err = fmt.Errorf("decision log %s is closed", fdo.path)
+		return
+	}
+
+	if fdo.maxBytes > 0 && fdo.size > 0 && fdo.size+int64(len(line)) > fdo.maxBytes {
+		if err := fdo.rotate(); err != nil {
+			fdo.err = err
+			return
+		}
+	}
+
+	n, err := fdo.file.Write(line)
+	fdo.size += int64(n)
+	if err != nil {
+		fdo.err = fmt.Errorf("failed to write decision log %s: %w", fdo.path, err)
+	}
+}
+
+// Err returns the last error encountered while writing, if any
+func (fdo *FileDecisionObserver) Err() error {
+	fdo.mu.Lock()
+	defer fdo.mu.Unlock()
+	return fdo.err
+}
+
+// Close closes the decision log file
+func (fdo *FileDecisionObserver) Close() error {
+	fdo.mu.Lock()
+	defer fdo.mu.Unlock()
+
+	if fdo.file == nil {
+		return nil
+	}
+	err := fdo.file.Close()
+	fdo.file = nil
+	return err
+}
+
+// open opens the log file for appending and records its current size
+func (fdo *FileDecisionObserver) open() error {
+	file, err := os.OpenFile(fdo.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open decision log %s: %w", fdo.path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat decision log %s: %w", fdo.path, err)
+	}
+
+	fdo.file = file
+	fdo.size = info.Size()
+	return nil
+}
+
+// rotate moves the current file to path.1 and starts a new one
+func (fdo *FileDecisionObserver) rotate() error {
+	if err := fdo.file.Close(); err != nil {
+		return fmt.Errorf("failed to close decision log %s: %w", fdo.path, err)
+	}
+	fdo.file = nil
+
+	if err := os.Rename(fdo.path, fdo.path+".1"); err != nil {
+		// Keep appending to the current file rather than losing decisions
+		if openErr := fdo.open(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("failed to rotate decision log %s: %w", fdo.path, err)
+	}
+
+	return fdo.open()
+}
+
+func (fdo *FileDecisionObserver) setErr(err error) {
+	fdo.mu.Lock()
+	defer fdo.mu.Unlock()
+	fdo.err = err
+}
@@ -0,0 +1,99 @@
+package autotune
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readDecisionLines decodes every line of a decision log
+func readDecisionLines(t *testing.T, path string) []TuningDecision {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var decisions []TuningDecision
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var decision TuningDecision
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &decision), "line is valid JSON")
+		decisions = append(decisions, decision)
+	}
+	require.NoError(t, scanner.Err())
+	return decisions
+}
+
+// TestFileDecisionObserverRotation tests JSON line output and size-based rotation
+func TestFileDecisionObserverRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.log")
+
+	observer, err := NewFileDecisionObserver(path, 1024)
+	require.NoError(t, err)
+	defer observer.Close()
+
+	for i := 0; i < 20; i++ {
+		observer.OnDecision(TuningDecision{
+			OldGOGC:    100,
+			NewGOGC:    100 + i,
+			Reason:     "rotation test",
+			Confidence: 0.8,
+			Timestamp:  time.Now(),
+		})
+	}
+	require.NoError(t, observer.Err())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), int64(1024))
+
+	rotatedInfo, err := os.Stat(path + ".1")
+	require.NoError(t, err, "log was rotated")
+	assert.LessOrEqual(t, rotatedInfo.Size(), int64(1024))
+
+	current := readDecisionLines(t, path)
+	rotated := readDecisionLines(t, path+".1")
+	require.NotEmpty(t, current)
+	require.NotEmpty(t, rotated)
+	assert.Equal(t, 119, current[len(current)-1].NewGOGC)
+	assert.Equal(t, current[0].NewGOGC-1, rotated[len(rotated)-1].NewGOGC)
+}
+
+// TestFileDecisionObserverWithTuner tests the observer receiving applied decisions
+func TestFileDecisionObserverWithTuner(t *testing.T) {
+	originalGOGC := debug.SetGCPercent(100)
+	defer debug.SetGCPercent(originalGOGC)
+
+	path := filepath.Join(t.TempDir(), "decisions.log")
+	observer, err := NewFileDecisionObserver(path, 0)
+	require.NoError(t, err)
+
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+	tuner.AddDecisionObserver(observer)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tuner.applyTuningDecision(TuningDecision{NewGOGC: 100 + i, Reason: "concurrent", Timestamp: time.Now()})
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, observer.Close())
+
+	decisions := readDecisionLines(t, path)
+	assert.Len(t, decisions, 10)
+
+	// Writes after close are reported
+	observer.OnDecision(TuningDecision{})
+	assert.Error(t, observer.Err())
+}
@@ -0,0 +1,122 @@
+package autotune
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MetricType is the Prometheus type of an exported metric
+type MetricType string
+
+const (
+	// MetricTypeGauge is a value that can go up and down
+	MetricTypeGauge MetricType = "gauge"
+	// MetricTypeCounter is a value that only increases
+	MetricTypeCounter MetricType = "counter"
+	// MetricTypeHistogram is a distribution of observations in buckets
+	MetricTypeHistogram MetricType = "histogram"
+)
+
+// MetricDescriptor describes an exported metric
+type MetricDescriptor struct {
+	Name string     `json:"name"`
+	Type MetricType `json:"type"`
+	Help string     `json:"help"`
+	Unit string     `json:"unit,omitempty"`
+}
+
+// metricCatalog is the source of truth for the exported metrics, in export
+// order. Add a metric here before emitting it.
+var metricCatalog = []MetricDescriptor{
+	{Name: "autotune_running", Type: MetricTypeGauge, Unit: "", Help: "Whether the tuner is running (1) or idle (0)"},
+	{Name: "autotune_metrics_stale", Type: MetricTypeGauge, Unit: "", Help: "Whether the monitor loop has stopped completing tuning cycles (1) or not (0)"},
+	{Name: "autotune_last_cycle_timestamp_seconds", Type: MetricTypeGauge, Unit: "seconds", Help: "Unix time the last tuning cycle completed"},
+	{Name: "autotune_gc_pause_time_ns", Type: MetricTypeGauge, Unit: "nanoseconds", Help: "Current GC pause time in nanoseconds"},
+	{Name: "autotune_gc_pause_p95_ns", Type: MetricTypeGauge, Unit: "nanoseconds", Help: "95th percentile of the last 256 GC pauses in nanoseconds"},
+	{Name: "autotune_gc_pause_p99_ns", Type: MetricTypeGauge, Unit: "nanoseconds", Help: "99th percentile of the last 256 GC pauses in nanoseconds"},
+	{Name: "autotune_gc_pause_seconds", Type: MetricTypeHistogram, Unit: "seconds", Help: "Individual GC pause durations in seconds (a summary when PauseMetricType is summary)"},
+	{Name: "autotune_gc_pause_trend", Type: MetricTypeGauge, Unit: "ns/s", Help: "Slope of the GC pause time over the last 10 samples in nanoseconds per second, positive when worsening"},
+	{Name: "autotune_alloc_rate_bytes_per_second", Type: MetricTypeGauge, Unit: "bytes/s", Help: "Bytes allocated per second since the previous sample"},
+	{Name: "autotune_gc_frequency_per_second", Type: MetricTypeGauge, Unit: "1/s", Help: "Current GC frequency per second"},
+	{Name: "autotune_heap_size_bytes", Type: MetricTypeGauge, Unit: "bytes", Help: "Current heap size in bytes"},
+	{Name: "autotune_heap_alloc_bytes", Type: MetricTypeGauge, Unit: "bytes", Help: "Current heap allocation in bytes"},
+	{Name: "autotune_heap_fragmentation_ratio", Type: MetricTypeGauge, Unit: "ratio", Help: "Share of the heap obtained from the OS that isn't in in-use spans"},
+	{Name: "autotune_memory_pressure_ratio", Type: MetricTypeGauge, Unit: "ratio", Help: "Memory usage relative to the MemoryLimitPercent threshold, used for tuning"},
+	{Name: "autotune_memory_pressure_raw_ratio", Type: MetricTypeGauge, Unit: "ratio", Help: "Memory usage relative to the container memory limit, used for alerts"},
+	{Name: "autotune_memory_limit_bytes", Type: MetricTypeGauge, Unit: "bytes", Help: "Soft memory limit (GOMEMLIMIT) set by the tuner, 0 when not managed"},
+	{Name: "autotune_gogc_current", Type: MetricTypeGauge, Unit: "percent", Help: "Current GOGC value"},
+	{Name: "autotune_gogc_target", Type: MetricTypeGauge, Unit: "percent", Help: "Unclamped GOGC target of the last decision"},
+	{Name: "autotune_seconds_since_last_decision", Type: MetricTypeGauge, Unit: "seconds", Help: "Seconds since the last applied decision, or since the tuner started if none"},
+	{Name: "autotune_total_decisions_total", Type: MetricTypeCounter, Unit: "", Help: "Total number of tuning decisions made"},
+	{Name: "autotune_successful_tunes_total", Type: MetricTypeCounter, Unit: "", Help: "Number of successful tuning decisions"},
+	{Name: "autotune_rate_limited_total", Type: MetricTypeCounter, Unit: "", Help: "Number of applied decisions held back from their target by MaxChangePerInterval"},
+	{Name: "autotune_bound_hits_total", Type: MetricTypeCounter, Unit: "", Help: "Number of applied decisions clamped to MinGOGC (bound=\"min\") or MaxGOGC (bound=\"max\")"},
+	{Name: "autotune_decisions_by_category_total", Type: MetricTypeCounter, Unit: "", Help: "Number of GOGC changes by the factor that drove them, labeled by category"},
+	{Name: "autotune_reverted_tunes_total", Type: MetricTypeCounter, Unit: "", Help: "Number of reverted tuning decisions"},
+	{Name: "autotune_forced_gc_total", Type: MetricTypeCounter, Unit: "", Help: "Number of GCs forced by runtime.GC, excluded from the GC frequency"},
+	{Name: "autotune_tuning_health_score", Type: MetricTypeGauge, Unit: "ratio", Help: "Tuning health score from 0 (struggling) to 1 (healthy)"},
+	{Name: "autotune_container_memory_limit_bytes", Type: MetricTypeGauge, Unit: "bytes", Help: "Container memory limit in bytes"},
+	{Name: "autotune_container_cpu_limit_cores", Type: MetricTypeGauge, Unit: "cores", Help: "Container CPU limit in cores"},
+	{Name: "autotune_container_cpu_burst_cores", Type: MetricTypeGauge, Unit: "cores", Help: "Container CPU burst allowance above the limit in cores"},
+}
+
+// metricDescriptors indexes metricCatalog by name
+var metricDescriptors = func() map[string]MetricDescriptor {
+	descriptors := make(map[string]MetricDescriptor, len(metricCatalog))
+	for _, desc := range metricCatalog {
+		descriptors[desc.Name] = desc
+	}
+	return descriptors
+}()
+
+// Describe returns the name, type, help text and unit of every metric that
+// may be exported, for building dashboards and documentation. Some metrics
+// are only exported under certain conditions, such as a detected container
+// limit.
+func (me *MetricsExporter) Describe() []MetricDescriptor {
+	return describeMetrics()
+}
+
+// describeMetrics returns a copy of the metric catalog
+func describeMetrics() []MetricDescriptor {
+	return append([]MetricDescriptor(nil), metricCatalog...)
+}
+
+// writePrometheusMetric writes a metric sample preceded by its HELP and TYPE
+// lines from the catalog. format is the verb for value, e.g. %d.
+func writePrometheusMetric(w io.Writer, name, labels, format string, value interface{}) {
+	if desc, ok := metricDescriptors[name]; ok {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, desc.Help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", name, desc.Type)
+	}
+	fmt.Fprintf(w, "%s%s "+format+"\n", name, labels, value)
+}
+
+// withLabel returns the labels plus one more, formatted
+func withLabel(labels map[string]string, key, value string) string {
+	merged := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		merged[k] = v
+	}
+	merged[key] = value
+	return formatLabels(merged)
+}
+
+// writeBoundHitMetric writes autotune_bound_hits_total with one sample per
+// bound
+func writeBoundHitMetric(w io.Writer, labels map[string]string, stats map[string]interface{}) {
+	const name = "autotune_bound_hits_total"
+
+	fmt.Fprintf(w, "# HELP %s %s\n", name, metricDescriptors[name].Help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricDescriptors[name].Type)
+	fmt.Fprintf(w, "%s%s %d\n", name, withLabel(labels, "bound", "min"), stats["bound_hits_min"])
+	fmt.Fprintf(w, "%s%s %d\n", name, withLabel(labels, "bound", "max"), stats["bound_hits_max"])
+}
+
+// handleMetricsDescribe handles the metric descriptor endpoint
+func (obs *ObservabilityServer) handleMetricsDescribe(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(describeMetrics())
+}
@@ -0,0 +1,86 @@
+package autotune
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleNames returns the distinct metric names of the samples in Prometheus
+// text output, with histogram and summary sample suffixes removed
+func sampleNames(output string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range regexp.MustCompile(`(?m)^(autotune_\w+?)(_bucket|_sum|_count)?[{ ]`).FindAllStringSubmatch(output, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// TestMetricCatalog tests that every exported metric is described
+func TestMetricCatalog(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+	tuner.containerResources = &ContainerResources{MemoryLimit: 1 << 30, CPULimit: 2, CPUBurst: 0.5}
+	tuner.lastCycleTime = time.Now()
+
+	exporter := NewMetricsExporter(tuner)
+	descriptors := exporter.Describe()
+	require.Len(t, descriptors, len(metricCatalog))
+
+	seen := make(map[string]bool)
+	for _, desc := range descriptors {
+		assert.False(t, seen[desc.Name], "duplicate metric %s", desc.Name)
+		seen[desc.Name] = true
+		assert.NotEmpty(t, desc.Help)
+		assert.Contains(t, []MetricType{MetricTypeGauge, MetricTypeCounter, MetricTypeHistogram}, desc.Type)
+	}
+
+	// Modifying the result doesn't affect the catalog
+	descriptors[0].Name = "changed"
+	assert.NotEqual(t, "changed", metricCatalog[0].Name)
+
+	obsConfig := DefaultObservabilityConfig()
+	obsConfig.ExportCycleTimestamp = true
+	obsConfig.PauseMetricType = PauseMetricHistogram
+	obs := NewObservabilityServer(obsConfig, tuner)
+	w := httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics?format=prometheus", nil))
+
+	// Both Prometheus paths export exactly the described metrics
+	var all []string
+	for _, desc := range metricCatalog {
+		all = append(all, desc.Name)
+	}
+	assert.ElementsMatch(t, all, sampleNames(w.Body.String()))
+
+	output, err := exporter.ExportToPrometheus()
+	require.NoError(t, err)
+	for _, name := range sampleNames(output) {
+		assert.True(t, seen[name], "metric %s missing from the catalog", name)
+	}
+}
+
+// TestMetricsDescribeEndpoint tests the metric descriptor endpoint
+func TestMetricsDescribeEndpoint(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+	obs := NewObservabilityServer(DefaultObservabilityConfig(), tuner)
+
+	w := httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics/describe", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var descriptors []MetricDescriptor
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &descriptors))
+	assert.Equal(t, metricCatalog, descriptors)
+}
@@ -0,0 +1,48 @@
+package autotune
+
+// nondeterministicSourcesLocked lists the active features that make
+// decisions depend on more than the config and the metrics history, so that
+// Simulate over the recorded history may not reproduce them. t.mu must be
+// held.
+func (t *Tuner) nondeterministicSourcesLocked() []string {
+	var sources []string
+
+	// Application code may veto or rewrite decisions on any input
+	if t.decisionFilter != nil {
+		sources = append(sources, "decision_filter")
+	}
+	// Custom strategies are application code that may keep its own state;
+	// the default and PID strategies are replayed by Simulate
+	switch t.config.Strategy.(type) {
+	case nil, *DefaultStrategy, *PIDStrategy:
+	default:
+		sources = append(sources, "strategy")
+	}
+	// Application callbacks feed values into the metrics that recorded
+	// traces don't keep
+	if len(t.metricHooks) > 0 {
+		sources = append(sources, "metric_hooks")
+	}
+	if t.latencyProvider != nil {
+		sources = append(sources, "latency_provider")
+	}
+	if t.recommendationSource != nil {
+		sources = append(sources, "recommendation_source")
+	}
+
+	return sources
+}
+
+// IsDeterministic reports whether the tuner's decisions are a function of
+// its config and metrics history alone, so that Simulate over the recorded
+// history reproduces them. Sampled values such as CPU and cgroup memory
+// usage are part of the history, and SLO mode is timed on the samples'
+// timestamps, so neither breaks it. It is false with a decision filter, a
+// custom strategy, metric hooks, a latency provider or a recommendation
+// source (application code). Config.RandSeed only affects the retry jitter
+// of exporters, never a decision, so it doesn't matter here.
+func (t *Tuner) IsDeterministic() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.nondeterministicSourcesLocked()) == 0
+}
@@ -0,0 +1,144 @@
+package autotune
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// representativeTrace returns an hour of samples 30s apart from a container
+// with a 1GiB limit, with pauses oscillating around the default target, a
+// GC frequency spike, a live heap climbing towards the limit and back, and
+// request latency and cluster recommendations
+func representativeTrace() []Metrics {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limit := uint64(1 << 30)
+
+	history := make([]Metrics, 120)
+	for i := range history {
+		phase := float64(i) / 10
+		live := uint64(float64(limit) * (0.4 + 0.35*math.Sin(float64(i)/40*math.Pi)))
+		frequency := 1 + 0.5*math.Cos(phase)
+		if i >= 60 && i < 75 {
+			frequency = 4
+		}
+
+		history[i] = Metrics{
+			GCPauseTime:       time.Duration(float64(10*time.Millisecond) * (1 + 0.6*math.Sin(phase))),
+			GCFrequency:       frequency,
+			HeapSize:          live * 2,
+			HeapAlloc:         live,
+			HeapInuse:         live + live/10,
+			LiveHeap:          live,
+			MemoryUsage:       live,
+			ContainerMemLimit: limit,
+			RequestLatency:    time.Duration(20+i%7) * time.Millisecond,
+			RecommendedGOGC:   150,
+			CurrentGOGC:       100,
+			NumGC:             uint32(i * 30),
+			Timestamp:         start.Add(time.Duration(i) * 30 * time.Second),
+		}
+	}
+	return history
+}
+
+// requireDeterministicSimulation runs Simulate twice over history and
+// requires identical decision sequences, returning the decisions
+func requireDeterministicSimulation(t *testing.T, config *Config, history []Metrics) []TuningDecision {
+	t.Helper()
+
+	first, err := Simulate(config, history)
+	require.NoError(t, err)
+	second, err := Simulate(config, history)
+	require.NoError(t, err)
+
+	require.Equal(t, len(first), len(second), "decision counts differ between runs")
+	for i := range first {
+		require.Equal(t, first[i], second[i], "decision %d differs between runs", i)
+	}
+	return first
+}
+
+// TestSimulateDeterminism tests that replaying a trace gives the same
+// decisions every time under a range of configs
+func TestSimulateDeterminism(t *testing.T) {
+	history := representativeTrace()
+
+	configs := map[string]func(*Config){
+		"default": func(c *Config) {},
+		"aggressive": func(c *Config) {
+			c.TuningAggressiveness = 1.5
+			c.StabilizationWindow = time.Minute
+		},
+		"rounded and ceiling": func(c *Config) {
+			c.GOGCRounding = 25
+			c.MaxPauseTime = 14 * time.Millisecond
+		},
+		"cluster recommendation": func(c *Config) {
+			c.RecommendationWeight = 1
+			c.BoundProximityMargin = 0.2
+		},
+		"no anti-oscillation": func(c *Config) {
+			c.DisableAntiOscillation = true
+			c.FactorSmoothingAlpha = 1
+		},
+	}
+
+	total := 0
+	for name, configure := range configs {
+		t.Run(name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.RandSeed = 42
+			configure(config)
+			total += len(requireDeterministicSimulation(t, config, history))
+		})
+	}
+	assert.Greater(t, total, 0, "the trace should produce decisions")
+}
+
+// TestIsDeterministic tests which features make a tuner nondeterministic
+func TestIsDeterministic(t *testing.T) {
+	newTuner := func(configure func(*Config)) *Tuner {
+		config := DefaultConfig()
+		configure(config)
+		tuner, err := NewTuner(config)
+		require.NoError(t, err)
+		return tuner
+	}
+
+	// The default config is deterministic, time-seeded jitter, SLO mode,
+	// CPU sampling and container metrics included
+	assert.True(t, newTuner(func(c *Config) {}).IsDeterministic())
+	assert.True(t, newTuner(func(c *Config) {
+		c.SLOMode = SLOConfig{PauseTarget: 10 * time.Millisecond}
+	}).IsDeterministic())
+	tuner := newTuner(func(c *Config) {})
+	tuner.containerResources = &ContainerResources{IsContainer: true}
+	assert.True(t, tuner.IsDeterministic())
+
+	pid, err := NewPIDStrategy(1, 0, 0)
+	require.NoError(t, err)
+	assert.True(t, newTuner(func(c *Config) { c.Strategy = pid }).IsDeterministic())
+	assert.False(t, newTuner(func(c *Config) {
+		c.Strategy = TargetStrategy(&fixedStrategy{target: 150})
+	}).IsDeterministic())
+
+	tuner = newTuner(func(c *Config) {})
+	tuner.SetDecisionFilter(func(proposed TuningDecision) (TuningDecision, bool) { return proposed, true })
+	assert.False(t, tuner.IsDeterministic())
+
+	tuner = newTuner(func(c *Config) {})
+	tuner.AddMetricHook("queue_depth", func() float64 { return 1 })
+	assert.False(t, tuner.IsDeterministic())
+
+	tuner = newTuner(func(c *Config) {})
+	tuner.SetLatencyProvider(func() time.Duration { return time.Millisecond })
+	assert.False(t, tuner.IsDeterministic())
+
+	tuner = newTuner(func(c *Config) {})
+	tuner.SetRecommendationSource(func() (int, bool) { return 150, true })
+	assert.False(t, tuner.IsDeterministic())
+}
@@ -0,0 +1,117 @@
+package autotune
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// metricsObserverQueueSize is the number of samples buffered per metrics
+// observer before new samples are dropped
+const metricsObserverQueueSize = 64
+
+// metricsSubscriber delivers metrics to one observer from its own goroutine,
+// so a slow observer delays neither the tuning cycle nor other observers.
+// Samples arriving while its queue is full are dropped and counted. The
+// SetOnMetricsUpdate callback is an inline subscriber instead, called by the
+// tuning cycle without a queue.
+type metricsSubscriber struct {
+	name     string
+	observer MetricsObserver
+	inline   bool
+	queue    chan Metrics
+	once     sync.Once
+	dropped  int64
+}
+
+// newMetricsSubscriber creates a subscriber for observer, named after its
+// registration index and type, e.g. "0:*autotune.TraceRecorder"
+func newMetricsSubscriber(index int, observer MetricsObserver) *metricsSubscriber {
+	return &metricsSubscriber{
+		name:     fmt.Sprintf("%d:%T", index, observer),
+		observer: observer,
+		queue:    make(chan Metrics, metricsObserverQueueSize),
+	}
+}
+
+// dispatchMetrics queues metrics for every observer without blocking,
+// starting an observer's worker on its first sample
+func (t *Tuner) dispatchMetrics(metrics Metrics) {
+	t.mu.RLock()
+	subscribers := t.metricsSubs
+	ctx := t.ctx
+	t.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		if sub.inline {
+			t.safeCall(sub.name, func() { sub.observer.OnMetrics(metrics) })
+			continue
+		}
+		sub.once.Do(func() { go t.runSubscriber(ctx, sub) })
+
+		select {
+		case sub.queue <- metrics:
+		default:
+			atomic.AddInt64(&sub.dropped, 1)
+		}
+	}
+}
+
+// runSubscriber delivers queued metrics to an observer until ctx, the
+// tuner's context when it started, is done
+func (t *Tuner) runSubscriber(ctx context.Context, sub *metricsSubscriber) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case metrics := <-sub.queue:
+			t.safeCall("metrics_observer", func() { sub.observer.OnMetrics(metrics) })
+		}
+	}
+}
+
+// restartMetricsSubscribersLocked replaces the subscribers whose workers
+// stopped with the tuner by fresh ones, keeping their drop counts, so
+// observers keep receiving metrics after a restart. Samples still queued for
+// the stopped workers are lost. t.mu must be held for writing.
+func (t *Tuner) restartMetricsSubscribersLocked() {
+	subscribers := make([]*metricsSubscriber, len(t.metricsSubs))
+	for i, sub := range t.metricsSubs {
+		if sub.inline {
+			subscribers[i] = sub
+			continue
+		}
+		subscribers[i] = &metricsSubscriber{
+			name:     sub.name,
+			observer: sub.observer,
+			queue:    make(chan Metrics, metricsObserverQueueSize),
+			dropped:  atomic.LoadInt64(&sub.dropped),
+		}
+	}
+	t.metricsSubs = subscribers
+}
+
+// metricsObserverDroppedLocked returns the samples dropped per observer.
+// t.mu must be held.
+func (t *Tuner) metricsObserverDroppedLocked() map[string]int64 {
+	dropped := make(map[string]int64, len(t.metricsSubs))
+	for _, sub := range t.metricsSubs {
+		if !sub.inline {
+			dropped[sub.name] = atomic.LoadInt64(&sub.dropped)
+		}
+	}
+	return dropped
+}
+
+// metricsObserverCountLocked returns the number of observers added with
+// AddMetricsObserver. t.mu must be held.
+func (t *Tuner) metricsObserverCountLocked() int {
+	count := 0
+	for _, sub := range t.metricsSubs {
+		if !sub.inline {
+			count++
+		}
+	}
+	return count
+}
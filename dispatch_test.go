@@ -0,0 +1,148 @@
+package autotune
+
+import (
+	"runtime/debug"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingObserver blocks on every sample until released
+type blockingObserver struct {
+	release  chan struct{}
+	received int64
+}
+
+func (o *blockingObserver) OnMetrics(metrics Metrics) {
+	<-o.release
+	atomic.AddInt64(&o.received, 1)
+}
+
+// countingObserver counts samples
+type countingObserver struct {
+	received int64
+}
+
+func (o *countingObserver) OnMetrics(metrics Metrics) {
+	atomic.AddInt64(&o.received, 1)
+}
+
+// TestSlowMetricsObserver tests that a slow observer neither blocks the
+// tuning cycle nor other observers, and that its drops are counted
+func TestSlowMetricsObserver(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	config := DefaultConfig()
+	config.ExternalMetrics = true
+	config.Logger = silentLogger{}
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	defer tuner.cancel()
+	tuner.setGCPercent = func(value int) int { return 100 }
+
+	slow := &blockingObserver{release: make(chan struct{})}
+	fast := &countingObserver{}
+	tuner.AddMetricsObserver(slow)
+	tuner.AddMetricsObserver(fast)
+
+	const cycles = 200
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < cycles; i++ {
+			tuner.IngestMetrics(Metrics{GCPauseTime: time.Millisecond, GCFrequency: 1, MemoryPressure: 0.5})
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("tuning cycles blocked on a slow observer")
+	}
+
+	dropped := tuner.GetStats()["metrics_observer_dropped"].(map[string]int64)
+	slowName, fastName := "0:*autotune.blockingObserver", "1:*autotune.countingObserver"
+	assert.GreaterOrEqual(t, dropped[slowName], int64(cycles-metricsObserverQueueSize-1))
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt64(&fast.received)+dropped[fastName] == cycles
+	}, 5*time.Second, 10*time.Millisecond)
+
+	close(slow.release)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt64(&slow.received)+dropped[slowName] == cycles
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// TestObserverCallbacksCoexist tests that the SetOnMetricsUpdate and
+// SetOnTuningDecision callbacks, the alert manager and the observability
+// server all receive samples and decisions without replacing each other
+func TestObserverCallbacksCoexist(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	config := DefaultConfig()
+	config.ExternalMetrics = true
+	config.Logger = silentLogger{}
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	defer tuner.cancel()
+	tuner.setGCPercent = func(value int) int { return 100 }
+	// Only the decisions applied below count
+	tuner.SetDecisionFilter(func(decision TuningDecision) (TuningDecision, bool) { return decision, false })
+
+	var replaced, callback int64
+	tuner.SetOnMetricsUpdate(func(Metrics) { atomic.AddInt64(&replaced, 1) })
+	alertManager := NewAlertManager(tuner)
+	var alerts []Alert
+	alertManager.AddObserver(&mockAlertObserver{alerts: &alerts})
+	obsConfig := DefaultObservabilityConfig()
+	obsConfig.HTTPPort = 0
+	obs := NewObservabilityServer(obsConfig, tuner)
+	require.NoError(t, obs.Start())
+	defer obs.Stop()
+	counting := &countingObserver{}
+	tuner.AddMetricsObserver(MetricsObserverFunc(counting.OnMetrics))
+	tuner.SetOnMetricsUpdate(func(Metrics) { atomic.AddInt64(&callback, 1) })
+
+	var decisions, observed int
+	tuner.AddDecisionObserver(DecisionObserverFunc(func(TuningDecision) { observed++ }))
+	tuner.SetOnTuningDecision(func(TuningDecision) { decisions++ })
+
+	require.NoError(t, tuner.IngestMetrics(Metrics{GCPauseTime: 150 * time.Millisecond, GCFrequency: 1, MemoryPressure: 0.5}))
+
+	// The callback runs inline, replacing the first one
+	assert.Equal(t, int64(1), atomic.LoadInt64(&callback))
+	assert.Zero(t, atomic.LoadInt64(&replaced))
+	assert.Eventually(t, func() bool {
+		obs.mu.RLock()
+		recorded := len(obs.metricsHistory)
+		obs.mu.RUnlock()
+		return recorded == 1 && atomic.LoadInt64(&counting.received) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		alertManager.mu.RLock()
+		defer alertManager.mu.RUnlock()
+		return len(alertManager.active) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NotContains(t, tuner.GetStats()["metrics_observer_dropped"], "on_metrics_update")
+
+	// Decisions reach both the callback and the observer
+	tuner.applyTuningDecision(TuningDecision{OldGOGC: 100, NewGOGC: 150})
+	assert.Equal(t, 1, decisions)
+	assert.Equal(t, 1, observed)
+
+	// Removing the callbacks keeps the observers
+	tuner.SetOnMetricsUpdate(nil)
+	tuner.SetOnTuningDecision(nil)
+	require.NoError(t, tuner.IngestMetrics(Metrics{GCPauseTime: time.Millisecond, GCFrequency: 1, MemoryPressure: 0.5}))
+	tuner.applyTuningDecision(TuningDecision{OldGOGC: 150, NewGOGC: 200})
+	assert.Equal(t, int64(1), atomic.LoadInt64(&callback))
+	assert.Equal(t, 1, decisions)
+	assert.Equal(t, 2, observed)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt64(&counting.received) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
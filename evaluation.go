@@ -0,0 +1,136 @@
+package autotune
+
+import "math"
+
+// revertEvaluationCycles is how many cycles after a decision its effect is
+// evaluated for when reverts are enabled; otherwise the next cycle decides
+const revertEvaluationCycles = 2
+
+// decisionEvaluation tracks an applied decision whose effect on the metrics
+// is pending: whether it improved the metric it targeted, and with
+// Config.RevertThreshold whether it regressed that metric enough to revert
+type decisionEvaluation struct {
+	decision    TuningDecision
+	generation  uint64  // GOGC generation when it was applied
+	revertable  bool    // whether a regression reverts the decision
+	before      float64 // targeted metric at decision time
+	after       float64 // sum of the targeted metric over evaluated cycles
+	cycles      int     // cycles evaluated so far
+	improvement float64 // sum of the targeted metric's relative improvement
+}
+
+// evaluationCycles returns how many cycles a decision is evaluated for
+func (t *Tuner) evaluationCycles() int {
+	if t.config.RevertThreshold > 0 {
+		return revertEvaluationCycles
+	}
+	return 1
+}
+
+// targetMetric returns the value, lower being better, and name of the
+// metric a category of decision targets: memory pressure for memory
+// decisions, GC frequency for frequency decisions, and otherwise the
+// distance of the GC pause, as selected by PauseTarget, from TargetLatency
+func (t *Tuner) targetMetric(category DecisionCategory, metrics Metrics) (float64, string) {
+	switch category {
+	case CategoryMemory:
+		return metrics.MemoryPressure, "memory pressure"
+	case CategoryFrequency:
+		return metrics.GCFrequency, "GC frequency"
+	default:
+		return math.Abs(float64(t.tuningPause(metrics)) - float64(t.config.TargetLatency)), "pause"
+	}
+}
+
+// targetImprovement returns the relative improvement, negative when worse,
+// of the metric a decision targeted, see targetMetric. It is zero when the
+// metric was already at its ideal value.
+func (t *Tuner) targetImprovement(decision TuningDecision, after Metrics) float64 {
+	from, _ := t.targetMetric(decision.Category, *decision.Metrics)
+	to, _ := t.targetMetric(decision.Category, after)
+
+	if from <= 0 {
+		return 0
+	}
+	return (from - to) / from
+}
+
+// startEvaluationLocked begins evaluating an applied decision, finishing the
+// evaluation of the previous one if it saw at least one cycle. t.mu must be
+// held for writing.
+func (t *Tuner) startEvaluationLocked(decision TuningDecision) {
+	// Memory limit changes leave the decision under evaluation in effect
+	if decision.memoryLimitChange() {
+		return
+	}
+
+	if previous := t.evaluation; previous != nil && previous.cycles > 0 {
+		t.finishEvaluationLocked(previous)
+	}
+	t.evaluation = nil
+
+	// Turning GC off or on trades memory for CPU by design, it isn't judged
+	if decision.Metrics == nil || decision.Category == CategoryRevert || decision.gcToggle() {
+		return
+	}
+	before, _ := t.targetMetric(decision.Category, *decision.Metrics)
+	t.evaluation = &decisionEvaluation{
+		decision:   decision,
+		generation: t.gogcGeneration,
+		// Pause ceiling emergencies aren't second-guessed
+		revertable: decision.Priority != PriorityHigh,
+		before:     before,
+	}
+}
+
+// finishEvaluationLocked records the outcome of an evaluated decision: it is
+// successful if the targeted metric improved on average over the evaluated
+// cycles, and the improvement is folded into avgImprovement. t.mu must be
+// held for writing.
+func (t *Tuner) finishEvaluationLocked(eval *decisionEvaluation) {
+	improvement := eval.improvement / float64(eval.cycles)
+	t.evaluatedDecisions++
+	t.avgImprovement += (improvement - t.avgImprovement) / float64(t.evaluatedDecisions)
+	if improvement > 0 {
+		t.successfulTunes++
+	}
+}
+
+// evaluateLastDecision compares a cycle's metrics with those at the time of
+// the decision under evaluation. Once it has been evaluated for
+// evaluationCycles cycles, it returns a decision reverting it if the mean of
+// its targeted metric over those cycles regressed by more than
+// Config.RevertThreshold from its value at decision time, and otherwise
+// records its outcome. Evaluation is abandoned when GOGC is changed by
+// anything else.
+func (t *Tuner) evaluateLastDecision(metrics Metrics) *TuningDecision {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	eval := t.evaluation
+	if eval == nil {
+		return nil
+	}
+	if eval.generation != t.gogcGeneration {
+		t.evaluation = nil
+		return nil
+	}
+
+	eval.cycles++
+	value, metric := t.targetMetric(eval.decision.Category, metrics)
+	eval.after += value
+	eval.improvement += t.targetImprovement(eval.decision, metrics)
+	if eval.cycles < t.evaluationCycles() {
+		return nil
+	}
+	t.evaluation = nil
+
+	if eval.revertable && t.config.RevertThreshold > 0 && eval.before > 0 {
+		regression := (eval.after/float64(eval.cycles) - eval.before) / eval.before
+		if regression > t.config.RevertThreshold {
+			return t.revertDecisionLocked(eval.decision, regression, metric, metrics)
+		}
+	}
+	t.finishEvaluationLocked(eval)
+	return nil
+}
@@ -0,0 +1,83 @@
+package autotune
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTargetImprovement tests the improvement of the metric each category of
+// decision targets
+func TestTargetImprovement(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig()) // 10ms target latency
+	require.NoError(t, err)
+
+	before := &Metrics{GCPauseTime: 30 * time.Millisecond, GCFrequency: 4, MemoryPressure: 0.8}
+	after := Metrics{GCPauseTime: 15 * time.Millisecond, GCFrequency: 3, MemoryPressure: 0.6}
+
+	// Pause distance from the target went from 20ms to 5ms
+	assert.InDelta(t, 0.75, tuner.targetImprovement(TuningDecision{Category: CategoryLatency, Metrics: before}, after), 1e-9)
+	assert.InDelta(t, 0.75, tuner.targetImprovement(TuningDecision{Category: CategoryMixed, Metrics: before}, after), 1e-9)
+	assert.InDelta(t, 0.25, tuner.targetImprovement(TuningDecision{Category: CategoryMemory, Metrics: before}, after), 1e-9)
+	assert.InDelta(t, 0.25, tuner.targetImprovement(TuningDecision{Category: CategoryFrequency, Metrics: before}, after), 1e-9)
+
+	// Overshooting the target counts as distance too
+	after.GCPauseTime = 2 * time.Millisecond
+	assert.InDelta(t, 0.6, tuner.targetImprovement(TuningDecision{Category: CategoryLatency, Metrics: before}, after), 1e-9)
+
+	// Worse is negative, and a metric already at its ideal value is zero
+	after.GCPauseTime = 40 * time.Millisecond
+	assert.InDelta(t, -0.5, tuner.targetImprovement(TuningDecision{Category: CategoryLatency, Metrics: before}, after), 1e-9)
+	assert.Equal(t, 0.0, tuner.targetImprovement(TuningDecision{Category: CategoryMemory, Metrics: &Metrics{}}, after))
+}
+
+// TestDecisionSuccess tests that decisions are marked successful once the
+// next cycle confirms their targeted metric improved
+func TestDecisionSuccess(t *testing.T) {
+	// Without reverts the next cycle decides
+	tuner, clock, _ := newRevertTuner(t, 0)
+	apply := func(pause time.Duration) {
+		tuner.processDecision(TuningDecision{
+			OldGOGC:   100,
+			NewGOGC:   150,
+			Category:  CategoryLatency,
+			Timestamp: *clock,
+			Metrics:   &Metrics{GCPauseTime: pause, MemoryPressure: 0.5, CurrentGOGC: 100},
+		})
+	}
+	ingest := func(pause time.Duration) {
+		*clock = clock.Add(30 * time.Second)
+		require.NoError(t, tuner.IngestMetrics(Metrics{GCPauseTime: pause, GCFrequency: 1, MemoryPressure: 0.5}))
+	}
+	stats := func() (int64, int64, float64) {
+		s := tuner.GetStats()
+		return s["evaluated_tunes"].(int64), s["successful_tunes"].(int64), s["avg_improvement"].(float64)
+	}
+
+	apply(30 * time.Millisecond)
+	evaluated, successful, _ := stats()
+	assert.Equal(t, int64(0), evaluated, "pending until the next cycle")
+	assert.Equal(t, int64(0), successful)
+
+	ingest(20 * time.Millisecond) // distance 20ms -> 10ms
+	evaluated, successful, avg := stats()
+	assert.Equal(t, int64(1), evaluated)
+	assert.Equal(t, int64(1), successful)
+	assert.InDelta(t, 0.5, avg, 1e-9)
+
+	apply(20 * time.Millisecond)
+	ingest(25 * time.Millisecond) // distance 10ms -> 15ms
+	evaluated, successful, avg = stats()
+	assert.Equal(t, int64(2), evaluated)
+	assert.Equal(t, int64(1), successful)
+	assert.InDelta(t, 0.0, avg, 1e-9, "mean of +50% and -50%")
+
+	// A manual GOGC change abandons the pending evaluation
+	apply(30 * time.Millisecond)
+	require.NoError(t, tuner.SetGOGC(200))
+	ingest(10 * time.Millisecond)
+	evaluated, _, _ = stats()
+	assert.Equal(t, int64(2), evaluated)
+}
@@ -248,8 +248,8 @@ func printFinalStatistics(tuner *autotune.Tuner) {
 	log.Printf("  Total Decisions Made: %d", stats["total_decisions"])
 	log.Printf("  Successful Tunes: %d", stats["successful_tunes"])
 	log.Printf("  Reverted Tunes: %d", stats["reverted_tunes"])
-	if totalDecisions := stats["total_decisions"].(int64); totalDecisions > 0 {
-		successRate := float64(stats["successful_tunes"].(int64)) / float64(totalDecisions) * 100
+	if evaluated := stats["evaluated_tunes"].(int64); evaluated > 0 {
+		successRate := float64(stats["successful_tunes"].(int64)) / float64(evaluated) * 100
 		log.Printf("  Success Rate: %.1f%%", successRate)
 	}
 	log.Printf("  Final GOGC Value: %d", stats["current_gogc"])
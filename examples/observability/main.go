@@ -312,12 +312,12 @@ func printFinalObservabilityReport(tuner *autotune.Tuner, obsServer *autotune.Ob
 // Helper functions
 
 func calculateSuccessRate(stats map[string]interface{}) float64 {
-	total := stats["total_decisions"].(int64)
-	if total == 0 {
+	evaluated := stats["evaluated_tunes"].(int64)
+	if evaluated == 0 {
 		return 0
 	}
 	successful := stats["successful_tunes"].(int64)
-	return float64(successful) / float64(total) * 100
+	return float64(successful) / float64(evaluated) * 100
 }
 
 func formatBytes(bytes uint64) string {
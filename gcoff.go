@@ -0,0 +1,74 @@
+package autotune
+
+import "fmt"
+
+const (
+	// gcOff is the GOGC value that turns the garbage collector off
+	gcOff = -1
+	// gcOffResumePressure is the memory pressure, relative to the
+	// MemoryLimitPercent threshold, below which GC is turned back off after
+	// pressure turned it on, so it doesn't flip on every cycle near the
+	// threshold
+	gcOffResumePressure = 0.8
+)
+
+// gcOffDecision returns the decision turning GC off or back on for
+// Config.DisableGC, if one is due, and whether GC stays off this cycle, in
+// which case GOGC isn't tuned. GC is off while the tuner holds a soft
+// memory limit and memory pressure is below the threshold.
+func (t *Tuner) gcOffDecision(metrics Metrics) (decision *TuningDecision, off bool) {
+	if !t.config.DisableGC {
+		return nil, false
+	}
+
+	t.mu.RLock()
+	limit := t.memLimit
+	t.mu.RUnlock()
+
+	current := metrics.CurrentGOGC
+	switch {
+	case current == gcOff && (limit == 0 || metrics.MemoryPressure >= 1):
+		return t.gcToggleDecision(metrics, t.config.MaxGOGC,
+			fmt.Sprintf("Turning GC back on at GOGC %d for memory pressure %.1f%%",
+				t.config.MaxGOGC, metrics.MemoryPressure*100)), false
+	case current == gcOff:
+		return nil, true
+	case limit > 0 && metrics.MemoryPressure < gcOffResumePressure:
+		return t.gcToggleDecision(metrics, gcOff,
+			fmt.Sprintf("Turning GC off (GOGC %d -> off), leaving collection to GOMEMLIMIT %.0fMiB",
+				current, float64(limit)/(1<<20))), true
+	}
+	return nil, false
+}
+
+// gcToggleDecision returns a decision setting GOGC to gogc, turning GC off
+// or back on
+func (t *Tuner) gcToggleDecision(metrics Metrics, gogc int, reason string) *TuningDecision {
+	return &TuningDecision{
+		OldGOGC:     metrics.CurrentGOGC,
+		NewGOGC:     gogc,
+		DesiredGOGC: gogc,
+		Category:    CategoryMemory,
+		Reason:      reason,
+		Confidence:  1,
+		Timestamp:   t.now(),
+		Metrics:     &metrics,
+	}
+}
+
+// restoreGCLocked turns GC back on at MaxGOGC if the tuner turned it off,
+// before the soft memory limit bounding the heap is restored. t.mu must be
+// held for writing.
+func (t *Tuner) restoreGCLocked() {
+	if !t.config.DisableGC || t.lastGOGC != gcOff {
+		return
+	}
+	t.setGCPercent(t.config.MaxGOGC)
+	t.lastGOGC = t.config.MaxGOGC
+	t.config.Logger.Info("Turned GC back on at GOGC %d", t.config.MaxGOGC)
+}
+
+// gcToggle reports whether a decision turns GC off or back on
+func (d TuningDecision) gcToggle() bool {
+	return d.NewGOGC == gcOff || d.OldGOGC == gcOff
+}
@@ -0,0 +1,90 @@
+package autotune
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDisableGC tests that GC is turned off once the soft memory limit is
+// set, back on under memory pressure, and on again when the tuner stops
+func TestDisableGC(t *testing.T) {
+	config := BatchConfig()
+	config.ExternalMetrics = true
+	config.Logger = &mockLogger{}
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	gogc := 100
+	tuner.setGCPercent = func(value int) int {
+		old := gogc
+		gogc = value
+		return old
+	}
+	limit := int64(math.MaxInt64)
+	tuner.setMemoryLimit = func(value int64) int64 {
+		old := limit
+		limit = value
+		return old
+	}
+	require.NoError(t, tuner.Start())
+
+	const mib = 1 << 20
+	ingest := func(pressure float64) {
+		require.NoError(t, tuner.IngestMetrics(Metrics{
+			GCPauseTime:       time.Millisecond,
+			GCFrequency:       1,
+			LiveHeap:          200 * mib,
+			MemoryPressure:    pressure,
+			MemoryPressureRaw: pressure * config.MemoryLimitPercent,
+			ContainerMemLimit: 1024 * mib,
+		}))
+	}
+
+	// GC stays on until the soft memory limit bounds the heap
+	ingest(0.3)
+	assert.Equal(t, 100, gogc)
+	assert.Equal(t, ofGiB(0.9), limit)
+
+	ingest(0.3)
+	assert.Equal(t, gcOff, gogc)
+
+	// While off, GOGC isn't tuned
+	ingest(0.5)
+	assert.Equal(t, gcOff, gogc)
+
+	// Pressure at the threshold turns it back on at the ceiling, and it
+	// stays on until pressure has clearly dropped
+	ingest(1.0)
+	assert.Equal(t, config.MaxGOGC, gogc)
+	ingest(0.9)
+	assert.NotEqual(t, gcOff, gogc)
+	ingest(0.5)
+	assert.Equal(t, gcOff, gogc)
+
+	decisions := tuner.Decisions()
+	require.NotEmpty(t, decisions)
+	assert.Contains(t, decisions[len(decisions)-1].Reason, "Turning GC off")
+
+	// Stop turns GC back on before restoring the memory limit
+	require.NoError(t, tuner.Stop())
+	assert.Equal(t, config.MaxGOGC, gogc)
+	assert.Equal(t, int64(math.MaxInt64), limit)
+}
+
+// TestDisableGCValidation tests that GC can only be turned off with the
+// soft memory limit bounding the heap
+func TestDisableGCValidation(t *testing.T) {
+	config := DefaultConfig()
+	config.DisableGC = true
+	assert.Error(t, validateConfig(config))
+
+	config.EnableMemoryLimit = true
+	assert.NoError(t, validateConfig(config))
+
+	config.GOGCBand = [2]int{100, 200}
+	assert.Error(t, validateConfig(config))
+}
@@ -0,0 +1,93 @@
+package autotune
+
+import (
+	"strconv"
+	"strings"
+)
+
+// GCDebugSettings holds the GC-relevant GODEBUG settings of the process.
+// Non-default values change how the collector behaves, and some of them make
+// parts of the tuning algorithm meaningless.
+type GCDebugSettings struct {
+	// GCStopTheWorld is gcstoptheworld: 1 disables concurrent GC and 2 also
+	// disables concurrent sweeping, so every collection is a full pause
+	GCStopTheWorld int `json:"gcstoptheworld"`
+	// GCShrinkStackOff is gcshrinkstackoff=1, which stops stack shrinking
+	GCShrinkStackOff bool `json:"gcshrinkstackoff"`
+	// GCCheckmark is gccheckmark=1, a debugging mode that re-verifies marking
+	// and makes every cycle much slower
+	GCCheckmark bool `json:"gccheckmark"`
+	// GCTrace is the gctrace level
+	GCTrace int `json:"gctrace"`
+	// GCPacerTrace is gcpacertrace=1
+	GCPacerTrace bool `json:"gcpacertrace"`
+	// MadvFree is madvdontneed=0, which releases freed memory with MADV_FREE
+	// so RSS only drops when the kernel needs the pages
+	MadvFree bool `json:"madvfree"`
+	// Raw holds the GC-relevant settings exactly as they appeared
+	Raw map[string]string `json:"raw,omitempty"`
+}
+
+// gcDebugKeys are the GODEBUG keys that affect GC behavior
+var gcDebugKeys = map[string]bool{
+	"gcstoptheworld":   true,
+	"gcshrinkstackoff": true,
+	"gccheckmark":      true,
+	"gctrace":          true,
+	"gcpacertrace":     true,
+	"madvdontneed":     true,
+}
+
+// ParseGCDebug extracts the GC-relevant settings from a GODEBUG value such
+// as "gctrace=1,gcstoptheworld=1". Unknown keys and malformed entries are
+// ignored.
+func ParseGCDebug(godebug string) GCDebugSettings {
+	settings := GCDebugSettings{}
+
+	for _, entry := range strings.Split(godebug, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || !gcDebugKeys[key] {
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+
+		if settings.Raw == nil {
+			settings.Raw = make(map[string]string)
+		}
+		settings.Raw[key] = value
+
+		// Later entries override earlier ones, matching the runtime
+		switch key {
+		case "gcstoptheworld":
+			settings.GCStopTheWorld = n
+		case "gcshrinkstackoff":
+			settings.GCShrinkStackOff = n != 0
+		case "gccheckmark":
+			settings.GCCheckmark = n != 0
+		case "gctrace":
+			settings.GCTrace = n
+		case "gcpacertrace":
+			settings.GCPacerTrace = n != 0
+		case "madvdontneed":
+			settings.MadvFree = n == 0
+		}
+	}
+
+	return settings
+}
+
+// ConcurrentGCDisabled reports whether every GC runs stop-the-world, in which
+// case pause times reflect heap size rather than anything GOGC can fix
+func (s GCDebugSettings) ConcurrentGCDisabled() bool {
+	return s.GCStopTheWorld > 0
+}
+
+// PauseTuningMeaningful reports whether GC pause times are a usable tuning
+// signal under these settings
+func (s GCDebugSettings) PauseTuningMeaningful() bool {
+	return !s.ConcurrentGCDisabled() && !s.GCCheckmark
+}
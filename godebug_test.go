@@ -0,0 +1,69 @@
+package autotune
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseGCDebug tests parsing of GC-relevant GODEBUG settings
+func TestParseGCDebug(t *testing.T) {
+	settings := ParseGCDebug("")
+	assert.Nil(t, settings.Raw)
+	assert.False(t, settings.ConcurrentGCDisabled())
+	assert.True(t, settings.PauseTuningMeaningful())
+
+	settings = ParseGCDebug("http2debug=1, gctrace=1,gcstoptheworld=2,gcpacertrace=1")
+	assert.Equal(t, 1, settings.GCTrace)
+	assert.Equal(t, 2, settings.GCStopTheWorld)
+	assert.True(t, settings.GCPacerTrace)
+	assert.True(t, settings.ConcurrentGCDisabled())
+	assert.False(t, settings.PauseTuningMeaningful())
+	assert.Equal(t, map[string]string{
+		"gctrace":        "1",
+		"gcstoptheworld": "2",
+		"gcpacertrace":   "1",
+	}, settings.Raw)
+
+	// Later entries win, malformed entries are ignored
+	settings = ParseGCDebug("gccheckmark=1,gcstoptheworld=1,gcstoptheworld=0,gcshrinkstackoff,gctrace=x")
+	assert.Equal(t, 0, settings.GCStopTheWorld)
+	assert.True(t, settings.GCCheckmark)
+	assert.False(t, settings.GCShrinkStackOff)
+	assert.Equal(t, 0, settings.GCTrace)
+	assert.False(t, settings.PauseTuningMeaningful())
+}
+
+// TestStopTheWorldDisablesPauseTuning tests that pause time is ignored without concurrent GC
+func TestStopTheWorldDisablesPauseTuning(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	metrics := Metrics{
+		GCPauseTime:    50 * time.Millisecond, // 5x target
+		GCFrequency:    1.0,
+		MemoryPressure: 0.5,
+		CurrentGOGC:    100,
+	}
+
+	withPauseTuning := tuner.calculateTargetGOGC(metrics)
+
+	tuner.gcDebug = ParseGCDebug("gcstoptheworld=1")
+	withoutPauseTuning := tuner.calculateTargetGOGC(metrics)
+
+	assert.Less(t, withoutPauseTuning, withPauseTuning)
+
+	// The detected settings are exposed in /config
+	obs := NewObservabilityServer(DefaultObservabilityConfig(), tuner)
+	w := httptest.NewRecorder()
+	obs.handleConfig(w, httptest.NewRequest("GET", "/config", nil))
+
+	var config map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &config))
+	require.Contains(t, config, "godebug")
+	assert.Equal(t, float64(1), config["godebug"].(map[string]interface{})["gcstoptheworld"])
+}
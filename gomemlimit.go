@@ -0,0 +1,111 @@
+package autotune
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// defaultMemoryLimitTarget is the fraction of the container memory limit
+	// GOMEMLIMIT is set to when Config.MemoryLimitTarget is zero
+	defaultMemoryLimitTarget = 0.9
+	// memoryLimitHeadroom is the minimum headroom above the live heap the
+	// soft limit leaves, so the GC doesn't run back to back near the limit
+	memoryLimitHeadroom = 0.1
+	// memoryLimitCeiling caps the soft limit as a fraction of the container
+	// limit, leaving room for memory the Go runtime doesn't manage
+	memoryLimitCeiling = 0.95
+	// memoryLimitResizeThreshold is the relative change below which the soft
+	// limit is left alone
+	memoryLimitResizeThreshold = 0.05
+)
+
+// memoryLimitTarget returns the soft memory limit for a sample:
+// MemoryLimitTarget of the container limit, raised to leave
+// memoryLimitHeadroom above the live heap when memory pressure is high, up
+// to memoryLimitCeiling of the container limit. It returns zero without a
+// container limit.
+func (t *Tuner) memoryLimitTarget(metrics Metrics) int64 {
+	if metrics.ContainerMemLimit == 0 {
+		return 0
+	}
+
+	fraction := t.config.MemoryLimitTarget
+	if fraction == 0 {
+		fraction = defaultMemoryLimitTarget
+	}
+	limit := float64(metrics.ContainerMemLimit) * fraction
+
+	if floor := float64(metrics.LiveHeap) * (1 + memoryLimitHeadroom); limit < floor {
+		limit = math.Min(floor, float64(metrics.ContainerMemLimit)*memoryLimitCeiling)
+	}
+	return int64(limit)
+}
+
+// adjustMemoryLimit sets GOMEMLIMIT for the current metrics when
+// Config.EnableMemoryLimit is set, recording changes as decisions
+func (t *Tuner) adjustMemoryLimit(metrics Metrics) {
+	if !t.config.EnableMemoryLimit {
+		return
+	}
+
+	target := t.memoryLimitTarget(metrics)
+	if target == 0 {
+		return
+	}
+
+	t.mu.RLock()
+	current := t.memLimit
+	t.mu.RUnlock()
+	if current > 0 && absDiffRatio(uint64(target), uint64(current)) < memoryLimitResizeThreshold {
+		return
+	}
+
+	reason := fmt.Sprintf("Setting GOMEMLIMIT to %.0fMiB (%.0f%% of the container limit)",
+		float64(target)/(1<<20), float64(target)/float64(metrics.ContainerMemLimit)*100)
+	if current > 0 {
+		reason = fmt.Sprintf("Adjusting GOMEMLIMIT %.0fMiB -> %.0fMiB for memory pressure %.1f%%",
+			float64(current)/(1<<20), float64(target)/(1<<20), metrics.MemoryPressureRaw*100)
+	}
+
+	t.processDecision(TuningDecision{
+		OldGOGC:     metrics.CurrentGOGC,
+		NewGOGC:     metrics.CurrentGOGC,
+		DesiredGOGC: metrics.CurrentGOGC,
+		OldMemLimit: current,
+		NewMemLimit: target,
+		Category:    CategoryMemory,
+		Reason:      reason,
+		Confidence:  1,
+		Timestamp:   t.now(),
+		Metrics:     &metrics,
+	})
+}
+
+// applyMemoryLimitLocked applies the soft memory limit of a decision,
+// remembering the limit in effect before the tuner first set one. t.mu must
+// be held for writing.
+func (t *Tuner) applyMemoryLimitLocked(limit int64) {
+	previous := t.setMemoryLimit(limit)
+	if t.memLimit == 0 {
+		t.originalMemLimit = previous
+	}
+	t.memLimit = limit
+}
+
+// restoreMemoryLimitLocked restores the soft memory limit in effect before
+// the tuner set one. t.mu must be held for writing.
+func (t *Tuner) restoreMemoryLimitLocked() {
+	if t.memLimit == 0 {
+		return
+	}
+	t.setMemoryLimit(t.originalMemLimit)
+	t.config.Logger.Info("Restored GOMEMLIMIT to %d bytes", t.originalMemLimit)
+	t.memLimit = 0
+}
+
+// memoryLimitChange reports whether a decision only changes the soft memory
+// limit, leaving GOGC alone
+func (d TuningDecision) memoryLimitChange() bool {
+	return d.NewMemLimit != 0 && d.NewGOGC == d.OldGOGC
+}
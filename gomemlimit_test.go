@@ -0,0 +1,135 @@
+package autotune
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ofGiB returns the given fraction of 1GiB in bytes
+func ofGiB(fraction float64) int64 {
+	return int64(float64(1<<30) * fraction)
+}
+
+// TestMemoryLimitTarget tests the soft memory limit chosen for a sample
+func TestMemoryLimitTarget(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableMemoryLimit = true
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	const mib = 1 << 20
+	metrics := Metrics{ContainerMemLimit: 1024 * mib, LiveHeap: 200 * mib}
+	assert.Equal(t, ofGiB(0.9), tuner.memoryLimitTarget(metrics))
+
+	// A live heap near the limit raises it for headroom, up to the ceiling
+	metrics.LiveHeap = 850 * mib
+	assert.Equal(t, int64(935*mib), tuner.memoryLimitTarget(metrics))
+	metrics.LiveHeap = 900 * mib
+	assert.Equal(t, ofGiB(0.95), tuner.memoryLimitTarget(metrics))
+
+	config.MemoryLimitTarget = 0.7
+	metrics.LiveHeap = 200 * mib
+	assert.Equal(t, ofGiB(0.7), tuner.memoryLimitTarget(metrics))
+
+	assert.Equal(t, int64(0), tuner.memoryLimitTarget(Metrics{LiveHeap: 200 * mib}), "no container limit")
+}
+
+// TestMemoryLimitTuning tests that GOMEMLIMIT changes are applied, recorded
+// as decisions and restored on Stop
+func TestMemoryLimitTuning(t *testing.T) {
+	config := DefaultConfig()
+	config.ExternalMetrics = true
+	config.EnableMemoryLimit = true
+	config.Logger = &mockLogger{}
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	var gogcCalls int
+	tuner.setGCPercent = func(value int) int {
+		gogcCalls++
+		return 100
+	}
+	var limits []int64
+	tuner.setMemoryLimit = func(limit int64) int64 {
+		previous := int64(math.MaxInt64)
+		if len(limits) > 0 {
+			previous = limits[len(limits)-1]
+		}
+		limits = append(limits, limit)
+		return previous
+	}
+	require.NoError(t, tuner.Start())
+
+	const mib = 1 << 20
+	ingest := func(live uint64) {
+		require.NoError(t, tuner.IngestMetrics(Metrics{
+			GCPauseTime:       time.Millisecond,
+			GCFrequency:       1,
+			LiveHeap:          live,
+			MemoryPressure:    float64(live) / (0.8 * 1024 * mib),
+			MemoryPressureRaw: float64(live) / (1024 * mib),
+			ContainerMemLimit: 1024 * mib,
+			CurrentGOGC:       100,
+		}))
+	}
+
+	ingest(200 * mib)
+	require.Equal(t, []int64{ofGiB(0.9)}, limits)
+	decisions := tuner.Decisions()
+	require.Len(t, decisions, 1)
+	assert.Equal(t, int64(0), decisions[0].OldMemLimit)
+	assert.Equal(t, ofGiB(0.9), decisions[0].NewMemLimit)
+	assert.Equal(t, 100, decisions[0].NewGOGC)
+	assert.Equal(t, CategoryMemory, decisions[0].Category)
+	assert.Equal(t, 0, gogcCalls, "limit changes leave GOGC alone")
+
+	// Small changes are ignored
+	ingest(210 * mib)
+	assert.Len(t, limits, 1)
+
+	ingest(900 * mib)
+	require.Len(t, limits, 2)
+	decisions = tuner.Decisions()
+	last := decisions[len(decisions)-1]
+	assert.Equal(t, ofGiB(0.9), last.OldMemLimit)
+	assert.Equal(t, ofGiB(0.95), last.NewMemLimit)
+	assert.Equal(t, ofGiB(0.95), tuner.GetStats()["memory_limit_bytes"])
+
+	require.NoError(t, tuner.Stop())
+	assert.Equal(t, int64(math.MaxInt64), limits[len(limits)-1], "original limit restored")
+	assert.Equal(t, int64(0), tuner.GetStats()["memory_limit_bytes"])
+}
+
+// TestMemoryLimitKeepsGOGC tests that memory pressure doesn't lower GOGC
+// while the soft memory limit is the backstop
+func TestMemoryLimitKeepsGOGC(t *testing.T) {
+	metrics := Metrics{
+		GCPauseTime:       10 * time.Millisecond,
+		GCFrequency:       1,
+		MemoryPressure:    0.95,
+		ContainerMemLimit: 1 << 30,
+		CurrentGOGC:       200,
+	}
+	target := func(enable bool) int {
+		config := DefaultConfig()
+		config.EnableMemoryLimit = enable
+		tuner, err := NewTuner(config)
+		require.NoError(t, err)
+		return tuner.calculateTargetGOGC(metrics)
+	}
+
+	assert.Less(t, target(false), 200)
+	assert.Equal(t, 200, target(true))
+
+	config := DefaultConfig()
+	config.EnableMemoryLimit = true
+	config.EnableBallast = true
+	assert.Error(t, validateConfig(config))
+	config.EnableBallast = false
+	config.MemoryLimitTarget = 0.3
+	assert.Error(t, validateConfig(config))
+}
@@ -0,0 +1,175 @@
+package autotune
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// graphiteTimeout bounds connecting to and writing to the Graphite server
+const graphiteTimeout = 5 * time.Second
+
+// validateGraphitePrefix checks that a prefix can start a Graphite path
+func validateGraphitePrefix(prefix string) error {
+	if strings.ContainsAny(prefix, " \t\r\n") {
+		return fmt.Errorf("graphite prefix %q must not contain whitespace", prefix)
+	}
+	return nil
+}
+
+// ExportToGraphite exports current metrics in the Graphite plaintext
+// protocol, one "<prefix>.<name> <value> <timestamp>" line per metric, where
+// the timestamp is the sample's Unix time in seconds. An empty prefix is
+// omitted, and custom metrics are exported as <prefix>.custom.<name>.
+func (me *MetricsExporter) ExportToGraphite(prefix string) ([]byte, error) {
+	if err := validateGraphitePrefix(prefix); err != nil {
+		return nil, err
+	}
+	prefix = strings.TrimSuffix(prefix, ".")
+
+	metrics := me.tuner.GetMetrics()
+	timestamp := metrics.Timestamp.Unix()
+
+	var buf bytes.Buffer
+	for _, metric := range pushMetrics(metrics, me.tuner.GetStats()) {
+		name := metric.name
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		fmt.Fprintf(&buf, "%s %s %d\n", name, metric.value, timestamp)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// GraphiteExporter periodically pushes metrics to a Graphite server over TCP
+// in the plaintext protocol. A lost connection is re-established on the
+// next push, with failed pushes retried with backoff.
+type GraphiteExporter struct {
+	exporter *MetricsExporter
+	addr     string
+	prefix   string
+	interval time.Duration
+	logger   Logger
+	queue    *pushQueue
+
+	// dial connects to the server, replaceable in tests
+	dial func(network, addr string, timeout time.Duration) (net.Conn, error)
+
+	connMu sync.Mutex
+	conn   net.Conn
+
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+	stopped sync.Once
+}
+
+// NewGraphiteExporter creates an exporter pushing the tuner's metrics to the
+// Graphite server at addr (host:port) every interval, under prefix
+func NewGraphiteExporter(tuner *Tuner, addr, prefix string, interval time.Duration) (*GraphiteExporter, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("graphite address is required")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("graphite push interval must be positive, got %v", interval)
+	}
+	if err := validateGraphitePrefix(prefix); err != nil {
+		return nil, err
+	}
+
+	ge := &GraphiteExporter{
+		exporter: NewMetricsExporter(tuner),
+		addr:     addr,
+		prefix:   prefix,
+		interval: interval,
+		logger:   tuner.config.Logger,
+		dial:     net.DialTimeout,
+		done:     make(chan struct{}),
+	}
+	ge.queue = newPushQueue("Graphite", 10, 3, ge.push, ge.logger, tuner.newRand())
+	return ge, nil
+}
+
+// Start begins pushing metrics every interval; calling it more than once is
+// a no-op
+func (ge *GraphiteExporter) Start() error {
+	ge.once.Do(func() {
+		ge.queue.start()
+		ge.wg.Add(1)
+		go ge.run()
+	})
+	return nil
+}
+
+// Stop stops pushing and closes the connection. Metrics still queued are
+// discarded.
+func (ge *GraphiteExporter) Stop() error {
+	ge.stopped.Do(func() {
+		close(ge.done)
+	})
+	ge.wg.Wait()
+	ge.queue.stop()
+
+	ge.connMu.Lock()
+	defer ge.connMu.Unlock()
+	if ge.conn != nil {
+		err := ge.conn.Close()
+		ge.conn = nil
+		return err
+	}
+	return nil
+}
+
+// Stats returns delivery counters
+func (ge *GraphiteExporter) Stats() map[string]interface{} {
+	return ge.queue.stats()
+}
+
+// run exports and queues metrics every interval until stopped
+func (ge *GraphiteExporter) run() {
+	defer ge.wg.Done()
+
+	ticker := time.NewTicker(ge.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ge.done:
+			return
+		case <-ticker.C:
+			payload, err := ge.exporter.ExportToGraphite(ge.prefix)
+			if err != nil {
+				ge.logger.Warn("Failed to export Graphite metrics: %v", err)
+				continue
+			}
+			ge.queue.enqueue(payload)
+		}
+	}
+}
+
+// push writes a payload to the server, connecting first if needed. A failed
+// write drops the connection so the next attempt reconnects.
+func (ge *GraphiteExporter) push(payload []byte) error {
+	ge.connMu.Lock()
+	defer ge.connMu.Unlock()
+
+	if ge.conn == nil {
+		conn, err := ge.dial("tcp", ge.addr, graphiteTimeout)
+		if err != nil {
+			return fmt.Errorf("failed to connect to graphite at %s: %w", ge.addr, err)
+		}
+		ge.conn = conn
+	}
+
+	ge.conn.SetWriteDeadline(time.Now().Add(graphiteTimeout))
+	if _, err := ge.conn.Write(payload); err != nil {
+		ge.conn.Close()
+		ge.conn = nil
+		return fmt.Errorf("failed to write to graphite at %s: %w", ge.addr, err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package autotune
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExportToGraphite tests the Graphite plaintext line format
+func TestExportToGraphite(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+	tuner.AddMetricHook("queue_depth", func() float64 { return 7 })
+	tuner.metricsHistory.push(tuner.collectMetrics())
+
+	exporter := NewMetricsExporter(tuner)
+	before := time.Now().Unix()
+	data, err := exporter.ExportToGraphite("app.autotune.")
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.NotEmpty(t, lines)
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		require.Len(t, fields, 3, line)
+		assert.True(t, strings.HasPrefix(fields[0], "app.autotune."), line)
+
+		_, err := strconv.ParseFloat(fields[1], 64)
+		assert.NoError(t, err, line)
+
+		timestamp, err := strconv.ParseInt(fields[2], 10, 64)
+		require.NoError(t, err, line)
+		assert.InDelta(t, before, timestamp, 1, line)
+	}
+
+	assert.Contains(t, string(data), "app.autotune.gc_pause_ns ")
+	assert.Contains(t, string(data), "app.autotune.custom.queue_depth 7 ")
+
+	_, err = exporter.ExportToGraphite("bad prefix")
+	assert.Error(t, err)
+}
+
+// TestGraphiteExporterReconnect tests pushing over TCP and reconnecting
+// after the connection is lost
+func TestGraphiteExporterReconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	config := DefaultConfig()
+	config.Logger = &mockLogger{}
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	_, err = NewGraphiteExporter(tuner, listener.Addr().String(), "app", 0)
+	assert.Error(t, err)
+
+	ge, err := NewGraphiteExporter(tuner, listener.Addr().String(), "app", 10*time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, ge.Start())
+	defer ge.Stop()
+
+	// readLine accepts a connection and reads one line from it
+	readLine := func() (net.Conn, string) {
+		conn, err := listener.Accept()
+		require.NoError(t, err)
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		require.NoError(t, err)
+		return conn, line
+	}
+
+	conn, line := readLine()
+	assert.True(t, strings.HasPrefix(line, "app.running "), line)
+
+	// Drop the connection, the exporter reconnects on a later push
+	conn.Close()
+	conn, line = readLine()
+	defer conn.Close()
+	assert.True(t, strings.HasPrefix(line, "app."), line)
+	assert.Greater(t, ge.Stats()["pushed"].(int64), int64(0))
+}
@@ -0,0 +1,313 @@
+package autotune
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tunerGroupLabel is the label identifying each tuner in a group's metrics
+const tunerGroupLabel = "tuner"
+
+// groupCounters are the stats summed across the tuners of a group
+var groupCounters = []string{
+	"total_decisions",
+	"successful_tunes",
+	"reverted_tunes",
+	"vetoed_decisions",
+	"rate_limited",
+	"stale_decisions",
+}
+
+// groupMember is a named tuner in a group, with the observability server
+// used to render its metrics. The server's HTTP listener is never started.
+type groupMember struct {
+	name  string
+	tuner *Tuner
+	obs   *ObservabilityServer
+}
+
+// TunerGroup aggregates several tuners in one process, such as tuners of
+// embedded subsystems, behind a single HTTP server. Its /metrics endpoint
+// serves the Prometheus metrics of every tuner, each labeled with
+// tuner="<name>", and /stats serves their stats plus combined counters.
+//
+// The group's lock is never held while a tuner's lock is taken: requests
+// copy the member list first and then query each tuner, so tuners may be
+// added, removed and used concurrently. Individual pause distributions
+// (PauseMetricType) aren't exported by groups.
+type TunerGroup struct {
+	config  *ObservabilityConfig
+	mu      sync.RWMutex
+	members []*groupMember
+	server  *http.Server
+}
+
+// NewTunerGroup creates an empty tuner group serving metrics according to
+// config (nil means DefaultObservabilityConfig)
+func NewTunerGroup(config *ObservabilityConfig) *TunerGroup {
+	if config == nil {
+		config = DefaultObservabilityConfig()
+	}
+
+	group := &TunerGroup{config: config}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(config.MetricsPath, group.handleMetrics)
+	mux.HandleFunc("/stats", group.handleStats)
+
+	group.server = &http.Server{
+		Addr:    fmt.Sprintf(":%d", config.HTTPPort),
+		Handler: mux,
+	}
+
+	return group
+}
+
+// Add adds a tuner under name, which becomes the value of its tuner label.
+// Names must be unique and non-empty, and a tuner can only be added once.
+func (g *TunerGroup) Add(name string, tuner *Tuner) error {
+	if name == "" {
+		return fmt.Errorf("tuner name is required")
+	}
+	if tuner == nil {
+		return fmt.Errorf("tuner %q is nil", name)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, member := range g.members {
+		if member.name == name {
+			return fmt.Errorf("a tuner named %q is already in the group", name)
+		}
+		if member.tuner == tuner {
+			return fmt.Errorf("tuner is already in the group as %q", member.name)
+		}
+	}
+
+	config := *g.config
+	config.ConstLabels = make(map[string]string, len(g.config.ConstLabels)+1)
+	for label, value := range g.config.ConstLabels {
+		config.ConstLabels[label] = value
+	}
+	config.ConstLabels[tunerGroupLabel] = name
+	config.PauseMetricType = PauseMetricNone
+	config.OnRecord = nil
+
+	g.members = append(g.members, &groupMember{
+		name:  name,
+		tuner: tuner,
+		obs:   NewObservabilityServer(&config, tuner),
+	})
+	return nil
+}
+
+// Remove removes the tuner with the given name, reporting whether it was in
+// the group
+func (g *TunerGroup) Remove(name string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for i, member := range g.members {
+		if member.name == name {
+			g.members = append(g.members[:i:i], g.members[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Names returns the names of the tuners in the group, in the order they
+// were added
+func (g *TunerGroup) Names() []string {
+	members := g.snapshot()
+	names := make([]string, len(members))
+	for i, member := range members {
+		names[i] = member.name
+	}
+	return names
+}
+
+// snapshot returns a copy of the member list, so tuners can be queried
+// without holding the group lock
+func (g *TunerGroup) snapshot() []*groupMember {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return append([]*groupMember(nil), g.members...)
+}
+
+// Stats returns the stats of every tuner by name under "tuners", the number
+// of tuners under "tuner_count", and the decision counters summed across
+// tuners, e.g. "total_decisions"
+func (g *TunerGroup) Stats() map[string]interface{} {
+	members := g.snapshot()
+
+	perTuner := make(map[string]interface{}, len(members))
+	totals := make(map[string]int64, len(groupCounters))
+	for _, member := range members {
+		stats := member.tuner.GetStats()
+		perTuner[member.name] = stats
+		for _, counter := range groupCounters {
+			if value, ok := stats[counter].(int64); ok {
+				totals[counter] += value
+			}
+		}
+	}
+
+	combined := map[string]interface{}{
+		"tuners":      perTuner,
+		"tuner_count": len(members),
+	}
+	for _, counter := range groupCounters {
+		combined[counter] = totals[counter]
+	}
+	return combined
+}
+
+// Handler returns the group's HTTP handler, for mounting on an existing
+// server instead of calling Start
+func (g *TunerGroup) Handler() http.Handler {
+	return g.server.Handler
+}
+
+// Start starts serving the group's endpoints on the configured port
+func (g *TunerGroup) Start() error {
+	go func() {
+		if err := g.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			groupLogger(g.snapshot()).Error("Tuner group server error: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop stops the group's HTTP server. The tuners keep running.
+func (g *TunerGroup) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return g.server.Shutdown(ctx)
+}
+
+// groupLogger returns the logger of the first member, or the default
+// logger for an empty group
+func groupLogger(members []*groupMember) Logger {
+	if len(members) > 0 {
+		return members[0].tuner.config.Logger
+	}
+	return &defaultLogger{}
+}
+
+// handleMetrics serves the Prometheus metrics of every tuner, merged so that
+// each metric's HELP and TYPE lines appear once
+func (g *TunerGroup) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if !g.config.EnablePrometheus {
+		http.Error(w, "Prometheus metrics disabled", http.StatusNotFound)
+		return
+	}
+
+	members := g.snapshot()
+	outputs := make([][]byte, len(members))
+	for i, member := range members {
+		var buf bytes.Buffer
+		member.obs.writePrometheusMetrics(&buf)
+		outputs[i] = buf.Bytes()
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(mergePrometheusText(outputs))
+}
+
+// handleStats serves the combined stats
+func (g *TunerGroup) handleStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(g.Stats())
+}
+
+// mergePrometheusText merges Prometheus text outputs into one, grouping the
+// samples of each metric family under a single HELP and TYPE header in the
+// order families first appear. The Prometheus text format requires a
+// family's samples to be contiguous.
+func mergePrometheusText(outputs [][]byte) []byte {
+	var order []string
+	headers := make(map[string][]string)
+	samples := make(map[string][]string)
+
+	// family returns the family a sample line belongs to, mapping histogram
+	// and summary series to their base name
+	family := func(line string) string {
+		name := line
+		if i := strings.IndexAny(line, "{ "); i >= 0 {
+			name = line[:i]
+		}
+		if _, ok := headers[name]; ok {
+			return name
+		}
+		for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+			if base := strings.TrimSuffix(name, suffix); base != name {
+				if _, ok := headers[base]; ok {
+					return base
+				}
+			}
+		}
+		return name
+	}
+
+	for _, output := range outputs {
+		for _, line := range strings.Split(string(output), "\n") {
+			if line == "" {
+				continue
+			}
+
+			if strings.HasPrefix(line, "# ") {
+				fields := strings.SplitN(line, " ", 4)
+				if len(fields) < 3 {
+					continue
+				}
+				name := fields[2]
+				if _, ok := headers[name]; !ok {
+					order = append(order, name)
+					headers[name] = nil
+				}
+				if len(headers[name]) < 2 && !containsLine(headers[name], line) {
+					headers[name] = append(headers[name], line)
+				}
+				continue
+			}
+
+			name := family(line)
+			if _, ok := headers[name]; !ok {
+				order = append(order, name)
+				headers[name] = nil
+			}
+			samples[name] = append(samples[name], line)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, name := range order {
+		for _, line := range headers[name] {
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+		for _, line := range samples[name] {
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+// containsLine reports whether lines contains line
+func containsLine(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package autotune
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTunerGroup tests merged, per-tuner labeled metrics and combined stats
+func TestTunerGroup(t *testing.T) {
+	api, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+	worker, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+	api.totalDecisions = 2
+	worker.totalDecisions = 3
+
+	config := DefaultObservabilityConfig()
+	config.ConstLabels = map[string]string{"service": "shop"}
+	group := NewTunerGroup(config)
+	require.NoError(t, group.Add("api", api))
+	require.NoError(t, group.Add("worker", worker))
+
+	assert.Error(t, group.Add("api", worker), "duplicate name")
+	assert.Error(t, group.Add("other", api), "duplicate tuner")
+	assert.Error(t, group.Add("", api))
+	assert.Equal(t, []string{"api", "worker"}, group.Names())
+
+	w := httptest.NewRecorder()
+	group.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+
+	assert.Contains(t, body, `autotune_total_decisions_total{service="shop",tuner="api"} 2`)
+	assert.Contains(t, body, `autotune_total_decisions_total{service="shop",tuner="worker"} 3`)
+	assert.Contains(t, body, `autotune_bound_hits_total{bound="max",service="shop",tuner="worker"} 0`)
+	assert.Equal(t, 1, strings.Count(body, "# HELP autotune_total_decisions_total "))
+	assert.Equal(t, 1, strings.Count(body, "# TYPE autotune_total_decisions_total "))
+
+	// Each family's samples are contiguous after its header
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "# TYPE autotune_running ") {
+			assert.True(t, strings.HasPrefix(lines[i+1], "autotune_running{"))
+			assert.True(t, strings.HasPrefix(lines[i+2], "autotune_running{"))
+		}
+	}
+
+	w = httptest.NewRecorder()
+	group.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/stats", nil))
+	var stats map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, float64(2), stats["tuner_count"])
+	assert.Equal(t, float64(5), stats["total_decisions"])
+	assert.Contains(t, stats["tuners"], "worker")
+
+	assert.True(t, group.Remove("api"))
+	assert.False(t, group.Remove("api"))
+	w = httptest.NewRecorder()
+	group.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.NotContains(t, w.Body.String(), `tuner="api"`)
+}
@@ -0,0 +1,101 @@
+package autotune
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// customMetricPrefix is prepended to custom metric names in Prometheus output
+const customMetricPrefix = "autotune_custom_"
+
+// metricHook is an application metric collected every cycle
+type metricHook struct {
+	name    string
+	collect func() float64
+}
+
+// sanitizeMetricName replaces characters that aren't legal in a Prometheus
+// metric name with underscores
+func sanitizeMetricName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// AddMetricHook registers an application metric, such as queue depth or
+// cache hit rate, collected alongside the GC metrics every cycle into
+// Metrics.Custom. It is exported in JSON and as autotune_custom_<name> in
+// Prometheus, for correlation with GC behavior. Characters other than
+// letters, digits and underscores in name are replaced by underscores, and
+// registering a name again replaces the previous hook. fn is called once per
+// tuning cycle, not on scrapes, which return the last cycle's values; NaN and
+// infinite results are dropped.
+func (t *Tuner) AddMetricHook(name string, fn func() float64) {
+	name = sanitizeMetricName(name)
+	if name == "" || fn == nil {
+		t.config.Logger.Warn("Ignoring metric hook with empty name or nil function")
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	hooks := make([]metricHook, 0, len(t.metricHooks)+1)
+	for _, hook := range t.metricHooks {
+		if hook.name != name {
+			hooks = append(hooks, hook)
+		}
+	}
+	t.metricHooks = append(hooks, metricHook{name: name, collect: fn})
+}
+
+// collectCustomMetrics calls the metric hooks, returning nil without hooks
+func (t *Tuner) collectCustomMetrics() map[string]float64 {
+	// AddMetricHook replaces the slice rather than modifying it, so the
+	// hooks can be called without holding the lock
+	t.mu.RLock()
+	hooks := t.metricHooks
+	t.mu.RUnlock()
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	custom := make(map[string]float64, len(hooks))
+	for _, hook := range hooks {
+		var value float64
+		if !t.safeCall("metric_hook:"+hook.name, func() { value = hook.collect() }) {
+			continue
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		custom[hook.name] = value
+	}
+	return custom
+}
+
+// customMetricNames returns the names of the custom metrics in order
+func customMetricNames(custom map[string]float64) []string {
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeCustomMetrics writes the custom metrics as gauges in name order
+func writeCustomMetrics(w io.Writer, labels string, custom map[string]float64) {
+	for _, name := range customMetricNames(custom) {
+		metric := customMetricPrefix + name
+		fmt.Fprintf(w, "# HELP %s Custom metric %s from an application hook\n", metric, name)
+		fmt.Fprintf(w, "# TYPE %s gauge\n", metric)
+		fmt.Fprintf(w, "%s%s %g\n", metric, labels, custom[name])
+	}
+}
@@ -0,0 +1,86 @@
+package autotune
+
+import (
+	"encoding/json"
+	"math"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMetricHooks tests collecting and exporting application metrics
+func TestMetricHooks(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	assert.Nil(t, tuner.collectMetrics().Custom)
+
+	depth := 42.0
+	tuner.AddMetricHook("queue.depth", func() float64 { return depth })
+	tuner.AddMetricHook("cache_hit_rate", func() float64 { return 0.5 })
+	tuner.AddMetricHook("cache_hit_rate", func() float64 { return 0.75 }) // Replaces the previous hook
+	tuner.AddMetricHook("broken", func() float64 { return math.NaN() })
+	tuner.AddMetricHook("", func() float64 { return 1 })
+
+	metrics := tuner.collectMetrics()
+	assert.Equal(t, map[string]float64{"queue_depth": 42, "cache_hit_rate": 0.75}, metrics.Custom)
+	tuner.metricsHistory.push(metrics)
+
+	// Prometheus, both paths
+	obsConfig := DefaultObservabilityConfig()
+	obsConfig.ConstLabels = map[string]string{"service": "api"}
+	obs := NewObservabilityServer(obsConfig, tuner)
+	w := httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics?format=prometheus", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, "# TYPE autotune_custom_queue_depth gauge\n")
+	assert.Contains(t, body, `autotune_custom_queue_depth{service="api"} 42`+"\n")
+	assert.Contains(t, body, `autotune_custom_cache_hit_rate{service="api"} 0.75`+"\n")
+	assert.NotContains(t, body, "autotune_custom_broken")
+
+	output, err := NewMetricsExporter(tuner).ExportToPrometheus()
+	require.NoError(t, err)
+	assert.Contains(t, output, "autotune_custom_queue_depth 42\n")
+
+	// JSON, with the hooks only running for a tuning cycle
+	depth = 7
+	customMetric := func() float64 {
+		w := httptest.NewRecorder()
+		obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics?format=json", nil))
+		var response struct {
+			CurrentMetrics Metrics `json:"current_metrics"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.CurrentMetrics.Custom["queue_depth"]
+	}
+	assert.Equal(t, 42.0, customMetric())
+	tuner.metricsHistory.push(tuner.collectMetrics())
+	assert.Equal(t, 7.0, customMetric())
+}
+
+// TestMetricHooksConcurrent tests registering hooks while a cycle collects
+// them; run with -race
+func TestMetricHooksConcurrent(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			tuner.AddMetricHook("hook_"+strconv.Itoa(i), func() float64 { return float64(i) })
+		}(i)
+		go func() {
+			defer wg.Done()
+			tuner.collectCustomMetrics()
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, tuner.collectCustomMetrics(), 10)
+}
@@ -0,0 +1,72 @@
+package autotune
+
+import (
+	"fmt"
+	"math"
+)
+
+// IngestMetrics feeds a metrics sample collected outside the tuner, e.g. by a
+// sidecar agent reading the application's runtime metrics, through the same
+// history and decision pipeline as an internally collected sample. It
+// requires Config.ExternalMetrics and runs a complete tuning cycle before
+// returning.
+//
+// Decisions are applied to this process's GOGC, which is right when the
+// tuner is co-located with the application. A tuner that isn't should
+// forward decisions from SetDecisionFilter and veto them, or poll
+// Recommend, and report the application's GOGC in Metrics.CurrentGOGC. A
+// zero CurrentGOGC means the GOGC the tuner last applied, and a zero
+// Timestamp means now. A zero HeapFragmentation is derived from HeapSize and
+// HeapInuse.
+func (t *Tuner) IngestMetrics(m Metrics) error {
+	if !t.config.ExternalMetrics {
+		return fmt.Errorf("IngestMetrics requires Config.ExternalMetrics")
+	}
+	if m.GCPauseTime < 0 || m.GCFrequency < 0 || math.IsNaN(m.GCFrequency) ||
+		math.IsNaN(m.MemoryPressure) || math.IsInf(m.MemoryPressure, 0) {
+		return fmt.Errorf("invalid metrics: pause %v, frequency %v, memory pressure %v",
+			m.GCPauseTime, m.GCFrequency, m.MemoryPressure)
+	}
+
+	t.mu.RLock()
+	generation := t.gogcGeneration
+	if m.CurrentGOGC <= 0 {
+		m.CurrentGOGC = t.lastGOGC
+	}
+	t.mu.RUnlock()
+
+	if m.HeapFragmentation == 0 {
+		m.HeapFragmentation = heapFragmentation(m.HeapSize, m.HeapInuse)
+	}
+	if m.Timestamp.IsZero() {
+		m.Timestamp = t.now()
+	}
+
+	t.runTuningCycle(m, generation)
+	return nil
+}
+
+// currentMetricsLocked returns a fresh runtime sample, or the latest ingested
+// one with external metrics. The latency provider, recommendation source,
+// metric hooks and CPU sampler run once per cycle, so the fields they feed
+// are carried over from the last cycle's sample instead. Caller must hold
+// t.mu.
+func (t *Tuner) currentMetricsLocked() Metrics {
+	latest, ok := t.metricsHistory.last()
+	if t.config.ExternalMetrics {
+		if !ok {
+			return Metrics{Timestamp: t.now(), CurrentGOGC: t.lastGOGC}
+		}
+		return copyMetrics(latest)
+	}
+
+	metrics := t.sampleMetrics(false)
+	if ok {
+		latest = copyMetrics(latest)
+		metrics.RequestLatency = latest.RequestLatency
+		metrics.RecommendedGOGC = latest.RecommendedGOGC
+		metrics.Custom = latest.Custom
+		metrics.CPUUsage = latest.CPUUsage
+	}
+	return metrics
+}
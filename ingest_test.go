@@ -0,0 +1,60 @@
+package autotune
+
+import (
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIngestMetrics tests driving decisions purely from ingested metrics
+func TestIngestMetrics(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	config := DefaultConfig()
+	config.ExternalMetrics = true
+	config.Logger = &mockLogger{}
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	tuner.setGCPercent = func(value int) int { return 100 }
+
+	var decisions []TuningDecision
+	tuner.SetOnTuningDecision(func(decision TuningDecision) {
+		decisions = append(decisions, decision)
+	})
+
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, tuner.IngestMetrics(Metrics{
+			Timestamp:      start.Add(time.Duration(i) * time.Minute),
+			GCPauseTime:    50 * time.Millisecond, // 5x target
+			GCFrequency:    1.0,
+			MemoryPressure: 0.5,
+			CurrentGOGC:    100,
+		}))
+	}
+
+	assert.Len(t, tuner.metricsHistory.items(), 5)
+	require.NotEmpty(t, decisions)
+	assert.Equal(t, 100, decisions[0].OldGOGC)
+	assert.Greater(t, decisions[0].NewGOGC, 100)
+
+	// GetMetrics and Recommend use the latest ingested sample
+	assert.Equal(t, 50*time.Millisecond, tuner.GetMetrics().GCPauseTime)
+	_, err = tuner.Recommend()
+	assert.NoError(t, err)
+
+	assert.Error(t, tuner.IngestMetrics(Metrics{GCFrequency: -1}))
+}
+
+// TestIngestMetricsRequiresExternalMode tests that ingestion is rejected
+// while the tuner collects its own metrics
+func TestIngestMetricsRequiresExternalMode(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	assert.Error(t, tuner.IngestMetrics(Metrics{CurrentGOGC: 100}))
+	assert.Empty(t, tuner.metricsHistory.items())
+}
@@ -0,0 +1,79 @@
+package autotune
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxMetricLabels bounds the number of labels attached to exported metrics.
+// Labels beyond the limit are dropped, in label name order.
+const maxMetricLabels = 10
+
+// labelNamePattern matches legal Prometheus label names
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// validLabelName reports whether name is a legal Prometheus label name. Names
+// starting with __ are reserved for Prometheus itself.
+func validLabelName(name string) bool {
+	return labelNamePattern.MatchString(name) && !strings.HasPrefix(name, "__")
+}
+
+// metricLabels merges ConstLabels with the labels returned by the Labeler for
+// this scrape, the Labeler taking precedence. Invalid names and labels beyond
+// maxMetricLabels are dropped with a warning.
+func (obs *ObservabilityServer) metricLabels() map[string]string {
+	merged := make(map[string]string, len(obs.config.ConstLabels))
+	for name, value := range obs.config.ConstLabels {
+		merged[name] = value
+	}
+	if obs.config.Labeler != nil {
+		for name, value := range obs.config.Labeler() {
+			merged[name] = value
+		}
+	}
+
+	names := make([]string, 0, len(merged))
+	for name := range merged {
+		if !validLabelName(name) {
+			obs.tuner.config.Logger.Warn("Dropping metric label with invalid name %q", name)
+			delete(merged, name)
+			continue
+		}
+		names = append(names, name)
+	}
+
+	if len(names) > maxMetricLabels {
+		sort.Strings(names)
+		obs.tuner.config.Logger.Warn("Dropping %d metric labels beyond the limit of %d: %v",
+			len(names)-maxMetricLabels, maxMetricLabels, names[maxMetricLabels:])
+		for _, name := range names[maxMetricLabels:] {
+			delete(merged, name)
+		}
+	}
+
+	return merged
+}
+
+// labelValueEscaper escapes label values for the Prometheus text format
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels renders labels as a Prometheus label set, e.g.
+// {shard="3",tenant="acme"}, or an empty string if there are none
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + labelValueEscaper.Replace(labels[name]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,86 @@
+package autotune
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFormatLabels tests rendering and escaping of label sets
+func TestFormatLabels(t *testing.T) {
+	assert.Equal(t, "", formatLabels(nil))
+	assert.Equal(t, `{a="1",b="x\"y\\z\n"}`, formatLabels(map[string]string{"b": "x\"y\\z\n", "a": "1"}))
+
+	assert.True(t, validLabelName("tenant"))
+	assert.True(t, validLabelName("_shard_2"))
+	assert.False(t, validLabelName("2shard"))
+	assert.False(t, validLabelName("tenant-id"))
+	assert.False(t, validLabelName("__name__"))
+	assert.False(t, validLabelName(""))
+}
+
+// TestMetricLabels tests merging, validation and bounding of metric labels
+func TestMetricLabels(t *testing.T) {
+	config := DefaultConfig()
+	logger := &mockLogger{}
+	config.Logger = logger
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	logger.warnCalls = 0
+
+	obsConfig := DefaultObservabilityConfig()
+	obsConfig.ConstLabels = map[string]string{"service": "api", "tenant": "default"}
+	obsConfig.Labeler = func() map[string]string {
+		return map[string]string{"tenant": "acme", "bad-name": "x"}
+	}
+	obs := NewObservabilityServer(obsConfig, tuner)
+
+	assert.Equal(t, map[string]string{"service": "api", "tenant": "acme"}, obs.metricLabels())
+	assert.Equal(t, 1, logger.warnCalls)
+
+	obsConfig.Labeler = func() map[string]string {
+		labels := make(map[string]string)
+		for i := 0; i < 15; i++ {
+			labels[fmt.Sprintf("l%02d", i)] = "v"
+		}
+		return labels
+	}
+	labels := obs.metricLabels()
+	assert.Len(t, labels, maxMetricLabels)
+	assert.Contains(t, labels, "l00")
+	assert.NotContains(t, labels, "service") // Dropped in name order
+	assert.Equal(t, 2, logger.warnCalls)
+}
+
+// TestPrometheusLabels tests that labels are attached per scrape
+func TestPrometheusLabels(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	shard := "1"
+	obsConfig := DefaultObservabilityConfig()
+	obsConfig.ConstLabels = map[string]string{"service": "api"}
+	obsConfig.Labeler = func() map[string]string { return map[string]string{"shard": shard} }
+	obs := NewObservabilityServer(obsConfig, tuner)
+
+	scrape := func() string {
+		w := httptest.NewRecorder()
+		obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics?format=prometheus", nil))
+		return w.Body.String()
+	}
+
+	body := scrape()
+	assert.Contains(t, body, `autotune_gogc_current{service="api",shard="1"} `)
+	assert.Contains(t, body, `autotune_total_decisions_total{service="api",shard="1"} `)
+
+	shard = "2"
+	assert.Contains(t, scrape(), `autotune_gogc_current{service="api",shard="2"} `)
+
+	// The Labeler isn't serialized in the config endpoint
+	w := httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/config", nil))
+	assert.Contains(t, w.Body.String(), `"ConstLabels":{"service":"api"}`)
+}
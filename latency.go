@@ -0,0 +1,147 @@
+package autotune
+
+import (
+	"sort"
+	"time"
+)
+
+// Request latency correlation.
+//
+// With a latency provider set, every metrics sample records the application's
+// observed request latency next to the GOGC in effect. The samples in the
+// metrics history (the last 100 cycles, about 50 minutes at the default
+// 30s interval) form the learning window: they are grouped into GOGC bands
+// and, once both the current band and another band have enough samples, the
+// tuning target is biased towards the band with the lowest mean latency.
+// Samples older than the window are forgotten, so the tuner follows changes
+// in workload instead of holding on to stale correlations.
+
+const (
+	// latencyBandWidth is the width of the GOGC bands latency is grouped by
+	latencyBandWidth = 50
+	// latencyMinBandSamples is the number of samples a band needs before its
+	// mean latency is trusted
+	latencyMinBandSamples = 3
+	// latencyMinImprovement is how much lower, relative to the current band,
+	// a band's mean latency must be before the target is biased towards it
+	latencyMinImprovement = 0.1
+)
+
+// LatencyBand summarizes the request latency observed while GOGC was within
+// [Low, High)
+type LatencyBand struct {
+	Low         int           `json:"low"`
+	High        int           `json:"high"`
+	Samples     int           `json:"samples"`
+	MeanLatency time.Duration `json:"mean_latency"`
+}
+
+// center returns the middle of the band
+func (b LatencyBand) center() int {
+	return (b.Low + b.High) / 2
+}
+
+// SetLatencyProvider sets a function returning the application's current
+// request latency (for example a recent p99). It is called once per tuning
+// cycle and opts in to latency correlation: the tuner then prefers GOGC
+// values that were historically associated with lower request latency.
+// Passing nil disables it.
+func (t *Tuner) SetLatencyProvider(provider func() time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.latencyProvider = provider
+}
+
+// LatencyCorrelation returns the mean request latency per GOGC band over the
+// learning window, ordered by GOGC
+func (t *Tuner) LatencyCorrelation() []LatencyBand {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return correlateLatency(t.metricsHistory.items())
+}
+
+// correlateLatency groups samples with a request latency into GOGC bands
+func correlateLatency(history []Metrics) []LatencyBand {
+	totals := make(map[int]time.Duration)
+	counts := make(map[int]int)
+
+	for _, m := range history {
+		if m.RequestLatency <= 0 || m.CurrentGOGC < 0 {
+			continue
+		}
+		low := m.CurrentGOGC / latencyBandWidth * latencyBandWidth
+		totals[low] += m.RequestLatency
+		counts[low]++
+	}
+
+	bands := make([]LatencyBand, 0, len(counts))
+	for low, count := range counts {
+		bands = append(bands, LatencyBand{
+			Low:         low,
+			High:        low + latencyBandWidth,
+			Samples:     count,
+			MeanLatency: totals[low] / time.Duration(count),
+		})
+	}
+	sort.Slice(bands, func(i, j int) bool { return bands[i].Low < bands[j].Low })
+
+	return bands
+}
+
+// cachedLatencyBands returns the latency bands for the current metrics
+// history, recomputing them only when the history has changed since the last
+// call. The returned slice must not be modified.
+func (t *Tuner) cachedLatencyBands() []LatencyBand {
+	t.cacheMu.Lock()
+	defer t.cacheMu.Unlock()
+
+	if t.latencyBands == nil || t.latencyBandsVersion != t.historyVersion {
+		t.latencyBands = correlateLatency(t.metricsHistory.items())
+		t.latencyBandsVersion = t.historyVersion
+	}
+	return t.latencyBands
+}
+
+// applyLatencyBias moves the target towards the GOGC band with the lowest
+// observed request latency. It returns the adjusted target and the band it
+// was biased towards, if any.
+func (t *Tuner) applyLatencyBias(target, current int) (int, *LatencyBand) {
+	t.mu.RLock()
+	enabled := t.latencyProvider != nil
+	t.mu.RUnlock()
+	if !enabled {
+		return target, nil
+	}
+
+	var best, currentBand *LatencyBand
+	bands := t.cachedLatencyBands()
+	for i := range bands {
+		band := &bands[i]
+		if band.Samples < latencyMinBandSamples {
+			continue
+		}
+		if current >= band.Low && current < band.High {
+			currentBand = band
+		}
+		if best == nil || band.MeanLatency < best.MeanLatency {
+			best = band
+		}
+	}
+
+	if best == nil || currentBand == nil || best == currentBand {
+		return target, nil
+	}
+
+	improvement := 1 - float64(best.MeanLatency)/float64(currentBand.MeanLatency)
+	if improvement < latencyMinImprovement {
+		return target, nil
+	}
+
+	weight := t.aggressiveness()
+	if weight > 1 {
+		weight = 1
+	}
+	biased := target + int(float64(best.center()-target)*weight)
+
+	return biased, best
+}
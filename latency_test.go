@@ -0,0 +1,91 @@
+package autotune
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// latencyHistory returns samples alternating between two GOGC values with
+// the given request latencies
+func latencyHistory(gogcA int, latencyA time.Duration, gogcB int, latencyB time.Duration, n int) []Metrics {
+	var history []Metrics
+	for i := 0; i < n; i++ {
+		history = append(history,
+			Metrics{CurrentGOGC: gogcA, RequestLatency: latencyA},
+			Metrics{CurrentGOGC: gogcB, RequestLatency: latencyB},
+		)
+	}
+	return history
+}
+
+// setMetricsHistory replaces the tuner's metrics history, invalidating caches
+func setMetricsHistory(tuner *Tuner, history []Metrics) {
+	tuner.metricsHistory.reset(history)
+	tuner.historyVersion++
+}
+
+// TestCorrelateLatency tests grouping request latency by GOGC band
+func TestCorrelateLatency(t *testing.T) {
+	history := latencyHistory(110, 20*time.Millisecond, 320, 10*time.Millisecond, 3)
+	history = append(history,
+		Metrics{CurrentGOGC: 140, RequestLatency: 30 * time.Millisecond},
+		Metrics{CurrentGOGC: 200}, // No latency recorded
+	)
+
+	bands := correlateLatency(history)
+	require.Len(t, bands, 2)
+	assert.Equal(t, LatencyBand{Low: 100, High: 150, Samples: 4, MeanLatency: 22500 * time.Microsecond}, bands[0])
+	assert.Equal(t, LatencyBand{Low: 300, High: 350, Samples: 3, MeanLatency: 10 * time.Millisecond}, bands[1])
+}
+
+// TestLatencyBias tests biasing the target towards low-latency GOGC bands
+func TestLatencyBias(t *testing.T) {
+	config := DefaultConfig()
+	config.TuningAggressiveness = 0.5
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	setMetricsHistory(tuner, latencyHistory(110, 20*time.Millisecond, 320, 10*time.Millisecond, 3))
+
+	// Opt-in only
+	target, band := tuner.applyLatencyBias(120, 110)
+	assert.Equal(t, 120, target)
+	assert.Nil(t, band)
+
+	tuner.SetLatencyProvider(func() time.Duration { return 15 * time.Millisecond })
+	assert.Equal(t, 15*time.Millisecond, tuner.collectMetrics().RequestLatency)
+
+	// Readers don't call the provider, they carry over the last cycle's latency
+	calls := 0
+	tuner.SetLatencyProvider(func() time.Duration { calls++; return 5 * time.Millisecond })
+	assert.Equal(t, 10*time.Millisecond, tuner.GetMetrics().RequestLatency)
+	_, err = tuner.Recommend()
+	require.NoError(t, err)
+	assert.Zero(t, calls)
+	tuner.SetLatencyProvider(func() time.Duration { return 15 * time.Millisecond })
+
+	target, band = tuner.applyLatencyBias(120, 110)
+	require.NotNil(t, band)
+	assert.Equal(t, 300, band.Low)
+	assert.Equal(t, 120+(325-120)/2, target)
+
+	// Already in the best band
+	target, band = tuner.applyLatencyBias(330, 320)
+	assert.Equal(t, 330, target)
+	assert.Nil(t, band)
+
+	// Differences below the improvement threshold are ignored
+	setMetricsHistory(tuner, latencyHistory(110, 10500*time.Microsecond, 320, 10*time.Millisecond, 3))
+	target, band = tuner.applyLatencyBias(120, 110)
+	assert.Equal(t, 120, target)
+	assert.Nil(t, band)
+
+	// Not enough samples in the current band
+	setMetricsHistory(tuner, latencyHistory(110, 20*time.Millisecond, 320, 10*time.Millisecond, 2))
+	target, band = tuner.applyLatencyBias(120, 110)
+	assert.Equal(t, 120, target)
+	assert.Nil(t, band)
+}
@@ -0,0 +1,194 @@
+package autotune
+
+import "time"
+
+// LeakAction selects what the tuner does when the live heap grows steadily
+// across many cycles, which usually indicates a memory leak rather than
+// something GOGC can fix. Lowering GOGC in that situation only burns CPU and
+// masks the leak until the container is killed.
+type LeakAction string
+
+const (
+	// LeakActionAlert flags the suspected leak in metrics, stats and alerts
+	// and stops the tuner from lowering GOGC while it persists (the default)
+	LeakActionAlert LeakAction = "alert"
+	// LeakActionSafeMode additionally suspends tuning and restores GOGC to
+	// the Go default of 100 (clamped to the bounds) until growth stops
+	LeakActionSafeMode LeakAction = "safe_mode"
+	// LeakActionIgnore disables leak detection
+	LeakActionIgnore LeakAction = "ignore"
+)
+
+const (
+	// defaultLeakDetectionWindow is the number of samples examined when
+	// Config.LeakDetectionWindow is zero
+	defaultLeakDetectionWindow = 20
+	// leakMinGrowth is the minimum relative growth of the live heap across
+	// the window for it to count as a leak
+	leakMinGrowth = 0.1
+	// leakMinIncreasingSteps is the fraction of steps in the window that must
+	// strictly increase, so a heap that merely stays flat isn't flagged
+	leakMinIncreasingSteps = 0.5
+)
+
+// linearSlope returns the least-squares slope of values against their
+// position in the slice
+func linearSlope(values []float64) float64 {
+	n := float64(len(values))
+	if n < 2 {
+		return 0
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range values {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+// slopePerSecond returns the least-squares slope of values sampled evenly
+// between first and last, per second. Without usable timestamps it returns
+// the slope per sample.
+func slopePerSecond(values []float64, first, last time.Time) float64 {
+	slope := linearSlope(values)
+	if elapsed := last.Sub(first); elapsed > 0 && !first.IsZero() {
+		slope = slope * float64(len(values)-1) / elapsed.Seconds()
+	}
+	return slope
+}
+
+// detectLeak examines the live heap over a window of samples, oldest first.
+// It returns the growth slope in bytes per second (per sample if the samples
+// carry no timestamps) and whether the growth looks like a leak: the heap
+// never shrinks, rises in most steps and grows by a meaningful amount.
+func detectLeak(window []Metrics, size int) (slope float64, suspected bool) {
+	if len(window) < 2 {
+		return 0, false
+	}
+
+	values := make([]float64, len(window))
+	increasing := 0
+	monotonic := true
+	for i, m := range window {
+		values[i] = float64(m.LiveHeap)
+		if i == 0 {
+			continue
+		}
+		switch {
+		case m.LiveHeap > window[i-1].LiveHeap:
+			increasing++
+		case m.LiveHeap < window[i-1].LiveHeap:
+			monotonic = false
+		}
+	}
+
+	first, last := window[0], window[len(window)-1]
+	slope = slopePerSecond(values, first.Timestamp, last.Timestamp)
+
+	if len(window) < size || !monotonic || first.LiveHeap == 0 {
+		return slope, false
+	}
+
+	growth := float64(last.LiveHeap-first.LiveHeap) / float64(first.LiveHeap)
+	steps := float64(len(window) - 1)
+	suspected = growth >= leakMinGrowth && float64(increasing)/steps >= leakMinIncreasingSteps
+
+	return slope, suspected
+}
+
+// leakWindow returns the configured leak detection window size
+func (t *Tuner) leakWindow() int {
+	return leakWindowSize(t.config)
+}
+
+// leakWindowSize returns the leak detection window of the config
+func leakWindowSize(config *Config) int {
+	if config.LeakDetectionWindow > 0 {
+		return config.LeakDetectionWindow
+	}
+	return defaultLeakDetectionWindow
+}
+
+// updateLeakMetrics sets the heap growth slope and leak flag on freshly
+// collected metrics, using the history plus the new sample
+func (t *Tuner) updateLeakMetrics(metrics *Metrics) {
+	if t.config.LeakAction == LeakActionIgnore {
+		return
+	}
+
+	size := t.leakWindow()
+	history := t.metricsHistory.items()
+	start := len(history) - (size - 1)
+	if start < 0 {
+		start = 0
+	}
+
+	window := make([]Metrics, 0, size)
+	window = append(window, history[start:]...)
+	window = append(window, *metrics)
+
+	metrics.HeapGrowthSlope, metrics.LeakSuspected = detectLeak(window, size)
+}
+
+// handleLeak updates the leak state for this cycle's metrics, logging
+// transitions and entering or leaving safe mode. It returns whether tuning
+// should be skipped for this cycle.
+func (t *Tuner) handleLeak(metrics Metrics) bool {
+	t.mu.Lock()
+
+	wasSuspected := t.leakSuspected
+	t.leakSuspected = metrics.LeakSuspected
+	t.heapGrowthSlope = metrics.HeapGrowthSlope
+
+	if metrics.LeakSuspected && !wasSuspected {
+		t.config.Logger.Error("Suspected memory leak: live heap grew steadily over the last %d cycles (%.0f bytes/sec)",
+			t.leakWindow(), metrics.HeapGrowthSlope)
+	} else if !metrics.LeakSuspected && wasSuspected {
+		t.config.Logger.Info("Live heap growth stopped, no longer suspecting a memory leak")
+	}
+
+	if t.config.LeakAction != LeakActionSafeMode {
+		t.mu.Unlock()
+		return false
+	}
+
+	if !metrics.LeakSuspected {
+		if t.safeMode {
+			t.safeMode = false
+			t.config.Logger.Info("Leaving safe mode, resuming tuning")
+		}
+		t.mu.Unlock()
+		return false
+	}
+
+	entering := !t.safeMode
+	t.safeMode = true
+	t.mu.Unlock()
+
+	if entering {
+		target := t.clampGOGC(100)
+		t.config.Logger.Warn("Entering safe mode: tuning suspended, GOGC restored to %d", target)
+		if current := metrics.CurrentGOGC; current != target {
+			t.applyTuningDecision(TuningDecision{
+				OldGOGC:     current,
+				NewGOGC:     target,
+				DesiredGOGC: target,
+				Reason:      "Entering safe mode due to suspected memory leak",
+				Confidence:  1.0,
+				Timestamp:   t.now(),
+				Metrics:     &metrics,
+			})
+		}
+	}
+
+	return true
+}
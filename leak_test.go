@@ -0,0 +1,136 @@
+package autotune
+
+import (
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// growingHistory returns n samples one second apart whose live heap grows by
+// step bytes per sample from start
+func growingHistory(n int, start, step uint64) []Metrics {
+	base := time.Now().Add(-time.Duration(n) * time.Second)
+	history := make([]Metrics, n)
+	for i := range history {
+		history[i] = Metrics{
+			LiveHeap:    start + uint64(i)*step,
+			CurrentGOGC: 200,
+			Timestamp:   base.Add(time.Duration(i) * time.Second),
+		}
+	}
+	return history
+}
+
+// TestLinearSlope tests the least-squares slope helper
+func TestLinearSlope(t *testing.T) {
+	assert.Equal(t, 0.0, linearSlope(nil))
+	assert.Equal(t, 0.0, linearSlope([]float64{5}))
+	assert.InDelta(t, 2.0, linearSlope([]float64{1, 3, 5, 7}), 1e-9)
+	assert.InDelta(t, -1.0, linearSlope([]float64{3, 2, 1}), 1e-9)
+	assert.InDelta(t, 0.0, linearSlope([]float64{4, 4, 4}), 1e-9)
+}
+
+// TestDetectLeak tests detection of sustained monotonic heap growth
+func TestDetectLeak(t *testing.T) {
+	growing := growingHistory(20, 100<<20, 1<<20)
+	slope, suspected := detectLeak(growing, 20)
+	assert.True(t, suspected)
+	assert.InDelta(t, float64(1<<20), slope, 1)
+
+	// Too few samples for the window
+	_, suspected = detectLeak(growing[:10], 20)
+	assert.False(t, suspected)
+
+	// A drop anywhere in the window means the heap isn't only growing
+	dropped := growingHistory(20, 100<<20, 1<<20)
+	dropped[10].LiveHeap = 50 << 20
+	_, suspected = detectLeak(dropped, 20)
+	assert.False(t, suspected)
+
+	// A plateau isn't a leak
+	flat := growingHistory(20, 100<<20, 0)
+	flat[19].LiveHeap = 120 << 20
+	_, suspected = detectLeak(flat, 20)
+	assert.False(t, suspected)
+
+	// Growth below the minimum isn't a leak
+	slow := growingHistory(20, 100<<20, 100<<10)
+	_, suspected = detectLeak(slow, 20)
+	assert.False(t, suspected)
+}
+
+// TestLeakAlertMode tests that a suspected leak stops GOGC reductions and alerts
+func TestLeakAlertMode(t *testing.T) {
+	config := DefaultConfig()
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	setMetricsHistory(tuner, growingHistory(19, 100<<20, 1<<20))
+	metrics := Metrics{
+		LiveHeap:       119 << 20,
+		CurrentGOGC:    200,
+		MemoryPressure: 0.95,
+		Timestamp:      time.Now(),
+	}
+	tuner.updateLeakMetrics(&metrics)
+	require.True(t, metrics.LeakSuspected)
+	assert.Greater(t, metrics.HeapGrowthSlope, 0.0)
+
+	// High pressure would normally lower GOGC
+	decision, _ := tuner.proposeTuningDecision(metrics)
+	assert.Nil(t, decision)
+
+	assert.False(t, tuner.handleLeak(metrics), "alert mode keeps tuning")
+	stats := tuner.GetStats()
+	assert.Equal(t, true, stats["leak_suspected"])
+	assert.Greater(t, stats["heap_growth_slope"], 0.0)
+	assert.Equal(t, false, stats["safe_mode"])
+
+	var alerts []Alert
+	alertManager := NewAlertManager(tuner)
+	alertManager.AddObserver(&mockAlertObserver{alerts: &alerts})
+	alertManager.checkAlerts(metrics)
+	found := false
+	for _, alert := range alerts {
+		if alert.Level == AlertLevelCritical && strings.HasPrefix(alert.Message, "Suspected memory leak") {
+			found = true
+		}
+	}
+	assert.True(t, found)
+
+	// Ignore disables detection
+	config.LeakAction = LeakActionIgnore
+	metrics.LeakSuspected = false
+	tuner.updateLeakMetrics(&metrics)
+	assert.False(t, metrics.LeakSuspected)
+}
+
+// TestLeakSafeMode tests entering and leaving safe mode
+func TestLeakSafeMode(t *testing.T) {
+	originalGOGC := debug.SetGCPercent(300)
+	defer debug.SetGCPercent(originalGOGC)
+
+	config := DefaultConfig()
+	config.LeakAction = LeakActionSafeMode
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	assert.True(t, tuner.handleLeak(Metrics{LeakSuspected: true}))
+	assert.Equal(t, 100, readGOGC())
+	assert.Equal(t, true, tuner.GetStats()["safe_mode"])
+	assert.Len(t, tuner.decisionHistory.items(), 1)
+
+	// Staying in safe mode doesn't record further decisions
+	assert.True(t, tuner.handleLeak(Metrics{LeakSuspected: true}))
+	assert.Len(t, tuner.decisionHistory.items(), 1)
+
+	assert.False(t, tuner.handleLeak(Metrics{}))
+	assert.Equal(t, false, tuner.GetStats()["safe_mode"])
+
+	config.LeakAction = "bogus"
+	assert.Error(t, validateConfig(config))
+}
@@ -0,0 +1,112 @@
+package autotune
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MemoryLimitSource identifies where the memory limit is detected from
+type MemoryLimitSource string
+
+const (
+	// MemoryLimitSourceEnv reads the limit in bytes from the
+	// AUTOTUNE_MEMORY_LIMIT environment variable, which can be populated with
+	// the Kubernetes downward API (limits.memory)
+	MemoryLimitSourceEnv MemoryLimitSource = "env"
+	// MemoryLimitSourceFile reads the limit in bytes from Config.MemoryLimitFile,
+	// such as a downward API volume file
+	MemoryLimitSourceFile MemoryLimitSource = "file"
+	// MemoryLimitSourceCgroupV2 reads memory.max from the cgroup v2 hierarchy
+	MemoryLimitSourceCgroupV2 MemoryLimitSource = "cgroupv2"
+	// MemoryLimitSourceCgroupV1 reads memory.limit_in_bytes from the cgroup v1
+	// memory controller
+	MemoryLimitSourceCgroupV1 MemoryLimitSource = "cgroupv1"
+	// MemoryLimitSourceProcMeminfo uses the host's total memory from
+	// /proc/meminfo
+	MemoryLimitSourceProcMeminfo MemoryLimitSource = "procmeminfo"
+)
+
+// DefaultMemoryLimitSources returns the memory limit detection order used
+// when Config.MemoryLimitSources is empty
+func DefaultMemoryLimitSources() []MemoryLimitSource {
+	return []MemoryLimitSource{
+		MemoryLimitSourceCgroupV2,
+		MemoryLimitSourceCgroupV1,
+		MemoryLimitSourceProcMeminfo,
+	}
+}
+
+// validate checks that the source is known
+func (s MemoryLimitSource) validate() error {
+	switch s {
+	case MemoryLimitSourceEnv, MemoryLimitSourceFile, MemoryLimitSourceCgroupV2,
+		MemoryLimitSourceCgroupV1, MemoryLimitSourceProcMeminfo:
+		return nil
+	default:
+		return fmt.Errorf("unknown memory limit source %q", s)
+	}
+}
+
+// read reads the memory limit from this source, looking up cgroup v2
+// files in cg
+func (s MemoryLimitSource) read(cg cgroupV2Paths, limitFile string) (uint64, error) {
+	switch s {
+	case MemoryLimitSourceEnv:
+		return parseMemoryLimit(os.Getenv("AUTOTUNE_MEMORY_LIMIT"), "AUTOTUNE_MEMORY_LIMIT")
+	case MemoryLimitSourceFile:
+		if limitFile == "" {
+			return 0, fmt.Errorf("no memory limit file configured")
+		}
+		data, err := readContainerFile(limitFile)
+		if err != nil {
+			return 0, err
+		}
+		return parseMemoryLimit(string(data), limitFile)
+	case MemoryLimitSourceCgroupV2:
+		return readCgroupV2MemoryLimit(cg)
+	case MemoryLimitSourceCgroupV1:
+		return readCgroupV1MemoryLimit()
+	case MemoryLimitSourceProcMeminfo:
+		return readProcMemInfo()
+	default:
+		return 0, fmt.Errorf("unknown memory limit source %q", s)
+	}
+}
+
+// parseMemoryLimit parses a memory limit in bytes read from origin
+func parseMemoryLimit(value, origin string) (uint64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("no memory limit in %s", origin)
+	}
+
+	limit, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory limit in %s: %w", origin, err)
+	}
+	if limit == 0 {
+		return 0, fmt.Errorf("no memory limit set in %s", origin)
+	}
+	return limit, nil
+}
+
+// detectMemoryLimitFrom tries each source in order and returns the first
+// limit found along with its source. An empty list means the default order.
+func detectMemoryLimitFrom(cg cgroupV2Paths, sources []MemoryLimitSource, limitFile string) (uint64, MemoryLimitSource, error) {
+	if len(sources) == 0 {
+		sources = DefaultMemoryLimitSources()
+	}
+
+	var errs []string
+	for _, source := range sources {
+		limit, err := source.read(cg, limitFile)
+		if err == nil {
+			return limit, source, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", source, err))
+	}
+
+	return 0, "", fmt.Errorf("unable to detect memory limit (%s)", strings.Join(errs, "; "))
+}
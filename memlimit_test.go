@@ -0,0 +1,123 @@
+package autotune
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureFileReader returns a reader serving the given files by path
+func fixtureFileReader(files map[string]string) func(string) ([]byte, error) {
+	return func(path string) ([]byte, error) {
+		content, ok := files[path]
+		if !ok {
+			return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+		}
+		return []byte(content), nil
+	}
+}
+
+// TestMemoryLimitSources tests detecting the memory limit from each source
+// and the configured order
+func TestMemoryLimitSources(t *testing.T) {
+	originalReadFile := readFile
+	defer func() { readFile = originalReadFile }()
+
+	readFile = fixtureFileReader(map[string]string{
+		"/sys/fs/cgroup/memory.max": "536870912\n",
+		"/proc/mounts":              "cgroup /sys/fs/cgroup/memory cgroup rw,memory 0 0\n",
+		"/proc/self/cgroup":         "4:memory:/kubepods/pod1\n",
+		"/sys/fs/cgroup/memory/memory/kubepods/pod1/memory.limit_in_bytes": "268435456\n",
+		"/proc/meminfo":           "MemTotal:       16384 kB\n",
+		"/etc/podinfo/mem_limit":  "1073741824\n",
+		"/etc/podinfo/mem_broken": "lots\n",
+	})
+	t.Setenv("AUTOTUNE_MEMORY_LIMIT", "2147483648")
+
+	tests := []struct {
+		source MemoryLimitSource
+		limit  uint64
+	}{
+		{MemoryLimitSourceEnv, 2 << 30},
+		{MemoryLimitSourceFile, 1 << 30},
+		{MemoryLimitSourceCgroupV2, 512 << 20},
+		{MemoryLimitSourceCgroupV1, 256 << 20},
+		{MemoryLimitSourceProcMeminfo, 16 << 20},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.source), func(t *testing.T) {
+			limit, source, err := detectMemoryLimitFrom(resolveCgroupV2Paths(), []MemoryLimitSource{tt.source}, "/etc/podinfo/mem_limit")
+			require.NoError(t, err)
+			assert.Equal(t, tt.limit, limit)
+			assert.Equal(t, tt.source, source)
+		})
+	}
+
+	// The default order prefers cgroup v2
+	limit, source, err := detectMemoryLimitFrom(resolveCgroupV2Paths(), nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(512<<20), limit)
+	assert.Equal(t, MemoryLimitSourceCgroupV2, source)
+
+	// Failing sources fall through to the next one
+	t.Setenv("AUTOTUNE_MEMORY_LIMIT", "")
+	limit, source, err = detectMemoryLimitFrom(resolveCgroupV2Paths(), []MemoryLimitSource{
+		MemoryLimitSourceEnv, MemoryLimitSourceFile, MemoryLimitSourceCgroupV1,
+	}, "/etc/podinfo/mem_broken")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(256<<20), limit)
+	assert.Equal(t, MemoryLimitSourceCgroupV1, source)
+
+	_, _, err = detectMemoryLimitFrom(resolveCgroupV2Paths(), []MemoryLimitSource{MemoryLimitSourceEnv, MemoryLimitSourceFile}, "/etc/podinfo/missing")
+	assert.ErrorContains(t, err, "env: no memory limit in AUTOTUNE_MEMORY_LIMIT")
+	assert.ErrorContains(t, err, "file: ")
+}
+
+// TestCgroupV2NestedMemoryLimit tests detecting a memory limit set on the
+// process's nested cgroup v2 rather than the mount root
+func TestCgroupV2NestedMemoryLimit(t *testing.T) {
+	originalReadFile := readFile
+	defer func() { readFile = originalReadFile }()
+
+	container := "/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1.slice/cri-containerd-abc.scope"
+	files := map[string]string{
+		"/proc/mounts": "proc /proc proc rw 0 0\n" +
+			"cgroup2 /sys/fs/cgroup cgroup2 rw,nosuid,nodev,noexec,relatime 0 0\n",
+		"/proc/self/cgroup":                              "0::" + container + "\n",
+		"/sys/fs/cgroup/memory.max":                      "max\n",
+		"/sys/fs/cgroup" + container + "/memory.max":     "268435456\n",
+		"/sys/fs/cgroup" + container + "/memory.current": "67108864\n",
+	}
+	readFile = fixtureFileReader(files)
+
+	limit, source, err := detectMemoryLimitFrom(resolveCgroupV2Paths(), []MemoryLimitSource{MemoryLimitSourceCgroupV2}, "")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(256<<20), limit)
+	assert.Equal(t, MemoryLimitSourceCgroupV2, source)
+
+	usage, err := getCurrentMemoryUsage(resolveCgroupV2Paths())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(64<<20), usage)
+
+	// The unified hierarchy is found wherever it is mounted
+	files["/proc/mounts"] = "cgroup2 /run/cgroup2 cgroup2 rw 0 0\n"
+	files["/run/cgroup2"+container+"/memory.max"] = "134217728\n"
+	limit, _, err = detectMemoryLimitFrom(resolveCgroupV2Paths(), []MemoryLimitSource{MemoryLimitSourceCgroupV2}, "")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(128<<20), limit)
+}
+
+// TestMemoryLimitSourcesValidation tests validation of the source list
+func TestMemoryLimitSourcesValidation(t *testing.T) {
+	config := DefaultConfig()
+	config.MemoryLimitSources = []MemoryLimitSource{MemoryLimitSourceEnv, "downward"}
+	assert.ErrorContains(t, validateConfig(config), `unknown memory limit source "downward"`)
+
+	config.MemoryLimitSources = []MemoryLimitSource{MemoryLimitSourceFile}
+	assert.Error(t, validateConfig(config))
+
+	config.MemoryLimitFile = "/etc/podinfo/mem_limit"
+	assert.NoError(t, validateConfig(config))
+}
@@ -0,0 +1,81 @@
+package autotune
+
+import (
+	"strings"
+)
+
+// THPMode is the kernel's transparent huge page setting
+type THPMode string
+
+const (
+	// THPUnknown means the setting couldn't be read, e.g. outside Linux
+	THPUnknown THPMode = ""
+	// THPAlways backs anonymous memory with huge pages wherever possible
+	THPAlways THPMode = "always"
+	// THPMadvise only uses huge pages for regions that request them
+	THPMadvise THPMode = "madvise"
+	// THPNever disables transparent huge pages
+	THPNever THPMode = "never"
+)
+
+// thpEnabledPath is the sysfs file holding the transparent huge page setting
+const thpEnabledPath = "/sys/kernel/mm/transparent_hugepage/enabled"
+
+// lazyReturnDamping scales GOGC reductions driven by memory pressure when
+// freed memory isn't promptly returned to the OS, since a lower GOGC then
+// costs CPU without lowering RSS
+const lazyReturnDamping = 0.25
+
+// MemoryReturnSettings describes how promptly memory freed by the GC is
+// returned to the OS, which decides whether lowering GOGC reduces RSS
+type MemoryReturnSettings struct {
+	// Madvise is "dontneed" when freed memory is released with
+	// MADV_DONTNEED and RSS drops immediately, or "free" for MADV_FREE
+	// (GODEBUG=madvdontneed=0), where RSS only drops once the kernel
+	// reclaims the pages under memory pressure
+	Madvise string `json:"madvise"`
+	// THP is the transparent huge page mode, empty when unknown
+	THP THPMode `json:"thp"`
+	// Delayed reports whether freed memory may stay resident, in which case
+	// GOGC reductions aimed at memory pressure are damped
+	Delayed bool `json:"delayed"`
+}
+
+// parseTHPMode extracts the selected mode from the contents of
+// transparent_hugepage/enabled, e.g. "always [madvise] never"
+func parseTHPMode(data string) THPMode {
+	for _, field := range strings.Fields(data) {
+		if strings.HasPrefix(field, "[") && strings.HasSuffix(field, "]") {
+			return THPMode(strings.Trim(field, "[]"))
+		}
+	}
+	return THPUnknown
+}
+
+// detectTHPMode reads the transparent huge page mode, THPUnknown when it's
+// unavailable
+func detectTHPMode() THPMode {
+	data, err := readContainerFile(thpEnabledPath)
+	if err != nil {
+		return THPUnknown
+	}
+	return parseTHPMode(string(data))
+}
+
+// newMemoryReturnSettings derives the memory return behavior from the
+// GODEBUG settings and THP mode. With THP always, the scavenger's releases
+// are undone when khugepaged collapses the freed pages back into huge pages.
+func newMemoryReturnSettings(gcDebug GCDebugSettings, thp THPMode) MemoryReturnSettings {
+	settings := MemoryReturnSettings{Madvise: "dontneed", THP: thp}
+	if gcDebug.MadvFree {
+		settings.Madvise = "free"
+	}
+	settings.Delayed = gcDebug.MadvFree || thp == THPAlways
+	return settings
+}
+
+// MemoryReturn returns how promptly freed memory is returned to the OS, as
+// detected at startup
+func (t *Tuner) MemoryReturn() MemoryReturnSettings {
+	return t.memoryReturn
+}
@@ -0,0 +1,80 @@
+package autotune
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryReturnDetection tests detecting the madvise and THP modes
+func TestMemoryReturnDetection(t *testing.T) {
+	originalReadFile := readFile
+	defer func() { readFile = originalReadFile }()
+
+	assert.Equal(t, THPMadvise, parseTHPMode("always [madvise] never\n"))
+	assert.Equal(t, THPUnknown, parseTHPMode(""))
+
+	readFile = fixtureFileReader(map[string]string{thpEnabledPath: "[always] madvise never\n"})
+	assert.Equal(t, THPAlways, detectTHPMode())
+	readFile = fixtureFileReader(nil)
+	assert.Equal(t, THPUnknown, detectTHPMode())
+
+	assert.False(t, ParseGCDebug("madvdontneed=1").MadvFree)
+	assert.True(t, ParseGCDebug("madvdontneed=0").MadvFree)
+
+	settings := newMemoryReturnSettings(ParseGCDebug(""), THPMadvise)
+	assert.Equal(t, MemoryReturnSettings{Madvise: "dontneed", THP: THPMadvise}, settings)
+
+	settings = newMemoryReturnSettings(ParseGCDebug("madvdontneed=0"), THPNever)
+	assert.Equal(t, "free", settings.Madvise)
+	assert.True(t, settings.Delayed)
+
+	settings = newMemoryReturnSettings(ParseGCDebug(""), THPAlways)
+	assert.True(t, settings.Delayed)
+}
+
+// TestDelayedMemoryReturnDampsReductions tests that memory pressure lowers
+// GOGC less when freed memory isn't promptly returned
+func TestDelayedMemoryReturnDampsReductions(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	metrics := Metrics{
+		GCPauseTime:    10 * time.Millisecond,
+		GCFrequency:    1.0,
+		MemoryPressure: 0.95,
+		CurrentGOGC:    1000,
+	}
+
+	tuner.memoryReturn = newMemoryReturnSettings(ParseGCDebug(""), THPNever)
+	prompt := tuner.calculateTargetGOGC(metrics)
+
+	tuner.memoryReturn = newMemoryReturnSettings(ParseGCDebug("madvdontneed=0"), THPNever)
+	delayed := tuner.calculateTargetGOGC(metrics)
+
+	assert.Less(t, prompt, 1000)
+	assert.Greater(t, delayed, prompt)
+
+	// Low pressure still raises GOGC as before
+	metrics.MemoryPressure = 0.2
+	tuner.memoryReturn = MemoryReturnSettings{}
+	raised := tuner.calculateTargetGOGC(metrics)
+	tuner.memoryReturn = newMemoryReturnSettings(ParseGCDebug("madvdontneed=0"), THPNever)
+	assert.Equal(t, raised, tuner.calculateTargetGOGC(metrics))
+
+	// The detected mode is exposed in /config
+	obs := NewObservabilityServer(DefaultObservabilityConfig(), tuner)
+	w := httptest.NewRecorder()
+	obs.handleConfig(w, httptest.NewRequest("GET", "/config", nil))
+
+	var config map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &config))
+	require.Contains(t, config, "memory_return")
+	memoryReturn := config["memory_return"].(map[string]interface{})
+	assert.Equal(t, "free", memoryReturn["madvise"])
+	assert.Equal(t, true, memoryReturn["delayed"])
+}
@@ -0,0 +1,151 @@
+package autotune
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// MetricsAggregate summarizes the recorded metrics within a time window
+type MetricsAggregate struct {
+	Start             time.Time     `json:"start"`
+	End               time.Time     `json:"end"`
+	Samples           int           `json:"samples"`
+	AvgGCPauseTime    time.Duration `json:"avg_gc_pause_time"`
+	AvgMemoryPressure float64       `json:"avg_memory_pressure"`
+	AvgGCFrequency    float64       `json:"avg_gc_frequency"`
+	Decisions         int           `json:"decisions"`
+}
+
+// MetricsDiff compares the window (from, to] against the window of equal
+// length ending at from. Deltas are After minus Before.
+type MetricsDiff struct {
+	From   time.Time        `json:"from"`
+	To     time.Time        `json:"to"`
+	Before MetricsAggregate `json:"before"`
+	After  MetricsAggregate `json:"after"`
+
+	GCPauseTimeDelta    time.Duration `json:"gc_pause_time_delta"`
+	MemoryPressureDelta float64       `json:"memory_pressure_delta"`
+	GCFrequencyDelta    float64       `json:"gc_frequency_delta"`
+	DecisionsDelta      int           `json:"decisions_delta"`
+}
+
+// DiffMetrics compares recorded metrics after from (up to to) with the
+// equally long window before it, for questions like "what changed around
+// the deploy at 14:32?". Both timestamps must lie within the recorded
+// history and each window must contain at least one sample.
+func (obs *ObservabilityServer) DiffMetrics(from, to time.Time) (MetricsDiff, error) {
+	if !from.Before(to) {
+		return MetricsDiff{}, fmt.Errorf("from %s must be before to %s",
+			from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	obs.mu.RLock()
+	history := obs.metricsHistory
+	obs.mu.RUnlock()
+
+	if len(history) == 0 {
+		return MetricsDiff{}, fmt.Errorf("no metrics history recorded")
+	}
+
+	oldest := history[0].Timestamp
+	newest := history[len(history)-1].Timestamp
+	for _, ts := range []time.Time{from, to} {
+		if ts.Before(oldest) || ts.After(newest) {
+			return MetricsDiff{}, fmt.Errorf("timestamp %s is outside the recorded history [%s, %s]",
+				ts.Format(time.RFC3339), oldest.Format(time.RFC3339), newest.Format(time.RFC3339))
+		}
+	}
+
+	decisions := obs.tuner.Decisions()
+
+	diff := MetricsDiff{
+		From:   from,
+		To:     to,
+		Before: aggregateMetrics(history, decisions, from.Add(-to.Sub(from)), from),
+		After:  aggregateMetrics(history, decisions, from, to),
+	}
+
+	if diff.Before.Samples == 0 {
+		return MetricsDiff{}, fmt.Errorf("no metrics recorded in the window before %s", from.Format(time.RFC3339))
+	}
+	if diff.After.Samples == 0 {
+		return MetricsDiff{}, fmt.Errorf("no metrics recorded between %s and %s",
+			from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	diff.GCPauseTimeDelta = diff.After.AvgGCPauseTime - diff.Before.AvgGCPauseTime
+	diff.MemoryPressureDelta = diff.After.AvgMemoryPressure - diff.Before.AvgMemoryPressure
+	diff.GCFrequencyDelta = diff.After.AvgGCFrequency - diff.Before.AvgGCFrequency
+	diff.DecisionsDelta = diff.After.Decisions - diff.Before.Decisions
+
+	return diff, nil
+}
+
+// aggregateMetrics averages the samples and counts the decisions with
+// timestamps in (start, end]
+func aggregateMetrics(history []TimestampedMetrics, decisions []TuningDecision, start, end time.Time) MetricsAggregate {
+	agg := MetricsAggregate{Start: start, End: end}
+
+	var totalPause time.Duration
+	var totalPressure, totalFrequency float64
+	for _, sample := range history {
+		if !sample.Timestamp.After(start) || sample.Timestamp.After(end) {
+			continue
+		}
+		agg.Samples++
+		totalPause += sample.Metrics.GCPauseTime
+		totalPressure += sample.Metrics.MemoryPressure
+		totalFrequency += sample.Metrics.GCFrequency
+	}
+
+	if agg.Samples > 0 {
+		agg.AvgGCPauseTime = totalPause / time.Duration(agg.Samples)
+		agg.AvgMemoryPressure = totalPressure / float64(agg.Samples)
+		agg.AvgGCFrequency = totalFrequency / float64(agg.Samples)
+	}
+
+	for _, decision := range decisions {
+		if decision.Timestamp.After(start) && !decision.Timestamp.After(end) {
+			agg.Decisions++
+		}
+	}
+
+	return agg
+}
+
+// handleMetricsDiff handles the metrics diff endpoint. from is required and
+// to defaults to the latest recorded sample; both are RFC 3339 timestamps.
+func (obs *ObservabilityServer) handleMetricsDiff(w http.ResponseWriter, r *http.Request) {
+	from, err := time.Parse(time.RFC3339, r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid from timestamp, expected RFC 3339: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var to time.Time
+	if raw := r.URL.Query().Get("to"); raw != "" {
+		to, err = time.Parse(time.RFC3339, raw)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid to timestamp, expected RFC 3339: %v", err), http.StatusBadRequest)
+			return
+		}
+	} else {
+		obs.mu.RLock()
+		if len(obs.metricsHistory) > 0 {
+			to = obs.metricsHistory[len(obs.metricsHistory)-1].Timestamp
+		}
+		obs.mu.RUnlock()
+	}
+
+	diff, err := obs.DiffMetrics(from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
@@ -0,0 +1,108 @@
+package autotune
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDiffTestServer returns a server with one sample per minute for ten
+// minutes, where pause time and pressure step up after the fifth minute
+func newDiffTestServer(t *testing.T) (*ObservabilityServer, time.Time) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	obs := NewObservabilityServer(DefaultObservabilityConfig(), tuner)
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for i := 0; i <= 10; i++ {
+		metrics := Metrics{GCPauseTime: 2 * time.Millisecond, MemoryPressure: 0.3, GCFrequency: 1}
+		if i > 5 {
+			metrics = Metrics{GCPauseTime: 6 * time.Millisecond, MemoryPressure: 0.6, GCFrequency: 2}
+		}
+		obs.metricsHistory = append(obs.metricsHistory, TimestampedMetrics{
+			Metrics:   metrics,
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+		})
+	}
+
+	tuner.decisionHistory.reset([]TuningDecision{
+		{OldGOGC: 100, NewGOGC: 120, Timestamp: base.Add(7 * time.Minute)},
+		{OldGOGC: 120, NewGOGC: 140, Timestamp: base.Add(8 * time.Minute)},
+	})
+
+	return obs, base
+}
+
+// TestDiffMetrics tests comparing the windows around a timestamp
+func TestDiffMetrics(t *testing.T) {
+	obs, base := newDiffTestServer(t)
+
+	diff, err := obs.DiffMetrics(base.Add(5*time.Minute), base.Add(10*time.Minute))
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, diff.Before.Samples)
+	assert.Equal(t, 5, diff.After.Samples)
+	assert.Equal(t, 2*time.Millisecond, diff.Before.AvgGCPauseTime)
+	assert.Equal(t, 6*time.Millisecond, diff.After.AvgGCPauseTime)
+	assert.Equal(t, 4*time.Millisecond, diff.GCPauseTimeDelta)
+	assert.InDelta(t, 0.3, diff.MemoryPressureDelta, 1e-9)
+	assert.InDelta(t, 1.0, diff.GCFrequencyDelta, 1e-9)
+	assert.Equal(t, 0, diff.Before.Decisions)
+	assert.Equal(t, 2, diff.After.Decisions)
+	assert.Equal(t, 2, diff.DecisionsDelta)
+}
+
+// TestDiffMetricsErrors tests rejection of invalid ranges
+func TestDiffMetricsErrors(t *testing.T) {
+	obs, base := newDiffTestServer(t)
+
+	_, err := obs.DiffMetrics(base.Add(5*time.Minute), base.Add(5*time.Minute))
+	assert.Error(t, err)
+
+	_, err = obs.DiffMetrics(base.Add(-time.Minute), base.Add(5*time.Minute))
+	assert.ErrorContains(t, err, "outside the recorded history")
+
+	_, err = obs.DiffMetrics(base.Add(5*time.Minute), base.Add(20*time.Minute))
+	assert.ErrorContains(t, err, "outside the recorded history")
+
+	// The window before from has no samples
+	_, err = obs.DiffMetrics(base.Add(90*time.Second), base.Add(100*time.Second))
+	assert.ErrorContains(t, err, "no metrics recorded in the window before")
+
+	empty := NewObservabilityServer(DefaultObservabilityConfig(), obs.tuner)
+	_, err = empty.DiffMetrics(base, base.Add(time.Minute))
+	assert.Error(t, err)
+}
+
+// TestMetricsDiffEndpoint tests the metrics diff endpoint
+func TestMetricsDiffEndpoint(t *testing.T) {
+	obs, base := newDiffTestServer(t)
+
+	from := base.Add(5 * time.Minute).Format(time.RFC3339)
+	req := httptest.NewRequest("GET", "/metrics/diff?from="+from, nil)
+	w := httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var diff MetricsDiff
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+	assert.Equal(t, 2, diff.DecisionsDelta)
+	assert.Equal(t, 4*time.Millisecond, diff.GCPauseTimeDelta)
+
+	req = httptest.NewRequest("GET", "/metrics/diff?from=yesterday", nil)
+	w = httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest("GET", "/metrics/diff?from="+base.Add(-time.Hour).Format(time.RFC3339), nil)
+	w = httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "outside the recorded history")
+}
@@ -1,10 +1,17 @@
 package autotune
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -19,8 +26,53 @@ type ObservabilityConfig struct {
 	EnablePrometheus bool
 	// EnableJSONMetrics enables JSON metrics export
 	EnableJSONMetrics bool
-	// MetricsRetention is how long to keep metrics history
+	// MetricsRetention is how long to keep metrics history (zero keeps the
+	// last 1000 entries regardless of age)
 	MetricsRetention time.Duration
+	// EnableUI serves a small embedded web UI at / that polls the tuner state
+	EnableUI bool
+	// EnableDashboard serves the same web UI at /dashboard, polling
+	// /dashboard/state, independently of EnableUI, e.g. when only the
+	// /dashboard prefix is routed to the server
+	EnableDashboard bool
+	// StaleThreshold is how long the tuner may go without completing a tuning
+	// cycle before autotune_metrics_stale is set (zero means three monitor
+	// intervals)
+	StaleThreshold time.Duration
+	// ExportCycleTimestamp adds autotune_last_cycle_timestamp_seconds, the
+	// Unix time the last tuning cycle completed, to the Prometheus output
+	ExportCycleTimestamp bool
+	// ConstLabels are attached to every exported Prometheus metric
+	ConstLabels map[string]string
+	// Labeler is called on every Prometheus scrape and returns labels to
+	// attach to the exported metrics, merged over ConstLabels. Every distinct
+	// label set is a separate time series, so only return labels with a small,
+	// fixed set of values (tenant, shard); never request IDs or timestamps. At
+	// most 10 labels are attached and names must be legal Prometheus label
+	// names, anything else is dropped.
+	Labeler func() map[string]string `json:"-"`
+	// PauseMetricType exports individual GC pauses as the
+	// autotune_gc_pause_seconds histogram or summary (empty means not
+	// exported)
+	PauseMetricType PauseMetricType
+	// PauseBuckets are the upper bounds in seconds of the pause histogram
+	// buckets (empty means 10µs to 1s)
+	PauseBuckets []float64
+	// PauseObjectives are the quantiles exported by the pause summary (empty
+	// means 0.5, 0.9 and 0.99)
+	PauseObjectives []float64
+	// PauseWindow is the number of most recent pauses the summary quantiles
+	// are computed over (zero means 1024)
+	PauseWindow int
+	// AuthToken is the bearer token required by endpoints that run
+	// expensive computations, such as POST /whatif. Those endpoints are
+	// disabled while it is empty.
+	AuthToken string `json:"-"`
+	// OnRecord is called with every sample the server records, for
+	// forwarding to a custom time-series backend. It runs on a separate
+	// goroutine behind a bounded buffer: samples arriving while the buffer
+	// is full are dropped and counted, see DroppedRecords.
+	OnRecord func(TimestampedMetrics) `json:"-"`
 }
 
 // DefaultObservabilityConfig returns default observability configuration
@@ -44,6 +96,18 @@ type ObservabilityServer struct {
 	// Metrics storage
 	metricsHistory []TimestampedMetrics
 	maxMetrics     int
+
+	// Observed GC pauses, see ObservabilityConfig.PauseMetricType
+	pauses *pauseWindow
+
+	// Registers recordMetrics as a metrics observer on the first Start
+	observeOnce sync.Once
+
+	// Delivery to ObservabilityConfig.OnRecord, nil without a callback
+	sink *recordSink
+
+	// now returns the current time, replaceable in tests
+	now func() time.Time
 }
 
 // TimestampedMetrics holds metrics with a timestamp
@@ -62,15 +126,31 @@ func NewObservabilityServer(config *ObservabilityConfig, tuner *Tuner) *Observab
 		config:     config,
 		tuner:      tuner,
 		maxMetrics: 1000, // Keep last 1000 metrics
+		pauses:     newPauseWindow(config.PauseWindow, pauseBuckets(config.PauseBuckets, tuner.config.Logger)),
+		now:        time.Now,
+	}
+	if config.OnRecord != nil {
+		obs.sink = newRecordSink(config.OnRecord, tuner.config.Logger)
 	}
 
 	// Set up HTTP server
 	mux := http.NewServeMux()
 	mux.HandleFunc(config.MetricsPath, obs.handleMetrics)
+	mux.HandleFunc(config.MetricsPath+"/diff", obs.handleMetricsDiff)
+	mux.HandleFunc(config.MetricsPath+"/describe", obs.handleMetricsDescribe)
 	mux.HandleFunc("/health", obs.handleHealth)
 	mux.HandleFunc("/stats", obs.handleStats)
 	mux.HandleFunc("/config", obs.handleConfig)
 	mux.HandleFunc("/decisions", obs.handleDecisions)
+	mux.HandleFunc("/whatif", obs.handleWhatIf)
+	if config.EnableUI {
+		mux.HandleFunc("/", obs.handleUI)
+		mux.HandleFunc("/ui/state", obs.handleUIState)
+	}
+	if config.EnableDashboard {
+		mux.HandleFunc("/dashboard", obs.handleDashboard)
+		mux.HandleFunc("/dashboard/state", obs.handleUIState)
+	}
 
 	obs.server = &http.Server{
 		Addr:    fmt.Sprintf(":%d", config.HTTPPort),
@@ -83,7 +163,7 @@ func NewObservabilityServer(config *ObservabilityConfig, tuner *Tuner) *Observab
 // Start starts the observability server
 func (obs *ObservabilityServer) Start() error {
 	// Start collecting metrics
-	obs.tuner.SetOnMetricsUpdate(obs.recordMetrics)
+	obs.observeOnce.Do(func() { obs.tuner.AddMetricsObserver(MetricsObserverFunc(obs.recordMetrics)) })
 
 	// Start HTTP server
 	go func() {
@@ -101,33 +181,53 @@ func (obs *ObservabilityServer) Stop() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if obs.sink != nil {
+		obs.sink.stop()
+	}
 	return obs.server.Shutdown(ctx)
 }
 
 // recordMetrics records metrics for observability
 func (obs *ObservabilityServer) recordMetrics(metrics Metrics) {
+	obs.pauses.observe(metrics.Pauses)
+	metrics.Pauses = nil
+
 	obs.mu.Lock()
 	defer obs.mu.Unlock()
 
+	now := obs.now()
 	timestamped := TimestampedMetrics{
 		Metrics:   metrics,
-		Timestamp: time.Now(),
+		Timestamp: now,
 	}
 
 	obs.metricsHistory = append(obs.metricsHistory, timestamped)
+	obs.pruneMetricsLocked(now)
 
-	// Remove old metrics
-	if len(obs.metricsHistory) > obs.maxMetrics {
-		obs.metricsHistory = obs.metricsHistory[1:]
+	if obs.sink != nil {
+		obs.sink.enqueue(timestamped)
 	}
+}
 
-	// Clean up old metrics based on retention policy
-	cutoff := time.Now().Add(-obs.config.MetricsRetention)
-	for i := 0; i < len(obs.metricsHistory); i++ {
-		if obs.metricsHistory[i].Timestamp.After(cutoff) {
-			obs.metricsHistory = obs.metricsHistory[i:]
-			break
+// pruneMetricsLocked drops history entries older than MetricsRetention,
+// wherever they are in the history, and then the oldest entries beyond
+// maxMetrics. obs.mu must be held.
+func (obs *ObservabilityServer) pruneMetricsLocked(now time.Time) {
+	if obs.config.MetricsRetention > 0 {
+		cutoff := now.Add(-obs.config.MetricsRetention)
+		kept := obs.metricsHistory[:0]
+		for _, entry := range obs.metricsHistory {
+			if !entry.Timestamp.Before(cutoff) {
+				kept = append(kept, entry)
+			}
 		}
+		// Clear the tail so dropped entries can be garbage collected
+		clear(obs.metricsHistory[len(kept):])
+		obs.metricsHistory = kept
+	}
+
+	if excess := len(obs.metricsHistory) - obs.maxMetrics; excess > 0 {
+		obs.metricsHistory = obs.metricsHistory[excess:]
 	}
 }
 
@@ -157,59 +257,96 @@ func (obs *ObservabilityServer) handlePrometheusMetrics(w http.ResponseWriter, r
 	}
 
 	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	obs.writePrometheusMetrics(w)
+}
 
-	// Get current metrics
-	currentMetrics := obs.tuner.GetMetrics()
-	stats := obs.tuner.GetStats()
-
-	// Write Prometheus metrics
-	fmt.Fprintf(w, "# HELP autotune_gc_pause_time_ns Current GC pause time in nanoseconds\n")
-	fmt.Fprintf(w, "# TYPE autotune_gc_pause_time_ns gauge\n")
-	fmt.Fprintf(w, "autotune_gc_pause_time_ns %d\n", currentMetrics.GCPauseTime.Nanoseconds())
-
-	fmt.Fprintf(w, "# HELP autotune_gc_frequency_per_second Current GC frequency per second\n")
-	fmt.Fprintf(w, "# TYPE autotune_gc_frequency_per_second gauge\n")
-	fmt.Fprintf(w, "autotune_gc_frequency_per_second %f\n", currentMetrics.GCFrequency)
-
-	fmt.Fprintf(w, "# HELP autotune_heap_size_bytes Current heap size in bytes\n")
-	fmt.Fprintf(w, "# TYPE autotune_heap_size_bytes gauge\n")
-	fmt.Fprintf(w, "autotune_heap_size_bytes %d\n", currentMetrics.HeapSize)
+// writePrometheusMetrics writes the tuner's metrics in the Prometheus text
+// format, with the server's labels, staleness threshold, cycle timestamp and
+// pause distribution
+func (obs *ObservabilityServer) writePrometheusMetrics(w io.Writer) {
+	writePrometheus(w, obs.tuner, prometheusOptions{
+		labels:               obs.metricLabels(),
+		staleThreshold:       obs.config.StaleThreshold,
+		exportCycleTimestamp: obs.config.ExportCycleTimestamp,
+		pauseMetric:          obs.writePauseMetric,
+	})
+}
 
-	fmt.Fprintf(w, "# HELP autotune_heap_alloc_bytes Current heap allocation in bytes\n")
-	fmt.Fprintf(w, "# TYPE autotune_heap_alloc_bytes gauge\n")
-	fmt.Fprintf(w, "autotune_heap_alloc_bytes %d\n", currentMetrics.HeapAlloc)
+// prometheusOptions holds the parts of the Prometheus output that depend on
+// the observability server. The zero value writes the tuner's metrics
+// without labels, with the default staleness threshold and without the
+// cycle timestamp and pause distribution.
+type prometheusOptions struct {
+	labels               map[string]string
+	staleThreshold       time.Duration
+	exportCycleTimestamp bool
+	// pauseMetric writes autotune_gc_pause_seconds, nil to omit it
+	pauseMetric func(w io.Writer, labels map[string]string)
+}
 
-	fmt.Fprintf(w, "# HELP autotune_memory_pressure_ratio Current memory pressure ratio\n")
-	fmt.Fprintf(w, "# TYPE autotune_memory_pressure_ratio gauge\n")
-	fmt.Fprintf(w, "autotune_memory_pressure_ratio %f\n", currentMetrics.MemoryPressure)
+// writePrometheus writes the tuner's metrics in the Prometheus text format,
+// each preceded by its HELP and TYPE lines from the catalog. It is shared by
+// the metrics endpoint and MetricsExporter.ExportToPrometheus so the two
+// can't drift apart.
+func writePrometheus(w io.Writer, tuner *Tuner, opts prometheusOptions) {
+	// Get current metrics
+	currentMetrics := tuner.GetMetrics()
+	stats := tuner.stats(opts.staleThreshold)
+	labels := formatLabels(opts.labels)
 
-	fmt.Fprintf(w, "# HELP autotune_gogc_current Current GOGC value\n")
-	fmt.Fprintf(w, "# TYPE autotune_gogc_current gauge\n")
-	fmt.Fprintf(w, "autotune_gogc_current %d\n", currentMetrics.CurrentGOGC)
+	tuner.mu.RLock()
+	stale := tuner.metricsStaleLocked(opts.staleThreshold)
+	lastCycle := tuner.lastCycleTime
+	tuner.mu.RUnlock()
 
-	fmt.Fprintf(w, "# HELP autotune_total_decisions_total Total number of tuning decisions made\n")
-	fmt.Fprintf(w, "# TYPE autotune_total_decisions_total counter\n")
-	fmt.Fprintf(w, "autotune_total_decisions_total %d\n", stats["total_decisions"])
+	// Write Prometheus metrics
+	writePrometheusMetric(w, "autotune_running", labels, "%d", boolToInt(stats["running"].(bool)))
+	writePrometheusMetric(w, "autotune_metrics_stale", labels, "%d", boolToInt(stale))
 
-	fmt.Fprintf(w, "# HELP autotune_successful_tunes_total Number of successful tuning decisions\n")
-	fmt.Fprintf(w, "# TYPE autotune_successful_tunes_total counter\n")
-	fmt.Fprintf(w, "autotune_successful_tunes_total %d\n", stats["successful_tunes"])
+	if opts.exportCycleTimestamp && !lastCycle.IsZero() {
+		writePrometheusMetric(w, "autotune_last_cycle_timestamp_seconds", labels, "%f", float64(lastCycle.UnixNano())/1e9)
+	}
 
-	fmt.Fprintf(w, "# HELP autotune_reverted_tunes_total Number of reverted tuning decisions\n")
-	fmt.Fprintf(w, "# TYPE autotune_reverted_tunes_total counter\n")
-	fmt.Fprintf(w, "autotune_reverted_tunes_total %d\n", stats["reverted_tunes"])
+	writePrometheusMetric(w, "autotune_gc_pause_time_ns", labels, "%d", currentMetrics.GCPauseTime.Nanoseconds())
+	writePrometheusMetric(w, "autotune_gc_pause_p95_ns", labels, "%d", currentMetrics.GCPauseP95.Nanoseconds())
+	writePrometheusMetric(w, "autotune_gc_pause_p99_ns", labels, "%d", currentMetrics.GCPauseP99.Nanoseconds())
+	if opts.pauseMetric != nil {
+		opts.pauseMetric(w, opts.labels)
+	}
+	writePrometheusMetric(w, "autotune_gc_pause_trend", labels, "%f", stats["trend"])
+	writePrometheusMetric(w, "autotune_alloc_rate_bytes_per_second", labels, "%d", currentMetrics.AllocRate)
+	writePrometheusMetric(w, "autotune_gc_frequency_per_second", labels, "%f", currentMetrics.GCFrequency)
+	writePrometheusMetric(w, "autotune_heap_size_bytes", labels, "%d", currentMetrics.HeapSize)
+	writePrometheusMetric(w, "autotune_heap_alloc_bytes", labels, "%d", currentMetrics.HeapAlloc)
+	writePrometheusMetric(w, "autotune_heap_fragmentation_ratio", labels, "%f", currentMetrics.HeapFragmentation)
+	writePrometheusMetric(w, "autotune_memory_pressure_ratio", labels, "%f", currentMetrics.MemoryPressure)
+	writePrometheusMetric(w, "autotune_memory_pressure_raw_ratio", labels, "%f", currentMetrics.MemoryPressureRaw)
+	writePrometheusMetric(w, "autotune_memory_limit_bytes", labels, "%d", stats["memory_limit_bytes"])
+	writePrometheusMetric(w, "autotune_gogc_current", labels, "%d", currentMetrics.CurrentGOGC)
+	writePrometheusMetric(w, "autotune_gogc_target", labels, "%d", stats["desired_gogc"])
+	writePrometheusMetric(w, "autotune_seconds_since_last_decision", labels, "%f", stats["seconds_since_last_decision"])
+	writePrometheusMetric(w, "autotune_total_decisions_total", labels, "%d", stats["total_decisions"])
+	writePrometheusMetric(w, "autotune_successful_tunes_total", labels, "%d", stats["successful_tunes"])
+	writePrometheusMetric(w, "autotune_rate_limited_total", labels, "%d", stats["rate_limited"])
+	writeBoundHitMetric(w, opts.labels, stats)
+	writeCategoryMetric(w, opts.labels, stats["decision_categories"].(map[DecisionCategory]int64))
+	writePrometheusMetric(w, "autotune_reverted_tunes_total", labels, "%d", stats["reverted_tunes"])
+	writePrometheusMetric(w, "autotune_forced_gc_total", labels, "%d", stats["forced_gc_total"])
+	writePrometheusMetric(w, "autotune_tuning_health_score", labels, "%f", stats["health_score"])
 
 	if currentMetrics.ContainerMemLimit > 0 {
-		fmt.Fprintf(w, "# HELP autotune_container_memory_limit_bytes Container memory limit in bytes\n")
-		fmt.Fprintf(w, "# TYPE autotune_container_memory_limit_bytes gauge\n")
-		fmt.Fprintf(w, "autotune_container_memory_limit_bytes %d\n", currentMetrics.ContainerMemLimit)
+		writePrometheusMetric(w, "autotune_container_memory_limit_bytes", labels, "%d", currentMetrics.ContainerMemLimit)
 	}
 
 	if currentMetrics.ContainerCPULimit > 0 {
-		fmt.Fprintf(w, "# HELP autotune_container_cpu_limit_cores Container CPU limit in cores\n")
-		fmt.Fprintf(w, "# TYPE autotune_container_cpu_limit_cores gauge\n")
-		fmt.Fprintf(w, "autotune_container_cpu_limit_cores %f\n", currentMetrics.ContainerCPULimit)
+		writePrometheusMetric(w, "autotune_container_cpu_limit_cores", labels, "%f", currentMetrics.ContainerCPULimit)
 	}
+
+	if currentMetrics.ContainerCPUBurst > 0 {
+		writePrometheusMetric(w, "autotune_container_cpu_burst_cores", labels, "%f", currentMetrics.ContainerCPUBurst)
+	}
+
+	writeCustomMetrics(w, labels, currentMetrics.Custom)
 }
 
 // handleJSONMetrics handles JSON format metrics
@@ -223,7 +360,7 @@ func (obs *ObservabilityServer) handleJSONMetrics(w http.ResponseWriter, r *http
 
 	// Get current metrics and stats
 	currentMetrics := obs.tuner.GetMetrics()
-	stats := obs.tuner.GetStats()
+	stats := obs.tuner.stats(obs.config.StaleThreshold)
 
 	response := map[string]interface{}{
 		"current_metrics": currentMetrics,
@@ -243,15 +380,27 @@ func (obs *ObservabilityServer) handleJSONMetrics(w http.ResponseWriter, r *http
 func (obs *ObservabilityServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	obs.tuner.mu.RLock()
+	running := obs.tuner.running
+	sloStatus, sloBreach := obs.tuner.sloStatusLocked()
+	obs.tuner.mu.RUnlock()
+
+	// An idle tuner is up but not tuning, which monitoring shouldn't mistake
+	// for healthy tuning
+	status := "healthy"
+	if !running {
+		status = "idle"
+	}
+
 	health := map[string]interface{}{
-		"status":        "healthy",
+		"status":        status,
 		"timestamp":     time.Now(),
-		"tuner_running": obs.tuner.running,
+		"tuner_running": running,
 	}
 
 	// Check for any critical issues
 	currentMetrics := obs.tuner.GetMetrics()
-	if currentMetrics.MemoryPressure > 0.95 {
+	if currentMetrics.MemoryPressureRaw > 0.95 {
 		health["status"] = "warning"
 		health["warnings"] = []string{"High memory pressure"}
 	}
@@ -264,6 +413,20 @@ func (obs *ObservabilityServer) handleHealth(w http.ResponseWriter, r *http.Requ
 		health["warnings"] = append(health["warnings"].([]string), "High GC pause time")
 	}
 
+	if sloStatus != SLOStatusDisabled {
+		health["slo"] = map[string]interface{}{
+			"status":         sloStatus,
+			"breach_seconds": sloBreach.Seconds(),
+		}
+		if sloStatus == SLOStatusBreached {
+			health["status"] = "warning"
+			if health["warnings"] == nil {
+				health["warnings"] = []string{}
+			}
+			health["warnings"] = append(health["warnings"].([]string), "GC pause SLO breached")
+		}
+	}
+
 	json.NewEncoder(w).Encode(health)
 }
 
@@ -271,7 +434,7 @@ func (obs *ObservabilityServer) handleHealth(w http.ResponseWriter, r *http.Requ
 func (obs *ObservabilityServer) handleStats(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	stats := obs.tuner.GetStats()
+	stats := obs.tuner.stats(obs.config.StaleThreshold)
 
 	// Add observability server stats
 	obs.mu.RLock()
@@ -286,35 +449,117 @@ func (obs *ObservabilityServer) handleStats(w http.ResponseWriter, r *http.Reque
 func (obs *ObservabilityServer) handleConfig(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	tunerConfig := obs.tuner.Config()
 	config := map[string]interface{}{
-		"tuner_config":         obs.tuner.config,
+		"tuner_config":         tunerConfig,
 		"observability_config": obs.config,
+		"godebug":              obs.tuner.GCDebug(),
+		"memory_return":        obs.tuner.MemoryReturn(),
 		"timestamp":            time.Now(),
 	}
 
 	json.NewEncoder(w).Encode(config)
 }
 
-// handleDecisions handles recent decisions endpoint
+// handleDecisions handles recent decisions endpoint. A decision's position
+// in a filtered listing isn't its position in the history, so clients refer
+// to decisions by the ID each one carries.
 func (obs *ObservabilityServer) handleDecisions(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	obs.tuner.mu.RLock()
-	decisions := obs.tuner.decisionHistory
-	obs.tuner.mu.RUnlock()
+	filter, err := parseDecisionFilter(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	decisions := filter.apply(obs.tuner.Decisions())
 
 	response := map[string]interface{}{
 		"decisions": decisions,
 		"count":     len(decisions),
+		"filters":   filter.applied(),
 		"timestamp": time.Now(),
 	}
 
 	json.NewEncoder(w).Encode(response)
 }
 
+// decisionFilter selects decisions for the decisions endpoint. Zero fields
+// don't filter.
+type decisionFilter struct {
+	since         time.Time
+	limit         int
+	minConfidence float64
+}
+
+// parseDecisionFilter parses the since (RFC 3339), limit and min_confidence
+// query parameters
+func parseDecisionFilter(query url.Values) (decisionFilter, error) {
+	var filter decisionFilter
+	if raw := query.Get("since"); raw != "" {
+		since, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid since timestamp, expected RFC 3339: %v", err)
+		}
+		filter.since = since
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return filter, fmt.Errorf("invalid limit %q, expected a positive integer", raw)
+		}
+		filter.limit = limit
+	}
+	if raw := query.Get("min_confidence"); raw != "" {
+		confidence, err := strconv.ParseFloat(raw, 64)
+		if err != nil || confidence < 0 || confidence > 1 {
+			return filter, fmt.Errorf("invalid min_confidence %q, expected a number between 0 and 1", raw)
+		}
+		filter.minConfidence = confidence
+	}
+	return filter, nil
+}
+
+// apply returns the decisions matching the filter, oldest first. The limit
+// keeps the most recent ones.
+func (f decisionFilter) apply(decisions []TuningDecision) []TuningDecision {
+	matched := make([]TuningDecision, 0, len(decisions))
+	for _, decision := range decisions {
+		if !f.since.IsZero() && decision.Timestamp.Before(f.since) {
+			continue
+		}
+		if decision.Confidence < f.minConfidence {
+			continue
+		}
+		matched = append(matched, decision)
+	}
+	if f.limit > 0 && len(matched) > f.limit {
+		matched = matched[len(matched)-f.limit:]
+	}
+	return matched
+}
+
+// applied returns the filters in effect, keyed by query parameter
+func (f decisionFilter) applied() map[string]interface{} {
+	applied := make(map[string]interface{})
+	if !f.since.IsZero() {
+		applied["since"] = f.since
+	}
+	if f.limit > 0 {
+		applied["limit"] = f.limit
+	}
+	if f.minConfidence > 0 {
+		applied["min_confidence"] = f.minConfidence
+	}
+	return applied
+}
+
 // MetricsExporter provides methods to export metrics to external systems
 type MetricsExporter struct {
 	tuner *Tuner
+	units ByteUnit
 }
 
 // NewMetricsExporter creates a new metrics exporter
@@ -322,7 +567,19 @@ func NewMetricsExporter(tuner *Tuner) *MetricsExporter {
 	return &MetricsExporter{tuner: tuner}
 }
 
-// ExportToJSON exports current metrics to JSON format
+// SetUnits sets the unit byte-valued metrics are reported in by the JSON and
+// CSV exports. Prometheus output is always in bytes.
+func (me *MetricsExporter) SetUnits(unit ByteUnit) error {
+	if err := unit.validate(); err != nil {
+		return err
+	}
+	me.units = unit
+	return nil
+}
+
+// ExportToJSON exports current metrics to JSON format. With units set via
+// SetUnits, byte-valued metrics are converted and their names suffixed with
+// the unit, e.g. HeapAlloc_mib.
 func (me *MetricsExporter) ExportToJSON() ([]byte, error) {
 	metrics := me.tuner.GetMetrics()
 	stats := me.tuner.GetStats()
@@ -333,45 +590,109 @@ func (me *MetricsExporter) ExportToJSON() ([]byte, error) {
 		"timestamp": time.Now(),
 	}
 
+	if me.units != "" {
+		converted, err := metricsWithUnits(metrics, me.units)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert metrics units: %w", err)
+		}
+		data["metrics"] = converted
+		data["units"] = me.units
+	}
+
 	return json.MarshalIndent(data, "", "  ")
 }
 
-// ExportToPrometheus exports current metrics to Prometheus format
-func (me *MetricsExporter) ExportToPrometheus() (string, error) {
+// ExportToCSV exports current metrics as a CSV header and a single row.
+// Byte-valued columns are in the unit set via SetUnits (bytes by default)
+// and their names end with it, e.g. heap_alloc_mib.
+func (me *MetricsExporter) ExportToCSV() ([]byte, error) {
 	metrics := me.tuner.GetMetrics()
-	stats := me.tuner.GetStats()
 
-	var output string
+	unit := me.units
+	if unit == "" {
+		unit = ByteUnitBytes
+	}
 
-	// Add metrics
-	output += fmt.Sprintf("autotune_gc_pause_time_ns %d\n", metrics.GCPauseTime.Nanoseconds())
-	output += fmt.Sprintf("autotune_gc_frequency_per_second %f\n", metrics.GCFrequency)
-	output += fmt.Sprintf("autotune_heap_size_bytes %d\n", metrics.HeapSize)
-	output += fmt.Sprintf("autotune_heap_alloc_bytes %d\n", metrics.HeapAlloc)
-	output += fmt.Sprintf("autotune_memory_pressure_ratio %f\n", metrics.MemoryPressure)
-	output += fmt.Sprintf("autotune_gogc_current %d\n", metrics.CurrentGOGC)
-	output += fmt.Sprintf("autotune_total_decisions_total %d\n", stats["total_decisions"])
-	output += fmt.Sprintf("autotune_successful_tunes_total %d\n", stats["successful_tunes"])
-	output += fmt.Sprintf("autotune_reverted_tunes_total %d\n", stats["reverted_tunes"])
+	header := []string{"timestamp", "gc_pause_time_ns", "gc_frequency_per_second"}
+	row := []string{
+		metrics.Timestamp.Format(time.RFC3339Nano),
+		strconv.FormatInt(metrics.GCPauseTime.Nanoseconds(), 10),
+		strconv.FormatFloat(metrics.GCFrequency, 'f', -1, 64),
+	}
 
-	if metrics.ContainerMemLimit > 0 {
-		output += fmt.Sprintf("autotune_container_memory_limit_bytes %d\n", metrics.ContainerMemLimit)
+	for _, metric := range byteMetrics(metrics) {
+		header = append(header, metric.column+"_"+string(unit))
+		row = append(row, strconv.FormatFloat(unit.convert(metric.value), 'f', -1, 64))
 	}
 
-	if metrics.ContainerCPULimit > 0 {
-		output += fmt.Sprintf("autotune_container_cpu_limit_cores %f\n", metrics.ContainerCPULimit)
+	header = append(header, "memory_pressure_ratio", "gogc_current")
+	row = append(row,
+		strconv.FormatFloat(metrics.MemoryPressure, 'f', -1, 64),
+		strconv.Itoa(metrics.CurrentGOGC),
+	)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write(header)
+	writer.Write(row)
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
 	}
 
-	return output, nil
+	return buf.Bytes(), nil
 }
 
-// AlertManager manages alerts based on metrics thresholds
+// ExportBinary exports the core numeric metrics in a compact versioned
+// binary layout, see DecodeBinaryMetrics. It is meant for high-frequency
+// local recording where JSON or Prometheus text would be too bulky.
+func (me *MetricsExporter) ExportBinary() ([]byte, error) {
+	return EncodeBinaryMetrics(me.tuner.GetMetrics()), nil
+}
+
+// ExportToPrometheus exports current metrics to Prometheus format, with the
+// same HELP and TYPE lines as the metrics endpoint. Metrics that depend on
+// the observability server, such as the pause distribution, and its labels
+// are omitted.
+func (me *MetricsExporter) ExportToPrometheus() (string, error) {
+	var output strings.Builder
+	writePrometheus(&output, me.tuner, prometheusOptions{})
+	return output.String(), nil
+}
+
+// AlertManager manages alerts based on metrics thresholds.
+//
+// Observers are notified on transitions rather than on every metrics update:
+// when an alert condition becomes true, when its level changes, and with an
+// info alert marked Resolved once it clears. See AlertManagerConfig for
+// re-alerting on sustained conditions and debouncing flapping ones.
 type AlertManager struct {
 	tuner     *Tuner
+	config    AlertManagerConfig
 	observers []AlertObserver
+	active    map[AlertCondition]*alertState
+	now       func() time.Time
 	mu        sync.RWMutex
 }
 
+// AlertManagerConfig configures alert deduplication
+type AlertManagerConfig struct {
+	// ReAlertInterval is how often an alert is repeated while its condition
+	// persists (zero notifies once per occurrence)
+	ReAlertInterval time.Duration
+	// FlappingWindow is how long a condition must stay clear before it is
+	// resolved. A condition that returns within the window continues the
+	// same occurrence instead of firing again (zero resolves immediately).
+	FlappingWindow time.Duration
+}
+
+// alertState tracks an active alert condition
+type alertState struct {
+	alert      Alert     // last alert notified for the condition
+	notifiedAt time.Time // when it was notified
+	clearedAt  time.Time // when the condition cleared, zero while it holds
+}
+
 // AlertObserver defines the interface for alert observers
 type AlertObserver interface {
 	OnAlert(alert Alert)
@@ -379,11 +700,14 @@ type AlertObserver interface {
 
 // Alert represents an alert condition
 type Alert struct {
-	Level      AlertLevel `json:"level"`
-	Message    string     `json:"message"`
-	Timestamp  time.Time  `json:"timestamp"`
-	Metrics    *Metrics   `json:"metrics,omitempty"`
-	Resolution string     `json:"resolution,omitempty"`
+	Level      AlertLevel     `json:"level"`
+	Condition  AlertCondition `json:"condition,omitempty"`
+	Message    string         `json:"message"`
+	Timestamp  time.Time      `json:"timestamp"`
+	Metrics    *Metrics       `json:"metrics,omitempty"`
+	Resolution string         `json:"resolution,omitempty"`
+	// Resolved marks the info alert sent when a condition clears
+	Resolved bool `json:"resolved,omitempty"`
 }
 
 // AlertLevel defines the severity of an alert
@@ -395,14 +719,35 @@ const (
 	AlertLevelCritical AlertLevel = "critical"
 )
 
-// NewAlertManager creates a new alert manager
+// AlertCondition identifies what an alert is about. At most one alert per
+// condition is active at a time, at its current level.
+type AlertCondition string
+
+const (
+	AlertConditionMemoryPressure AlertCondition = "memory_pressure"
+	AlertConditionGCPause        AlertCondition = "gc_pause"
+	AlertConditionGCFrequency    AlertCondition = "gc_frequency"
+	AlertConditionMemoryLeak     AlertCondition = "memory_leak"
+)
+
+// NewAlertManager creates a new alert manager notifying each alert once
+// per occurrence
 func NewAlertManager(tuner *Tuner) *AlertManager {
+	return NewAlertManagerWithConfig(tuner, AlertManagerConfig{})
+}
+
+// NewAlertManagerWithConfig creates a new alert manager with the given
+// deduplication settings
+func NewAlertManagerWithConfig(tuner *Tuner, config AlertManagerConfig) *AlertManager {
 	am := &AlertManager{
-		tuner: tuner,
+		tuner:  tuner,
+		config: config,
+		active: make(map[AlertCondition]*alertState),
+		now:    time.Now,
 	}
 
 	// Set up metrics monitoring
-	tuner.SetOnMetricsUpdate(am.checkAlerts)
+	tuner.AddMetricsObserver(MetricsObserverFunc(am.checkAlerts))
 
 	return am
 }
@@ -414,24 +759,102 @@ func (am *AlertManager) AddObserver(observer AlertObserver) {
 	am.observers = append(am.observers, observer)
 }
 
-// checkAlerts checks for alert conditions
+// checkAlerts checks for alert conditions and notifies observers of
+// transitions
 func (am *AlertManager) checkAlerts(metrics Metrics) {
+	now := am.now()
+	alerts := am.evaluateAlerts(metrics, now)
+
+	am.mu.Lock()
+	notify := am.transitionsLocked(alerts, &metrics, now)
+	observers := am.observers
+	am.mu.Unlock()
+
+	// Notify observers
+	for _, alert := range notify {
+		for _, observer := range observers {
+			observer.OnAlert(alert)
+		}
+	}
+}
+
+// transitionsLocked updates the active conditions from the alerts raised by
+// the latest metrics and returns the alerts to notify. am.mu must be held
+// for writing.
+func (am *AlertManager) transitionsLocked(alerts []Alert, metrics *Metrics, now time.Time) []Alert {
+	var notify []Alert
+	current := make(map[AlertCondition]bool, len(alerts))
+
+	for _, alert := range alerts {
+		current[alert.Condition] = true
+		state, ok := am.active[alert.Condition]
+		switch {
+		case !ok:
+			am.active[alert.Condition] = &alertState{alert: alert, notifiedAt: now}
+			notify = append(notify, alert)
+		case state.alert.Level != alert.Level,
+			am.config.ReAlertInterval > 0 && now.Sub(state.notifiedAt) >= am.config.ReAlertInterval:
+			*state = alertState{alert: alert, notifiedAt: now}
+			notify = append(notify, alert)
+		default:
+			state.clearedAt = time.Time{}
+		}
+	}
+
+	// Resolve cleared conditions, in a stable order
+	cleared := make([]string, 0, len(am.active))
+	for condition := range am.active {
+		if !current[condition] {
+			cleared = append(cleared, string(condition))
+		}
+	}
+	sort.Strings(cleared)
+
+	for _, name := range cleared {
+		condition := AlertCondition(name)
+		state := am.active[condition]
+		if state.clearedAt.IsZero() {
+			state.clearedAt = now
+		}
+		if now.Sub(state.clearedAt) < am.config.FlappingWindow {
+			continue
+		}
+		delete(am.active, condition)
+		notify = append(notify, Alert{
+			Level:     AlertLevelInfo,
+			Condition: condition,
+			Message:   "Resolved: " + state.alert.Message,
+			Timestamp: now,
+			Metrics:   metrics,
+			Resolved:  true,
+		})
+	}
+
+	return notify
+}
+
+// evaluateAlerts returns the alerts raised by the metrics, at most one per
+// condition
+func (am *AlertManager) evaluateAlerts(metrics Metrics, now time.Time) []Alert {
 	alerts := []Alert{}
 
-	// High memory pressure alert
-	if metrics.MemoryPressure > 0.9 {
+	// High memory pressure alert, against the container limit since that is
+	// what gets the process OOM killed
+	if metrics.MemoryPressureRaw > 0.9 {
 		alerts = append(alerts, Alert{
 			Level:      AlertLevelCritical,
-			Message:    fmt.Sprintf("Critical memory pressure: %.1f%%", metrics.MemoryPressure*100),
-			Timestamp:  time.Now(),
+			Condition:  AlertConditionMemoryPressure,
+			Message:    fmt.Sprintf("Critical memory pressure: %.1f%% of the container limit", metrics.MemoryPressureRaw*100),
+			Timestamp:  now,
 			Metrics:    &metrics,
 			Resolution: "Consider reducing memory usage or increasing container memory limits",
 		})
-	} else if metrics.MemoryPressure > 0.8 {
+	} else if metrics.MemoryPressureRaw > 0.8 {
 		alerts = append(alerts, Alert{
 			Level:      AlertLevelWarning,
-			Message:    fmt.Sprintf("High memory pressure: %.1f%%", metrics.MemoryPressure*100),
-			Timestamp:  time.Now(),
+			Condition:  AlertConditionMemoryPressure,
+			Message:    fmt.Sprintf("High memory pressure: %.1f%% of the container limit", metrics.MemoryPressureRaw*100),
+			Timestamp:  now,
 			Metrics:    &metrics,
 			Resolution: "Monitor memory usage and consider optimization",
 		})
@@ -441,16 +864,18 @@ func (am *AlertManager) checkAlerts(metrics Metrics) {
 	if metrics.GCPauseTime > 100*time.Millisecond {
 		alerts = append(alerts, Alert{
 			Level:      AlertLevelCritical,
+			Condition:  AlertConditionGCPause,
 			Message:    fmt.Sprintf("High GC pause time: %.2fms", float64(metrics.GCPauseTime)/1e6),
-			Timestamp:  time.Now(),
+			Timestamp:  now,
 			Metrics:    &metrics,
 			Resolution: "Consider tuning GOGC or reducing allocation rate",
 		})
 	} else if metrics.GCPauseTime > 50*time.Millisecond {
 		alerts = append(alerts, Alert{
 			Level:      AlertLevelWarning,
+			Condition:  AlertConditionGCPause,
 			Message:    fmt.Sprintf("Elevated GC pause time: %.2fms", float64(metrics.GCPauseTime)/1e6),
-			Timestamp:  time.Now(),
+			Timestamp:  now,
 			Metrics:    &metrics,
 			Resolution: "Monitor GC performance and consider optimization",
 		})
@@ -460,23 +885,27 @@ func (am *AlertManager) checkAlerts(metrics Metrics) {
 	if metrics.GCFrequency > 5.0 {
 		alerts = append(alerts, Alert{
 			Level:      AlertLevelWarning,
+			Condition:  AlertConditionGCFrequency,
 			Message:    fmt.Sprintf("High GC frequency: %.1f/sec", metrics.GCFrequency),
-			Timestamp:  time.Now(),
+			Timestamp:  now,
 			Metrics:    &metrics,
 			Resolution: "Consider increasing GOGC or reducing allocation rate",
 		})
 	}
 
-	// Notify observers
-	am.mu.RLock()
-	observers := am.observers
-	am.mu.RUnlock()
-
-	for _, alert := range alerts {
-		for _, observer := range observers {
-			observer.OnAlert(alert)
-		}
+	// Suspected memory leak alert
+	if metrics.LeakSuspected {
+		alerts = append(alerts, Alert{
+			Level:      AlertLevelCritical,
+			Condition:  AlertConditionMemoryLeak,
+			Message:    fmt.Sprintf("Suspected memory leak: live heap growing at %.0f bytes/sec", metrics.HeapGrowthSlope),
+			Timestamp:  now,
+			Metrics:    &metrics,
+			Resolution: "Investigate heap growth with a heap profile; lowering GOGC will not fix a leak",
+		})
 	}
+
+	return alerts
 }
 
 // LogAlertObserver logs alerts to the configured logger
@@ -500,3 +929,11 @@ func (lao *LogAlertObserver) OnAlert(alert Alert) {
 		lao.logger.Error("Alert: %s", alert.Message)
 	}
 }
+
+// boolToInt converts a boolean to a 0/1 gauge value
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
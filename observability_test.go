@@ -6,6 +6,8 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"runtime/debug"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -122,6 +124,54 @@ func TestMetricsRetention(t *testing.T) {
 	obs.mu.RUnlock()
 }
 
+// TestMetricsRetentionPruning tests that entries outside the retention
+// window are dropped wherever they are in the history
+func TestMetricsRetentionPruning(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	config := DefaultObservabilityConfig()
+	config.MetricsRetention = time.Minute
+	obs := NewObservabilityServer(config, tuner)
+	now := time.Now()
+	obs.now = func() time.Time { return now }
+
+	entry := func(gogc int, age time.Duration) TimestampedMetrics {
+		return TimestampedMetrics{Metrics: Metrics{CurrentGOGC: gogc}, Timestamp: now.Add(-age)}
+	}
+	gogcs := func() []int {
+		obs.mu.RLock()
+		defer obs.mu.RUnlock()
+		var values []int
+		for _, entry := range obs.metricsHistory {
+			values = append(values, entry.Metrics.CurrentGOGC)
+		}
+		return values
+	}
+
+	// Old and in-window entries mixed, starting with an in-window one
+	obs.metricsHistory = []TimestampedMetrics{
+		entry(100, 30*time.Second),
+		entry(110, 2*time.Minute),
+		entry(120, 10*time.Second),
+		entry(130, time.Hour),
+	}
+	obs.recordMetrics(Metrics{CurrentGOGC: 140})
+	assert.Equal(t, []int{100, 120, 140}, gogcs())
+
+	// Everything expired but the new entry
+	now = now.Add(time.Hour)
+	obs.recordMetrics(Metrics{CurrentGOGC: 150})
+	assert.Equal(t, []int{150}, gogcs())
+
+	// Zero retention only bounds the number of entries
+	config.MetricsRetention = 0
+	obs.maxMetrics = 2
+	obs.metricsHistory = []TimestampedMetrics{entry(100, 48*time.Hour), entry(110, time.Hour)}
+	obs.recordMetrics(Metrics{CurrentGOGC: 120})
+	assert.Equal(t, []int{110, 120}, gogcs())
+}
+
 // TestHTTPEndpoints tests HTTP endpoints
 func TestHTTPEndpoints(t *testing.T) {
 	tuner, err := NewTuner(DefaultConfig())
@@ -140,7 +190,17 @@ func TestHTTPEndpoints(t *testing.T) {
 	var health map[string]interface{}
 	err = json.Unmarshal(w.Body.Bytes(), &health)
 	require.NoError(t, err)
+	assert.Equal(t, "idle", health["status"])
+	assert.Equal(t, false, health["tuner_running"])
+
+	// A running tuner reports healthy
+	require.NoError(t, tuner.Start())
+	w = httptest.NewRecorder()
+	obs.handleHealth(w, req)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &health))
 	assert.Equal(t, "healthy", health["status"])
+	assert.Equal(t, true, health["tuner_running"])
+	require.NoError(t, tuner.Stop())
 
 	// Test stats endpoint
 	req = httptest.NewRequest("GET", "/stats", nil)
@@ -165,6 +225,7 @@ func TestHTTPEndpoints(t *testing.T) {
 	err = json.Unmarshal(w.Body.Bytes(), &config)
 	require.NoError(t, err)
 	assert.Contains(t, config, "tuner_config")
+	assert.Contains(t, config["tuner_config"], "DisableAntiOscillation")
 	assert.Contains(t, config, "observability_config")
 }
 
@@ -188,6 +249,9 @@ func TestPrometheusMetrics(t *testing.T) {
 	assert.Contains(t, body, "autotune_gc_frequency_per_second")
 	assert.Contains(t, body, "autotune_heap_size_bytes")
 	assert.Contains(t, body, "autotune_gogc_current")
+	assert.Contains(t, body, "autotune_tuning_health_score")
+	assert.Contains(t, body, "# TYPE autotune_gogc_target gauge")
+	assert.Contains(t, body, "autotune_running 0\n")
 	assert.Contains(t, body, "# HELP")
 	assert.Contains(t, body, "# TYPE")
 
@@ -264,9 +328,8 @@ func TestDecisionsEndpoint(t *testing.T) {
 		Timestamp:  time.Now(),
 	}
 
-	tuner.mu.Lock()
-	tuner.decisionHistory = append(tuner.decisionHistory, decision)
-	tuner.mu.Unlock()
+	tuner.setGCPercent = func(value int) int { return 100 }
+	tuner.applyTuningDecision(decision)
 
 	req := httptest.NewRequest("GET", "/decisions", nil)
 	w := httptest.NewRecorder()
@@ -280,6 +343,95 @@ func TestDecisionsEndpoint(t *testing.T) {
 	assert.Contains(t, response, "decisions")
 	assert.Contains(t, response, "count")
 	assert.Equal(t, float64(1), response["count"])
+
+	// Annotated outcomes are surfaced with the decision, by ID
+	decisions := response["decisions"].([]interface{})
+	assert.Equal(t, float64(1), decisions[0].(map[string]interface{})["ID"])
+	require.NoError(t, tuner.AnnotateDecision(1, "p99 SLO met"))
+	assert.Error(t, tuner.AnnotateDecision(0, "unknown"))
+	assert.Error(t, tuner.AnnotateDecision(2, "unknown"))
+
+	w = httptest.NewRecorder()
+	obs.handleDecisions(w, req)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	decisions = response["decisions"].([]interface{})
+	assert.Equal(t, "p99 SLO met", decisions[0].(map[string]interface{})["Outcome"])
+
+	// IDs stay with their decisions as the history rotates, and decisions
+	// that rotated out can't be annotated
+	for i := 0; i < maxDecisions; i++ {
+		tuner.applyTuningDecision(decision)
+	}
+	history := tuner.Decisions()
+	require.Len(t, history, maxDecisions)
+	assert.Equal(t, uint64(2), history[0].ID)
+	assert.Equal(t, uint64(maxDecisions+1), history[maxDecisions-1].ID)
+	assert.Error(t, tuner.AnnotateDecision(1, "rotated out"))
+	require.NoError(t, tuner.AnnotateDecision(3, "p99 SLO missed"))
+	assert.Equal(t, "p99 SLO missed", tuner.Decisions()[1].Outcome)
+	assert.Empty(t, tuner.Decisions()[0].Outcome)
+}
+
+// TestDecisionsEndpointFilters tests filtering decisions by time,
+// confidence and count
+func TestDecisionsEndpointFilters(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+	obs := NewObservabilityServer(DefaultObservabilityConfig(), tuner)
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tuner.decisionHistory.reset([]TuningDecision{
+		{ID: 1, OldGOGC: 100, NewGOGC: 110, Confidence: 0.9, Timestamp: base},
+		{ID: 2, OldGOGC: 110, NewGOGC: 120, Confidence: 0.3, Timestamp: base.Add(time.Minute)},
+		{ID: 3, OldGOGC: 120, NewGOGC: 130, Confidence: 0.8, Timestamp: base.Add(2 * time.Minute)},
+		{ID: 4, OldGOGC: 130, NewGOGC: 140, Confidence: 0.7, Timestamp: base.Add(3 * time.Minute)},
+	})
+	tuner.lastDecisionID = 4
+
+	get := func(query string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/decisions"+query, nil))
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+	newGOGCs := func(response map[string]interface{}) []float64 {
+		var values []float64
+		for _, decision := range response["decisions"].([]interface{}) {
+			values = append(values, decision.(map[string]interface{})["NewGOGC"].(float64))
+		}
+		return values
+	}
+
+	code, response := get("")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []float64{110, 120, 130, 140}, newGOGCs(response))
+	assert.Empty(t, response["filters"])
+
+	_, response = get("?since=" + base.Add(time.Minute).Format(time.RFC3339))
+	assert.Equal(t, []float64{120, 130, 140}, newGOGCs(response))
+
+	_, response = get("?min_confidence=0.75")
+	assert.Equal(t, []float64{110, 130}, newGOGCs(response))
+
+	// The limit keeps the most recent matches
+	_, response = get("?min_confidence=0.5&limit=2")
+	assert.Equal(t, []float64{130, 140}, newGOGCs(response))
+	assert.Equal(t, float64(2), response["count"])
+	assert.Equal(t, map[string]interface{}{"limit": float64(2), "min_confidence": 0.5}, response["filters"])
+
+	// Decisions keep their IDs, so a filtered listing annotates the right one
+	first := response["decisions"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, float64(3), first["ID"])
+	require.NoError(t, tuner.AnnotateDecision(uint64(first["ID"].(float64)), "p99 SLO met"))
+	assert.Equal(t, "p99 SLO met", tuner.Decisions()[2].Outcome)
+	assert.Empty(t, tuner.Decisions()[0].Outcome)
+
+	for _, query := range []string{"?since=yesterday", "?limit=0", "?limit=ten", "?min_confidence=1.5", "?min_confidence=high"} {
+		code, response := get(query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+		assert.NotEmpty(t, response["error"], query)
+	}
 }
 
 // TestMetricsExporter tests metrics exporter
@@ -308,6 +460,80 @@ func TestMetricsExporter(t *testing.T) {
 	assert.NotEmpty(t, promData)
 	assert.Contains(t, promData, "autotune_gc_pause_time_ns")
 	assert.Contains(t, promData, "autotune_gogc_current")
+	assert.Contains(t, promData, "autotune_tuning_health_score")
+	assert.Contains(t, promData, "autotune_gogc_target")
+	assert.Contains(t, promData, "autotune_running 0\n")
+}
+
+// TestExportToPrometheusMatchesEndpoint tests that the exporter and the
+// metrics endpoint serialize the same way
+func TestExportToPrometheusMatchesEndpoint(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	// External metrics keep the heap stats from changing between exports
+	config := DefaultConfig()
+	config.ExternalMetrics = true
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	now := time.Now()
+	tuner.now = func() time.Time { return now }
+
+	obs := NewObservabilityServer(DefaultObservabilityConfig(), tuner)
+	w := httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics?format=prometheus", nil))
+
+	output, err := NewMetricsExporter(tuner).ExportToPrometheus()
+	require.NoError(t, err)
+	assert.Equal(t, w.Body.String(), output)
+	assert.Contains(t, output, "# HELP autotune_gogc_current Current GOGC value\n# TYPE autotune_gogc_current gauge\nautotune_gogc_current 100\n")
+}
+
+// TestAlertManagerWithObservabilityServer tests that an alert manager and an
+// observability server wired to the same tuner, as in the observability
+// example, both receive every metrics update
+func TestAlertManagerWithObservabilityServer(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	config := DefaultConfig()
+	config.ExternalMetrics = true
+	config.Logger = silentLogger{}
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	defer tuner.cancel()
+	tuner.setGCPercent = func(value int) int { return 100 }
+
+	obsConfig := DefaultObservabilityConfig()
+	obsConfig.HTTPPort = 0
+	obs := NewObservabilityServer(obsConfig, tuner)
+
+	alerts := make(alertChannelObserver, 10)
+	alertManager := NewAlertManager(tuner)
+	alertManager.AddObserver(alerts)
+
+	var updates int64
+	tuner.SetOnMetricsUpdate(func(Metrics) { atomic.AddInt64(&updates, 1) })
+
+	require.NoError(t, obs.Start())
+	defer obs.Stop()
+
+	require.NoError(t, tuner.IngestMetrics(Metrics{GCPauseTime: 150 * time.Millisecond, GCFrequency: 1, MemoryPressure: 0.5}))
+
+	// checkAlerts ran
+	select {
+	case alert := <-alerts:
+		assert.Equal(t, AlertLevelCritical, alert.Level)
+	case <-time.After(5 * time.Second):
+		t.Fatal("alert manager didn't receive the metrics update")
+	}
+
+	// recordMetrics ran
+	assert.Eventually(t, func() bool {
+		obs.mu.RLock()
+		defer obs.mu.RUnlock()
+		return len(obs.metricsHistory) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&updates))
 }
 
 // TestAlertManager tests alert manager
@@ -328,9 +554,9 @@ func TestAlertManager(t *testing.T) {
 
 	// Test alert generation
 	highPressureMetrics := Metrics{
-		MemoryPressure: 0.95,                   // Should trigger critical alert
-		GCPauseTime:    150 * time.Millisecond, // Should trigger critical alert
-		GCFrequency:    6.0,                    // Should trigger warning alert
+		MemoryPressureRaw: 0.95,                   // Should trigger critical alert
+		GCPauseTime:       150 * time.Millisecond, // Should trigger critical alert
+		GCFrequency:       6.0,                    // Should trigger warning alert
 	}
 
 	alertManager.checkAlerts(highPressureMetrics)
@@ -352,6 +578,78 @@ func TestAlertManager(t *testing.T) {
 
 	assert.True(t, foundCritical)
 	assert.True(t, foundWarning)
+
+	// Memory alerts are against the container limit, not the tuning
+	// threshold, so all three conditions resolve
+	receivedAlerts = nil
+	alertManager.checkAlerts(Metrics{MemoryPressure: 1.1, MemoryPressureAdjusted: 1.1, MemoryPressureRaw: 0.7})
+	require.Len(t, receivedAlerts, 3)
+	for _, alert := range receivedAlerts {
+		assert.Equal(t, AlertLevelInfo, alert.Level)
+		assert.True(t, alert.Resolved)
+	}
+
+	receivedAlerts = nil
+	alertManager.checkAlerts(Metrics{MemoryPressure: 1.06, MemoryPressureAdjusted: 1.06, MemoryPressureRaw: 0.85})
+	require.Len(t, receivedAlerts, 1)
+	assert.Equal(t, AlertLevelWarning, receivedAlerts[0].Level)
+	assert.Equal(t, "High memory pressure: 85.0% of the container limit", receivedAlerts[0].Message)
+}
+
+// TestAlertManagerTransitions tests that alerts are notified on
+// transitions, re-alerted after ReAlertInterval and debounced over
+// FlappingWindow
+func TestAlertManagerTransitions(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	alertManager := NewAlertManagerWithConfig(tuner, AlertManagerConfig{
+		ReAlertInterval: 10 * time.Minute,
+		FlappingWindow:  2 * time.Minute,
+	})
+	now := time.Unix(1700000000, 0)
+	alertManager.now = func() time.Time { return now }
+
+	var alerts []Alert
+	alertManager.AddObserver(&mockAlertObserver{alerts: &alerts})
+	check := func(pressure float64) []Alert {
+		alerts = nil
+		alertManager.checkAlerts(Metrics{MemoryPressureRaw: pressure})
+		now = now.Add(time.Minute)
+		return alerts
+	}
+
+	fired := check(0.95)
+	require.Len(t, fired, 1)
+	assert.Equal(t, AlertLevelCritical, fired[0].Level)
+	assert.Equal(t, AlertConditionMemoryPressure, fired[0].Condition)
+
+	// A sustained condition isn't repeated until the re-alert interval
+	for i := 0; i < 9; i++ {
+		assert.Empty(t, check(0.96), "minute %d", i+1)
+	}
+	fired = check(0.96)
+	require.Len(t, fired, 1)
+	assert.Equal(t, AlertLevelCritical, fired[0].Level)
+
+	// A level change is a transition
+	fired = check(0.85)
+	require.Len(t, fired, 1)
+	assert.Equal(t, AlertLevelWarning, fired[0].Level)
+
+	// Clearing briefly doesn't resolve, and the return isn't a new alert
+	assert.Empty(t, check(0.5))
+	assert.Empty(t, check(0.85))
+
+	// Staying clear for the flapping window resolves it once
+	assert.Empty(t, check(0.5))
+	assert.Empty(t, check(0.5))
+	fired = check(0.5)
+	require.Len(t, fired, 1)
+	assert.True(t, fired[0].Resolved)
+	assert.Equal(t, AlertLevelInfo, fired[0].Level)
+	assert.Equal(t, "Resolved: High memory pressure: 85.0% of the container limit", fired[0].Message)
+	assert.Empty(t, check(0.5))
 }
 
 // TestLogAlertObserver tests log alert observer
@@ -508,6 +806,17 @@ func BenchmarkJSONExport(b *testing.B) {
 
 // Mock implementations for testing
 
+// alertChannelObserver forwards alerts to a channel, for alerts raised on
+// observer goroutines
+type alertChannelObserver chan Alert
+
+func (c alertChannelObserver) OnAlert(alert Alert) {
+	select {
+	case c <- alert:
+	default:
+	}
+}
+
 type mockAlertObserver struct {
 	alerts *[]Alert
 }
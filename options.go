@@ -0,0 +1,60 @@
+package autotune
+
+import "time"
+
+// Option modifies a Config, see NewTunerWithOptions
+type Option func(*Config)
+
+// NewTunerWithOptions creates a new GC tuner from DefaultConfig with the
+// given options applied in order. The resulting configuration is validated
+// like NewTuner's.
+func NewTunerWithOptions(opts ...Option) (*Tuner, error) {
+	config := DefaultConfig()
+	for _, opt := range opts {
+		opt(config)
+	}
+	return NewTuner(config)
+}
+
+// WithMonitorInterval sets Config.MonitorInterval
+func WithMonitorInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.MonitorInterval = interval
+	}
+}
+
+// WithTargetLatency sets Config.TargetLatency
+func WithTargetLatency(latency time.Duration) Option {
+	return func(c *Config) {
+		c.TargetLatency = latency
+	}
+}
+
+// WithBounds sets Config.MinGOGC and Config.MaxGOGC
+func WithBounds(minGOGC, maxGOGC int) Option {
+	return func(c *Config) {
+		c.MinGOGC = minGOGC
+		c.MaxGOGC = maxGOGC
+	}
+}
+
+// WithAggressiveness sets Config.TuningAggressiveness
+func WithAggressiveness(aggressiveness float64) Option {
+	return func(c *Config) {
+		c.TuningAggressiveness = aggressiveness
+	}
+}
+
+// WithLogger sets Config.Logger
+func WithLogger(logger Logger) Option {
+	return func(c *Config) {
+		c.Logger = logger
+	}
+}
+
+// WithStrategy sets Config.Strategy
+func WithStrategy(strategy Strategy) Option {
+	return func(c *Config) {
+		c.Strategy = strategy
+	}
+}
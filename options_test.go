@@ -0,0 +1,52 @@
+package autotune
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewTunerWithOptions tests composing options over the defaults
+func TestNewTunerWithOptions(t *testing.T) {
+	logger := &mockLogger{}
+	strategy, err := NewPIDStrategy(1, 0.1, 0)
+	require.NoError(t, err)
+	tuner, err := NewTunerWithOptions(
+		WithTargetLatency(5*time.Millisecond),
+		WithBounds(100, 400),
+		WithAggressiveness(0.5),
+		WithMonitorInterval(10*time.Second),
+		WithLogger(logger),
+		WithStrategy(strategy),
+	)
+	require.NoError(t, err)
+
+	expected := DefaultConfig()
+	expected.TargetLatency = 5 * time.Millisecond
+	expected.MinGOGC = 100
+	expected.MaxGOGC = 400
+	expected.TuningAggressiveness = 0.5
+	expected.MonitorInterval = 10 * time.Second
+	expected.Logger = logger
+	expected.Strategy = strategy
+	assert.Equal(t, expected, tuner.config)
+
+	// Later options override earlier ones
+	tuner, err = NewTunerWithOptions(WithBounds(100, 400), WithBounds(60, 300))
+	require.NoError(t, err)
+	assert.Equal(t, 60, tuner.config.MinGOGC)
+	assert.Equal(t, 300, tuner.config.MaxGOGC)
+
+	// No options means the defaults
+	tuner, err = NewTunerWithOptions()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig().TargetLatency, tuner.config.TargetLatency)
+
+	// The result is validated
+	_, err = NewTunerWithOptions(WithBounds(400, 100))
+	assert.Error(t, err)
+	_, err = NewTunerWithOptions(WithMonitorInterval(time.Millisecond))
+	assert.Error(t, err)
+}
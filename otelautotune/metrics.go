@@ -0,0 +1,224 @@
+package otelautotune
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bpradana/autotune"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// defaultOTLPInterval is the push interval when OTLPConfig.Interval is zero
+const defaultOTLPInterval = 30 * time.Second
+
+// OTLPConfig configures an OTLPExporter
+type OTLPConfig struct {
+	// Endpoint is the collector's OTLP/HTTP endpoint, either host:port or a
+	// URL such as https://collector:4318/v1/metrics. Required unless
+	// MeterProvider is set.
+	Endpoint string
+	// Insecure sends metrics over plain HTTP when Endpoint is host:port
+	Insecure bool
+	// Headers are sent with every export, e.g. an authorization token
+	Headers map[string]string
+	// Interval between pushes (zero means 30s)
+	Interval time.Duration
+	// ResourceAttributes are added to the default resource, which carries
+	// the service name and SDK attributes from the OTEL_* environment
+	ResourceAttributes []attribute.KeyValue
+
+	// MeterProvider, when set, records the metrics instead of an OTLP
+	// pipeline created from the settings above, which are then ignored.
+	// Use it to integrate with an existing OpenTelemetry SDK setup.
+	MeterProvider metric.MeterProvider
+}
+
+// OTLPExporter reports a tuner's metrics as OpenTelemetry instruments:
+// gauges for the GC pause time, GC frequency, memory pressure and GOGC, and
+// counters for the decision totals. Instruments are named like the
+// Prometheus metrics, with counters dropping the _total suffix that
+// Prometheus exporters add back, so dashboards work with either path.
+type OTLPExporter struct {
+	// provider is the meter provider owned by the exporter, nil when
+	// supplied through OTLPConfig.MeterProvider
+	provider     *sdkmetric.MeterProvider
+	registration metric.Registration
+}
+
+// gauge is a gauge instrument and how to read its value
+type gauge struct {
+	name  string
+	value func(autotune.Metrics) float64
+}
+
+// counter is a counter instrument and the stats key holding its total
+type counter struct {
+	name string
+	stat string
+}
+
+var gauges = []gauge{
+	{"autotune_gc_pause_time_ns", func(m autotune.Metrics) float64 { return float64(m.GCPauseTime.Nanoseconds()) }},
+	{"autotune_gc_frequency_per_second", func(m autotune.Metrics) float64 { return m.GCFrequency }},
+	{"autotune_memory_pressure_ratio", func(m autotune.Metrics) float64 { return m.MemoryPressure }},
+	{"autotune_gogc_current", func(m autotune.Metrics) float64 { return float64(m.CurrentGOGC) }},
+}
+
+var counters = []counter{
+	{"autotune_total_decisions_total", "total_decisions"},
+	{"autotune_successful_tunes_total", "successful_tunes"},
+	{"autotune_rate_limited_total", "rate_limited"},
+	{"autotune_reverted_tunes_total", "reverted_tunes"},
+}
+
+// NewOTLPExporter starts reporting the tuner's metrics. Without a
+// MeterProvider in config, it pushes them to config.Endpoint every
+// config.Interval. Call Shutdown to stop.
+func NewOTLPExporter(ctx context.Context, tuner *autotune.Tuner, config OTLPConfig) (*OTLPExporter, error) {
+	e := &OTLPExporter{}
+
+	mp := config.MeterProvider
+	if mp == nil {
+		provider, err := newOTLPMeterProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		e.provider = provider
+		mp = provider
+	}
+
+	registration, err := registerMetrics(tuner, mp.Meter(instrumentationName))
+	if err != nil {
+		if e.provider != nil {
+			e.provider.Shutdown(ctx)
+		}
+		return nil, err
+	}
+	e.registration = registration
+	return e, nil
+}
+
+// Shutdown stops reporting. An exporter-owned pipeline pushes the final
+// values and is shut down; a supplied MeterProvider is left running.
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	err := e.registration.Unregister()
+	if e.provider != nil {
+		err = errors.Join(err, e.provider.Shutdown(ctx))
+	}
+	return err
+}
+
+// newOTLPMeterProvider creates a meter provider pushing to the OTLP/HTTP
+// endpoint periodically
+func newOTLPMeterProvider(ctx context.Context, config OTLPConfig) (*sdkmetric.MeterProvider, error) {
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("OTLP endpoint is required without a meter provider")
+	}
+	if config.Interval < 0 {
+		return nil, fmt.Errorf("OTLP push interval must not be negative, got %v", config.Interval)
+	}
+	interval := config.Interval
+	if interval == 0 {
+		interval = defaultOTLPInterval
+	}
+
+	var opts []otlpmetrichttp.Option
+	if strings.Contains(config.Endpoint, "://") {
+		opts = append(opts, otlpmetrichttp.WithEndpointURL(config.Endpoint))
+	} else {
+		opts = append(opts, otlpmetrichttp.WithEndpoint(config.Endpoint))
+		if config.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+	}
+	if len(config.Headers) > 0 {
+		opts = append(opts, otlpmetrichttp.WithHeaders(config.Headers))
+	}
+
+	exporter, err := otlpmetrichttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(config.ResourceAttributes...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OTLP resource: %w", err)
+	}
+
+	return sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(interval))),
+	), nil
+}
+
+// registerMetrics creates the instruments on meter, observed from the
+// tuner's latest metrics and stats on every collection
+func registerMetrics(tuner *autotune.Tuner, meter metric.Meter) (metric.Registration, error) {
+	descriptors := make(map[string]autotune.MetricDescriptor)
+	for _, desc := range autotune.NewMetricsExporter(tuner).Describe() {
+		descriptors[desc.Name] = desc
+	}
+
+	var observables []metric.Observable
+	gaugeInstruments := make([]metric.Float64ObservableGauge, len(gauges))
+	for i, g := range gauges {
+		desc := descriptors[g.name]
+		instrument, err := meter.Float64ObservableGauge(g.name,
+			metric.WithDescription(desc.Help), metric.WithUnit(otelUnit(desc.Unit)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", g.name, err)
+		}
+		gaugeInstruments[i] = instrument
+		observables = append(observables, instrument)
+	}
+
+	counterInstruments := make([]metric.Int64ObservableCounter, len(counters))
+	for i, c := range counters {
+		desc := descriptors[c.name]
+		name := strings.TrimSuffix(c.name, "_total")
+		instrument, err := meter.Int64ObservableCounter(name, metric.WithDescription(desc.Help))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", name, err)
+		}
+		counterInstruments[i] = instrument
+		observables = append(observables, instrument)
+	}
+
+	return meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		metrics := tuner.GetMetrics()
+		stats := tuner.GetStats()
+		for i, g := range gauges {
+			o.ObserveFloat64(gaugeInstruments[i], g.value(metrics))
+		}
+		for i, c := range counters {
+			if total, ok := stats[c.stat].(int64); ok {
+				o.ObserveInt64(counterInstruments[i], total)
+			}
+		}
+		return nil
+	}, observables...)
+}
+
+// otelUnit converts a metric catalog unit to its UCUM form
+func otelUnit(unit string) string {
+	switch unit {
+	case "nanoseconds":
+		return "ns"
+	case "seconds":
+		return "s"
+	case "bytes":
+		return "By"
+	case "ratio":
+		return "1"
+	case "percent":
+		return "%"
+	}
+	return unit
+}
@@ -0,0 +1,147 @@
+package otelautotune
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bpradana/autotune"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// newIngestTuner creates a tuner fed with IngestMetrics
+func newIngestTuner(t *testing.T) *autotune.Tuner {
+	t.Helper()
+	config := autotune.DefaultConfig()
+	config.ExternalMetrics = true
+	tuner, err := autotune.NewTuner(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tuner
+}
+
+// TestOTLPExporterMeterProvider tests the instruments reported through a
+// supplied meter provider
+func TestOTLPExporterMeterProvider(t *testing.T) {
+	tuner := newIngestTuner(t)
+	if err := tuner.IngestMetrics(autotune.Metrics{
+		GCPauseTime:    5 * time.Millisecond,
+		GCFrequency:    1.5,
+		MemoryPressure: 0.4,
+		CurrentGOGC:    150,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	exporter, err := NewOTLPExporter(context.Background(), tuner, OTLPConfig{MeterProvider: provider})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	if len(rm.ScopeMetrics) != 1 || rm.ScopeMetrics[0].Scope.Name != instrumentationName {
+		t.Fatalf("unexpected scopes %+v", rm.ScopeMetrics)
+	}
+
+	gauges := map[string]float64{}
+	counters := map[string]int64{}
+	units := map[string]string{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		units[m.Name] = m.Unit
+		switch data := m.Data.(type) {
+		case metricdata.Gauge[float64]:
+			gauges[m.Name] = data.DataPoints[0].Value
+		case metricdata.Sum[int64]:
+			if !data.IsMonotonic {
+				t.Errorf("%s is not monotonic", m.Name)
+			}
+			counters[m.Name] = data.DataPoints[0].Value
+		default:
+			t.Errorf("unexpected data %T for %s", m.Data, m.Name)
+		}
+	}
+
+	expectedGauges := map[string]float64{
+		"autotune_gc_pause_time_ns":        5e6,
+		"autotune_gc_frequency_per_second": 1.5,
+		"autotune_memory_pressure_ratio":   0.4,
+		"autotune_gogc_current":            150,
+	}
+	for name, want := range expectedGauges {
+		if got, ok := gauges[name]; !ok || got != want {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	for _, name := range []string{"autotune_total_decisions", "autotune_successful_tunes", "autotune_rate_limited", "autotune_reverted_tunes"} {
+		if got, ok := counters[name]; !ok || got != 0 {
+			t.Errorf("%s = %v (present %v), want 0", name, got, ok)
+		}
+	}
+	if units["autotune_gc_pause_time_ns"] != "ns" || units["autotune_memory_pressure_ratio"] != "1" {
+		t.Errorf("unexpected units %v", units)
+	}
+
+	// Shutdown unregisters but leaves a supplied provider running
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	rm = metricdata.ResourceMetrics{}
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("supplied provider was shut down: %v", err)
+	}
+}
+
+// TestOTLPExporterPush tests pushing to an OTLP/HTTP endpoint with headers
+// and resource attributes
+func TestOTLPExporterPush(t *testing.T) {
+	var requests, authorized int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/metrics" {
+			atomic.AddInt64(&requests, 1)
+		}
+		if r.Header.Get("Authorization") == "Bearer secret" {
+			atomic.AddInt64(&authorized, 1)
+		}
+	}))
+	defer server.Close()
+
+	exporter, err := NewOTLPExporter(context.Background(), newIngestTuner(t), OTLPConfig{
+		Endpoint:           server.URL + "/v1/metrics",
+		Headers:            map[string]string{"Authorization": "Bearer secret"},
+		Interval:           time.Hour,
+		ResourceAttributes: []attribute.KeyValue{attribute.String("service.name", "checkout")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Shutdown pushes the final values
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt64(&requests) != 1 || atomic.LoadInt64(&authorized) != 1 {
+		t.Errorf("got %d requests, %d authorized, want 1", requests, authorized)
+	}
+}
+
+// TestOTLPExporterValidation tests configuration errors
+func TestOTLPExporterValidation(t *testing.T) {
+	tuner := newIngestTuner(t)
+	if _, err := NewOTLPExporter(context.Background(), tuner, OTLPConfig{}); err == nil {
+		t.Error("expected an error without endpoint or meter provider")
+	}
+	if _, err := NewOTLPExporter(context.Background(), tuner, OTLPConfig{Endpoint: "localhost:4318", Interval: -time.Second}); err == nil {
+		t.Error("expected an error for a negative interval")
+	}
+}
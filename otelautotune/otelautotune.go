@@ -0,0 +1,110 @@
+// Package otelautotune emits autotune tuning cycles and decisions as
+// OpenTelemetry spans, so GC tuning activity shows up inline with
+// application traces, and exports the tuner's metrics over OTLP. It is a
+// separate module so that autotune itself doesn't depend on OpenTelemetry.
+package otelautotune
+
+import (
+	"context"
+
+	"github.com/bpradana/autotune"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// instrumentationName identifies the spans' instrumentation scope
+const instrumentationName = "github.com/bpradana/autotune/otelautotune"
+
+// Span names
+const (
+	// EvaluateSpanName is the span emitted for every tuning cycle
+	EvaluateSpanName = "autotune.evaluate"
+	// DecisionSpanName is the span emitted for every applied decision
+	DecisionSpanName = "autotune.decision"
+)
+
+// Tracer emits a span per tuning cycle with the metrics evaluated, and a
+// span per applied decision with the old and new GOGC, confidence, reason
+// and the metrics at decision time. It implements both
+// autotune.MetricsObserver and autotune.DecisionObserver.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer creates a tracer using tp (nil means a no-op provider)
+func NewTracer(tp trace.TracerProvider) *Tracer {
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	return &Tracer{tracer: tp.Tracer(instrumentationName)}
+}
+
+// SetTracerProvider registers a tracer using tp with the tuner, so every
+// tuning cycle and decision from then on is emitted as a span. Call it once
+// per tuner; observers can't be removed.
+func SetTracerProvider(tuner *autotune.Tuner, tp trace.TracerProvider) *Tracer {
+	tracer := NewTracer(tp)
+	tuner.AddMetricsObserver(tracer)
+	tuner.AddDecisionObserver(tracer)
+	return tracer
+}
+
+// OnMetrics implements autotune.MetricsObserver
+func (t *Tracer) OnMetrics(metrics autotune.Metrics) {
+	_, span := t.tracer.Start(context.Background(), EvaluateSpanName,
+		trace.WithTimestamp(metrics.Timestamp),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(metricsAttributes(metrics)...),
+	)
+	span.End(trace.WithTimestamp(metrics.Timestamp))
+}
+
+// OnDecision implements autotune.DecisionObserver
+func (t *Tracer) OnDecision(decision autotune.TuningDecision) {
+	attrs := []attribute.KeyValue{
+		attribute.Int("autotune.gogc.old", decision.OldGOGC),
+		attribute.Int("autotune.gogc.new", decision.NewGOGC),
+		attribute.Int("autotune.gogc.desired", decision.DesiredGOGC),
+		attribute.Float64("autotune.confidence", decision.Confidence),
+		attribute.String("autotune.reason", decision.Reason),
+		attribute.String("autotune.category", string(decision.Category)),
+	}
+	if decision.ClampedBy != autotune.ClampNone {
+		attrs = append(attrs, attribute.String("autotune.clamped_by", string(decision.ClampedBy)))
+	}
+	if decision.Priority != autotune.PriorityNormal {
+		attrs = append(attrs, attribute.String("autotune.priority", string(decision.Priority)))
+	}
+	if decision.Metrics != nil {
+		attrs = append(attrs, metricsAttributes(*decision.Metrics)...)
+	}
+
+	_, span := t.tracer.Start(context.Background(), DecisionSpanName,
+		trace.WithTimestamp(decision.Timestamp),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...),
+	)
+	if decision.Priority == autotune.PriorityHigh {
+		span.SetStatus(codes.Error, "GC pause ceiling exceeded")
+	}
+	span.End(trace.WithTimestamp(decision.Timestamp))
+}
+
+// metricsAttributes returns span attributes for a metrics sample
+func metricsAttributes(metrics autotune.Metrics) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.Int64("autotune.gc.pause_ns", metrics.GCPauseTime.Nanoseconds()),
+		attribute.Float64("autotune.gc.frequency", metrics.GCFrequency),
+		attribute.Int64("autotune.heap.size_bytes", int64(metrics.HeapSize)),
+		attribute.Int64("autotune.heap.alloc_bytes", int64(metrics.HeapAlloc)),
+		attribute.Int64("autotune.heap.live_bytes", int64(metrics.LiveHeap)),
+		attribute.Float64("autotune.memory.pressure", metrics.MemoryPressure),
+		attribute.Int("autotune.gogc.current", metrics.CurrentGOGC),
+	}
+	if metrics.ContainerMemLimit > 0 {
+		attrs = append(attrs, attribute.Int64("autotune.container.memory_limit_bytes", int64(metrics.ContainerMemLimit)))
+	}
+	return attrs
+}
@@ -0,0 +1,194 @@
+package otelautotune
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bpradana/autotune"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordedSpan is a span captured by recordingProvider
+type recordedSpan struct {
+	name   string
+	start  time.Time
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	ended  bool
+}
+
+// recordingProvider is a tracer provider that records the spans it creates
+type recordingProvider struct {
+	noop.TracerProvider
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (p *recordingProvider) Tracer(name string, options ...trace.TracerOption) trace.Tracer {
+	return &recordingTracer{provider: p}
+}
+
+// finished returns the spans that have ended
+func (p *recordingProvider) finished() []*recordedSpan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var spans []*recordedSpan
+	for _, span := range p.spans {
+		if span.ended {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+type recordingTracer struct {
+	noop.Tracer
+	provider *recordingProvider
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(options...)
+	span := &recordingSpan{provider: t.provider, record: &recordedSpan{
+		name:  name,
+		start: config.Timestamp(),
+		attrs: make(map[attribute.Key]attribute.Value),
+	}}
+	for _, attr := range config.Attributes() {
+		span.record.attrs[attr.Key] = attr.Value
+	}
+
+	t.provider.mu.Lock()
+	t.provider.spans = append(t.provider.spans, span.record)
+	t.provider.mu.Unlock()
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+type recordingSpan struct {
+	noop.Span
+	provider *recordingProvider
+	record   *recordedSpan
+}
+
+func (s *recordingSpan) SetStatus(code codes.Code, description string) {
+	s.provider.mu.Lock()
+	defer s.provider.mu.Unlock()
+	s.record.status = code
+}
+
+func (s *recordingSpan) End(options ...trace.SpanEndOption) {
+	s.provider.mu.Lock()
+	defer s.provider.mu.Unlock()
+	s.record.ended = true
+}
+
+// TestOnDecision tests the span emitted for a decision
+func TestOnDecision(t *testing.T) {
+	provider := &recordingProvider{}
+	tracer := NewTracer(provider)
+
+	now := time.Now()
+	tracer.OnDecision(autotune.TuningDecision{
+		OldGOGC:     100,
+		NewGOGC:     150,
+		DesiredGOGC: 180,
+		ClampedBy:   autotune.ClampRateLimit,
+		Category:    autotune.CategoryLatency,
+		Reason:      "increasing GOGC 100 -> 150",
+		Confidence:  0.8,
+		Timestamp:   now,
+		Metrics:     &autotune.Metrics{GCPauseTime: 12 * time.Millisecond, MemoryPressure: 0.4, CurrentGOGC: 100},
+	})
+
+	spans := provider.finished()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.name != DecisionSpanName {
+		t.Errorf("span name %q, want %q", span.name, DecisionSpanName)
+	}
+	if !span.start.Equal(now) {
+		t.Errorf("span start %v, want the decision time %v", span.start, now)
+	}
+
+	want := map[attribute.Key]attribute.Value{
+		"autotune.gogc.old":     attribute.IntValue(100),
+		"autotune.gogc.new":     attribute.IntValue(150),
+		"autotune.gogc.desired": attribute.IntValue(180),
+		"autotune.confidence":   attribute.Float64Value(0.8),
+		"autotune.reason":       attribute.StringValue("increasing GOGC 100 -> 150"),
+		"autotune.category":     attribute.StringValue("latency"),
+		"autotune.clamped_by":   attribute.StringValue("rate_limit"),
+		"autotune.gc.pause_ns":  attribute.Int64Value(int64(12 * time.Millisecond)),
+		"autotune.gogc.current": attribute.IntValue(100),
+	}
+	for key, value := range want {
+		if got, ok := span.attrs[key]; !ok || got != value {
+			t.Errorf("attribute %s = %v, want %v", key, got.Emit(), value.Emit())
+		}
+	}
+	if _, ok := span.attrs["autotune.priority"]; ok {
+		t.Error("normal priority decisions have no priority attribute")
+	}
+	if span.status != codes.Unset {
+		t.Errorf("span status %v, want unset", span.status)
+	}
+
+	// Emergency decisions are flagged
+	tracer.OnDecision(autotune.TuningDecision{OldGOGC: 100, NewGOGC: 150, Priority: autotune.PriorityHigh, Timestamp: now})
+	spans = provider.finished()
+	if spans[1].status != codes.Error || spans[1].attrs["autotune.priority"].AsString() != "high" {
+		t.Errorf("pause ceiling decision not flagged: status %v, attributes %v", spans[1].status, spans[1].attrs)
+	}
+}
+
+// TestSetTracerProvider tests that a tuner emits a span per cycle and per
+// decision once a tracer provider is set
+func TestSetTracerProvider(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	config := autotune.DefaultConfig()
+	config.ExternalMetrics = true
+	config.MaxPauseTime = 20 * time.Millisecond // decides on the first cycle
+	tuner, err := autotune.NewTuner(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tuner.Stop()
+
+	provider := &recordingProvider{}
+	SetTracerProvider(tuner, provider)
+
+	if err := tuner.IngestMetrics(autotune.Metrics{GCPauseTime: 50 * time.Millisecond, CurrentGOGC: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Evaluation spans are emitted asynchronously
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		names := make(map[string]int)
+		for _, span := range provider.finished() {
+			names[span.name]++
+		}
+		if names[EvaluateSpanName] == 1 && names[DecisionSpanName] == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got spans %v, want one evaluation and one decision", names)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestNilTracerProvider tests that a nil provider doesn't panic
+func TestNilTracerProvider(t *testing.T) {
+	tracer := NewTracer(nil)
+	tracer.OnMetrics(autotune.Metrics{Timestamp: time.Now()})
+	tracer.OnDecision(autotune.TuningDecision{Timestamp: time.Now()})
+}
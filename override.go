@@ -0,0 +1,107 @@
+package autotune
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetGOGC sets GOGC manually, for example from an operator command or a
+// schedule. The value must be within the tuner's bounds (the GOGC band in
+// cooperative mode). A tuning decision proposed before the call is
+// discarded rather than applied over it; later cycles tune from the new
+// value.
+func (t *Tuner) SetGOGC(gogc int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	old, err := t.setGOGCLocked(gogc)
+	if err != nil {
+		return err
+	}
+
+	t.config.Logger.Info("GOGC set manually from %d to %d", old, gogc)
+	return nil
+}
+
+// SetGOGCOverride sets GOGC like SetGOGC and pins it there for duration,
+// for example during a known batch job. Metrics are still collected while
+// the override lasts; tuning resumes at the first cycle after it expires,
+// or when Resume is called.
+func (t *Tuner) SetGOGCOverride(gogc int, duration time.Duration) error {
+	if duration <= 0 {
+		return fmt.Errorf("GOGC override duration must be positive, got %v", duration)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	old, err := t.setGOGCLocked(gogc)
+	if err != nil {
+		return err
+	}
+	t.overrideUntil = t.now().Add(duration)
+
+	t.config.Logger.Info("GOGC overridden from %d to %d for %v, tuning paused until %s",
+		old, gogc, duration, t.overrideUntil.Format(time.RFC3339))
+	return nil
+}
+
+// Pause stops the tuner from changing GOGC until Resume is called. Metrics
+// are still collected and observers notified, and a decision proposed
+// before the call is discarded.
+func (t *Tuner) Pause() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.paused {
+		return
+	}
+	t.paused = true
+	t.config.Logger.Info("GC tuning paused at GOGC %d", t.lastGOGC)
+}
+
+// Resume resumes tuning after Pause, ending a GOGC override early
+func (t *Tuner) Resume() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.paused && t.overrideUntil.IsZero() {
+		return
+	}
+	t.paused = false
+	t.overrideUntil = time.Time{}
+	t.config.Logger.Info("GC tuning resumed at GOGC %d", t.lastGOGC)
+}
+
+// setGOGCLocked sets GOGC outside the tuning loop, within the bounds, and
+// returns the previous value. Caller must hold t.mu.
+func (t *Tuner) setGOGCLocked(gogc int) (int, error) {
+	if minGOGC, maxGOGC := t.bounds(); gogc < minGOGC || gogc > maxGOGC {
+		return 0, fmt.Errorf("GOGC %d is outside the bounds [%d, %d]", gogc, minGOGC, maxGOGC)
+	}
+
+	old := t.setGCPercent(gogc)
+	t.lastGOGC = gogc
+	t.gogcGeneration++
+	t.recordCategoryLocked(CategoryManual)
+	t.resetStabilityLocked()
+	return old, nil
+}
+
+// overriddenLocked reports whether a GOGC override is in effect. Caller must
+// hold t.mu.
+func (t *Tuner) overriddenLocked() bool {
+	return !t.overrideUntil.IsZero() && t.now().Before(t.overrideUntil)
+}
+
+// tuningHeldLocked reports whether tuning is paused or GOGC overridden,
+// ending an expired override. Caller must hold t.mu for writing.
+func (t *Tuner) tuningHeldLocked() bool {
+	if !t.overrideUntil.IsZero() && !t.overriddenLocked() {
+		t.overrideUntil = time.Time{}
+		if !t.paused {
+			t.config.Logger.Info("GOGC override expired, resuming tuning at GOGC %d", t.lastGOGC)
+		}
+	}
+	return t.paused || !t.overrideUntil.IsZero()
+}
@@ -0,0 +1,162 @@
+package autotune
+
+import (
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSetGOGC tests manually setting GOGC within the bounds
+func TestSetGOGC(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	require.NoError(t, tuner.SetGOGC(300))
+	assert.Equal(t, 300, readGOGC())
+
+	assert.Error(t, tuner.SetGOGC(tuner.config.MinGOGC-1))
+	assert.Error(t, tuner.SetGOGC(tuner.config.MaxGOGC+1))
+	assert.Equal(t, 300, readGOGC())
+
+	// A manual change is not an external change
+	tuner.detectExternalGOGCChange()
+	assert.Equal(t, int64(0), tuner.GetStats()["external_changes"])
+}
+
+// TestStaleDecisionDiscarded tests that a decision proposed before a manual
+// GOGC change isn't applied over it
+func TestStaleDecisionDiscarded(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	// The filter runs between proposing and applying; a manual override
+	// lands there, as it could from another goroutine
+	tuner.SetDecisionFilter(func(proposed TuningDecision) (TuningDecision, bool) {
+		require.NoError(t, tuner.SetGOGC(250))
+		return TuningDecision{OldGOGC: proposed.OldGOGC, NewGOGC: proposed.NewGOGC, Reason: proposed.Reason}, true
+	})
+
+	tuner.mu.RLock()
+	generation := tuner.gogcGeneration
+	tuner.mu.RUnlock()
+
+	tuner.processDecision(TuningDecision{OldGOGC: 100, NewGOGC: 150, Reason: "auto", Timestamp: time.Now(), generation: generation})
+	assert.Equal(t, 250, readGOGC(), "the manual override must win")
+	stats := tuner.GetStats()
+	assert.Equal(t, int64(1), stats["stale_decisions"])
+	assert.Equal(t, int64(0), stats["total_decisions"])
+
+	// Decisions proposed after the override apply normally
+	tuner.SetDecisionFilter(nil)
+	tuner.mu.RLock()
+	generation = tuner.gogcGeneration
+	tuner.mu.RUnlock()
+	tuner.processDecision(TuningDecision{OldGOGC: 250, NewGOGC: 200, Reason: "auto", Timestamp: time.Now(), generation: generation})
+	assert.Equal(t, 200, readGOGC())
+	assert.Equal(t, int64(1), tuner.GetStats()["total_decisions"])
+
+	// Decisions without a generation, such as entering safe mode, always apply
+	require.NoError(t, tuner.SetGOGC(300))
+	tuner.processDecision(TuningDecision{OldGOGC: 300, NewGOGC: 100, Reason: "safe mode", Timestamp: time.Now()})
+	assert.Equal(t, 100, readGOGC())
+}
+
+// pausedTuner returns a tuner driven by ingested metrics and a fake clock,
+// recording the GOGC values it applies
+func pausedTuner(t *testing.T) (tuner *Tuner, now *time.Time, applied *[]int) {
+	config := DefaultConfig()
+	config.ExternalMetrics = true
+	config.Logger = &mockLogger{}
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	clock := time.Now()
+	tuner.now = func() time.Time { return clock }
+	var values []int
+	current := 100
+	tuner.setGCPercent = func(value int) int {
+		old := current
+		current = value
+		values = append(values, value)
+		return old
+	}
+	return tuner, &clock, &values
+}
+
+// ingestSlowPauses feeds samples that call for a higher GOGC
+func ingestSlowPauses(t *testing.T, tuner *Tuner, now *time.Time, n int) {
+	for i := 0; i < n; i++ {
+		*now = now.Add(time.Minute)
+		require.NoError(t, tuner.IngestMetrics(Metrics{
+			Timestamp:      *now,
+			GCPauseTime:    50 * time.Millisecond, // 5x target
+			GCFrequency:    1.0,
+			MemoryPressure: 0.5,
+			CurrentGOGC:    tuner.GetMetrics().CurrentGOGC,
+		}))
+	}
+}
+
+// TestPauseResume tests that a paused tuner keeps collecting metrics
+// without changing GOGC
+func TestPauseResume(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	tuner, now, applied := pausedTuner(t)
+
+	tuner.Pause()
+	assert.Equal(t, true, tuner.GetStats()["paused"])
+	ingestSlowPauses(t, tuner, now, 5)
+	assert.Empty(t, *applied)
+	assert.Len(t, tuner.GetMetricsHistory(), 5)
+	assert.Equal(t, int64(0), tuner.GetStats()["total_decisions"])
+
+	tuner.Resume()
+	assert.Equal(t, false, tuner.GetStats()["paused"])
+	ingestSlowPauses(t, tuner, now, 1)
+	require.NotEmpty(t, *applied)
+	assert.Greater(t, (*applied)[0], 100)
+}
+
+// TestGOGCOverride tests pinning GOGC for a window
+func TestGOGCOverride(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	tuner, now, applied := pausedTuner(t)
+
+	assert.Error(t, tuner.SetGOGCOverride(tuner.config.MaxGOGC+1, time.Hour))
+	assert.Error(t, tuner.SetGOGCOverride(tuner.config.MinGOGC-1, time.Hour))
+	assert.Error(t, tuner.SetGOGCOverride(300, 0))
+	assert.Empty(t, *applied)
+
+	require.NoError(t, tuner.SetGOGCOverride(300, 10*time.Minute))
+	assert.Equal(t, []int{300}, *applied)
+	stats := tuner.GetStats()
+	assert.Equal(t, true, stats["gogc_overridden"])
+	assert.Equal(t, false, stats["paused"])
+	assert.Equal(t, now.Add(10*time.Minute), stats["gogc_override_until"])
+
+	// Held while the override lasts
+	ingestSlowPauses(t, tuner, now, 9)
+	assert.Equal(t, []int{300}, *applied)
+
+	// Tuning resumes after it expires
+	ingestSlowPauses(t, tuner, now, 1)
+	assert.Equal(t, false, tuner.GetStats()["gogc_overridden"])
+	require.Len(t, *applied, 2)
+	assert.Greater(t, (*applied)[1], 300)
+
+	// Resume ends an override early
+	require.NoError(t, tuner.SetGOGCOverride(200, time.Hour))
+	tuner.Resume()
+	assert.Equal(t, false, tuner.GetStats()["gogc_overridden"])
+	ingestSlowPauses(t, tuner, now, 1)
+	assert.Len(t, *applied, 4)
+}
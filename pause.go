@@ -0,0 +1,228 @@
+package autotune
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PauseMetricType selects how individual GC pauses are exported to
+// Prometheus as autotune_gc_pause_seconds
+type PauseMetricType string
+
+const (
+	// PauseMetricNone doesn't export individual pauses (the default)
+	PauseMetricNone PauseMetricType = ""
+	// PauseMetricHistogram exports a histogram with fixed buckets, which can
+	// be aggregated across instances on the server
+	PauseMetricHistogram PauseMetricType = "histogram"
+	// PauseMetricSummary exports a summary with client-side quantiles over a
+	// sliding window of the most recent pauses
+	PauseMetricSummary PauseMetricType = "summary"
+)
+
+const (
+	// defaultPauseWindow is the number of pauses summary quantiles are
+	// computed over when ObservabilityConfig.PauseWindow is zero
+	defaultPauseWindow = 1024
+	// maxPausesPerSample is the size of the runtime's pause buffer, pauses
+	// beyond it are lost between samples
+	maxPausesPerSample = len(runtime.MemStats{}.PauseNs)
+)
+
+// defaultPauseObjectives are the quantiles exported when
+// ObservabilityConfig.PauseObjectives is empty
+var defaultPauseObjectives = []float64{0.5, 0.9, 0.99}
+
+// defaultPauseBuckets are the histogram upper bounds in seconds used when
+// ObservabilityConfig.PauseBuckets is empty
+var defaultPauseBuckets = []float64{
+	0.00001, 0.00005, 0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005,
+	0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1,
+}
+
+// newPauses returns the pauses of the GCs completed since the previous
+// sample, oldest first. Without a previous sample it returns every pause
+// still in the runtime's buffer.
+func newPauses(m *runtime.MemStats, prevNumGC uint32, hasPrev bool) []time.Duration {
+	count := int(m.NumGC)
+	if hasPrev && prevNumGC <= m.NumGC {
+		count = int(m.NumGC - prevNumGC)
+	}
+	if count > maxPausesPerSample {
+		count = maxPausesPerSample
+	}
+	if count <= 0 {
+		return nil
+	}
+
+	pauses := make([]time.Duration, count)
+	for i := 0; i < count; i++ {
+		// The pause of GC number n (1-based) is at PauseNs[(n-1)%256]
+		gc := int(m.NumGC) - count + i
+		pauses[i] = time.Duration(m.PauseNs[gc%maxPausesPerSample])
+	}
+	return pauses
+}
+
+// pauseWindow accumulates observed GC pauses: cumulative histogram buckets,
+// sum and count, plus a ring of the most recent pauses for quantiles
+type pauseWindow struct {
+	mu      sync.Mutex
+	ring    []time.Duration
+	next    int
+	full    bool
+	count   uint64
+	sum     time.Duration
+	bounds  []float64 // histogram upper bounds in seconds, ascending
+	buckets []uint64  // observations per bound, not cumulative
+}
+
+// newPauseWindow creates a pause window keeping the given number of recent
+// pauses and counting them into histogram buckets with the given bounds
+func newPauseWindow(size int, bounds []float64) *pauseWindow {
+	if size <= 0 {
+		size = defaultPauseWindow
+	}
+	return &pauseWindow{
+		ring:    make([]time.Duration, size),
+		bounds:  bounds,
+		buckets: make([]uint64, len(bounds)),
+	}
+}
+
+// observe records pauses
+func (pw *pauseWindow) observe(pauses []time.Duration) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	for _, pause := range pauses {
+		pw.ring[pw.next] = pause
+		pw.next = (pw.next + 1) % len(pw.ring)
+		if pw.next == 0 {
+			pw.full = true
+		}
+		pw.count++
+		pw.sum += pause
+
+		seconds := pause.Seconds()
+		if i := sort.SearchFloat64s(pw.bounds, seconds); i < len(pw.bounds) {
+			pw.buckets[i]++
+		}
+	}
+}
+
+// quantiles returns the given quantiles of the pauses in the window using
+// the nearest-rank method, NaN when the window is empty. The window is
+// sorted once per call, so the cost is bounded by the window size.
+func (pw *pauseWindow) quantiles(objectives []float64) []float64 {
+	pw.mu.Lock()
+	n := pw.next
+	if pw.full {
+		n = len(pw.ring)
+	}
+	sorted := append([]time.Duration(nil), pw.ring[:n]...)
+	pw.mu.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	values := make([]float64, len(objectives))
+	for i, q := range objectives {
+		if len(sorted) == 0 {
+			values[i] = math.NaN()
+			continue
+		}
+		rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+		if rank < 0 {
+			rank = 0
+		}
+		values[i] = sorted[rank].Seconds()
+	}
+	return values
+}
+
+// pauseObjectives returns the configured summary quantiles, skipping any
+// outside [0, 1]
+func (obs *ObservabilityServer) pauseObjectives() []float64 {
+	if len(obs.config.PauseObjectives) == 0 {
+		return defaultPauseObjectives
+	}
+
+	objectives := make([]float64, 0, len(obs.config.PauseObjectives))
+	for _, q := range obs.config.PauseObjectives {
+		if q < 0 || q > 1 || math.IsNaN(q) {
+			obs.tuner.config.Logger.Warn("Ignoring pause quantile %v outside [0, 1]", q)
+			continue
+		}
+		objectives = append(objectives, q)
+	}
+	return objectives
+}
+
+// pauseBuckets returns the configured histogram bounds in ascending order,
+// skipping any that aren't positive and finite, or the defaults without any
+func pauseBuckets(configured []float64, logger Logger) []float64 {
+	bounds := make([]float64, 0, len(configured))
+	for _, bound := range configured {
+		if bound <= 0 || math.IsNaN(bound) || math.IsInf(bound, 0) {
+			logger.Warn("Ignoring pause bucket %v, bounds must be positive seconds", bound)
+			continue
+		}
+		bounds = append(bounds, bound)
+	}
+	if len(bounds) == 0 {
+		return defaultPauseBuckets
+	}
+
+	sort.Float64s(bounds)
+	unique := bounds[:1]
+	for _, bound := range bounds[1:] {
+		if bound != unique[len(unique)-1] {
+			unique = append(unique, bound)
+		}
+	}
+	return unique
+}
+
+// writePauseMetric writes autotune_gc_pause_seconds as the configured
+// histogram or summary. It writes nothing when PauseMetricType is unset.
+func (obs *ObservabilityServer) writePauseMetric(w io.Writer, labels map[string]string) {
+	const name = "autotune_gc_pause_seconds"
+
+	metricType := obs.config.PauseMetricType
+	if metricType != PauseMetricHistogram && metricType != PauseMetricSummary {
+		return
+	}
+
+	pw := obs.pauses
+	pw.mu.Lock()
+	count, sum := pw.count, pw.sum
+	buckets := append([]uint64(nil), pw.buckets...)
+	pw.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", name, metricDescriptors[name].Help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+
+	if metricType == PauseMetricHistogram {
+		var cumulative uint64
+		for i, bound := range pw.bounds {
+			cumulative += buckets[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name, withLabel(labels, "le", strconv.FormatFloat(bound, 'g', -1, 64)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, withLabel(labels, "le", "+Inf"), count)
+	} else {
+		objectives := obs.pauseObjectives()
+		for i, value := range pw.quantiles(objectives) {
+			fmt.Fprintf(w, "%s%s %g\n", name, withLabel(labels, "quantile", strconv.FormatFloat(objectives[i], 'g', -1, 64)), value)
+		}
+	}
+
+	formatted := formatLabels(labels)
+	fmt.Fprintf(w, "%s_sum%s %g\n", name, formatted, sum.Seconds())
+	fmt.Fprintf(w, "%s_count%s %d\n", name, formatted, count)
+}
@@ -0,0 +1,141 @@
+package autotune
+
+import (
+	"math"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewPauses tests extracting the pauses since the previous sample from
+// the runtime's ring buffer
+func TestNewPauses(t *testing.T) {
+	var m runtime.MemStats
+	for i := range m.PauseNs {
+		m.PauseNs[i] = uint64(i + 1)
+	}
+
+	m.NumGC = 3
+	assert.Equal(t, []time.Duration{1, 2, 3}, newPauses(&m, 0, false))
+	assert.Equal(t, []time.Duration{3}, newPauses(&m, 2, true))
+	assert.Nil(t, newPauses(&m, 3, true))
+
+	// Wraps around the ring buffer
+	m.NumGC = 258
+	assert.Equal(t, []time.Duration{255, 256, 1, 2}, newPauses(&m, 254, true))
+
+	// Pauses that rotated out of the buffer are lost
+	assert.Len(t, newPauses(&m, 0, true), maxPausesPerSample)
+
+	// A counter going backwards (e.g. synthetic history) doesn't panic
+	m.NumGC = 2
+	assert.Equal(t, []time.Duration{1, 2}, newPauses(&m, 10, true))
+}
+
+// TestPauseWindow tests bucket counts and quantiles over a bounded window
+func TestPauseWindow(t *testing.T) {
+	pw := newPauseWindow(10, []float64{0.001, 0.01, 0.1})
+
+	assert.True(t, math.IsNaN(pw.quantiles([]float64{0.5})[0]))
+
+	var pauses []time.Duration
+	for i := 1; i <= 10; i++ {
+		pauses = append(pauses, time.Duration(i)*time.Millisecond)
+	}
+	pw.observe(pauses)
+
+	q := pw.quantiles([]float64{0, 0.5, 0.9, 1})
+	assert.Equal(t, []float64{0.001, 0.005, 0.009, 0.01}, q)
+
+	// The window only keeps the most recent pauses, count and sum don't reset
+	pw.observe([]time.Duration{time.Second, time.Second, time.Second, time.Second, time.Second, time.Second})
+	assert.Equal(t, []float64{0.009, 1}, pw.quantiles([]float64{0.3, 0.5}))
+	assert.Equal(t, uint64(16), pw.count)
+	assert.InDelta(t, 6.055, pw.sum.Seconds(), 1e-9)
+	assert.Len(t, pw.ring, 10)
+
+	// 1s pauses exceed every bucket bound and only count towards +Inf
+	var bucketed uint64
+	for _, n := range pw.buckets {
+		bucketed += n
+	}
+	assert.Equal(t, uint64(10), bucketed)
+}
+
+// TestPauseMetricExport tests exporting pauses as a histogram or summary
+func TestPauseMetricExport(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	scrape := func(obs *ObservabilityServer) string {
+		w := httptest.NewRecorder()
+		obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics?format=prometheus", nil))
+		return w.Body.String()
+	}
+	pauses := []time.Duration{200 * time.Microsecond, 2 * time.Millisecond, 20 * time.Millisecond}
+
+	// Not exported by default
+	obs := NewObservabilityServer(DefaultObservabilityConfig(), tuner)
+	obs.recordMetrics(Metrics{Pauses: pauses})
+	assert.NotContains(t, scrape(obs), "autotune_gc_pause_seconds")
+
+	obsConfig := DefaultObservabilityConfig()
+	obsConfig.PauseMetricType = PauseMetricHistogram
+	obsConfig.ConstLabels = map[string]string{"service": "api"}
+	obs = NewObservabilityServer(obsConfig, tuner)
+	obs.recordMetrics(Metrics{Pauses: pauses})
+	output := scrape(obs)
+	assert.Contains(t, output, "# TYPE autotune_gc_pause_seconds histogram\n")
+	assert.Contains(t, output, `autotune_gc_pause_seconds_bucket{le="0.0001",service="api"} 0`+"\n")
+	assert.Contains(t, output, `autotune_gc_pause_seconds_bucket{le="0.00025",service="api"} 1`+"\n")
+	assert.Contains(t, output, `autotune_gc_pause_seconds_bucket{le="0.01",service="api"} 2`+"\n")
+	assert.Contains(t, output, `autotune_gc_pause_seconds_bucket{le="+Inf",service="api"} 3`+"\n")
+	assert.Contains(t, output, `autotune_gc_pause_seconds_sum{service="api"} 0.0222`+"\n")
+	assert.Contains(t, output, `autotune_gc_pause_seconds_count{service="api"} 3`+"\n")
+
+	// The pauses aren't kept in the metrics history
+	assert.Nil(t, obs.metricsHistory[0].Metrics.Pauses)
+
+	obsConfig = DefaultObservabilityConfig()
+	obsConfig.PauseMetricType = PauseMetricSummary
+	obsConfig.PauseObjectives = []float64{0.5, 0.99, 2}
+	obs = NewObservabilityServer(obsConfig, tuner)
+	obs.recordMetrics(Metrics{Pauses: pauses})
+	output = scrape(obs)
+	assert.Contains(t, output, "# TYPE autotune_gc_pause_seconds summary\n")
+	assert.Contains(t, output, `autotune_gc_pause_seconds{quantile="0.5"} 0.002`+"\n")
+	assert.Contains(t, output, `autotune_gc_pause_seconds{quantile="0.99"} 0.02`+"\n")
+	assert.NotContains(t, output, `quantile="2"`)
+	assert.Contains(t, output, "autotune_gc_pause_seconds_count 3\n")
+}
+
+// TestPauseBuckets tests configuring the pause histogram buckets
+func TestPauseBuckets(t *testing.T) {
+	logger := &mockLogger{}
+	assert.Equal(t, defaultPauseBuckets, pauseBuckets(nil, logger))
+	assert.Equal(t, 1.0, defaultPauseBuckets[len(defaultPauseBuckets)-1])
+
+	// Sorted and deduplicated, invalid bounds are skipped
+	assert.Equal(t, []float64{0.001, 0.01}, pauseBuckets([]float64{0.01, -1, 0.001, math.NaN(), 0.01}, logger))
+	assert.Equal(t, defaultPauseBuckets, pauseBuckets([]float64{0}, logger))
+
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+	obsConfig := DefaultObservabilityConfig()
+	obsConfig.PauseMetricType = PauseMetricHistogram
+	obsConfig.PauseBuckets = []float64{0.5, 0.005}
+	obs := NewObservabilityServer(obsConfig, tuner)
+	obs.recordMetrics(Metrics{Pauses: []time.Duration{time.Millisecond, 100 * time.Millisecond, 2 * time.Second}})
+
+	w := httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics?format=prometheus", nil))
+	output := w.Body.String()
+	assert.Contains(t, output, `autotune_gc_pause_seconds_bucket{le="0.005"} 1`+"\n")
+	assert.Contains(t, output, `autotune_gc_pause_seconds_bucket{le="0.5"} 2`+"\n")
+	assert.Contains(t, output, `autotune_gc_pause_seconds_bucket{le="+Inf"} 3`+"\n")
+	assert.NotContains(t, output, `le="0.0001"`)
+}
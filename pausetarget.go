@@ -0,0 +1,59 @@
+package autotune
+
+import (
+	"runtime"
+	"sort"
+	"time"
+)
+
+// PauseStatistic selects which GC pause statistic is compared with
+// TargetLatency, see Config.PauseTarget
+type PauseStatistic string
+
+const (
+	// PauseStatisticAverage is the average of the last 10 pauses (the
+	// default)
+	PauseStatisticAverage PauseStatistic = "avg"
+	// PauseStatisticP95 is the 95th percentile of recent pauses
+	PauseStatisticP95 PauseStatistic = "p95"
+	// PauseStatisticP99 is the 99th percentile of recent pauses
+	PauseStatisticP99 PauseStatistic = "p99"
+)
+
+// Pause returns the GC pause statistic s of the metrics. A percentile that
+// wasn't recorded, as in ingested metrics without it, falls back to
+// GCPauseTime.
+func (m Metrics) Pause(s PauseStatistic) time.Duration {
+	switch {
+	case s == PauseStatisticP95 && m.GCPauseP95 > 0:
+		return m.GCPauseP95
+	case s == PauseStatisticP99 && m.GCPauseP99 > 0:
+		return m.GCPauseP99
+	}
+	return m.GCPauseTime
+}
+
+// tuningPause returns the pause statistic compared with TargetLatency
+func (t *Tuner) tuningPause(metrics Metrics) time.Duration {
+	return metrics.Pause(t.config.PauseTarget)
+}
+
+// recentPausePercentiles returns the 95th and 99th percentile of the pauses
+// in the MemStats ring buffer, which holds the last 256 GCs, using the
+// nearest-rank method
+func recentPausePercentiles(m *runtime.MemStats) (p95, p99 time.Duration) {
+	count := int(m.NumGC)
+	if count > len(m.PauseNs) {
+		count = len(m.PauseNs)
+	}
+	if count == 0 {
+		return 0, 0
+	}
+
+	pauses := make([]int, count)
+	for i := 0; i < count; i++ {
+		pauses[i] = int(m.PauseNs[(int(m.NumGC)-1-i+len(m.PauseNs))%len(m.PauseNs)])
+	}
+	sort.Ints(pauses)
+	return time.Duration(percentileInt(pauses, 0.95)), time.Duration(percentileInt(pauses, 0.99))
+}
@@ -0,0 +1,102 @@
+package autotune
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecentPausePercentiles tests computing pause percentiles from the
+// MemStats ring buffer
+func TestRecentPausePercentiles(t *testing.T) {
+	var m runtime.MemStats
+	p95, p99 := recentPausePercentiles(&m)
+	assert.Zero(t, p95)
+	assert.Zero(t, p99)
+
+	// 100 GCs of 1ms with two 200ms spikes
+	m.NumGC = 100
+	for i := 0; i < 100; i++ {
+		m.PauseNs[i] = uint64(time.Millisecond)
+	}
+	m.PauseNs[10] = uint64(200 * time.Millisecond)
+	m.PauseNs[70] = uint64(200 * time.Millisecond)
+	p95, p99 = recentPausePercentiles(&m)
+	assert.Equal(t, time.Millisecond, p95)
+	assert.Equal(t, 200*time.Millisecond, p99)
+
+	// Once the ring has wrapped, all 256 entries count
+	m.NumGC = 1000
+	for i := range m.PauseNs {
+		m.PauseNs[i] = uint64(time.Millisecond)
+	}
+	for i := 0; i < 13; i++ {
+		m.PauseNs[i*7] = uint64(50 * time.Millisecond)
+	}
+	p95, p99 = recentPausePercentiles(&m)
+	assert.Equal(t, 50*time.Millisecond, p95)
+	assert.Equal(t, 50*time.Millisecond, p99)
+}
+
+// TestMetricsPause tests selecting a pause statistic
+func TestMetricsPause(t *testing.T) {
+	metrics := Metrics{GCPauseTime: time.Millisecond, GCPauseP95: 5 * time.Millisecond, GCPauseP99: 20 * time.Millisecond}
+	assert.Equal(t, time.Millisecond, metrics.Pause(""))
+	assert.Equal(t, time.Millisecond, metrics.Pause(PauseStatisticAverage))
+	assert.Equal(t, 5*time.Millisecond, metrics.Pause(PauseStatisticP95))
+	assert.Equal(t, 20*time.Millisecond, metrics.Pause(PauseStatisticP99))
+
+	// Metrics without percentiles fall back to the average
+	assert.Equal(t, time.Millisecond, Metrics{GCPauseTime: time.Millisecond}.Pause(PauseStatisticP99))
+
+	config := DefaultConfig()
+	config.PauseTarget = "p50"
+	_, err := NewTuner(config)
+	assert.Error(t, err)
+}
+
+// TestPauseTarget tests that with a percentile pause target tail pauses
+// drive GOGC up even when the average is below the target latency
+func TestPauseTarget(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	sample := Metrics{
+		Timestamp:      time.Now(),
+		GCPauseTime:    2 * time.Millisecond,
+		GCPauseP95:     4 * time.Millisecond,
+		GCPauseP99:     40 * time.Millisecond,
+		MemoryPressure: 0.5,
+		CurrentGOGC:    100,
+	}
+
+	decide := func(target PauseStatistic) *TuningDecision {
+		config := DefaultConfig()
+		config.ExternalMetrics = true
+		config.Logger = &mockLogger{}
+		config.PauseTarget = target
+		config.TuningAggressiveness = 1
+		config.FactorSmoothingAlpha = 1
+		tuner, err := NewTuner(config)
+		require.NoError(t, err)
+		tuner.setGCPercent = func(value int) int { return 100 }
+		tuner.qosClass = QoSClassUnknown
+		tuner.metricsHistory.reset([]Metrics{sample, sample})
+
+		decision, _ := tuner.proposeTuningDecision(sample)
+		return decision
+	}
+
+	// The average is well below the target, so nothing pushes GOGC up
+	if decision := decide(PauseStatisticAverage); decision != nil {
+		assert.Less(t, decision.NewGOGC, 100)
+	}
+
+	decision := decide(PauseStatisticP99)
+	require.NotNil(t, decision)
+	assert.Greater(t, decision.NewGOGC, 100)
+	assert.Contains(t, decision.Reason, "GC pause p99 40.00ms > target 10.00ms")
+}
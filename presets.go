@@ -0,0 +1,87 @@
+package autotune
+
+import "time"
+
+// LatencySensitiveConfig returns a configuration for request-serving workloads
+// where tail latency matters more than memory or CPU efficiency.
+//
+// The target pause is low so the latency factor dominates, and the floor is
+// kept at the Go default of 100 so the tuner never trades latency for memory
+// by collecting more often than an untuned process. Aggressiveness and the
+// per-interval step are small and the stabilization window is long, because
+// a GOGC swing that causes a burst of GC work is itself a latency event.
+func LatencySensitiveConfig() *Config {
+	config := DefaultConfig()
+	config.MonitorInterval = 15 * time.Second
+	config.MinGOGC = 100
+	config.MaxGOGC = 400
+	config.TargetLatency = 2 * time.Millisecond
+	config.TuningAggressiveness = 0.2
+	config.StabilizationWindow = 10 * time.Minute
+	config.MaxChangePerInterval = 25
+	return config
+}
+
+// ThroughputConfig returns a configuration for services that care about
+// aggregate throughput and can tolerate longer individual pauses.
+//
+// A high ceiling lets the tuner trade memory for fewer GC cycles, which is
+// where most of the CPU savings come from. The relaxed pause target keeps
+// the latency factor from pulling GOGC back down, and larger steps with a
+// shorter stabilization window let it follow load changes quickly.
+func ThroughputConfig() *Config {
+	config := DefaultConfig()
+	config.MinGOGC = 100
+	config.MaxGOGC = 1200
+	config.TargetLatency = 25 * time.Millisecond
+	config.MemoryLimitPercent = 0.85
+	config.TuningAggressiveness = 0.5
+	config.StabilizationWindow = 3 * time.Minute
+	config.MaxChangePerInterval = 100
+	return config
+}
+
+// MemoryConstrainedConfig returns a configuration for containers running
+// close to their memory limit, where an OOM kill is the worst outcome.
+//
+// The floor is allowed below the Go default so the tuner can collect more
+// often under pressure, the ceiling stays low so heap growth between cycles
+// is bounded, and the memory threshold leaves 30% of the limit for non-heap
+// memory such as stacks and runtime overhead. A short interval notices
+// pressure changes before they turn into an OOM.
+func MemoryConstrainedConfig() *Config {
+	config := DefaultConfig()
+	config.MonitorInterval = 15 * time.Second
+	config.MinGOGC = 25
+	config.MaxGOGC = 200
+	config.MemoryLimitPercent = 0.7
+	config.TuningAggressiveness = 0.4
+	config.MaxChangePerInterval = 25
+	return config
+}
+
+// BatchConfig returns a configuration for batch and offline jobs where
+// total runtime matters and pause times are irrelevant.
+//
+// GC is turned off once the soft memory limit is in place, so the job only
+// collects when the heap nears the container limit. Memory pressure above
+// the threshold turns GC back on at the ceiling, the largest value
+// validateConfig allows, with the soft limit still making the runtime
+// collect harder as the heap approaches it. Without a container memory limit
+// GC stays on and is tuned up to that ceiling. The pause target is set high
+// enough that it never drives decisions, and the tuner moves in big steps at
+// a relaxed interval since batch phases change slowly.
+func BatchConfig() *Config {
+	config := DefaultConfig()
+	config.MonitorInterval = time.Minute
+	config.MinGOGC = 100
+	config.MaxGOGC = 2000
+	config.TargetLatency = 100 * time.Millisecond
+	config.MemoryLimitPercent = 0.9
+	config.EnableMemoryLimit = true
+	config.DisableGC = true
+	config.TuningAggressiveness = 0.8
+	config.StabilizationWindow = 2 * time.Minute
+	config.MaxChangePerInterval = 200
+	return config
+}
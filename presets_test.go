@@ -0,0 +1,64 @@
+package autotune
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPresetsValidate tests that every preset passes validation
+func TestPresetsValidate(t *testing.T) {
+	presets := map[string]func() *Config{
+		"latency_sensitive":  LatencySensitiveConfig,
+		"throughput":         ThroughputConfig,
+		"memory_constrained": MemoryConstrainedConfig,
+		"batch":              BatchConfig,
+	}
+
+	for name, preset := range presets {
+		t.Run(name, func(t *testing.T) {
+			config := preset()
+			require.NotNil(t, config)
+			assert.NoError(t, validateConfig(config))
+			assert.NotNil(t, config.Logger)
+
+			tuner, err := NewTuner(config)
+			require.NoError(t, err)
+			assert.NotNil(t, tuner)
+		})
+	}
+}
+
+// TestPresetCharacteristics tests the defining traits of each preset
+func TestPresetCharacteristics(t *testing.T) {
+	defaults := DefaultConfig()
+
+	latency := LatencySensitiveConfig()
+	assert.Less(t, latency.TargetLatency, defaults.TargetLatency)
+	assert.Less(t, latency.TuningAggressiveness, defaults.TuningAggressiveness)
+	assert.Less(t, latency.MaxGOGC-latency.MinGOGC, defaults.MaxGOGC-defaults.MinGOGC)
+
+	throughput := ThroughputConfig()
+	assert.Greater(t, throughput.MaxGOGC, defaults.MaxGOGC)
+	assert.Greater(t, throughput.TargetLatency, defaults.TargetLatency)
+
+	memory := MemoryConstrainedConfig()
+	assert.Less(t, memory.MinGOGC, defaults.MinGOGC)
+	assert.Less(t, memory.MaxGOGC, defaults.MaxGOGC)
+	assert.Less(t, memory.MemoryLimitPercent, defaults.MemoryLimitPercent)
+
+	batch := BatchConfig()
+	assert.Equal(t, 2000, batch.MaxGOGC)
+	assert.Equal(t, time.Minute, batch.MonitorInterval)
+	assert.True(t, batch.DisableGC)
+
+	// GC may only be off with the soft memory limit bounding the heap
+	batch.EnableMemoryLimit = false
+	assert.Error(t, validateConfig(batch))
+
+	// Presets return independent copies
+	latency.MinGOGC = 500
+	assert.Equal(t, 100, LatencySensitiveConfig().MinGOGC)
+}
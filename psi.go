@@ -0,0 +1,108 @@
+package autotune
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// psiStallThreshold is the memory PSI some avg10 percentage at which the
+	// tuner treats memory as under pressure
+	psiStallThreshold = 10.0
+	// psiMaxReduction is the largest fraction GOGC is reduced by in one
+	// cycle because of memory stalls
+	psiMaxReduction = 0.5
+)
+
+// PSIStats holds pressure stall information for one resource, as reported by
+// cgroup v2 in files such as memory.pressure. Averages are the percentage of
+// wall time (0-100) over the last 10 and 60 seconds in which some or all
+// non-idle tasks were stalled waiting for the resource.
+type PSIStats struct {
+	SomeAvg10 float64 `json:"some_avg10"`
+	SomeAvg60 float64 `json:"some_avg60"`
+	FullAvg10 float64 `json:"full_avg10"`
+	FullAvg60 float64 `json:"full_avg60"`
+}
+
+// parsePSI parses the contents of a PSI file:
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//
+// The full line is absent for CPU pressure on older kernels and its averages
+// are then left at zero.
+func parsePSI(content string) (*PSIStats, error) {
+	stats := &PSIStats{}
+	found := false
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		var avg10, avg60 *float64
+		switch fields[0] {
+		case "some":
+			avg10, avg60 = &stats.SomeAvg10, &stats.SomeAvg60
+		case "full":
+			avg10, avg60 = &stats.FullAvg10, &stats.FullAvg60
+		default:
+			continue
+		}
+
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+
+			var target *float64
+			switch key {
+			case "avg10":
+				target = avg10
+			case "avg60":
+				target = avg60
+			default:
+				continue
+			}
+
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid PSI value %q: %w", field, err)
+			}
+			*target = parsed
+		}
+		found = true
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no PSI data found")
+	}
+	return stats, nil
+}
+
+// psiReductionFactor returns the factor GOGC is multiplied by for a given
+// memory PSI: stalling 10% of the time reduces GOGC by 20%, and stalling 25%
+// or more halves it
+func psiReductionFactor(psi float64) float64 {
+	reduction := psi / 50
+	if reduction > psiMaxReduction {
+		reduction = psiMaxReduction
+	}
+	return 1 - reduction
+}
+
+// readCgroupV2PSI reads pressure stall information for a resource ("memory",
+// "cpu" or "io") from cgroup v2
+func readCgroupV2PSI(cg cgroupV2Paths, resource string) (*PSIStats, error) {
+	data, err := cg.readFile(resource + ".pressure")
+	if err != nil {
+		return nil, err
+	}
+	return parsePSI(string(data))
+}
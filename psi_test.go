@@ -0,0 +1,109 @@
+package autotune
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParsePSI tests parsing of cgroup v2 pressure stall files
+func TestParsePSI(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected *PSIStats
+		wantErr  bool
+	}{
+		{
+			name: "memory pressure",
+			content: "some avg10=12.50 avg60=4.20 avg300=1.05 total=123456\n" +
+				"full avg10=3.75 avg60=1.10 avg300=0.25 total=45678\n",
+			expected: &PSIStats{SomeAvg10: 12.5, SomeAvg60: 4.2, FullAvg10: 3.75, FullAvg60: 1.1},
+		},
+		{
+			name:     "no full line",
+			content:  "some avg10=0.35 avg60=0.10 avg300=0.00 total=8123\n",
+			expected: &PSIStats{SomeAvg10: 0.35, SomeAvg60: 0.1},
+		},
+		{
+			name:    "invalid value",
+			content: "some avg10=abc avg60=0.00 avg300=0.00 total=0\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			content: "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := parsePSI(tt.content)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, stats)
+		})
+	}
+}
+
+// TestContainerStatsPSI tests exposing PSI on container stats
+func TestContainerStatsPSI(t *testing.T) {
+	originalReadFile := readFile
+	defer func() { readFile = originalReadFile }()
+
+	readFile = func(path string) ([]byte, error) {
+		switch path {
+		case "/sys/fs/cgroup/memory.pressure":
+			return []byte("some avg10=12.50 avg60=4.20 avg300=1.05 total=123456\n" +
+				"full avg10=3.75 avg60=1.10 avg300=0.25 total=45678\n"), nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	stats, err := GetContainerStats()
+	require.NoError(t, err)
+	require.NotNil(t, stats.MemoryPSI)
+	assert.Equal(t, 12.5, stats.MemoryPSI.SomeAvg10)
+	assert.Nil(t, stats.CPUPSI)
+
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+	metrics := tuner.GetMetrics()
+	assert.True(t, metrics.MemoryPSIAvailable)
+	assert.Equal(t, 12.5, metrics.MemoryPSI)
+}
+
+// TestMemoryPSITuning tests memory PSI as the authoritative pressure input
+func TestMemoryPSITuning(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	base := Metrics{CurrentGOGC: 200, GCPauseTime: tuner.config.TargetLatency, GCFrequency: 1}
+
+	// Stalls reduce GOGC promptly, regardless of the usage ratio
+	stalled := base
+	stalled.MemoryPressure = 0.2
+	stalled.MemoryPSIAvailable = true
+	stalled.MemoryPSI = 25
+	assert.Equal(t, 100, tuner.calculateTargetGOGC(stalled))
+
+	stalled.MemoryPSI = 10
+	assert.Equal(t, 160, tuner.calculateTargetGOGC(stalled))
+
+	// Without stalls a high usage ratio no longer lowers GOGC
+	highRatio := base
+	highRatio.MemoryPressure = 0.95
+	assert.Less(t, tuner.calculateTargetGOGC(highRatio), 200)
+	highRatio.MemoryPSIAvailable = true
+	highRatio.MemoryPSI = 0.5
+	assert.Equal(t, 200, tuner.calculateTargetGOGC(highRatio))
+
+	reason := tuner.buildReasonString(stalled, 200, 160)
+	assert.Contains(t, reason, "Memory stalls 10.0% (PSI)")
+}
@@ -0,0 +1,267 @@
+package autotune
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// backoff computes jittered exponential delays between retries of a failing
+// operation. Each failure doubles the delay up to max; the returned delay is
+// drawn from the upper half of the current window so that replicas pushing
+// to the same collector don't retry in lockstep.
+type backoff struct {
+	base    time.Duration
+	max     time.Duration
+	attempt int
+	rand    *rand.Rand
+}
+
+// newBackoff creates a backoff starting at base and capped at max, drawing
+// jitter from rng (nil means a time-seeded source, created on first use)
+func newBackoff(base, max time.Duration, rng *rand.Rand) *backoff {
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	if max < base {
+		max = base
+	}
+	return &backoff{base: base, max: max, rand: rng}
+}
+
+// next returns the delay before the next retry and advances the backoff
+func (b *backoff) next() time.Duration {
+	window := b.window()
+	b.attempt++
+
+	if b.rand == nil {
+		b.rand = newRand(0)
+	}
+	half := int64(window / 2)
+	return time.Duration(half + b.rand.Int63n(half+1))
+}
+
+// window returns the un-jittered delay for the current attempt
+func (b *backoff) window() time.Duration {
+	window := b.base
+	for i := 0; i < b.attempt && window < b.max; i++ {
+		window *= 2
+	}
+	if window > b.max {
+		window = b.max
+	}
+	return window
+}
+
+// reset returns the backoff to its initial delay after a success
+func (b *backoff) reset() {
+	b.attempt = 0
+}
+
+// permanentPushError marks a push failure that retrying won't fix, such as
+// a rejected request; the payload is dropped without further attempts
+type permanentPushError struct {
+	err error
+}
+
+func (e *permanentPushError) Error() string { return e.err.Error() }
+
+func (e *permanentPushError) Unwrap() error { return e.err }
+
+// pushQueue delivers payloads to a push-based sink from a background worker.
+//
+// The queue is bounded: while the sink is failing the worker sleeps for a
+// jittered exponential backoff, and payloads enqueued in the meantime are
+// dropped and counted once the queue is full. Enqueueing never blocks, so a
+// flapping collector can't stall the tuner or grow memory without bound.
+type pushQueue struct {
+	name        string
+	push        func(payload []byte) error
+	maxAttempts int
+	logger      Logger
+
+	queue   chan []byte
+	backoff *backoff
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+	stopped sync.Once
+
+	pushed         int64
+	failed         int64
+	dropped        int64
+	currentBackoff int64
+}
+
+// newPushQueue creates a push queue holding at most size pending payloads.
+// Each payload is attempted up to maxAttempts times before it is dropped.
+// Backoff jitter is drawn from rng, see Tuner.newRand.
+func newPushQueue(name string, size, maxAttempts int, push func([]byte) error, logger Logger, rng *rand.Rand) *pushQueue {
+	if size <= 0 {
+		size = 100
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	if logger == nil {
+		logger = &defaultLogger{}
+	}
+
+	return &pushQueue{
+		name:        name,
+		push:        push,
+		maxAttempts: maxAttempts,
+		logger:      logger,
+		queue:       make(chan []byte, size),
+		backoff:     newBackoff(100*time.Millisecond, 30*time.Second, rng),
+		done:        make(chan struct{}),
+	}
+}
+
+// start launches the delivery worker; calling it more than once is a no-op
+func (q *pushQueue) start() {
+	q.once.Do(func() {
+		q.wg.Add(1)
+		go q.run()
+	})
+}
+
+// stop stops the delivery worker and waits for it to exit. Payloads still
+// queued are discarded.
+func (q *pushQueue) stop() {
+	q.stopped.Do(func() {
+		close(q.done)
+	})
+	q.wg.Wait()
+}
+
+// enqueue queues a payload for delivery without blocking. It returns false
+// and counts the payload as dropped when the queue is full.
+func (q *pushQueue) enqueue(payload []byte) bool {
+	select {
+	case q.queue <- payload:
+		return true
+	default:
+		atomic.AddInt64(&q.dropped, 1)
+		return false
+	}
+}
+
+// stats returns delivery counters for inclusion in exporter stats
+func (q *pushQueue) stats() map[string]interface{} {
+	return map[string]interface{}{
+		"pushed":          atomic.LoadInt64(&q.pushed),
+		"failed":          atomic.LoadInt64(&q.failed),
+		"dropped":         atomic.LoadInt64(&q.dropped),
+		"queued":          len(q.queue),
+		"current_backoff": time.Duration(atomic.LoadInt64(&q.currentBackoff)),
+	}
+}
+
+// run delivers queued payloads until stopped
+func (q *pushQueue) run() {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-q.done:
+			return
+		case payload := <-q.queue:
+			if !q.deliver(payload) {
+				return
+			}
+		}
+	}
+}
+
+// deliver pushes one payload, backing off between failed attempts. It
+// returns false if the queue was stopped while waiting.
+func (q *pushQueue) deliver(payload []byte) bool {
+	for attempt := 1; ; attempt++ {
+		err := q.push(payload)
+		if err == nil {
+			atomic.AddInt64(&q.pushed, 1)
+			q.backoff.reset()
+			atomic.StoreInt64(&q.currentBackoff, 0)
+			return true
+		}
+
+		atomic.AddInt64(&q.failed, 1)
+		var permanent *permanentPushError
+		if errors.As(err, &permanent) {
+			atomic.AddInt64(&q.dropped, 1)
+			q.logger.Warn("%s push rejected, dropping sample: %v", q.name, err)
+			return true
+		}
+		if attempt >= q.maxAttempts {
+			atomic.AddInt64(&q.dropped, 1)
+			q.logger.Warn("%s push failed after %d attempts, dropping sample: %v", q.name, attempt, err)
+		}
+
+		delay := q.backoff.next()
+		atomic.StoreInt64(&q.currentBackoff, int64(delay))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-q.done:
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+
+		if attempt >= q.maxAttempts {
+			return true
+		}
+	}
+}
+
+// pushMetric is a metric value rendered for the push-based exporters
+type pushMetric struct {
+	name  string
+	value string
+}
+
+// pushMetrics returns the metrics exported by the push-based exporters
+// (Graphite and StatsD), in export order, named without the autotune_
+// prefix. Custom metrics are named custom.<name>.
+func pushMetrics(metrics Metrics, stats map[string]interface{}) []pushMetric {
+	var out []pushMetric
+	add := func(name string, value interface{}) {
+		out = append(out, pushMetric{name: name, value: fmt.Sprint(value)})
+	}
+	float := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	add("running", boolToInt(stats["running"].(bool)))
+	add("gc_pause_ns", metrics.GCPauseTime.Nanoseconds())
+	add("gc_frequency_per_second", float(metrics.GCFrequency))
+	add("heap_size_bytes", metrics.HeapSize)
+	add("heap_alloc_bytes", metrics.HeapAlloc)
+	add("heap_fragmentation_ratio", float(metrics.HeapFragmentation))
+	add("memory_pressure_ratio", float(metrics.MemoryPressure))
+	add("memory_pressure_raw_ratio", float(metrics.MemoryPressureRaw))
+	add("gogc_current", metrics.CurrentGOGC)
+	add("gogc_target", stats["desired_gogc"])
+	add("total_decisions", stats["total_decisions"])
+	add("successful_tunes", stats["successful_tunes"])
+	add("reverted_tunes", stats["reverted_tunes"])
+	add("health_score", float(stats["health_score"].(float64)))
+
+	if metrics.ContainerMemLimit > 0 {
+		add("container_memory_limit_bytes", metrics.ContainerMemLimit)
+	}
+	if metrics.ContainerCPULimit > 0 {
+		add("container_cpu_limit_cores", float(metrics.ContainerCPULimit))
+	}
+
+	for _, name := range customMetricNames(metrics.Custom) {
+		add("custom."+name, float(metrics.Custom[name]))
+	}
+
+	return out
+}
@@ -0,0 +1,114 @@
+package autotune
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBackoff tests jittered exponential backoff growth and reset
+func TestBackoff(t *testing.T) {
+	b := newBackoff(10*time.Millisecond, 80*time.Millisecond, nil)
+
+	expected := []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		40 * time.Millisecond,
+		80 * time.Millisecond,
+		80 * time.Millisecond, // Capped at max
+	}
+
+	for _, window := range expected {
+		assert.Equal(t, window, b.window())
+		delay := b.next()
+		assert.GreaterOrEqual(t, delay, window/2)
+		assert.LessOrEqual(t, delay, window)
+	}
+
+	b.reset()
+	assert.Equal(t, 10*time.Millisecond, b.window())
+}
+
+// TestPushQueueFailingEndpoint tests that a failing endpoint backs off and drops samples
+func TestPushQueueFailingEndpoint(t *testing.T) {
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	push := func(payload []byte) error {
+		resp, err := http.Post(server.URL, "text/plain", bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("server returned %d", resp.StatusCode)
+		}
+		return nil
+	}
+
+	q := newPushQueue("test", 5, 1, push, &mockLogger{}, nil)
+	q.backoff = newBackoff(time.Millisecond, 40*time.Millisecond, nil)
+	q.start()
+	defer q.stop()
+
+	// Keep producing samples much faster than the failing endpoint drains them
+	var backoffs []time.Duration
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && atomic.LoadInt64(&requests) < 6 {
+		q.enqueue([]byte("sample"))
+		if current := q.stats()["current_backoff"].(time.Duration); current > 0 {
+			if len(backoffs) == 0 || backoffs[len(backoffs)-1] != current {
+				backoffs = append(backoffs, current)
+			}
+		}
+		time.Sleep(100 * time.Microsecond)
+	}
+
+	stats := q.stats()
+	require.GreaterOrEqual(t, atomic.LoadInt64(&requests), int64(6))
+	assert.Greater(t, stats["failed"].(int64), int64(0))
+	assert.Greater(t, stats["dropped"].(int64), int64(0))
+	assert.LessOrEqual(t, stats["queued"].(int), 5)
+	assert.Equal(t, int64(0), stats["pushed"].(int64))
+
+	// Later backoffs are longer than the first one
+	require.GreaterOrEqual(t, len(backoffs), 2)
+	assert.Greater(t, backoffs[len(backoffs)-1], backoffs[0])
+}
+
+// TestPushQueueRetriesThenSucceeds tests that a payload is retried up to maxAttempts
+func TestPushQueueRetriesThenSucceeds(t *testing.T) {
+	var attempts int64
+	push := func(payload []byte) error {
+		if atomic.AddInt64(&attempts, 1) < 3 {
+			return fmt.Errorf("transient failure")
+		}
+		return nil
+	}
+
+	q := newPushQueue("test", 5, 3, push, &mockLogger{}, nil)
+	q.backoff = newBackoff(time.Millisecond, 5*time.Millisecond, nil)
+	q.start()
+	defer q.stop()
+
+	assert.True(t, q.enqueue([]byte("alert")))
+	assert.Eventually(t, func() bool {
+		return q.stats()["pushed"].(int64) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	stats := q.stats()
+	assert.Equal(t, int64(2), stats["failed"].(int64))
+	assert.Equal(t, int64(0), stats["dropped"].(int64))
+	assert.Equal(t, time.Duration(0), stats["current_backoff"].(time.Duration))
+}
@@ -0,0 +1,65 @@
+package autotune
+
+const (
+	// defaultMaxAggressivenessBoost is the aggressiveness boost cap when
+	// Config.MaxAggressivenessBoost is zero
+	defaultMaxAggressivenessBoost = 2.0
+	// aggressivenessRampStep is the boost added for every consecutive decision
+	// in the same direction after the first
+	aggressivenessRampStep = 0.25
+)
+
+// aggressivenessBoost returns the multiplier applied to the configured
+// aggressiveness. Consecutive decisions in the same direction mean the tuner
+// keeps undershooting, typically after a regime change, so each one ramps the
+// boost up to the configured cap. Stable cycles decay it again.
+func (t *Tuner) aggressivenessBoost() float64 {
+	if t.sameDirectionStreak <= 1 {
+		return 1
+	}
+
+	maxBoost := t.config.MaxAggressivenessBoost
+	if maxBoost == 0 {
+		maxBoost = defaultMaxAggressivenessBoost
+	}
+
+	boost := 1 + aggressivenessRampStep*float64(t.sameDirectionStreak-1)
+	if boost > maxBoost {
+		boost = maxBoost
+	}
+	return boost
+}
+
+// recordDecisionDirectionLocked tracks consecutive decisions moving GOGC in
+// the same direction. t.mu must be held for writing.
+func (t *Tuner) recordDecisionDirectionLocked(oldGOGC, newGOGC int) {
+	direction := 0
+	switch {
+	case newGOGC > oldGOGC:
+		direction = 1
+	case newGOGC < oldGOGC:
+		direction = -1
+	default:
+		return
+	}
+
+	if direction == t.lastDirection {
+		t.sameDirectionStreak++
+	} else {
+		t.lastDirection = direction
+		t.sameDirectionStreak = 1
+	}
+
+	if boost := t.aggressivenessBoost(); boost > 1 {
+		t.config.Logger.Debug("%d consecutive decisions in the same direction, boosting aggressiveness %.2fx",
+			t.sameDirectionStreak, boost)
+	}
+}
+
+// decayAggressivenessLocked steps the boost back down after a stable cycle.
+// t.mu must be held for writing.
+func (t *Tuner) decayAggressivenessLocked() {
+	if t.sameDirectionStreak > 0 {
+		t.sameDirectionStreak--
+	}
+}
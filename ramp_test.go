@@ -0,0 +1,94 @@
+package autotune
+
+import (
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cyclesToConverge applies decisions for a workload whose pause time jumped
+// to three times the target and returns the cycles needed to reach GOGC 300
+func cyclesToConverge(t *testing.T, maxBoost float64) int {
+	config := DefaultConfig()
+	config.MaxAggressivenessBoost = maxBoost
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	gogc := 100
+	for cycle := 1; cycle <= 100; cycle++ {
+		metrics := Metrics{
+			CurrentGOGC:    gogc,
+			GCPauseTime:    3 * config.TargetLatency,
+			MemoryPressure: 0.5,
+			GCFrequency:    1,
+		}
+		target := tuner.calculateTargetGOGC(metrics)
+		if target-gogc > config.MaxChangePerInterval {
+			target = gogc + config.MaxChangePerInterval
+		}
+		tuner.applyTuningDecision(TuningDecision{OldGOGC: gogc, NewGOGC: target, Timestamp: time.Now()})
+		gogc = target
+
+		if gogc >= 300 {
+			return cycle
+		}
+	}
+	t.Fatalf("GOGC did not reach 300, stuck at %d", gogc)
+	return 0
+}
+
+// TestAggressivenessRamp tests that repeated same-direction decisions speed
+// up convergence after a step change
+func TestAggressivenessRamp(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	withoutRamp := cyclesToConverge(t, 1)
+	withRamp := cyclesToConverge(t, 0)
+	assert.Less(t, withRamp, withoutRamp)
+}
+
+// TestAggressivenessBoost tests ramping the boost up to the cap and decaying it
+func TestAggressivenessBoost(t *testing.T) {
+	config := DefaultConfig()
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	tuner.qosClass = QoSClassUnknown
+
+	tuner.mu.Lock()
+	defer tuner.mu.Unlock()
+
+	tuner.recordDecisionDirectionLocked(100, 110)
+	assert.Equal(t, 1.0, tuner.aggressivenessBoost())
+
+	tuner.recordDecisionDirectionLocked(110, 120)
+	assert.Equal(t, 1.25, tuner.aggressivenessBoost())
+	assert.InDelta(t, config.TuningAggressiveness*1.25, tuner.aggressiveness(), 1e-9)
+
+	// Capped
+	for i := 0; i < 10; i++ {
+		tuner.recordDecisionDirectionLocked(120, 130)
+	}
+	assert.Equal(t, defaultMaxAggressivenessBoost, tuner.aggressivenessBoost())
+
+	// Stable cycles decay the boost
+	for i := 0; i < 10; i++ {
+		tuner.decayAggressivenessLocked()
+	}
+	assert.Equal(t, 1.25, tuner.aggressivenessBoost())
+
+	// A reversal resets it
+	tuner.recordDecisionDirectionLocked(130, 120)
+	assert.Equal(t, 1.0, tuner.aggressivenessBoost())
+	tuner.recordDecisionDirectionLocked(120, 120)
+	assert.Equal(t, 1.0, tuner.aggressivenessBoost())
+
+	config.MaxAggressivenessBoost = 1
+	tuner.recordDecisionDirectionLocked(120, 110)
+	assert.Equal(t, 1.0, tuner.aggressivenessBoost())
+
+	config.MaxAggressivenessBoost = 0.5
+	assert.Error(t, validateConfig(config))
+}
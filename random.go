@@ -0,0 +1,26 @@
+package autotune
+
+import (
+	"math/rand"
+	"time"
+)
+
+// newRand returns a random source seeded with seed, or with the current time
+// if seed is zero
+func newRand(seed int64) *rand.Rand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// newRand returns a random source for a component that needs randomness,
+// such as a push queue's retry jitter. A rand.Rand isn't safe for concurrent
+// use, so each component gets its own, seeded from the tuner's source: with
+// Config.RandSeed set, components created in the same order draw the same
+// values, and none of them contend on the global math/rand source.
+func (t *Tuner) newRand() *rand.Rand {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return rand.New(rand.NewSource(t.rand.Int63()))
+}
@@ -0,0 +1,67 @@
+package autotune
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jitterSequence returns the first retry delays of a push queue created by
+// a tuner with the given seed
+func jitterSequence(t *testing.T, seed int64) []time.Duration {
+	config := DefaultConfig()
+	config.RandSeed = seed
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	q := newPushQueue("test", 1, 1, func([]byte) error { return nil }, &mockLogger{}, tuner.newRand())
+	delays := make([]time.Duration, 8)
+	for i := range delays {
+		delays[i] = q.backoff.next()
+	}
+	return delays
+}
+
+// TestRandSeed tests that tuners with the same seed make identical jitter
+// choices
+func TestRandSeed(t *testing.T) {
+	assert.Equal(t, jitterSequence(t, 42), jitterSequence(t, 42))
+	assert.NotEqual(t, jitterSequence(t, 42), jitterSequence(t, 43))
+
+	// Components of one tuner get independent sources
+	config := DefaultConfig()
+	config.RandSeed = 42
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	assert.NotEqual(t, tuner.newRand().Int63(), tuner.newRand().Int63())
+}
+
+// TestWebhookRandSeed tests that alert observers with the same seed make
+// identical jitter choices
+func TestWebhookRandSeed(t *testing.T) {
+	delays := func(q *pushQueue) []time.Duration {
+		values := make([]time.Duration, 8)
+		for i := range values {
+			values[i] = q.backoff.next()
+		}
+		return values
+	}
+	webhook := func(seed int64) []time.Duration {
+		w, err := NewWebhookAlertObserver("http://localhost/alerts", WithWebhookRandSeed(seed), WithWebhookLogger(&mockLogger{}))
+		require.NoError(t, err)
+		defer w.Stop()
+		return delays(w.queue)
+	}
+	slack := func(seed int64) []time.Duration {
+		s, err := NewSlackAlertObserver("http://localhost/alerts", WithSlackRandSeed(seed), WithSlackLogger(&mockLogger{}))
+		require.NoError(t, err)
+		defer s.Stop()
+		return delays(s.webhook.queue)
+	}
+
+	assert.Equal(t, webhook(42), webhook(42))
+	assert.NotEqual(t, webhook(42), webhook(43))
+	assert.Equal(t, webhook(42), slack(42))
+}
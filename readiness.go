@@ -0,0 +1,46 @@
+package autotune
+
+import "context"
+
+// stableCycles is the number of consecutive cycles whose target stays within
+// the minimum change threshold before the tuner counts as converged
+const stableCycles = 3
+
+// recordStableCycleLocked counts a cycle that didn't need a GOGC change and
+// releases WaitForStable callers once the tuner has converged. t.mu must be
+// held for writing.
+func (t *Tuner) recordStableCycleLocked() {
+	t.stabilityCount++
+	t.decayAggressivenessLocked()
+	if t.stabilityCount == stableCycles {
+		close(t.stableCh)
+	}
+}
+
+// resetStabilityLocked restarts the convergence count after GOGC changed.
+// t.mu must be held for writing.
+func (t *Tuner) resetStabilityLocked() {
+	if t.stabilityCount >= stableCycles {
+		t.stableCh = make(chan struct{})
+	}
+	t.stabilityCount = 0
+}
+
+// WaitForStable blocks until the tuner has converged or ctx is done, for
+// example to keep a canary replica out of rotation until it is well tuned.
+// The tuner has converged once stableCycles consecutive cycles needed no GOGC
+// change; any applied decision, including a revert, and any external GOGC
+// change restart the count. A workload that never settles never converges,
+// so callers should pass a context with a deadline.
+func (t *Tuner) WaitForStable(ctx context.Context) error {
+	t.mu.RLock()
+	stable := t.stableCh
+	t.mu.RUnlock()
+
+	select {
+	case <-stable:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,61 @@
+package autotune
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWaitForStable tests waiting for the tuner to converge over a synthetic
+// sequence of stable and changing cycles
+func TestWaitForStable(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- tuner.WaitForStable(context.Background()) }()
+
+	stableCycle := func() {
+		tuner.mu.Lock()
+		tuner.recordStableCycleLocked()
+		tuner.mu.Unlock()
+	}
+	assertWaiting := func() {
+		select {
+		case err := <-done:
+			t.Fatalf("WaitForStable returned early: %v", err)
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+
+	// Two stable cycles, then a GOGC change restarts the count
+	stableCycle()
+	stableCycle()
+	assertWaiting()
+
+	current := readGOGC()
+	tuner.applyTuningDecision(TuningDecision{OldGOGC: current, NewGOGC: current, Timestamp: time.Now()})
+	stableCycle()
+	stableCycle()
+	assertWaiting()
+
+	stableCycle()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("WaitForStable did not return after convergence")
+	}
+
+	// Already converged
+	assert.NoError(t, tuner.WaitForStable(context.Background()))
+
+	// Losing convergence makes new callers wait again
+	tuner.applyTuningDecision(TuningDecision{OldGOGC: current, NewGOGC: current, Timestamp: time.Now()})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, tuner.WaitForStable(ctx), context.DeadlineExceeded)
+}
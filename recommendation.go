@@ -0,0 +1,82 @@
+package autotune
+
+// Cluster-wide recommendations.
+//
+// A fleet controller computing a GOGC from aggregate telemetry can feed it to
+// each tuner through a recommendation source. The source is called once per
+// tuning cycle and, when it returns a value, the local target is moved towards
+// the recommendation (clamped to the local bounds) by Config.RecommendationWeight:
+//
+//	target = local + (recommendation - local) * weight
+//
+// Local safety always wins: under high memory pressure the recommendation can
+// only lower the target, never raise it, and the per-interval change limit
+// and bounds still apply to the blended target.
+
+const (
+	// defaultRecommendationWeight is the weight used when
+	// Config.RecommendationWeight is zero
+	defaultRecommendationWeight = 0.5
+	// recommendationMaxPressure is the memory pressure above which a
+	// recommendation may no longer raise the target
+	recommendationMaxPressure = 0.8
+)
+
+// SetRecommendationSource sets a function returning a cluster-wide GOGC
+// recommendation, with ok false when none is available. It is called once
+// per tuning cycle and biases the local target towards the recommendation,
+// see Config.RecommendationWeight. Passing nil disables it.
+func (t *Tuner) SetRecommendationSource(source func() (gogc int, ok bool)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recommendationSource = source
+}
+
+// readRecommendation calls the recommendation source, returning zero when
+// there is no source, no recommendation or an invalid one
+func (t *Tuner) readRecommendation() int {
+	t.mu.RLock()
+	source := t.recommendationSource
+	t.mu.RUnlock()
+	if source == nil {
+		return 0
+	}
+	var gogc int
+	var ok bool
+	t.safeCall("recommendation_source", func() { gogc, ok = source() })
+	if !ok || gogc <= 0 {
+		return 0
+	}
+	return gogc
+}
+
+// recommendationWeight returns the configured recommendation weight
+func (t *Tuner) recommendationWeight() float64 {
+	if t.config.RecommendationWeight > 0 {
+		return t.config.RecommendationWeight
+	}
+	return defaultRecommendationWeight
+}
+
+// applyRecommendationBias moves the target towards the cluster
+// recommendation carried by the metrics sample. It returns the target
+// unchanged when there is no recommendation or when following it would
+// raise GOGC under high memory pressure.
+func (t *Tuner) applyRecommendationBias(target int, metrics Metrics) int {
+	if metrics.RecommendedGOGC == 0 {
+		return target
+	}
+
+	recommended := t.clampGOGC(metrics.RecommendedGOGC)
+	biased := target + int(float64(recommended-target)*t.recommendationWeight())
+	if biased > target && metrics.MemoryPressure > recommendationMaxPressure {
+		return target
+	}
+	return biased
+}
+
+// lastRecommendation returns the cluster recommendation of the latest sample
+func (t *Tuner) lastRecommendation() int {
+	latest, _ := t.metricsHistory.last()
+	return latest.RecommendedGOGC
+}
@@ -0,0 +1,86 @@
+package autotune
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApplyRecommendationBias tests blending the local target with the
+// cluster recommendation
+func TestApplyRecommendationBias(t *testing.T) {
+	config := DefaultConfig()
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	// No recommendation
+	assert.Equal(t, 200, tuner.applyRecommendationBias(200, Metrics{}))
+
+	// Halfway by default
+	assert.Equal(t, 300, tuner.applyRecommendationBias(200, Metrics{RecommendedGOGC: 400}))
+	assert.Equal(t, 150, tuner.applyRecommendationBias(200, Metrics{RecommendedGOGC: 100}))
+
+	// The recommendation is clamped to the local bounds
+	assert.Equal(t, (200+config.MaxGOGC)/2, tuner.applyRecommendationBias(200, Metrics{RecommendedGOGC: 100000}))
+
+	// High memory pressure blocks raising but not lowering
+	assert.Equal(t, 200, tuner.applyRecommendationBias(200, Metrics{RecommendedGOGC: 400, MemoryPressure: 0.9}))
+	assert.Equal(t, 150, tuner.applyRecommendationBias(200, Metrics{RecommendedGOGC: 100, MemoryPressure: 0.9}))
+
+	config.RecommendationWeight = 1
+	assert.Equal(t, 400, tuner.applyRecommendationBias(200, Metrics{RecommendedGOGC: 400}))
+
+	config.RecommendationWeight = 1.5
+	assert.Error(t, validateConfig(config))
+}
+
+// TestRecommendationSource tests that a recommendation nudges the decision
+// target and shows up in stats
+func TestRecommendationSource(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	config := DefaultConfig()
+	config.TargetLatency = time.Nanosecond // Any real pause exceeds the target
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	runtime.GC()
+	for i := 0; i < 5; i++ {
+		tuner.metricsHistory.push(Metrics{
+			GCPauseTime: time.Millisecond,
+			CurrentGOGC: 100,
+			Timestamp:   time.Now(),
+		})
+	}
+
+	local, err := tuner.Recommend()
+	require.NoError(t, err)
+	require.NotNil(t, local)
+
+	// The source is read once per cycle, and Recommend carries the last
+	// cycle's recommendation over to its fresh sample
+	calls := 0
+	tuner.SetRecommendationSource(func() (int, bool) { calls++; return config.MinGOGC, true })
+	metrics := tuner.collectMetrics()
+	assert.Equal(t, config.MinGOGC, metrics.RecommendedGOGC)
+	metrics.GCPauseTime = time.Millisecond
+	tuner.metricsHistory.push(metrics)
+
+	biased, err := tuner.Recommend()
+	require.NoError(t, err)
+	require.NotNil(t, biased)
+	assert.Less(t, biased.DesiredGOGC, local.DesiredGOGC)
+	assert.Greater(t, biased.DesiredGOGC, config.MinGOGC)
+	assert.Contains(t, biased.Reason, "cluster recommendation")
+	assert.Equal(t, 1, calls)
+
+	assert.Equal(t, config.MinGOGC, tuner.GetStats()["cluster_recommendation"])
+
+	// No recommendation available
+	tuner.SetRecommendationSource(func() (int, bool) { return 0, false })
+	assert.Equal(t, 0, tuner.collectMetrics().RecommendedGOGC)
+}
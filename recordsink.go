@@ -0,0 +1,91 @@
+package autotune
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// recordQueueSize is the number of recorded samples buffered for the
+// OnRecord callback before new ones are dropped
+const recordQueueSize = 256
+
+// recordSink hands recorded samples to ObservabilityConfig.OnRecord from a
+// background goroutine, so a slow sink can't block recording. Samples are
+// dropped and counted while the buffer is full.
+type recordSink struct {
+	fn      func(TimestampedMetrics)
+	logger  Logger
+	records chan TimestampedMetrics
+	done    chan struct{}
+	started sync.Once
+	stopped sync.Once
+	wg      sync.WaitGroup
+	dropped int64
+}
+
+// newRecordSink creates a record sink calling fn
+func newRecordSink(fn func(TimestampedMetrics), logger Logger) *recordSink {
+	return &recordSink{
+		fn:      fn,
+		logger:  logger,
+		records: make(chan TimestampedMetrics, recordQueueSize),
+		done:    make(chan struct{}),
+	}
+}
+
+// enqueue queues a sample without blocking, starting the worker on first use
+func (rs *recordSink) enqueue(record TimestampedMetrics) {
+	rs.started.Do(func() {
+		rs.wg.Add(1)
+		go rs.run()
+	})
+
+	select {
+	case rs.records <- record:
+	default:
+		atomic.AddInt64(&rs.dropped, 1)
+	}
+}
+
+// run delivers queued samples until stopped
+func (rs *recordSink) run() {
+	defer rs.wg.Done()
+
+	for {
+		select {
+		case <-rs.done:
+			return
+		case record := <-rs.records:
+			rs.deliver(record)
+		}
+	}
+}
+
+// deliver calls the sink, recovering from panics so one bad sample doesn't
+// stop delivery
+func (rs *recordSink) deliver(record TimestampedMetrics) {
+	defer func() {
+		if r := recover(); r != nil {
+			rs.logger.Error("Panic in OnRecord callback: %v", r)
+		}
+	}()
+	rs.fn(record)
+}
+
+// stop stops the worker and waits for it to exit. Samples still queued are
+// discarded.
+func (rs *recordSink) stop() {
+	rs.stopped.Do(func() {
+		close(rs.done)
+	})
+	rs.wg.Wait()
+}
+
+// DroppedRecords returns the number of recorded samples not handed to
+// ObservabilityConfig.OnRecord because the callback fell behind
+func (obs *ObservabilityServer) DroppedRecords() int64 {
+	if obs.sink == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&obs.sink.dropped)
+}
@@ -0,0 +1,81 @@
+package autotune
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOnRecord tests forwarding recorded samples to a callback
+func TestOnRecord(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var received []TimestampedMetrics
+	obsConfig := DefaultObservabilityConfig()
+	obsConfig.OnRecord = func(record TimestampedMetrics) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, record)
+	}
+	obs := NewObservabilityServer(obsConfig, tuner)
+	defer obs.Stop()
+
+	obs.recordMetrics(Metrics{CurrentGOGC: 150})
+	obs.recordMetrics(Metrics{CurrentGOGC: 200})
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 2
+	}, time.Second, time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, 150, received[0].Metrics.CurrentGOGC)
+	assert.Equal(t, 200, received[1].Metrics.CurrentGOGC)
+	assert.Equal(t, obs.metricsHistory[1].Timestamp, received[1].Timestamp)
+	mu.Unlock()
+	assert.Equal(t, int64(0), obs.DroppedRecords())
+}
+
+// TestOnRecordSlowSink tests that a slow callback can't block recording
+func TestOnRecordSlowSink(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	obsConfig := DefaultObservabilityConfig()
+	obsConfig.OnRecord = func(TimestampedMetrics) { <-release }
+	obs := NewObservabilityServer(obsConfig, tuner)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < recordQueueSize+100; i++ {
+			obs.recordMetrics(Metrics{})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("recording blocked on a slow sink")
+	}
+
+	// One sample is held by the blocked callback, the buffer is full and
+	// the rest were dropped
+	assert.GreaterOrEqual(t, obs.DroppedRecords(), int64(99))
+	assert.Len(t, obs.metricsHistory, recordQueueSize+100)
+
+	close(release)
+	obs.Stop()
+
+	// Without a callback nothing is dropped
+	obs = NewObservabilityServer(DefaultObservabilityConfig(), tuner)
+	obs.recordMetrics(Metrics{})
+	assert.Equal(t, int64(0), obs.DroppedRecords())
+}
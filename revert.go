@@ -0,0 +1,56 @@
+package autotune
+
+import "fmt"
+
+// revertDecisionLocked returns a decision restoring the GOGC in effect
+// before decision. t.mu must be held.
+func (t *Tuner) revertDecisionLocked(decision TuningDecision, regression float64, metric string, metrics Metrics) *TuningDecision {
+	return &TuningDecision{
+		OldGOGC:     decision.NewGOGC,
+		NewGOGC:     decision.OldGOGC,
+		DesiredGOGC: decision.OldGOGC,
+		Category:    CategoryRevert,
+		Reason: fmt.Sprintf("reverting GOGC %d -> %d due to %.0f%% %s regression",
+			decision.NewGOGC, decision.OldGOGC, regression*100, metric),
+		Confidence: decision.Confidence,
+		Timestamp:  t.now(),
+		Metrics:    &metrics,
+		generation: t.gogcGeneration,
+	}
+}
+
+// recordRevertLocked marks the decision an applied revert undid and holds
+// off decisions in its direction for the stabilization window. t.mu must be
+// held for writing.
+func (t *Tuner) recordRevertLocked(revert TuningDecision) {
+	t.revertedTunes++
+	history := t.decisionHistory.items()
+	for i := len(history) - 1; i >= 0; i-- {
+		if d := history[i]; d.Category != CategoryRevert && d.OldGOGC == revert.NewGOGC && d.NewGOGC == revert.OldGOGC {
+			t.decisionHistory.update(i, func(d *TuningDecision) { d.reverted = true })
+			break
+		}
+	}
+
+	t.revertHoldUntil = t.now().Add(t.config.StabilizationWindow)
+	t.revertHoldDirection = sign(revert.OldGOGC - revert.NewGOGC)
+}
+
+// revertHeld reports whether moving GOGC from current to target would redo
+// a change reverted less than the stabilization window ago
+func (t *Tuner) revertHeld(current, target int) bool {
+	return t.revertHoldDirection != 0 &&
+		sign(target-current) == t.revertHoldDirection &&
+		t.now().Before(t.revertHoldUntil)
+}
+
+// sign returns -1, 0 or 1 for negative, zero and positive x
+func sign(x int) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	}
+	return 0
+}
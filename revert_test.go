@@ -0,0 +1,194 @@
+package autotune
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRevertTuner returns an external metrics tuner with a controllable clock
+// whose GOGC changes are recorded instead of applied
+func newRevertTuner(t *testing.T, threshold float64) (*Tuner, *time.Time, *int) {
+	config := DefaultConfig()
+	config.ExternalMetrics = true
+	config.Logger = &mockLogger{}
+	config.RevertThreshold = threshold
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	clock := time.Now()
+	gogc := 100
+	tuner.now = func() time.Time { return clock }
+	tuner.setGCPercent = func(value int) int {
+		old := gogc
+		gogc = value
+		return old
+	}
+	return tuner, &clock, &gogc
+}
+
+// TestRevertRegression tests that a decision followed by a regression of
+// its targeted metric beyond the threshold is reverted
+func TestRevertRegression(t *testing.T) {
+	tuner, clock, gogc := newRevertTuner(t, DefaultConfig().RevertThreshold)
+
+	// The pause is 10ms from the 10ms target before the decision
+	tuner.processDecision(TuningDecision{
+		OldGOGC:   100,
+		NewGOGC:   250,
+		Category:  CategoryLatency,
+		Reason:    "test",
+		Timestamp: *clock,
+		Metrics:   &Metrics{GCPauseTime: 20 * time.Millisecond, MemoryPressure: 0.5, CurrentGOGC: 100},
+	})
+	require.Equal(t, 250, *gogc)
+
+	// and 13ms from it on average after it
+	for _, pause := range []time.Duration{24, 22} {
+		assert.Equal(t, 250, *gogc, "reverts wait for the whole evaluation window")
+		*clock = clock.Add(30 * time.Second)
+		require.NoError(t, tuner.IngestMetrics(Metrics{GCPauseTime: pause * time.Millisecond, GCFrequency: 1, MemoryPressure: 0.5}))
+	}
+
+	assert.Equal(t, 100, *gogc)
+	decisions := tuner.Decisions()
+	require.Len(t, decisions, 2)
+	assert.True(t, decisions[0].reverted)
+	revert := decisions[1]
+	assert.Equal(t, CategoryRevert, revert.Category)
+	assert.Equal(t, 250, revert.OldGOGC)
+	assert.Equal(t, 100, revert.NewGOGC)
+	assert.Equal(t, "reverting GOGC 250 -> 100 due to 30% pause regression", revert.Reason)
+
+	stats := tuner.GetStats()
+	assert.Equal(t, int64(1), stats["reverted_tunes"])
+	assert.Equal(t, int64(0), stats["successful_tunes"])
+
+	// The reverted change isn't retried for the stabilization window, but
+	// the opposite direction is allowed
+	assert.True(t, tuner.revertHeld(100, 150))
+	assert.False(t, tuner.revertHeld(100, 80))
+	*clock = clock.Add(tuner.config.StabilizationWindow)
+	assert.False(t, tuner.revertHeld(100, 150))
+}
+
+// TestRevertImprovement tests that a decision that doesn't regress the
+// metrics within the evaluation cycles isn't reverted
+func TestRevertImprovement(t *testing.T) {
+	tuner, clock, gogc := newRevertTuner(t, 0.25)
+
+	tuner.processDecision(TuningDecision{
+		OldGOGC:   100,
+		NewGOGC:   150,
+		Timestamp: *clock,
+		Metrics:   &Metrics{GCPauseTime: 20 * time.Millisecond, MemoryPressure: 0.5, CurrentGOGC: 100},
+	})
+
+	for i := 0; i < revertEvaluationCycles; i++ {
+		*clock = clock.Add(30 * time.Second)
+		require.NoError(t, tuner.IngestMetrics(Metrics{GCPauseTime: 15 * time.Millisecond, GCFrequency: 1, MemoryPressure: 0.55}))
+	}
+
+	assert.Equal(t, 150, *gogc)
+	stats := tuner.GetStats()
+	assert.Equal(t, int64(0), stats["reverted_tunes"])
+	assert.Equal(t, int64(1), stats["evaluated_tunes"])
+	assert.Equal(t, int64(1), stats["successful_tunes"])
+}
+
+// TestRevertTargetsCategory tests that only the metric a decision targeted
+// can revert it, averaged over the evaluation cycles
+func TestRevertTargetsCategory(t *testing.T) {
+	tuner, clock, gogc := newRevertTuner(t, 0.25)
+	ingest := func(pause time.Duration, pressure float64) {
+		*clock = clock.Add(30 * time.Second)
+		require.NoError(t, tuner.IngestMetrics(Metrics{GCPauseTime: pause, GCFrequency: 1, MemoryPressure: pressure}))
+	}
+
+	// Memory pressure rising doesn't revert a latency decision
+	tuner.processDecision(TuningDecision{
+		OldGOGC:   100,
+		NewGOGC:   150,
+		Category:  CategoryLatency,
+		Timestamp: *clock,
+		Metrics:   &Metrics{GCPauseTime: 20 * time.Millisecond, MemoryPressure: 0.4, CurrentGOGC: 100},
+	})
+	for i := 0; i < revertEvaluationCycles; i++ {
+		ingest(15*time.Millisecond, 0.7)
+	}
+	assert.Equal(t, 150, *gogc)
+
+	// A single noisy sample doesn't revert a memory decision
+	*clock = clock.Add(30 * time.Second)
+	tuner.processDecision(TuningDecision{
+		OldGOGC:   150,
+		NewGOGC:   120,
+		Category:  CategoryMemory,
+		Timestamp: *clock,
+		Metrics:   &Metrics{GCPauseTime: 15 * time.Millisecond, MemoryPressure: 0.6, CurrentGOGC: 150, Timestamp: *clock},
+	})
+	ingest(15*time.Millisecond, 0.8)
+	ingest(15*time.Millisecond, 0.6)
+	assert.Equal(t, 120, *gogc)
+	assert.Equal(t, int64(0), tuner.GetStats()["reverted_tunes"])
+
+	// but a sustained regression does
+	*clock = clock.Add(30 * time.Second)
+	tuner.processDecision(TuningDecision{
+		OldGOGC:   120,
+		NewGOGC:   100,
+		Category:  CategoryMemory,
+		Timestamp: *clock,
+		Metrics:   &Metrics{GCPauseTime: 15 * time.Millisecond, MemoryPressure: 0.6, CurrentGOGC: 120, Timestamp: *clock},
+	})
+	ingest(15*time.Millisecond, 0.8)
+	ingest(15*time.Millisecond, 0.8)
+	assert.Equal(t, 120, *gogc)
+	assert.Equal(t, int64(1), tuner.GetStats()["reverted_tunes"])
+}
+
+// TestRevertDisabled tests that a zero threshold disables reverts
+func TestRevertDisabled(t *testing.T) {
+	assert.Equal(t, 0.25, DefaultConfig().RevertThreshold, "reverts are on by default")
+
+	tuner, clock, gogc := newRevertTuner(t, 0)
+
+	tuner.processDecision(TuningDecision{
+		OldGOGC:   100,
+		NewGOGC:   250,
+		Timestamp: *clock,
+		Metrics:   &Metrics{GCPauseTime: 10 * time.Millisecond, MemoryPressure: 0.5, CurrentGOGC: 100},
+	})
+	*clock = clock.Add(30 * time.Second)
+	require.NoError(t, tuner.IngestMetrics(Metrics{GCPauseTime: 50 * time.Millisecond, GCFrequency: 1, MemoryPressure: 0.5}))
+
+	assert.NotEqual(t, 100, *gogc)
+	assert.Equal(t, int64(0), tuner.GetStats()["reverted_tunes"])
+
+	config := DefaultConfig()
+	config.RevertThreshold = -0.1
+	assert.Error(t, validateConfig(config))
+}
+
+// TestOscillationIgnoresReverts tests that a revert and the decision it
+// undid don't count towards oscillation prevention
+func TestOscillationIgnoresReverts(t *testing.T) {
+	tuner, clock, _ := newRevertTuner(t, 0.25)
+	now := *clock
+
+	tuner.decisionHistory.reset([]TuningDecision{
+		{OldGOGC: 100, NewGOGC: 120, Timestamp: now.Add(-4 * time.Minute)},
+		{OldGOGC: 120, NewGOGC: 140, Timestamp: now.Add(-3 * time.Minute)},
+		{OldGOGC: 140, NewGOGC: 190, Timestamp: now.Add(-2 * time.Minute), reverted: true},
+		{OldGOGC: 190, NewGOGC: 140, Timestamp: now.Add(-time.Minute), Category: CategoryRevert},
+		{OldGOGC: 140, NewGOGC: 160, Timestamp: now},
+	})
+	assert.False(t, tuner.shouldSkipDueToOscillation())
+
+	// Counted as regular decisions, the same history looks like oscillation
+	tuner.decisionHistory.update(2, func(d *TuningDecision) { d.reverted = false })
+	tuner.decisionHistory.update(3, func(d *TuningDecision) { d.Category = CategoryLatency })
+	assert.True(t, tuner.shouldSkipDueToOscillation())
+}
@@ -0,0 +1,94 @@
+package autotune
+
+const (
+	// defaultMetricsHistorySize is the number of metrics samples kept when
+	// Config.MetricsHistorySize is zero
+	defaultMetricsHistorySize = 100
+	// maxDecisions is the number of decisions kept in the decision history
+	maxDecisions = 50
+)
+
+// metricsHistorySize returns the number of metrics samples a tuner with the
+// config keeps
+func metricsHistorySize(config *Config) int {
+	if config.MetricsHistorySize > 0 {
+		return config.MetricsHistorySize
+	}
+	return defaultMetricsHistorySize
+}
+
+// ring is a fixed-capacity history keeping the most recent entries. Every
+// entry is stored twice, capacity apart, so the entries in chronological
+// order are always a contiguous window of the buffer: push is O(1) without
+// reallocating, and items returns the window without copying.
+type ring[T any] struct {
+	buf   []T // twice the capacity, entry slots mirrored capacity apart
+	start int // slot of the oldest entry
+	n     int // number of entries
+}
+
+// newRing creates a ring keeping the last capacity entries
+func newRing[T any](capacity int) *ring[T] {
+	return &ring[T]{buf: make([]T, 2*capacity)}
+}
+
+// capacity returns the maximum number of entries kept
+func (r *ring[T]) capacity() int {
+	return len(r.buf) / 2
+}
+
+// push appends entries, dropping the oldest ones beyond the capacity
+func (r *ring[T]) push(values ...T) {
+	c := r.capacity()
+	if c == 0 {
+		return
+	}
+	for _, value := range values {
+		slot := (r.start + r.n) % c
+		if r.n == c {
+			slot = r.start
+			r.start = (r.start + 1) % c
+		} else {
+			r.n++
+		}
+		r.buf[slot] = value
+		r.buf[slot+c] = value
+	}
+}
+
+// items returns the entries, oldest first. The slice aliases the ring: it is
+// only valid until the next push and must not be modified, use update.
+// Appending to it copies.
+func (r *ring[T]) items() []T {
+	return r.buf[r.start : r.start+r.n : r.start+r.n]
+}
+
+// len returns the number of entries
+func (r *ring[T]) len() int {
+	return r.n
+}
+
+// last returns the newest entry, false when the ring is empty
+func (r *ring[T]) last() (T, bool) {
+	if r.n == 0 {
+		var zero T
+		return zero, false
+	}
+	return r.buf[r.start+r.n-1], true
+}
+
+// update calls fn on entry i, counted from the oldest, and stores the result
+// in both of its slots
+func (r *ring[T]) update(i int, fn func(*T)) {
+	c := r.capacity()
+	slot := (r.start + i) % c
+	fn(&r.buf[slot])
+	r.buf[slot+c] = r.buf[slot]
+}
+
+// reset replaces the entries with values, keeping the last capacity of them
+func (r *ring[T]) reset(values []T) {
+	clear(r.buf)
+	r.start, r.n = 0, 0
+	r.push(values...)
+}
@@ -0,0 +1,101 @@
+package autotune
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRing tests that the ring keeps the most recent entries in
+// chronological order across wraparound
+func TestRing(t *testing.T) {
+	r := newRing[int](3)
+	assert.Empty(t, r.items())
+	_, ok := r.last()
+	assert.False(t, ok)
+
+	r.push(1, 2)
+	assert.Equal(t, []int{1, 2}, r.items())
+
+	for i := 3; i <= 7; i++ {
+		r.push(i)
+		last, ok := r.last()
+		require.True(t, ok)
+		assert.Equal(t, i, last)
+	}
+	assert.Equal(t, []int{5, 6, 7}, r.items())
+	assert.Equal(t, 3, r.len())
+
+	// Updates are visible wherever the window starts
+	r.update(0, func(v *int) { *v = 50 })
+	assert.Equal(t, []int{50, 6, 7}, r.items())
+	r.push(8, 9)
+	assert.Equal(t, []int{7, 8, 9}, r.items())
+	r.update(2, func(v *int) { *v = 90 })
+	r.push(10)
+	assert.Equal(t, []int{8, 90, 10}, r.items())
+
+	// Appending to the window doesn't overwrite the ring
+	buf := append([]int(nil), r.buf...)
+	grown := append(r.items(), 11)
+	assert.Equal(t, []int{8, 90, 10, 11}, grown)
+	assert.Equal(t, buf, r.buf)
+
+	r.reset([]int{1, 2, 3, 4})
+	assert.Equal(t, []int{2, 3, 4}, r.items())
+	r.reset(nil)
+	assert.Zero(t, r.len())
+}
+
+// TestMetricsHistorySize tests configuring the metrics history size
+func TestMetricsHistorySize(t *testing.T) {
+	config := DefaultConfig()
+	config.ExternalMetrics = true
+	config.Logger = &mockLogger{}
+	config.MetricsHistorySize = 25
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	tuner.setGCPercent = func(value int) int { return 100 }
+
+	now := time.Now()
+	for i := 0; i < 40; i++ {
+		require.NoError(t, tuner.IngestMetrics(Metrics{
+			Timestamp:   now.Add(time.Duration(i) * time.Minute),
+			GCPauseTime: time.Millisecond,
+			CurrentGOGC: 100,
+		}))
+	}
+
+	history := tuner.GetMetricsHistory()
+	require.Len(t, history, 25)
+	for i, metrics := range history {
+		assert.Equal(t, now.Add(time.Duration(15+i)*time.Minute), metrics.Timestamp)
+	}
+
+	config = DefaultConfig()
+	config.MetricsHistorySize = 5
+	_, err = NewTuner(config)
+	assert.Error(t, err)
+
+	// The history must hold the leak detection window
+	config.MetricsHistorySize = 15
+	_, err = NewTuner(config)
+	assert.Error(t, err)
+	config.LeakDetectionWindow = 15
+	_, err = NewTuner(config)
+	assert.NoError(t, err)
+}
+
+// BenchmarkRingPush measures recording a sample in a full history
+func BenchmarkRingPush(b *testing.B) {
+	r := newRing[Metrics](defaultMetricsHistorySize)
+	r.push(make([]Metrics, defaultMetricsHistorySize)...)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.push(Metrics{NumGC: uint32(i)})
+	}
+}
@@ -0,0 +1,121 @@
+package autotune
+
+import (
+	rtmetrics "runtime/metrics"
+	"sort"
+)
+
+// runtime/metrics keys read by the tuner
+const (
+	rtGOGCKey     = "/gc/gogc:percent"
+	rtLiveHeapKey = "/gc/heap/live:bytes"
+	rtGCCPUKey    = "/cpu/classes/gc/total:cpu-seconds"
+	rtTotalCPUKey = "/cpu/classes/total:cpu-seconds"
+)
+
+// runtimeMetricFallbacks maps each runtime/metrics key the tuner reads to a
+// description of the source used when the key isn't registered
+var runtimeMetricFallbacks = map[string]string{
+	rtGOGCKey:     "debug.SetGCPercent",
+	rtLiveHeapKey: "MemStats.HeapAlloc",
+	rtGCCPUKey:    "MemStats.GCCPUFraction",
+	rtTotalCPUKey: "MemStats.GCCPUFraction",
+}
+
+// runtimeMetricKinds is the value kind of each key the tuner reads
+var runtimeMetricKinds = map[string]rtmetrics.ValueKind{
+	rtGOGCKey:     rtmetrics.KindUint64,
+	rtLiveHeapKey: rtmetrics.KindUint64,
+	rtGCCPUKey:    rtmetrics.KindFloat64,
+	rtTotalCPUKey: rtmetrics.KindFloat64,
+}
+
+// runtimeMetricsCollector reads tuner inputs from runtime/metrics. The set of
+// registered keys varies across Go releases and builds, so each key is probed
+// once up front; reading a key that isn't registered reports it as missing
+// and callers use the MemStats equivalent instead.
+type runtimeMetricsCollector struct {
+	supported map[string]bool
+}
+
+// newRuntimeMetricsCollector probes the tuner's keys against the metrics the
+// runtime describes. A key whose kind changed counts as missing.
+func newRuntimeMetricsCollector(descriptions []rtmetrics.Description) *runtimeMetricsCollector {
+	c := &runtimeMetricsCollector{supported: make(map[string]bool)}
+	for _, desc := range descriptions {
+		if kind, wanted := runtimeMetricKinds[desc.Name]; wanted && desc.Kind == kind {
+			c.supported[desc.Name] = true
+		}
+	}
+	return c
+}
+
+// runtimeCollector is the collector for the running Go runtime
+var runtimeCollector = newRuntimeMetricsCollector(rtmetrics.All())
+
+// readUint64 reads a uint64 metric, reporting false if the key isn't
+// registered with this runtime
+func (c *runtimeMetricsCollector) readUint64(key string) (uint64, bool) {
+	if !c.supported[key] {
+		return 0, false
+	}
+
+	sample := []rtmetrics.Sample{{Name: key}}
+	rtmetrics.Read(sample)
+	if sample[0].Value.Kind() != rtmetrics.KindUint64 {
+		return 0, false
+	}
+	return sample[0].Value.Uint64(), true
+}
+
+// readFloat64 reads a float64 metric, reporting false if the key isn't
+// registered with this runtime
+func (c *runtimeMetricsCollector) readFloat64(key string) (float64, bool) {
+	if !c.supported[key] {
+		return 0, false
+	}
+
+	sample := []rtmetrics.Sample{{Name: key}}
+	rtmetrics.Read(sample)
+	if sample[0].Value.Kind() != rtmetrics.KindFloat64 {
+		return 0, false
+	}
+	return sample[0].Value.Float64(), true
+}
+
+// sources describes where each metric is read from, keyed by runtime/metrics
+// key: "runtime/metrics" when the key is registered, the fallback otherwise
+func (c *runtimeMetricsCollector) sources() map[string]string {
+	sources := make(map[string]string, len(runtimeMetricFallbacks))
+	for key, fallback := range runtimeMetricFallbacks {
+		if c.supported[key] {
+			sources[key] = "runtime/metrics"
+		} else {
+			sources[key] = fallback
+		}
+	}
+	return sources
+}
+
+// logSources logs the source of every metric, noting fallbacks
+func (c *runtimeMetricsCollector) logSources(logger Logger) {
+	sources := c.sources()
+	keys := make([]string, 0, len(sources))
+	for key := range sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	native := 0
+	for _, key := range keys {
+		if c.supported[key] {
+			native++
+			continue
+		}
+		logger.Info("runtime/metrics key %s is not available, falling back to %s", key, sources[key])
+	}
+
+	if native == len(keys) {
+		logger.Debug("Reading all %d runtime metrics from runtime/metrics", native)
+	}
+}
@@ -0,0 +1,46 @@
+package autotune
+
+import (
+	"runtime"
+	"runtime/debug"
+	rtmetrics "runtime/metrics"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRuntimeMetricsFallback tests that keys missing from runtime/metrics
+// fall back to their MemStats equivalents
+func TestRuntimeMetricsFallback(t *testing.T) {
+	var descriptions []rtmetrics.Description
+	for _, desc := range rtmetrics.All() {
+		if desc.Name != rtLiveHeapKey {
+			descriptions = append(descriptions, desc)
+		}
+	}
+
+	original := runtimeCollector
+	runtimeCollector = newRuntimeMetricsCollector(descriptions)
+	defer func() { runtimeCollector = original }()
+
+	live, ok := readRuntimeLiveHeap()
+	assert.False(t, ok)
+	assert.Equal(t, "MemStats.HeapAlloc", runtimeCollector.sources()[rtLiveHeapKey])
+
+	// The live heap falls back to HeapAlloc
+	m := &runtime.MemStats{HeapAlloc: 1024, HeapInuse: 4096}
+	assert.Equal(t, uint64(1024), liveHeapBytes(LiveHeapSourceRuntimeMetrics, m, live, ok))
+
+	// GOGC is still readable through its own key or fallback
+	current := debug.SetGCPercent(-1)
+	debug.SetGCPercent(current)
+	assert.Equal(t, current, readGOGC())
+
+	logger := &mockLogger{}
+	runtimeCollector.logSources(logger)
+	assert.Equal(t, 1, logger.infoCalls)
+
+	if original.supported[rtGOGCKey] {
+		assert.Equal(t, "runtime/metrics", runtimeCollector.sources()[rtGOGCKey])
+	}
+}
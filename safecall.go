@@ -0,0 +1,57 @@
+package autotune
+
+import (
+	"sync"
+)
+
+// userFuncFailures counts panics in user-supplied functions by name
+type userFuncFailures struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+// record counts a failure of the named function
+func (f *userFuncFailures) record(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.counts == nil {
+		f.counts = make(map[string]int64)
+	}
+	f.counts[name]++
+}
+
+// snapshot returns a copy of the failure counts
+func (f *userFuncFailures) snapshot() map[string]int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	counts := make(map[string]int64, len(f.counts))
+	for name, count := range f.counts {
+		counts[name] = count
+	}
+	return counts
+}
+
+// safeCall calls a user-supplied function such as a provider, hook, filter
+// or callback, recovering from a panic so a bug in application code can't
+// crash the tuning cycle. It returns false when fn panicked, after logging
+// the panic and counting it under name; callers then treat the call as
+// having produced no result. Config.DisablePanicRecovery lets panics
+// propagate instead.
+func (t *Tuner) safeCall(name string, fn func()) (ok bool) {
+	if t.config.DisablePanicRecovery {
+		fn()
+		return true
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.config.Logger.Error("Panic in %s: %v", name, r)
+			t.userFuncFailures.record(name)
+			ok = false
+		}
+	}()
+	fn()
+	return true
+}
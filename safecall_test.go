@@ -0,0 +1,74 @@
+package autotune
+
+import (
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPanickingUserFunctions tests that panics in user-supplied functions
+// are recovered, counted and treated as no result
+func TestPanickingUserFunctions(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	config := DefaultConfig()
+	config.Logger = &mockLogger{}
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	tuner.setGCPercent = func(value int) int { return 100 }
+
+	tuner.SetLatencyProvider(func() time.Duration { panic("latency provider bug") })
+	tuner.SetRecommendationSource(func() (int, bool) { panic("recommendation bug") })
+	tuner.AddMetricHook("queue_depth", func() float64 { panic("hook bug") })
+	tuner.AddMetricHook("cache_hits", func() float64 { return 42 })
+	tuner.SetOnMetricsUpdate(func(Metrics) { panic("metrics callback bug") })
+	tuner.SetOnTuningDecision(func(TuningDecision) { panic("decision callback bug") })
+
+	metrics := tuner.collectMetrics()
+	assert.Zero(t, metrics.RequestLatency)
+	assert.Zero(t, metrics.RecommendedGOGC)
+	assert.Equal(t, map[string]float64{"cache_hits": 42}, metrics.Custom)
+
+	for i := 0; i < 3; i++ {
+		tuner.performTuningCycle()
+	}
+
+	// Every cycle completed despite the panics
+	assert.False(t, tuner.lastCycleTime.IsZero())
+	assert.Len(t, tuner.metricsHistory.items(), 3)
+
+	failures := tuner.GetStats()["user_func_failures"].(map[string]int64)
+	assert.Equal(t, int64(4), failures["latency_provider"])
+	assert.Equal(t, int64(4), failures["recommendation_source"])
+	assert.Equal(t, int64(4), failures["metric_hook:queue_depth"])
+	assert.Equal(t, int64(3), failures["on_metrics_update"])
+
+	// A panicking callback doesn't undo the applied decision
+	tuner.processDecision(TuningDecision{OldGOGC: 100, NewGOGC: 150, Timestamp: time.Now()})
+	assert.Len(t, tuner.Decisions(), 1)
+	assert.Equal(t, int64(1), tuner.GetStats()["user_func_failures"].(map[string]int64)["on_tuning_decision"])
+
+	// A panicking filter skips the decision
+	tuner.SetDecisionFilter(func(TuningDecision) (TuningDecision, bool) { panic("filter bug") })
+	decisions := len(tuner.Decisions())
+	tuner.processDecision(TuningDecision{OldGOGC: 100, NewGOGC: 150, Timestamp: time.Now()})
+	assert.Len(t, tuner.Decisions(), decisions)
+	assert.Equal(t, int64(1), tuner.GetStats()["user_func_failures"].(map[string]int64)["decision_filter"])
+	assert.Equal(t, int64(0), tuner.GetStats()["vetoed_decisions"])
+}
+
+// TestDisablePanicRecovery tests that panics propagate when recovery is disabled
+func TestDisablePanicRecovery(t *testing.T) {
+	config := DefaultConfig()
+	config.DisablePanicRecovery = true
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	assert.Panics(t, func() {
+		tuner.safeCall("test", func() { panic("bug") })
+	})
+	assert.Empty(t, tuner.GetStats()["user_func_failures"])
+}
@@ -0,0 +1,105 @@
+package autotune
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Simulate replays recorded metrics through the decision logic under config
+// and returns the decisions it would make, without changing the process's
+// GOGC or any running tuner. The first sample's CurrentGOGC is the starting
+// point (100 if unset); after that each sample's CurrentGOGC is replaced by
+// the simulated value, so decisions build on each other as they would live.
+//
+// Recorded measurements such as pause times and GC frequency are replayed
+// as is, even though a different GOGC would have changed them, so the result
+// shows how the decision logic reacts rather than predicting the workload.
+// Adjusted memory pressure is recomputed from the recorded live heap and
+// container limit, so MemoryLimitPercent overrides take effect.
+//
+// Each sample goes through the same gates as a live cycle: SLO mode, the
+// pause ceiling, reverts of regressing decisions and leak safe mode. A
+// PIDStrategy is replayed from a fresh state, but other custom strategies
+// are called as they are, so a strategy that keeps state between calls
+// should not be shared with a running tuner.
+func Simulate(config *Config, history []Metrics) ([]TuningDecision, error) {
+	return simulate(config, history, QoSClassUnknown, 0)
+}
+
+// simulate implements Simulate for a tuner with the given QoS class and
+// memory request
+func simulate(config *Config, history []Metrics, qosClass QoSClass, memoryRequest uint64) ([]TuningDecision, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if err := validateConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	simConfig := *config
+	simConfig.Logger = silentLogger{}
+	// Replay through a PID controller of its own so the live one keeps its
+	// state, and through the default strategy of the simulated tuner rather
+	// than a live one
+	switch strategy := simConfig.Strategy.(type) {
+	case *PIDStrategy:
+		simConfig.Strategy = strategy.fresh()
+	case *DefaultStrategy:
+		simConfig.Strategy = nil
+	}
+
+	gogc := 100
+	if len(history) > 0 && history[0].CurrentGOGC > 0 {
+		gogc = history[0].CurrentGOGC
+	}
+
+	memLimit := int64(math.MaxInt64)
+	var clock time.Time
+	var decisions []TuningDecision
+	sim := &Tuner{
+		config:          &simConfig,
+		now:             func() time.Time { return clock },
+		setGCPercent:    func(value int) int { old := gogc; gogc = value; return old },
+		setMemoryLimit:  func(limit int64) int64 { old := memLimit; memLimit = limit; return old },
+		metricsHistory:  newRing[Metrics](metricsHistorySize(&simConfig)),
+		decisionHistory: newRing[TuningDecision](maxDecisions),
+		lastGOGC:        gogc,
+		stableCh:        make(chan struct{}),
+		qosClass:        qosClass,
+		memoryRequest:   memoryRequest,
+		rand:            newRand(simConfig.RandSeed),
+	}
+	sim.decisionObs = []DecisionObserver{DecisionObserverFunc(func(decision TuningDecision) {
+		decisions = append(decisions, decision)
+	})}
+
+	for _, sample := range history {
+		clock = sample.Timestamp
+		sample.CurrentGOGC = gogc
+		if sample.ContainerMemLimit > 0 && sample.MemoryUsage > 0 {
+			sample.MemoryLimit = sim.memoryBaseline(sample.ContainerMemLimit)
+			if sample.MemoryLimit > 0 {
+				sample.MemoryPressureAdjusted = float64(sample.MemoryUsage) / float64(sample.MemoryLimit)
+				sample.MemoryPressure = sample.MemoryPressureAdjusted
+			}
+		}
+
+		sim.mu.Lock()
+		sloActing, held := sim.recordCycleLocked(sample)
+		sim.mu.Unlock()
+		if !held {
+			sim.tune(sample, sloActing, 0)
+		}
+	}
+
+	return decisions, nil
+}
+
+// silentLogger discards all log messages
+type silentLogger struct{}
+
+func (silentLogger) Debug(msg string, fields ...interface{}) {}
+func (silentLogger) Info(msg string, fields ...interface{})  {}
+func (silentLogger) Warn(msg string, fields ...interface{})  {}
+func (silentLogger) Error(msg string, fields ...interface{}) {}
@@ -0,0 +1,153 @@
+package autotune
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pauseHistory returns samples a minute apart with the given pause time
+func pauseHistory(n int, pause time.Duration) []Metrics {
+	start := time.Now().Add(-time.Duration(n) * time.Minute)
+	history := make([]Metrics, n)
+	for i := range history {
+		history[i] = Metrics{
+			GCPauseTime:    pause,
+			GCFrequency:    1,
+			MemoryPressure: 0.5,
+			CurrentGOGC:    100,
+			Timestamp:      start.Add(time.Duration(i) * time.Minute),
+		}
+	}
+	return history
+}
+
+// TestSimulate tests replaying recorded metrics through the decision logic
+func TestSimulate(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+	debug.SetGCPercent(123)
+
+	history := pauseHistory(10, 50*time.Millisecond)
+
+	decisions, err := Simulate(DefaultConfig(), history)
+	require.NoError(t, err)
+	require.NotEmpty(t, decisions)
+
+	// Decisions build on the simulated GOGC, not the recorded one
+	gogc := 100
+	for _, decision := range decisions {
+		assert.Equal(t, gogc, decision.OldGOGC)
+		assert.Greater(t, decision.NewGOGC, decision.OldGOGC)
+		gogc = decision.NewGOGC
+	}
+	assert.Equal(t, 123, readGOGC(), "the process GOGC must not change")
+
+	// Pauses within a looser target produce no increases
+	config := DefaultConfig()
+	config.TargetLatency = time.Second
+	relaxed, err := Simulate(config, history)
+	require.NoError(t, err)
+	for _, decision := range relaxed {
+		assert.LessOrEqual(t, decision.NewGOGC, decision.OldGOGC)
+	}
+
+	_, err = Simulate(&Config{}, history)
+	assert.Error(t, err)
+
+	decisions, err = Simulate(nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, decisions)
+}
+
+// TestSimulateGates tests that simulations apply the same gates as live
+// tuning cycles
+func TestSimulateGates(t *testing.T) {
+	history := pauseHistory(10, 50*time.Millisecond)
+
+	// Pauses within the SLO leave the tuner dormant
+	config := DefaultConfig()
+	config.SLOMode = SLOConfig{PauseTarget: time.Second, BreachDuration: time.Minute}
+	decisions, err := Simulate(config, history)
+	require.NoError(t, err)
+	assert.Empty(t, decisions)
+
+	// The pause ceiling still applies while the SLO is met
+	config.MaxPauseTime = 20 * time.Millisecond
+	decisions, err = Simulate(config, history)
+	require.NoError(t, err)
+	require.NotEmpty(t, decisions)
+	assert.Equal(t, PriorityHigh, decisions[0].Priority)
+
+	// A suspected leak puts the simulated tuner in safe mode
+	config = DefaultConfig()
+	config.LeakAction = LeakActionSafeMode
+	leaking := pauseHistory(10, 50*time.Millisecond)
+	for i := range leaking {
+		leaking[i].LeakSuspected = true
+	}
+	decisions, err = Simulate(config, leaking)
+	require.NoError(t, err)
+	assert.Empty(t, decisions)
+}
+
+// TestWhatIfEndpoint tests simulating a config override over the recorded
+// history
+func TestWhatIfEndpoint(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	post := func(obs *ObservabilityServer, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/whatif", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		obs.server.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// Disabled without a token
+	obs := NewObservabilityServer(DefaultObservabilityConfig(), tuner)
+	assert.Equal(t, http.StatusNotFound, post(obs, "", "{}").Code)
+
+	obsConfig := DefaultObservabilityConfig()
+	obsConfig.AuthToken = "secret"
+	obs = NewObservabilityServer(obsConfig, tuner)
+	for _, sample := range pauseHistory(10, 50*time.Millisecond) {
+		obs.recordMetrics(sample)
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post(obs, "", "{}").Code)
+	assert.Equal(t, http.StatusUnauthorized, post(obs, "wrong", "{}").Code)
+
+	w := httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/whatif", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	assert.Equal(t, http.StatusBadRequest, post(obs, "secret", "not json").Code)
+	assert.Equal(t, http.StatusBadRequest, post(obs, "secret", `{"MinGOGC": 1}`).Code)
+
+	w = post(obs, "secret", `{"TargetLatency": 1000000000}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Samples   int              `json:"samples"`
+		Decisions []TuningDecision `json:"decisions"`
+		Baseline  []TuningDecision `json:"baseline"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 10, response.Samples)
+	require.NotEmpty(t, response.Baseline)
+	require.NotEmpty(t, response.Decisions)
+	assert.Greater(t, response.Baseline[0].NewGOGC, response.Decisions[0].NewGOGC)
+
+	// The live config is unchanged
+	assert.Equal(t, DefaultConfig().TargetLatency, tuner.config.TargetLatency)
+}
@@ -0,0 +1,205 @@
+package autotune
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultSlackDuplicateWindow is how long a repeated alert is suppressed
+// when no window is configured
+const defaultSlackDuplicateWindow = 10 * time.Minute
+
+// slackColors maps alert levels to attachment colors
+var slackColors = map[AlertLevel]string{
+	AlertLevelInfo:     "#2eb886", // green
+	AlertLevelWarning:  "#daa038", // yellow
+	AlertLevelCritical: "#a30200", // red
+}
+
+// SlackAlertObserver posts alerts to a Slack incoming webhook as a message
+// with a color-coded attachment holding the alert, its resolution and the
+// key metrics. Delivery works as for WebhookAlertObserver.
+//
+// Repeats of an alert with the same level and message within the duplicate
+// window are suppressed. Numbers in the message are ignored when comparing,
+// so a sustained condition such as "High memory pressure: 85.2%" followed by
+// "High memory pressure: 86.0%" is posted once per window.
+type SlackAlertObserver struct {
+	webhook  *WebhookAlertObserver
+	window   time.Duration
+	randSeed int64
+	logger   Logger
+	now      func() time.Time
+
+	mu         sync.Mutex
+	lastSent   map[string]time.Time
+	suppressed int64
+}
+
+// SlackOption configures a SlackAlertObserver
+type SlackOption func(*SlackAlertObserver)
+
+// WithSlackDuplicateWindow sets how long repeats of an alert are suppressed,
+// zero or negative to post every alert (default: 10m)
+func WithSlackDuplicateWindow(window time.Duration) SlackOption {
+	return func(s *SlackAlertObserver) {
+		s.window = window
+	}
+}
+
+// WithSlackRandSeed seeds the retry jitter, see WithWebhookRandSeed
+func WithSlackRandSeed(seed int64) SlackOption {
+	return func(s *SlackAlertObserver) {
+		s.randSeed = seed
+	}
+}
+
+// WithSlackLogger sets the logger for dropped alerts (default: the standard
+// logger)
+func WithSlackLogger(logger Logger) SlackOption {
+	return func(s *SlackAlertObserver) {
+		s.logger = logger
+	}
+}
+
+// NewSlackAlertObserver creates an observer posting alerts to the Slack
+// incoming webhook URL and starts its delivery worker. Call Stop to stop it.
+func NewSlackAlertObserver(webhookURL string, opts ...SlackOption) (*SlackAlertObserver, error) {
+	s := &SlackAlertObserver{
+		window:   defaultSlackDuplicateWindow,
+		logger:   &defaultLogger{},
+		now:      time.Now,
+		lastSent: make(map[string]time.Time),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	webhook, err := NewWebhookAlertObserver(webhookURL, WithWebhookLogger(s.logger), WithWebhookRandSeed(s.randSeed))
+	if err != nil {
+		return nil, err
+	}
+	webhook.encode = encodeSlackAlert
+	s.webhook = webhook
+	return s, nil
+}
+
+// OnAlert queues the alert for delivery without blocking, unless it repeats
+// an alert posted within the duplicate window
+func (s *SlackAlertObserver) OnAlert(alert Alert) {
+	if s.duplicate(alert) {
+		return
+	}
+	s.webhook.OnAlert(alert)
+}
+
+// Stop stops the delivery worker, aborting a request in flight. Alerts
+// still queued are discarded.
+func (s *SlackAlertObserver) Stop() {
+	s.webhook.Stop()
+}
+
+// Stats returns delivery counters and the number of suppressed duplicates
+func (s *SlackAlertObserver) Stats() map[string]interface{} {
+	stats := s.webhook.Stats()
+	s.mu.Lock()
+	stats["suppressed"] = s.suppressed
+	s.mu.Unlock()
+	return stats
+}
+
+// slackNumber matches the numbers ignored when comparing alert messages
+var slackNumber = regexp.MustCompile(`[0-9]+(\.[0-9]+)?`)
+
+// duplicate reports whether the alert repeats one posted within the
+// duplicate window, recording it as posted otherwise
+func (s *SlackAlertObserver) duplicate(alert Alert) bool {
+	if s.window <= 0 {
+		return false
+	}
+
+	key := string(alert.Level) + "\x00" + slackNumber.ReplaceAllString(alert.Message, "#")
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sent, ok := s.lastSent[key]; ok && now.Sub(sent) < s.window {
+		s.suppressed++
+		return true
+	}
+	// Forget expired alerts so the map stays bounded
+	for k, sent := range s.lastSent {
+		if now.Sub(sent) >= s.window {
+			delete(s.lastSent, k)
+		}
+	}
+	s.lastSent[key] = now
+	return false
+}
+
+// slackMessage is the payload of a Slack incoming webhook
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// encodeSlackAlert renders an alert as a Slack message. The top-level text
+// is the notification fallback; the attachment holds the details.
+func encodeSlackAlert(alert Alert) ([]byte, error) {
+	level := strings.ToUpper(string(alert.Level))
+	blocks := []slackBlock{{
+		Type: "section",
+		Text: &slackText{Type: "mrkdwn", Text: fmt.Sprintf("*%s:* %s", level, alert.Message)},
+	}}
+
+	if alert.Metrics != nil {
+		m := alert.Metrics
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Fields: []slackText{
+				{Type: "mrkdwn", Text: fmt.Sprintf("*GC pause*\n%.2fms", float64(m.GCPauseTime)/1e6)},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Memory pressure*\n%.1f%%", m.MemoryPressure*100)},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*GOGC*\n%d", m.CurrentGOGC)},
+			},
+		})
+	}
+
+	if alert.Resolution != "" {
+		blocks = append(blocks, slackBlock{
+			Type:     "context",
+			Elements: []slackText{{Type: "mrkdwn", Text: "Resolution: " + alert.Resolution}},
+		})
+	}
+
+	color, ok := slackColors[alert.Level]
+	if !ok {
+		color = slackColors[AlertLevelInfo]
+	}
+
+	return json.Marshal(slackMessage{
+		Text:        fmt.Sprintf("[%s] %s", alert.Level, alert.Message),
+		Attachments: []slackAttachment{{Color: color, Blocks: blocks}},
+	})
+}
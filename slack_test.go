@@ -0,0 +1,105 @@
+package autotune
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEncodeSlackAlert tests the Slack message layout and level colors
+func TestEncodeSlackAlert(t *testing.T) {
+	payload, err := encodeSlackAlert(Alert{
+		Level:      AlertLevelCritical,
+		Message:    "High GC pause time: 120.00ms",
+		Metrics:    &Metrics{GCPauseTime: 120 * time.Millisecond, MemoryPressure: 0.5, CurrentGOGC: 200},
+		Resolution: "Consider tuning GOGC",
+	})
+	require.NoError(t, err)
+
+	var message slackMessage
+	require.NoError(t, json.Unmarshal(payload, &message))
+	assert.Equal(t, "[critical] High GC pause time: 120.00ms", message.Text)
+	require.Len(t, message.Attachments, 1)
+	attachment := message.Attachments[0]
+	assert.Equal(t, "#a30200", attachment.Color)
+	require.Len(t, attachment.Blocks, 3)
+	assert.Equal(t, "*CRITICAL:* High GC pause time: 120.00ms", attachment.Blocks[0].Text.Text)
+	assert.Equal(t, []slackText{
+		{Type: "mrkdwn", Text: "*GC pause*\n120.00ms"},
+		{Type: "mrkdwn", Text: "*Memory pressure*\n50.0%"},
+		{Type: "mrkdwn", Text: "*GOGC*\n200"},
+	}, attachment.Blocks[1].Fields)
+	assert.Equal(t, "Resolution: Consider tuning GOGC", attachment.Blocks[2].Elements[0].Text)
+
+	for level, color := range map[AlertLevel]string{AlertLevelInfo: "#2eb886", AlertLevelWarning: "#daa038"} {
+		payload, err := encodeSlackAlert(Alert{Level: level, Message: "test"})
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(payload, &message))
+		assert.Equal(t, color, message.Attachments[0].Color)
+		assert.Len(t, message.Attachments[0].Blocks, 1, "no metrics or resolution")
+	}
+}
+
+// TestSlackAlertObserverDuplicates tests that repeats of an alert within
+// the duplicate window are suppressed
+func TestSlackAlertObserverDuplicates(t *testing.T) {
+	received := make(chan slackMessage, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message slackMessage
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(body, &message))
+		received <- message
+	}))
+	defer server.Close()
+
+	observer, err := NewSlackAlertObserver(server.URL, WithSlackDuplicateWindow(time.Minute), WithSlackLogger(silentLogger{}))
+	require.NoError(t, err)
+	defer observer.Stop()
+
+	now := time.Unix(1700000000, 0)
+	observer.now = func() time.Time { return now }
+
+	observer.OnAlert(Alert{Level: AlertLevelWarning, Message: "High memory pressure: 85.2% of the container limit"})
+	observer.OnAlert(Alert{Level: AlertLevelWarning, Message: "High memory pressure: 86.0% of the container limit"})
+	// A different level isn't a duplicate
+	observer.OnAlert(Alert{Level: AlertLevelCritical, Message: "Critical memory pressure: 91.0% of the container limit"})
+
+	now = now.Add(time.Minute)
+	observer.OnAlert(Alert{Level: AlertLevelWarning, Message: "High memory pressure: 87.5% of the container limit"})
+
+	var texts []string
+	for i := 0; i < 3; i++ {
+		select {
+		case message := <-received:
+			texts = append(texts, message.Text)
+		case <-time.After(2 * time.Second):
+			t.Fatal("alert not delivered")
+		}
+	}
+	assert.Equal(t, []string{
+		"[warning] High memory pressure: 85.2% of the container limit",
+		"[critical] Critical memory pressure: 91.0% of the container limit",
+		"[warning] High memory pressure: 87.5% of the container limit",
+	}, texts)
+	assert.Equal(t, int64(1), observer.Stats()["suppressed"])
+}
+
+// TestSlackAlertObserverNoWindow tests that a zero window posts every alert
+func TestSlackAlertObserverNoWindow(t *testing.T) {
+	observer, err := NewSlackAlertObserver("https://hooks.slack.com/services/T/B/X", WithSlackDuplicateWindow(0))
+	require.NoError(t, err)
+	observer.Stop()
+
+	alert := Alert{Level: AlertLevelWarning, Message: "repeated"}
+	assert.False(t, observer.duplicate(alert))
+	assert.False(t, observer.duplicate(alert))
+
+	_, err = NewSlackAlertObserver("not a url")
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package autotune
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultSLORecoveryMargin is the fraction below the pause target pauses
+	// must fall to end a breach when SLOConfig.RecoveryMargin is zero
+	defaultSLORecoveryMargin = 0.1
+	// sloAggressivenessBoost multiplies the aggressiveness while the tuner
+	// acts on a breach
+	sloAggressivenessBoost = 2.0
+)
+
+// SLOStatus is the state of the pause-time SLO
+type SLOStatus string
+
+const (
+	// SLOStatusDisabled means SLO mode is off
+	SLOStatusDisabled SLOStatus = "disabled"
+	// SLOStatusMet means GC pauses are within the SLO
+	SLOStatusMet SLOStatus = "met"
+	// SLOStatusBreached means GC pauses exceed the SLO, or the tuner is
+	// still acting on a breach that hasn't recovered past the margin
+	SLOStatusBreached SLOStatus = "breached"
+)
+
+// SLOConfig configures SLO mode, in which the tuner collects metrics but
+// leaves GOGC alone until the GC pause SLO has been breached for
+// BreachDuration. It then tunes with doubled aggressiveness until pauses
+// are back below the target by RecoveryMargin, and goes dormant again.
+type SLOConfig struct {
+	// PauseTarget is the GC pause time SLO (zero disables SLO mode)
+	PauseTarget time.Duration
+	// BreachDuration is how long pauses must exceed PauseTarget before the
+	// tuner acts (zero means from the first breaching cycle)
+	BreachDuration time.Duration
+	// RecoveryMargin is the fraction below PauseTarget pauses must fall for
+	// the breach to end, in [0, 1) (zero means 0.1)
+	RecoveryMargin float64
+}
+
+// enabled reports whether SLO mode is on
+func (c SLOConfig) enabled() bool {
+	return c.PauseTarget > 0
+}
+
+// validate checks the SLO config
+func (c SLOConfig) validate() error {
+	if c.PauseTarget < 0 {
+		return fmt.Errorf("SLO pause target must not be negative")
+	}
+	if c.BreachDuration < 0 {
+		return fmt.Errorf("SLO breach duration must not be negative")
+	}
+	if c.RecoveryMargin < 0 || c.RecoveryMargin >= 1 {
+		return fmt.Errorf("SLO recovery margin must be in [0, 1)")
+	}
+	return nil
+}
+
+// recoveryThreshold returns the pause time below which a breach ends
+func (c SLOConfig) recoveryThreshold() time.Duration {
+	margin := c.RecoveryMargin
+	if margin == 0 {
+		margin = defaultSLORecoveryMargin
+	}
+	return time.Duration(float64(c.PauseTarget) * (1 - margin))
+}
+
+// updateSLOLocked advances the SLO state with a cycle's pause time and
+// reports whether the tuner may make decisions this cycle. It always
+// returns true outside SLO mode. t.mu must be held for writing.
+func (t *Tuner) updateSLOLocked(metrics Metrics) bool {
+	slo := t.config.SLOMode
+	if !slo.enabled() {
+		return true
+	}
+
+	now := t.now()
+	pause := metrics.GCPauseTime
+
+	switch {
+	case pause > slo.PauseTarget:
+		if t.sloBreachStart.IsZero() {
+			t.sloBreachStart = now
+			t.config.Logger.Warn("GC pause %v breaches the SLO of %v", pause, slo.PauseTarget)
+		}
+		if !t.sloActing && now.Sub(t.sloBreachStart) >= slo.BreachDuration {
+			t.sloActing = true
+			t.config.Logger.Warn("GC pause SLO breached for %v, tuning until pauses recover below %v",
+				now.Sub(t.sloBreachStart).Round(time.Second), slo.recoveryThreshold())
+		}
+	case !t.sloActing || pause <= slo.recoveryThreshold():
+		if t.sloActing {
+			t.config.Logger.Info("GC pause SLO recovered after %v, tuning is dormant",
+				now.Sub(t.sloBreachStart).Round(time.Second))
+		}
+		t.sloActing = false
+		t.sloBreachStart = time.Time{}
+	}
+
+	return t.sloActing
+}
+
+// sloStatusLocked returns the SLO status and how long the current breach
+// has lasted. t.mu must be held.
+func (t *Tuner) sloStatusLocked() (SLOStatus, time.Duration) {
+	if !t.config.SLOMode.enabled() {
+		return SLOStatusDisabled, 0
+	}
+	if t.sloBreachStart.IsZero() {
+		return SLOStatusMet, 0
+	}
+	return SLOStatusBreached, clockDistance(t.now(), t.sloBreachStart)
+}
+
+// sloBoost returns the aggressiveness multiplier for SLO mode
+func (t *Tuner) sloBoost() float64 {
+	if t.sloActing {
+		return sloAggressivenessBoost
+	}
+	return 1
+}
@@ -0,0 +1,90 @@
+package autotune
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSLOMode tests that the tuner stays dormant until the SLO has been
+// breached for the breach duration and goes dormant again on recovery
+func TestSLOMode(t *testing.T) {
+	config := DefaultConfig()
+	config.ExternalMetrics = true
+	config.Logger = &mockLogger{}
+	config.SLOMode = SLOConfig{PauseTarget: 10 * time.Millisecond, BreachDuration: time.Minute}
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	clock := time.Now()
+	tuner.now = func() time.Time { return clock }
+	tuner.setGCPercent = func(value int) int { return 100 }
+
+	// ingest feeds a sample with the given pause, advancing the clock
+	ingest := func(pause time.Duration, advance time.Duration) {
+		clock = clock.Add(advance)
+		require.NoError(t, tuner.IngestMetrics(Metrics{
+			GCPauseTime:    pause,
+			GCFrequency:    1.0,
+			MemoryPressure: 0.5,
+			CurrentGOGC:    100,
+		}))
+	}
+	status := func() (SLOStatus, float64) {
+		stats := tuner.GetStats()
+		return stats["slo_status"].(SLOStatus), stats["slo_breach_seconds"].(float64)
+	}
+
+	// Healthy: metrics are collected, nothing is tuned
+	for i := 0; i < 3; i++ {
+		ingest(5*time.Millisecond, 10*time.Second)
+	}
+	s, _ := status()
+	assert.Equal(t, SLOStatusMet, s)
+	assert.Empty(t, tuner.Decisions())
+
+	// Breached, but not for long enough yet
+	ingest(50*time.Millisecond, 10*time.Second)
+	ingest(50*time.Millisecond, 30*time.Second)
+	s, seconds := status()
+	assert.Equal(t, SLOStatusBreached, s)
+	assert.Equal(t, 30.0, seconds)
+	assert.Empty(t, tuner.Decisions())
+
+	// Breached for the breach duration: act with boosted aggressiveness
+	ingest(50*time.Millisecond, 40*time.Second)
+	require.NotEmpty(t, tuner.Decisions())
+	assert.Greater(t, tuner.Decisions()[0].NewGOGC, 100)
+	assert.Equal(t, 2*config.TuningAggressiveness, tuner.aggressiveness())
+
+	// Within the SLO but not past the recovery margin: still acting
+	ingest(9500*time.Microsecond, 10*time.Second)
+	s, _ = status()
+	assert.Equal(t, SLOStatusBreached, s)
+
+	obs := NewObservabilityServer(DefaultObservabilityConfig(), tuner)
+	w := httptest.NewRecorder()
+	obs.handleHealth(w, httptest.NewRequest("GET", "/health", nil))
+	var health map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &health))
+	assert.Equal(t, "warning", health["status"])
+	assert.Equal(t, "breached", health["slo"].(map[string]interface{})["status"])
+
+	// Recovered: dormant again
+	ingest(5*time.Millisecond, 10*time.Second)
+	s, seconds = status()
+	assert.Equal(t, SLOStatusMet, s)
+	assert.Zero(t, seconds)
+	assert.Equal(t, config.TuningAggressiveness, tuner.aggressiveness())
+
+	decisions := len(tuner.Decisions())
+	ingest(5*time.Millisecond, 10*time.Second)
+	assert.Len(t, tuner.Decisions(), decisions)
+
+	config.SLOMode.RecoveryMargin = 1
+	assert.Error(t, validateConfig(config))
+}
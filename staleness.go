@@ -0,0 +1,55 @@
+package autotune
+
+import "time"
+
+// staleCycles is the number of monitor intervals without a completed tuning
+// cycle after which the tuner's metrics count as stale by default
+const staleCycles = 3
+
+// LastCycleTime returns when the last tuning cycle completed, or the zero
+// time if none has
+func (t *Tuner) LastCycleTime() time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.lastCycleTime
+}
+
+// metricsStaleLocked reports whether the monitor loop has gone longer than
+// threshold without completing a cycle, which means it is wedged and the
+// tuner state is frozen. A zero threshold means three monitor intervals. An
+// idle tuner is never stale. Callers must hold the lock.
+func (t *Tuner) metricsStaleLocked(threshold time.Duration) bool {
+	if !t.running {
+		return false
+	}
+	if threshold <= 0 {
+		threshold = staleCycles * t.config.MonitorInterval
+	}
+
+	// Before the first cycle, measure from Start
+	last := t.lastCycleTime
+	if last.Before(t.startTime) {
+		last = t.startTime
+	}
+	return t.now().Sub(last) > threshold
+}
+
+// secondsSinceLastDecisionLocked returns the time since the last applied
+// decision, or since Start if there is none, in seconds. It is zero for a
+// tuner that was never started and has made no decisions. Callers must
+// hold the lock.
+func (t *Tuner) secondsSinceLastDecisionLocked() float64 {
+	since := t.startTime
+	if last, ok := t.decisionHistory.last(); ok {
+		since = last.Timestamp
+	}
+	if since.IsZero() {
+		return 0
+	}
+
+	elapsed := t.now().Sub(since)
+	if elapsed < 0 {
+		return 0
+	}
+	return elapsed.Seconds()
+}
@@ -0,0 +1,93 @@
+package autotune
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMetricsStale tests flagging a wedged monitor loop
+func TestMetricsStale(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	obsConfig := DefaultObservabilityConfig()
+	obsConfig.ExportCycleTimestamp = true
+	obs := NewObservabilityServer(obsConfig, tuner)
+
+	scrape := func() string {
+		w := httptest.NewRecorder()
+		obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics?format=prometheus", nil))
+		return w.Body.String()
+	}
+
+	// An idle tuner isn't stale
+	assert.Contains(t, scrape(), "autotune_metrics_stale 0\n")
+	assert.NotContains(t, scrape(), "autotune_last_cycle_timestamp_seconds")
+
+	// Started long ago without completing a cycle
+	tuner.mu.Lock()
+	tuner.running = true
+	tuner.startTime = time.Now().Add(-10 * time.Minute)
+	tuner.mu.Unlock()
+
+	assert.Contains(t, scrape(), "autotune_metrics_stale 1\n")
+	assert.Equal(t, true, tuner.GetStats()["metrics_stale"])
+
+	tuner.markCycleComplete()
+	body := scrape()
+	assert.Contains(t, body, "autotune_metrics_stale 0\n")
+	assert.Contains(t, body, "autotune_last_cycle_timestamp_seconds ")
+	assert.WithinDuration(t, time.Now(), tuner.LastCycleTime(), time.Second)
+
+	// The last cycle ages past the threshold
+	tuner.mu.Lock()
+	tuner.lastCycleTime = time.Now().Add(-2 * time.Minute)
+	tuner.mu.Unlock()
+	assert.Contains(t, scrape(), "autotune_metrics_stale 1\n")
+
+	obsConfig.StaleThreshold = 5 * time.Minute
+	assert.Contains(t, scrape(), "autotune_metrics_stale 0\n")
+
+	// /stats uses the same threshold as the Prometheus output
+	w := httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/stats", nil))
+	assert.Contains(t, w.Body.String(), `"metrics_stale":false`)
+	assert.Equal(t, true, tuner.GetStats()["metrics_stale"])
+}
+
+// TestSecondsSinceLastDecision tests measuring how long the tuner has been
+// quiet
+func TestSecondsSinceLastDecision(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	now := time.Now()
+	tuner.now = func() time.Time { return now }
+
+	// Never started and no decisions
+	assert.Equal(t, 0.0, tuner.GetStats()["seconds_since_last_decision"])
+
+	// Measured from Start without decisions
+	tuner.startTime = now.Add(-90 * time.Second)
+	assert.Equal(t, 90.0, tuner.GetStats()["seconds_since_last_decision"])
+
+	// Measured from the last decision
+	tuner.decisionHistory.reset([]TuningDecision{
+		{OldGOGC: 100, NewGOGC: 150, Timestamp: now.Add(-60 * time.Second)},
+		{OldGOGC: 150, NewGOGC: 200, Timestamp: now.Add(-15 * time.Second)},
+	})
+	assert.Equal(t, 15.0, tuner.GetStats()["seconds_since_last_decision"])
+
+	exporter := NewMetricsExporter(tuner)
+	output, err := exporter.ExportToPrometheus()
+	require.NoError(t, err)
+	assert.Contains(t, output, "autotune_seconds_since_last_decision 15.000000\n")
+
+	// A decision timestamped in the future (clock stepped back) reads as zero
+	tuner.decisionHistory.update(1, func(d *TuningDecision) { d.Timestamp = now.Add(time.Minute) })
+	assert.Equal(t, 0.0, tuner.GetStats()["seconds_since_last_decision"])
+}
@@ -0,0 +1,254 @@
+package autotune
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// statsdMaxPacketSize keeps each UDP datagram within a typical MTU so
+	// it isn't fragmented or truncated
+	statsdMaxPacketSize = 1432
+	// statsdTimeout bounds writing one datagram
+	statsdTimeout = time.Second
+)
+
+// validateStatsDPrefix checks that a prefix can start a StatsD metric name
+func validateStatsDPrefix(prefix string) error {
+	if strings.ContainsAny(prefix, " \t\r\n:|@#") {
+		return fmt.Errorf("statsd prefix %q must not contain whitespace or any of :|@#", prefix)
+	}
+	return nil
+}
+
+// validateStatsDTags checks that tags can be sent in the DogStatsD tag
+// section
+func validateStatsDTags(tags map[string]string) error {
+	for key, value := range tags {
+		if key == "" {
+			return fmt.Errorf("statsd tag keys must not be empty")
+		}
+		if strings.ContainsAny(key, " \t\r\n:|@#,") || strings.ContainsAny(value, " \t\r\n|@#,") {
+			return fmt.Errorf("statsd tag %q=%q must not contain whitespace or any of |@#,", key, value)
+		}
+	}
+	return nil
+}
+
+// formatStatsDTags renders tags in DogStatsD form, "|#key:value,...",
+// sorted by key, or an empty string without tags
+func formatStatsDTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key
+		if tags[key] != "" {
+			pairs[i] += ":" + tags[key]
+		}
+	}
+	return "|#" + strings.Join(pairs, ",")
+}
+
+// ExportToStatsD exports current metrics as StatsD gauges, one
+// "<prefix>.<name>:<value>|g" line per metric with the same names as
+// ExportToGraphite. Tags are appended DogStatsD style, "|#key:value", and
+// omitted when empty; plain StatsD servers ignore or reject them.
+func (me *MetricsExporter) ExportToStatsD(prefix string, tags map[string]string) ([]byte, error) {
+	if err := validateStatsDPrefix(prefix); err != nil {
+		return nil, err
+	}
+	if err := validateStatsDTags(tags); err != nil {
+		return nil, err
+	}
+	prefix = strings.TrimSuffix(prefix, ".")
+	suffix := formatStatsDTags(tags)
+
+	var buf bytes.Buffer
+	for _, metric := range pushMetrics(me.tuner.GetMetrics(), me.tuner.GetStats()) {
+		name := metric.name
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		fmt.Fprintf(&buf, "%s:%s|g%s\n", name, metric.value, suffix)
+	}
+	return buf.Bytes(), nil
+}
+
+// splitStatsDPackets splits newline-terminated lines into datagrams of at
+// most statsdMaxPacketSize bytes without breaking lines. A line longer than
+// the limit is sent on its own.
+func splitStatsDPackets(data []byte) [][]byte {
+	var packets [][]byte
+	var packet []byte
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if len(packet) > 0 && len(packet)+len(line) > statsdMaxPacketSize {
+			packets = append(packets, packet)
+			packet = nil
+		}
+		packet = append(packet, line...)
+	}
+	if len(packet) > 0 {
+		packets = append(packets, packet)
+	}
+	return packets
+}
+
+// StatsDExporter periodically pushes metrics to a StatsD or DogStatsD
+// server over UDP. Datagrams are sent from a background worker through a
+// bounded queue, so a backed-up socket never blocks the tuner; datagrams
+// that don't fit in the queue are dropped and counted.
+type StatsDExporter struct {
+	exporter *MetricsExporter
+	addr     string
+	prefix   string
+	tags     map[string]string
+	interval time.Duration
+	logger   Logger
+	queue    *pushQueue
+
+	// dial connects to the server, replaceable in tests
+	dial func(network, addr string) (net.Conn, error)
+
+	connMu sync.Mutex
+	conn   net.Conn
+
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+	stopped sync.Once
+}
+
+// NewStatsDExporter creates an exporter pushing the tuner's metrics to the
+// StatsD server at addr (host:port) every interval, under prefix and with
+// optional DogStatsD tags such as pod and namespace
+func NewStatsDExporter(tuner *Tuner, addr, prefix string, tags map[string]string, interval time.Duration) (*StatsDExporter, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("statsd address is required")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("statsd flush interval must be positive, got %v", interval)
+	}
+	if err := validateStatsDPrefix(prefix); err != nil {
+		return nil, err
+	}
+	if err := validateStatsDTags(tags); err != nil {
+		return nil, err
+	}
+
+	copied := make(map[string]string, len(tags))
+	for key, value := range tags {
+		copied[key] = value
+	}
+
+	se := &StatsDExporter{
+		exporter: NewMetricsExporter(tuner),
+		addr:     addr,
+		prefix:   prefix,
+		tags:     copied,
+		interval: interval,
+		logger:   tuner.config.Logger,
+		dial:     net.Dial,
+		done:     make(chan struct{}),
+	}
+	// UDP delivery isn't confirmed, so a failed write is only retried once
+	se.queue = newPushQueue("StatsD", 10, 2, se.push, se.logger, tuner.newRand())
+	return se, nil
+}
+
+// Start begins pushing metrics every interval; calling it more than once is
+// a no-op
+func (se *StatsDExporter) Start() error {
+	se.once.Do(func() {
+		se.queue.start()
+		se.wg.Add(1)
+		go se.run()
+	})
+	return nil
+}
+
+// Stop stops pushing and closes the socket. Metrics still queued are
+// discarded.
+func (se *StatsDExporter) Stop() error {
+	se.stopped.Do(func() {
+		close(se.done)
+	})
+	se.wg.Wait()
+	se.queue.stop()
+
+	se.connMu.Lock()
+	defer se.connMu.Unlock()
+	if se.conn != nil {
+		err := se.conn.Close()
+		se.conn = nil
+		return err
+	}
+	return nil
+}
+
+// Stats returns delivery counters, counting datagrams
+func (se *StatsDExporter) Stats() map[string]interface{} {
+	return se.queue.stats()
+}
+
+// run exports and queues metrics every interval until stopped
+func (se *StatsDExporter) run() {
+	defer se.wg.Done()
+
+	ticker := time.NewTicker(se.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-se.done:
+			return
+		case <-ticker.C:
+			data, err := se.exporter.ExportToStatsD(se.prefix, se.tags)
+			if err != nil {
+				se.logger.Warn("Failed to export StatsD metrics: %v", err)
+				continue
+			}
+			for _, packet := range splitStatsDPackets(data) {
+				se.queue.enqueue(packet)
+			}
+		}
+	}
+}
+
+// push sends one datagram, opening the socket first if needed. A failed
+// write drops the socket so the next attempt re-resolves the address.
+func (se *StatsDExporter) push(packet []byte) error {
+	se.connMu.Lock()
+	defer se.connMu.Unlock()
+
+	if se.conn == nil {
+		conn, err := se.dial("udp", se.addr)
+		if err != nil {
+			return fmt.Errorf("failed to open statsd socket to %s: %w", se.addr, err)
+		}
+		se.conn = conn
+	}
+
+	se.conn.SetWriteDeadline(time.Now().Add(statsdTimeout))
+	if _, err := se.conn.Write(packet); err != nil {
+		se.conn.Close()
+		se.conn = nil
+		return fmt.Errorf("failed to write to statsd at %s: %w", se.addr, err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package autotune
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExportToStatsD tests the StatsD line format with DogStatsD tags
+func TestExportToStatsD(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+	tuner.AddMetricHook("queue_depth", func() float64 { return 7 })
+	tuner.metricsHistory.push(tuner.collectMetrics())
+
+	exporter := NewMetricsExporter(tuner)
+	data, err := exporter.ExportToStatsD("app.autotune.", map[string]string{"pod": "web-1", "namespace": "prod"})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.NotEmpty(t, lines)
+	for _, line := range lines {
+		assert.True(t, strings.HasPrefix(line, "app.autotune."), line)
+		assert.True(t, strings.HasSuffix(line, "|g|#namespace:prod,pod:web-1"), line)
+	}
+	assert.Contains(t, string(data), "app.autotune.gc_pause_ns:")
+	assert.Contains(t, string(data), "app.autotune.custom.queue_depth:7|g|")
+
+	// Without tags or prefix
+	data, err = exporter.ExportToStatsD("", nil)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "running:0|g\n"), string(data))
+
+	_, err = exporter.ExportToStatsD("bad:prefix", nil)
+	assert.Error(t, err)
+	_, err = exporter.ExportToStatsD("app", map[string]string{"pod": "a,b"})
+	assert.Error(t, err)
+}
+
+// TestSplitStatsDPackets tests that datagrams stay within the size limit
+// without breaking lines
+func TestSplitStatsDPackets(t *testing.T) {
+	line := strings.Repeat("x", 99) + "\n"
+	data := []byte(strings.Repeat(line, 30))
+
+	packets := splitStatsDPackets(data)
+	require.Len(t, packets, 3)
+	for _, packet := range packets {
+		assert.LessOrEqual(t, len(packet), statsdMaxPacketSize)
+		assert.True(t, bytes.HasSuffix(packet, []byte("\n")))
+	}
+	assert.Equal(t, data, bytes.Join(packets, nil))
+
+	long := []byte(strings.Repeat("y", 2000) + "\n")
+	assert.Equal(t, [][]byte{long}, splitStatsDPackets(long))
+}
+
+// TestStatsDExporter tests pushing datagrams over UDP
+func TestStatsDExporter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	config := DefaultConfig()
+	config.Logger = &mockLogger{}
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+
+	_, err = NewStatsDExporter(tuner, conn.LocalAddr().String(), "app", nil, 0)
+	assert.Error(t, err)
+	_, err = NewStatsDExporter(tuner, "", "app", nil, time.Second)
+	assert.Error(t, err)
+
+	se, err := NewStatsDExporter(tuner, conn.LocalAddr().String(), "app", map[string]string{"pod": "web-1"}, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, se.Start())
+	defer se.Stop()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 65536)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(buf[:n]), "app.running:0|g|#pod:web-1\n"), string(buf[:n]))
+	assert.Eventually(t, func() bool { return se.Stats()["pushed"].(int64) > 0 }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,239 @@
+package autotune
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Strategy is the decision core of a tuner: each cycle it proposes a GOGC
+// value, and the tuner decides whether and how far to move towards it. The
+// safety rails stay with the tuner: the minimum change threshold, the
+// confidence threshold, anti-oscillation, MaxChangePerInterval, the GOGC
+// bounds and rounding, reverts, the pause ceiling and safe mode.
+//
+// Propose is called with the cycle's metrics, the metrics history ending
+// with them (oldest first) and the tuner's config; it must not modify or
+// retain history or cfg. It is also called by Recommend with fresh metrics
+// that aren't in the history yet.
+type Strategy interface {
+	Propose(metrics Metrics, history []Metrics, cfg *Config) Proposal
+}
+
+// Proposal is a Strategy's proposed GOGC and why
+type Proposal struct {
+	// GOGC is the proposed target
+	GOGC int
+	// Confidence in the proposal, from 0 to 1; proposals below 0.6 aren't
+	// applied. Zero means the tuner's estimate from the stability of the
+	// metrics history.
+	Confidence float64
+	// Reason describes what drove the proposal, e.g. "GC pause 12.00ms >
+	// target 10.00ms"; the tuner prefixes the resulting GOGC change
+	Reason string
+	// Category classifies the decision (empty means CategoryMixed)
+	Category DecisionCategory
+}
+
+// TuningStrategy is a simpler form of Strategy that only computes the
+// target GOGC. Wrap it with TargetStrategy to use it as a Strategy.
+type TuningStrategy interface {
+	ComputeTarget(metrics Metrics, history []Metrics, cfg *Config) int
+}
+
+// TargetStrategy adapts a TuningStrategy to a Strategy whose proposals have
+// the tuner's estimated confidence and no reason
+func TargetStrategy(strategy TuningStrategy) Strategy {
+	return targetStrategy{strategy}
+}
+
+type targetStrategy struct {
+	strategy TuningStrategy
+}
+
+func (s targetStrategy) Propose(metrics Metrics, history []Metrics, cfg *Config) Proposal {
+	return Proposal{GOGC: s.strategy.ComputeTarget(metrics, history, cfg)}
+}
+
+// DefaultStrategy is the built-in heuristic: it combines factors for the
+// pause time, memory pressure, GC frequency and CPU overhead, smoothed and
+// scaled by the effective aggressiveness. It depends on the state of the
+// tuner it was obtained from, see Tuner.DefaultStrategy.
+type DefaultStrategy struct {
+	tuner *Tuner
+}
+
+// DefaultStrategy returns the tuner's built-in heuristic, for strategies
+// that refine its proposals rather than replace them
+func (t *Tuner) DefaultStrategy() *DefaultStrategy {
+	return &DefaultStrategy{tuner: t}
+}
+
+// Propose implements Strategy. It uses the tuner's config rather than cfg.
+func (s *DefaultStrategy) Propose(metrics Metrics, history []Metrics, cfg *Config) Proposal {
+	target, category := s.tuner.calculateTarget(metrics)
+	return Proposal{
+		GOGC:       target,
+		Confidence: s.tuner.calculateConfidence(metrics),
+		Reason:     joinStrings(s.tuner.reasonFactors(metrics), ", "),
+		Category:   category,
+	}
+}
+
+// SetStrategy replaces the tuner's strategy, see Config.Strategy. Passing
+// nil restores the default.
+func (t *Tuner) SetStrategy(strategy Strategy) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.config.Strategy = strategy
+}
+
+// propose returns the configured strategy's proposal, or the default
+// strategy's without one, with the zero fields filled in. ok is false when
+// the strategy panicked.
+func (t *Tuner) propose(metrics Metrics) (proposal Proposal, ok bool) {
+	strategy := t.config.Strategy
+	if strategy == nil {
+		return t.DefaultStrategy().Propose(metrics, t.metricsHistory.items(), t.config), true
+	}
+
+	if !t.safeCall("tuning_strategy", func() {
+		proposal = strategy.Propose(metrics, t.metricsHistory.items(), t.config)
+	}) {
+		return Proposal{}, false
+	}
+
+	if proposal.Confidence == 0 {
+		proposal.Confidence = t.calculateConfidence(metrics)
+	}
+	proposal.Confidence = math.Max(0, math.Min(1, proposal.Confidence))
+	if proposal.Category == "" {
+		proposal.Category = CategoryMixed
+	}
+	return proposal, true
+}
+
+// PIDStrategy is a Strategy that drives the GC pause selected by
+// PauseTarget towards TargetLatency with a PID controller. The error is the
+// pause time's relative deviation from the target, positive when pauses are
+// too long, and the output scales the GOGC the controller started from:
+//
+//	target = start * (1 + Kp*e + Ki*∫e dt + Kd*de/dt)
+//
+// with time in seconds. While error pushes the output past MinGOGC or
+// MaxGOGC, the integral is clamped to the value that holds the output at
+// the bound, so the controller backs off as soon as the error reverses
+// instead of first unwinding an integral built up while GOGC was pinned.
+//
+// The controller state advances once per recorded sample. Samples that
+// aren't the last in the history, such as those Recommend evaluates, are
+// answered without updating it. A PIDStrategy must not be shared between
+// tuners.
+type PIDStrategy struct {
+	kp, ki, kd float64
+
+	mu         sync.Mutex
+	started    bool
+	start      int
+	integral   float64
+	lastError  float64
+	lastTime   time.Time
+	lastTarget int
+}
+
+// NewPIDStrategy creates a PID strategy with the proportional, integral and
+// derivative gains, which must not be negative. Kp 0.5, Ki 0.01 and Kd 0
+// are a reasonable start; raise Ki to remove a persistent offset from the
+// target faster, at the risk of overshooting.
+func NewPIDStrategy(kp, ki, kd float64) (*PIDStrategy, error) {
+	if kp < 0 || ki < 0 || kd < 0 {
+		return nil, fmt.Errorf("PID gains must not be negative, got Kp=%g Ki=%g Kd=%g", kp, ki, kd)
+	}
+	if kp == 0 && ki == 0 && kd == 0 {
+		return nil, fmt.Errorf("at least one PID gain must be positive")
+	}
+	return &PIDStrategy{kp: kp, ki: ki, kd: kd}, nil
+}
+
+// Propose implements Strategy. Proposals are categorized as latency
+// decisions and carry the tuner's estimated confidence.
+func (p *PIDStrategy) Propose(metrics Metrics, history []Metrics, cfg *Config) Proposal {
+	target := p.ComputeTarget(metrics, history, cfg)
+	var reason string
+	if pause := metrics.Pause(cfg.PauseTarget); pause > 0 {
+		reason = fmt.Sprintf("PID control of GC pause %.2fms towards target %.2fms",
+			float64(pause)/1e6, float64(cfg.TargetLatency)/1e6)
+	}
+	return Proposal{GOGC: target, Reason: reason, Category: CategoryLatency}
+}
+
+// ComputeTarget implements TuningStrategy
+func (p *PIDStrategy) ComputeTarget(metrics Metrics, history []Metrics, cfg *Config) int {
+	// Without a pause there is nothing to control
+	pause := metrics.Pause(cfg.PauseTarget)
+	if pause <= 0 || cfg.TargetLatency <= 0 {
+		return metrics.CurrentGOGC
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.started {
+		p.started = true
+		p.start = metrics.CurrentGOGC
+	}
+
+	recorded := len(history) > 0 && history[len(history)-1].Timestamp.Equal(metrics.Timestamp)
+	if recorded && !p.lastTime.IsZero() && metrics.Timestamp.Equal(p.lastTime) {
+		return p.lastTarget
+	}
+
+	e := float64(pause-cfg.TargetLatency) / float64(cfg.TargetLatency)
+
+	dt := cfg.MonitorInterval.Seconds()
+	if !p.lastTime.IsZero() {
+		if elapsed := metrics.Timestamp.Sub(p.lastTime).Seconds(); elapsed > 0 {
+			dt = elapsed
+		}
+	}
+
+	integral := p.integral + e*dt
+	derivative := 0.0
+	if !p.lastTime.IsZero() && dt > 0 {
+		derivative = (e - p.lastError) / dt
+	}
+
+	// Anti-windup: while the output is past a bound and the error pushes
+	// further, clamp the integral to what puts the output at the bound
+	output := func(integral float64) float64 {
+		return float64(p.start) * sanitizeFactor(1+p.kp*e+p.ki*integral+p.kd*derivative)
+	}
+	raw := output(integral)
+	if p.ki > 0 && p.start > 0 {
+		atBound := func(bound int) float64 {
+			return (float64(bound)/float64(p.start) - 1 - p.kp*e - p.kd*derivative) / p.ki
+		}
+		switch {
+		case raw > float64(cfg.MaxGOGC) && e > 0:
+			integral = math.Min(integral, atBound(cfg.MaxGOGC))
+		case raw < float64(cfg.MinGOGC) && e < 0:
+			integral = math.Max(integral, atBound(cfg.MinGOGC))
+		}
+		raw = output(integral)
+	}
+
+	target := int(raw)
+	if recorded {
+		p.integral = integral
+		p.lastError = e
+		p.lastTime = metrics.Timestamp
+		p.lastTarget = target
+	}
+	return target
+}
+
+// fresh returns a PID strategy with the same gains and no state
+func (p *PIDStrategy) fresh() *PIDStrategy {
+	return &PIDStrategy{kp: p.kp, ki: p.ki, kd: p.kd}
+}
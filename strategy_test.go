@@ -0,0 +1,247 @@
+package autotune
+
+import (
+	"fmt"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedStrategy is a TuningStrategy always returning the same target
+type fixedStrategy struct {
+	target int
+	calls  int
+}
+
+func (s *fixedStrategy) ComputeTarget(metrics Metrics, history []Metrics, cfg *Config) int {
+	s.calls++
+	if s.target < 0 {
+		panic("strategy failure")
+	}
+	return s.target
+}
+
+// TestNewPIDStrategy tests validating PID gains
+func TestNewPIDStrategy(t *testing.T) {
+	_, err := NewPIDStrategy(0.5, 0.01, 0)
+	assert.NoError(t, err)
+	_, err = NewPIDStrategy(-0.5, 0.01, 0)
+	assert.Error(t, err)
+	_, err = NewPIDStrategy(0, 0, 0)
+	assert.Error(t, err)
+}
+
+// TestPIDStrategy tests that the controller moves GOGC against the pause
+// error and accumulates it over recorded samples only
+func TestPIDStrategy(t *testing.T) {
+	config := DefaultConfig()
+	config.MonitorInterval = 10 * time.Second
+	start := time.Now()
+
+	var history []Metrics
+	sample := func(pid *PIDStrategy, i int, pause time.Duration, gogc int) int {
+		metrics := Metrics{Timestamp: start.Add(time.Duration(i) * 10 * time.Second), GCPauseTime: pause, CurrentGOGC: gogc}
+		history = append(history, metrics)
+		return pid.ComputeTarget(metrics, history, config)
+	}
+
+	// Proportional only: the target follows the error's sign and size
+	pid, err := NewPIDStrategy(0.5, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 150, sample(pid, 0, 20*time.Millisecond, 100)) // e = 1
+	assert.Equal(t, 75, sample(pid, 1, 5*time.Millisecond, 150))   // e = -0.5, from the start
+	assert.Equal(t, 100, sample(pid, 2, 10*time.Millisecond, 75))  // on target
+
+	// No pause, no opinion
+	assert.Equal(t, 75, pid.ComputeTarget(Metrics{CurrentGOGC: 75}, history, config))
+
+	// The integral builds up while the error persists
+	history = nil
+	pid, err = NewPIDStrategy(0, 0.01, 0)
+	require.NoError(t, err)
+	first := sample(pid, 0, 20*time.Millisecond, 100)
+	second := sample(pid, 1, 20*time.Millisecond, first)
+	assert.Equal(t, 110, first) // 0.01 * 1 * 10s
+	assert.Equal(t, 120, second)
+
+	// Repeating the last recorded sample returns the same target
+	assert.Equal(t, second, pid.ComputeTarget(history[len(history)-1], history, config))
+
+	// Samples outside the history, as from Recommend, don't advance it
+	preview := Metrics{Timestamp: start.Add(25 * time.Second), GCPauseTime: 20 * time.Millisecond, CurrentGOGC: second}
+	assert.Equal(t, pid.ComputeTarget(preview, history, config), pid.ComputeTarget(preview, history, config))
+	assert.Equal(t, 130, sample(pid, 2, 20*time.Millisecond, second))
+}
+
+// TestPIDStrategyAntiWindup tests that the integral is clamped while GOGC is
+// pinned at a bound, so the controller backs off as soon as the error
+// reverses
+func TestPIDStrategyAntiWindup(t *testing.T) {
+	config := DefaultConfig()
+	config.MonitorInterval = 10 * time.Second
+	pid, err := NewPIDStrategy(0.1, 0.01, 0)
+	require.NoError(t, err)
+
+	start := time.Now()
+	var history []Metrics
+	sample := func(i int, pause time.Duration, gogc int) int {
+		metrics := Metrics{Timestamp: start.Add(time.Duration(i) * 10 * time.Second), GCPauseTime: pause, CurrentGOGC: gogc}
+		history = append(history, metrics)
+		return pid.ComputeTarget(metrics, history, config)
+	}
+
+	// Long pauses the tuner can't fix with GOGC pinned at the maximum
+	target := sample(0, 50*time.Millisecond, 400)
+	for i := 1; i < 100; i++ {
+		target = sample(i, 50*time.Millisecond, config.MaxGOGC)
+	}
+	assert.InDelta(t, config.MaxGOGC, target, 1)
+	assert.InDelta(t, 0.6, pid.integral*pid.ki, 0.001)
+
+	// Once pauses drop below the target, the controller backs off from the
+	// bound right away
+	assert.Less(t, sample(100, 2*time.Millisecond, config.MaxGOGC), config.MaxGOGC)
+}
+
+// TestTuningStrategy tests that the tuner follows a target-only strategy
+// through its usual safeguards
+func TestTuningStrategy(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	strategy := &fixedStrategy{target: 300}
+	config := DefaultConfig()
+	config.ExternalMetrics = true
+	config.Logger = &mockLogger{}
+	config.Strategy = TargetStrategy(strategy)
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	tuner.setGCPercent = func(value int) int { return 100 }
+
+	var decisions []TuningDecision
+	tuner.SetOnTuningDecision(func(decision TuningDecision) {
+		decisions = append(decisions, decision)
+	})
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, tuner.IngestMetrics(Metrics{
+			Timestamp:   now.Add(time.Duration(i) * time.Minute),
+			GCPauseTime: time.Millisecond,
+			CurrentGOGC: 100,
+		}))
+	}
+
+	require.NotEmpty(t, decisions)
+	assert.Equal(t, 300, decisions[0].DesiredGOGC)
+	assert.Equal(t, 100+config.MaxChangePerInterval, decisions[0].NewGOGC)
+	assert.Equal(t, ClampRateLimit, decisions[0].ClampedBy)
+	assert.Equal(t, CategoryMixed, decisions[0].Category)
+
+	// A panicking strategy skips the cycle
+	strategy.target = -1
+	calls, made := strategy.calls, len(decisions)
+	require.NoError(t, tuner.IngestMetrics(Metrics{Timestamp: now.Add(10 * time.Minute), GCPauseTime: time.Millisecond, CurrentGOGC: 150}))
+	assert.Greater(t, strategy.calls, calls)
+	assert.Len(t, decisions, made)
+}
+
+// TestSimulatePIDStrategy tests that simulations don't disturb a live PID
+// controller
+func TestSimulatePIDStrategy(t *testing.T) {
+	pid, err := NewPIDStrategy(0.5, 0.01, 0)
+	require.NoError(t, err)
+	config := DefaultConfig()
+	config.Strategy = pid
+
+	start := time.Now()
+	var history []Metrics
+	for i := 0; i < 5; i++ {
+		history = append(history, Metrics{Timestamp: start.Add(time.Duration(i) * time.Minute), GCPauseTime: 30 * time.Millisecond, CurrentGOGC: 100})
+	}
+	decisions, err := Simulate(config, history)
+	require.NoError(t, err)
+	require.NotEmpty(t, decisions)
+	assert.Equal(t, CategoryLatency, decisions[0].Category)
+	assert.Greater(t, decisions[0].NewGOGC, 100)
+
+	assert.False(t, pid.started)
+}
+
+// dampedStrategy wraps the default strategy, halving its proposed change
+type dampedStrategy struct {
+	base *DefaultStrategy
+}
+
+func (s dampedStrategy) Propose(metrics Metrics, history []Metrics, cfg *Config) Proposal {
+	proposal := s.base.Propose(metrics, history, cfg)
+	proposal.GOGC = metrics.CurrentGOGC + (proposal.GOGC-metrics.CurrentGOGC)/2
+	proposal.Reason = "damped: " + proposal.Reason
+	proposal.Confidence = 0.9
+	return proposal
+}
+
+// TestStrategyProposal tests that the tuner builds decisions from a
+// strategy's proposal
+func TestStrategyProposal(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	config := DefaultConfig()
+	config.ExternalMetrics = true
+	config.Logger = &mockLogger{}
+	config.MaxChangePerInterval = 1000
+	config.TuningAggressiveness = 1
+	config.FactorSmoothingAlpha = 1
+	tuner, err := NewTuner(config)
+	require.NoError(t, err)
+	tuner.setGCPercent = func(value int) int { return 100 }
+	tuner.qosClass = QoSClassUnknown
+
+	sample := Metrics{Timestamp: time.Now(), GCPauseTime: 50 * time.Millisecond, GCFrequency: 1, MemoryPressure: 0.5, CurrentGOGC: 100}
+	tuner.metricsHistory.reset([]Metrics{sample, sample})
+
+	// Without a strategy the default one decides
+	base := tuner.DefaultStrategy().Propose(sample, tuner.metricsHistory.items(), tuner.config)
+	assert.Equal(t, CategoryLatency, base.Category)
+	assert.Contains(t, base.Reason, "GC pause 50.00ms > target 10.00ms")
+	decision, _ := tuner.proposeTuningDecision(sample)
+	require.NotNil(t, decision)
+	assert.Equal(t, base.GOGC, decision.DesiredGOGC)
+	assert.Equal(t, fmt.Sprintf("increasing GOGC 100 -> %d due to: %s", decision.NewGOGC, base.Reason), decision.Reason)
+
+	// A strategy refining the default one
+	tuner.SetStrategy(dampedStrategy{base: tuner.DefaultStrategy()})
+	decision, _ = tuner.proposeTuningDecision(sample)
+	require.NotNil(t, decision)
+	assert.Equal(t, 100+(base.GOGC-100)/2, decision.DesiredGOGC)
+	assert.Equal(t, 0.9, decision.Confidence)
+	assert.Equal(t, CategoryLatency, decision.Category)
+	assert.Contains(t, decision.Reason, "due to: damped: GC pause")
+
+	// Low confidence proposals aren't applied
+	tuner.SetStrategy(proposalFunc(func(Metrics) Proposal { return Proposal{GOGC: 300, Confidence: 0.3} }))
+	decision, _ = tuner.proposeTuningDecision(sample)
+	assert.Nil(t, decision)
+
+	// Zero fields are filled in by the tuner
+	tuner.SetStrategy(proposalFunc(func(Metrics) Proposal { return Proposal{GOGC: 300} }))
+	decision, _ = tuner.proposeTuningDecision(sample)
+	require.NotNil(t, decision)
+	assert.Equal(t, tuner.calculateConfidence(sample), decision.Confidence)
+	assert.Equal(t, CategoryMixed, decision.Category)
+	assert.Equal(t, "Optimizing performance by increasing GOGC 100 -> 300", decision.Reason)
+
+	tuner.SetStrategy(nil)
+	decision, _ = tuner.proposeTuningDecision(sample)
+	require.NotNil(t, decision)
+	assert.Equal(t, base.GOGC, decision.DesiredGOGC)
+}
+
+// proposalFunc is a Strategy returning the function's proposal
+type proposalFunc func(Metrics) Proposal
+
+func (f proposalFunc) Propose(metrics Metrics, history []Metrics, cfg *Config) Proposal {
+	return f(metrics)
+}
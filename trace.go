@@ -0,0 +1,235 @@
+package autotune
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// traceQueueSize is the number of records a TraceRecorder buffers between
+	// the tuning cycle and its writer goroutine
+	traceQueueSize = 256
+	// traceBufferSize is the size of the TraceRecorder write buffer
+	traceBufferSize = 64 * 1024
+)
+
+// TraceRecorder is a MetricsObserver that records the metrics stream to a
+// file in the compact binary format (see EncodeBinaryMetrics), one fixed-size
+// record per cycle, for offline analysis and replay with ReadTrace.
+//
+// Records are handed to a writer goroutine and written through a buffer that
+// is flushed every flush interval and on Close, so recording never blocks the
+// tuning cycle; if the writer falls behind, records are dropped and counted.
+// When a write would grow the file beyond maxBytes, it is rotated to path.1
+// (replacing any previous rotation) and a new file is started.
+type TraceRecorder struct {
+	path     string
+	maxBytes int64
+
+	records chan []byte
+	done    chan struct{}
+	closeMu sync.Mutex
+	closed  bool
+
+	// Owned by the writer goroutine until done is closed
+	file   *os.File
+	writer *bufio.Writer
+	size   int64
+
+	mu      sync.Mutex
+	err     error
+	dropped int64
+}
+
+// NewTraceRecorder creates a trace recorder writing to path, appending to
+// any existing trace. A maxBytes of zero or less disables rotation and a
+// flushInterval of zero or less means one second.
+func NewTraceRecorder(path string, maxBytes int64, flushInterval time.Duration) (*TraceRecorder, error) {
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+
+	tr := &TraceRecorder{
+		path:     path,
+		maxBytes: maxBytes,
+		records:  make(chan []byte, traceQueueSize),
+		done:     make(chan struct{}),
+	}
+	if err := tr.open(); err != nil {
+		return nil, err
+	}
+
+	go tr.run(flushInterval)
+	return tr, nil
+}
+
+// OnMetrics queues the metrics for writing without blocking
+func (tr *TraceRecorder) OnMetrics(metrics Metrics) {
+	tr.closeMu.Lock()
+	defer tr.closeMu.Unlock()
+	if tr.closed {
+		return
+	}
+
+	select {
+	case tr.records <- EncodeBinaryMetrics(metrics):
+	default:
+		tr.mu.Lock()
+		tr.dropped++
+		tr.mu.Unlock()
+	}
+}
+
+// Dropped returns the number of records dropped because the writer fell
+// behind
+func (tr *TraceRecorder) Dropped() int64 {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return tr.dropped
+}
+
+// Err returns the last error encountered while writing, if any
+func (tr *TraceRecorder) Err() error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return tr.err
+}
+
+// Close writes any queued records, flushes the buffer and closes the file
+func (tr *TraceRecorder) Close() error {
+	tr.closeMu.Lock()
+	if tr.closed {
+		tr.closeMu.Unlock()
+		return nil
+	}
+	tr.closed = true
+	close(tr.records)
+	tr.closeMu.Unlock()
+
+	<-tr.done
+
+	if err := tr.writer.Flush(); err != nil {
+		tr.setErr(fmt.Errorf("failed to flush trace %s: %w", tr.path, err))
+	}
+	if err := tr.file.Close(); err != nil {
+		tr.setErr(fmt.Errorf("failed to close trace %s: %w", tr.path, err))
+	}
+	return tr.Err()
+}
+
+// run writes queued records and flushes periodically until the queue is
+// closed
+func (tr *TraceRecorder) run(flushInterval time.Duration) {
+	defer close(tr.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case record, ok := <-tr.records:
+			if !ok {
+				return
+			}
+			tr.write(record)
+		case <-ticker.C:
+			if err := tr.writer.Flush(); err != nil {
+				tr.setErr(fmt.Errorf("failed to flush trace %s: %w", tr.path, err))
+			}
+		}
+	}
+}
+
+// write appends a record, rotating first if it would exceed maxBytes
+func (tr *TraceRecorder) write(record []byte) {
+	if tr.maxBytes > 0 && tr.size > 0 && tr.size+int64(len(record)) > tr.maxBytes {
+		if err := tr.rotate(); err != nil {
+			tr.setErr(err)
+		}
+	}
+
+	n, err := tr.writer.Write(record)
+	tr.size += int64(n)
+	if err != nil {
+		tr.setErr(fmt.Errorf("failed to write trace %s: %w", tr.path, err))
+	}
+}
+
+// open opens the trace file for appending and records its current size
+func (tr *TraceRecorder) open() error {
+	file, err := os.OpenFile(tr.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open trace %s: %w", tr.path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat trace %s: %w", tr.path, err)
+	}
+
+	tr.file = file
+	tr.writer = bufio.NewWriterSize(file, traceBufferSize)
+	tr.size = info.Size()
+	return nil
+}
+
+// rotate flushes and moves the current file to path.1 and starts a new one.
+// If the rename fails, writing continues to the current file.
+func (tr *TraceRecorder) rotate() error {
+	if err := tr.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush trace %s: %w", tr.path, err)
+	}
+	if err := tr.file.Close(); err != nil {
+		return fmt.Errorf("failed to close trace %s: %w", tr.path, err)
+	}
+
+	renameErr := os.Rename(tr.path, tr.path+".1")
+	if err := tr.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return fmt.Errorf("failed to rotate trace %s: %w", tr.path, renameErr)
+	}
+	return nil
+}
+
+func (tr *TraceRecorder) setErr(err error) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.err = err
+}
+
+// ReadTrace reads the metrics recorded by a TraceRecorder, oldest first.
+// Records of earlier binary versions are decoded too, so a trace appended to
+// across an upgrade stays readable. A partial record at the end of the file,
+// left by a process that exited mid-write, is ignored. To read across a
+// rotation, read path.1 before path.
+func ReadTrace(path string) ([]Metrics, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trace %s: %w", path, err)
+	}
+
+	metrics := make([]Metrics, 0, len(data)/BinaryMetricsSize)
+	for i := 0; len(data) > 0; i++ {
+		size, ok := binaryRecordSize(data)
+		if !ok {
+			return nil, fmt.Errorf("failed to decode trace %s record %d: unsupported binary metrics version %d", path, i, data[0])
+		}
+		if len(data) < size {
+			break
+		}
+		m, err := DecodeBinaryMetrics(data[:size])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode trace %s record %d: %w", path, i, err)
+		}
+		metrics = append(metrics, m)
+		data = data[size:]
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,146 @@
+package autotune
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// traceMetrics returns n distinct metrics samples one second apart
+func traceMetrics(n int) []Metrics {
+	base := time.Unix(1700000000, 0)
+	metrics := make([]Metrics, n)
+	for i := range metrics {
+		metrics[i] = Metrics{
+			GCPauseTime:    time.Duration(i+1) * time.Millisecond,
+			HeapAlloc:      uint64(i+1) << 20,
+			MemoryPressure: float64(i) / 10,
+			NumGC:          uint32(i),
+			CurrentGOGC:    100 + i*10,
+			Timestamp:      base.Add(time.Duration(i) * time.Second),
+		}
+	}
+	return metrics
+}
+
+// TestTraceRecorderRoundTrip tests recording metrics and reading them back
+func TestTraceRecorderRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.trace")
+	recorder, err := NewTraceRecorder(path, 0, time.Hour)
+	require.NoError(t, err)
+
+	recorded := traceMetrics(5)
+	for _, m := range recorded {
+		recorder.OnMetrics(m)
+	}
+	require.NoError(t, recorder.Close())
+	assert.Equal(t, int64(0), recorder.Dropped())
+
+	// Recording after Close is a no-op
+	recorder.OnMetrics(recorded[0])
+	require.NoError(t, recorder.Close())
+
+	replayed, err := ReadTrace(path)
+	require.NoError(t, err)
+	require.Len(t, replayed, len(recorded))
+	for i := range recorded {
+		assert.Equal(t, recorded[i].GCPauseTime, replayed[i].GCPauseTime)
+		assert.Equal(t, recorded[i].HeapAlloc, replayed[i].HeapAlloc)
+		assert.Equal(t, recorded[i].CurrentGOGC, replayed[i].CurrentGOGC)
+		assert.True(t, recorded[i].Timestamp.Equal(replayed[i].Timestamp))
+	}
+
+	// A partial trailing record is ignored
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = file.Write(EncodeBinaryMetrics(recorded[0])[:10])
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	replayed, err = ReadTrace(path)
+	require.NoError(t, err)
+	assert.Len(t, replayed, len(recorded))
+
+	_, err = ReadTrace(filepath.Join(t.TempDir(), "missing.trace"))
+	assert.Error(t, err)
+}
+
+// TestReadTraceMixedVersions tests reading a trace appended to across a
+// binary format upgrade
+func TestReadTraceMixedVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.trace")
+	recorded := traceMetrics(3)
+	recorded[2].MemoryPSIAvailable = true
+	recorded[2].MemoryPSI = 20
+	recorded[2].GCPauseP99 = 40 * time.Millisecond
+
+	var data []byte
+	data = append(data, encodeBinaryMetricsV1(recorded[0])...)
+	data = append(data, encodeBinaryMetricsV1(recorded[1])...)
+	data = append(data, EncodeBinaryMetrics(recorded[2])...)
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	replayed, err := ReadTrace(path)
+	require.NoError(t, err)
+	require.Len(t, replayed, 3)
+	for i := range recorded {
+		assert.Equal(t, recorded[i].CurrentGOGC, replayed[i].CurrentGOGC)
+	}
+	assert.True(t, replayed[2].MemoryPSIAvailable)
+	assert.Equal(t, 20.0, replayed[2].MemoryPSI)
+
+	// A v1 record has no percentiles, so Pause falls back to the mean; a v2
+	// record replays the configured statistic
+	assert.Equal(t, replayed[1].GCPauseTime, replayed[1].Pause(PauseStatisticP99))
+	assert.Equal(t, 40*time.Millisecond, replayed[2].Pause(PauseStatisticP99))
+
+	// An unknown version can't be skipped over
+	require.NoError(t, os.WriteFile(path, append(data, 99), 0o644))
+	_, err = ReadTrace(path)
+	assert.Error(t, err)
+}
+
+// TestTraceRecorderRotation tests size-based rotation of the trace file
+func TestTraceRecorderRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.trace")
+	recorder, err := NewTraceRecorder(path, int64(2*BinaryMetricsSize), time.Hour)
+	require.NoError(t, err)
+
+	for _, m := range traceMetrics(5) {
+		recorder.OnMetrics(m)
+	}
+	require.NoError(t, recorder.Close())
+
+	rotated, err := ReadTrace(path + ".1")
+	require.NoError(t, err)
+	require.Len(t, rotated, 2)
+	assert.Equal(t, 120, rotated[0].CurrentGOGC)
+	assert.Equal(t, 130, rotated[1].CurrentGOGC)
+
+	current, err := ReadTrace(path)
+	require.NoError(t, err)
+	require.Len(t, current, 1)
+	assert.Equal(t, 140, current[0].CurrentGOGC)
+}
+
+// TestTraceRecorderPeriodicFlush tests that records reach the file without Close
+func TestTraceRecorderPeriodicFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.trace")
+	recorder, err := NewTraceRecorder(path, 0, 10*time.Millisecond)
+	require.NoError(t, err)
+	defer recorder.Close()
+
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+	tuner.AddMetricsObserver(recorder)
+	tuner.performTuningCycle()
+
+	assert.Eventually(t, func() bool {
+		replayed, err := ReadTrace(path)
+		return err == nil && len(replayed) == 1
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,26 @@
+package autotune
+
+// pauseTrendWindow is the number of recent samples the GC pause trend is
+// computed over
+const pauseTrendWindow = 10
+
+// pauseTrend returns the least-squares slope of the GC pause time over the
+// last samples of the history in nanoseconds per second; positive means
+// pauses are getting longer. Callers must hold the lock.
+func (t *Tuner) pauseTrend() float64 {
+	history := t.metricsHistory.items()
+	start := len(history) - pauseTrendWindow
+	if start < 0 {
+		start = 0
+	}
+	window := history[start:]
+	if len(window) < 2 {
+		return 0
+	}
+
+	values := make([]float64, len(window))
+	for i, m := range window {
+		values[i] = float64(m.GCPauseTime)
+	}
+	return slopePerSecond(values, window[0].Timestamp, window[len(window)-1].Timestamp)
+}
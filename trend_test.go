@@ -0,0 +1,44 @@
+package autotune
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPauseTrend tests the slope of the GC pause time over recent samples
+func TestPauseTrend(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	assert.Zero(t, tuner.pauseTrend())
+
+	// Pauses growing 1ms every 10s
+	start := time.Now()
+	var history []Metrics
+	for i := 0; i < 20; i++ {
+		history = append(history, Metrics{
+			GCPauseTime: time.Duration(i) * time.Millisecond,
+			Timestamp:   start.Add(time.Duration(i) * 10 * time.Second),
+		})
+	}
+	setMetricsHistory(tuner, history)
+	assert.InDelta(t, float64(time.Millisecond)/10, tuner.pauseTrend(), 1e-6)
+	assert.Greater(t, tuner.GetStats()["trend"], 0.0)
+
+	// Only the recent window counts: the pauses recover after a spike
+	for i := 0; i < pauseTrendWindow; i++ {
+		history = append(history, Metrics{
+			GCPauseTime: time.Duration(pauseTrendWindow-i) * time.Millisecond,
+			Timestamp:   start.Add(time.Duration(20+i) * 10 * time.Second),
+		})
+	}
+	setMetricsHistory(tuner, history)
+	assert.Less(t, tuner.pauseTrend(), 0.0)
+
+	// Without timestamps the slope is per sample
+	setMetricsHistory(tuner, []Metrics{{GCPauseTime: 1}, {GCPauseTime: 3}, {GCPauseTime: 5}})
+	assert.InDelta(t, 2.0, tuner.pauseTrend(), 1e-9)
+}
@@ -0,0 +1,63 @@
+package autotune
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// uiPage is the self-contained web UI served at / when EnableUI is set and
+// at /dashboard when EnableDashboard is set. It polls /ui/state or
+// /dashboard/state respectively, relative to its own path.
+//
+//go:embed ui/index.html
+var uiPage []byte
+
+// uiDecisionLimit is the number of recent decisions included in the UI state
+const uiDecisionLimit = 20
+
+// handleUI serves the embedded web UI
+func (obs *ObservabilityServer) handleUI(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	writeUIPage(w)
+}
+
+// handleDashboard serves the embedded web UI at /dashboard
+func (obs *ObservabilityServer) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	writeUIPage(w)
+}
+
+// writeUIPage writes the embedded web UI
+func writeUIPage(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(uiPage)
+}
+
+// handleUIState serves the JSON polled by the web UI at /ui/state and
+// /dashboard/state. It is independent of EnableJSONMetrics so the UI works
+// with JSON metrics disabled.
+func (obs *ObservabilityServer) handleUIState(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	obs.tuner.mu.RLock()
+	decisions := obs.tuner.decisionHistory.items()
+	if len(decisions) > uiDecisionLimit {
+		decisions = decisions[len(decisions)-uiDecisionLimit:]
+	}
+	decisions = append([]TuningDecision(nil), decisions...)
+	obs.tuner.mu.RUnlock()
+
+	state := map[string]interface{}{
+		"current_metrics": obs.tuner.GetMetrics(),
+		"stats":           obs.tuner.stats(obs.config.StaleThreshold),
+		"decisions":       decisions,
+		"timestamp":       time.Now(),
+	}
+
+	json.NewEncoder(w).Encode(state)
+}
@@ -0,0 +1,96 @@
+package autotune
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUI tests the embedded web UI and the state it polls
+func TestUI(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	// Disabled by default
+	obs := NewObservabilityServer(DefaultObservabilityConfig(), tuner)
+	w := httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	config := DefaultObservabilityConfig()
+	config.EnableUI = true
+	config.EnableJSONMetrics = false
+	obs = NewObservabilityServer(config, tuner)
+
+	w = httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), "ui/state")
+
+	w = httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	for i := 0; i < uiDecisionLimit+5; i++ {
+		tuner.decisionHistory.push(TuningDecision{
+			OldGOGC: 100, NewGOGC: 100 + i, Timestamp: time.Now(),
+		})
+	}
+
+	w = httptest.NewRecorder()
+	obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/ui/state", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var state struct {
+		CurrentMetrics Metrics                `json:"current_metrics"`
+		Stats          map[string]interface{} `json:"stats"`
+		Decisions      []TuningDecision       `json:"decisions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &state))
+	assert.NotZero(t, state.CurrentMetrics.HeapAlloc)
+	assert.Contains(t, state.Stats, "total_decisions")
+	require.Len(t, state.Decisions, uiDecisionLimit)
+	assert.Equal(t, 100+uiDecisionLimit+4, state.Decisions[uiDecisionLimit-1].NewGOGC)
+}
+
+// TestDashboard tests serving the web UI at /dashboard
+func TestDashboard(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+
+	get := func(obs *ObservabilityServer, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		obs.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	// Not registered unless enabled, even with the UI at /
+	config := DefaultObservabilityConfig()
+	config.EnableUI = true
+	obs := NewObservabilityServer(config, tuner)
+	assert.Equal(t, http.StatusNotFound, get(obs, "/dashboard").Code)
+
+	config = DefaultObservabilityConfig()
+	config.EnableDashboard = true
+	obs = NewObservabilityServer(config, tuner)
+	assert.Equal(t, http.StatusNotFound, get(obs, "/").Code)
+
+	w := get(obs, "/dashboard")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	page := w.Body.String()
+	assert.Contains(t, page, `"dashboard/state"`)
+	// Self-contained: no scripts, styles or fonts from other hosts
+	assert.NotContains(t, page, "http://")
+	assert.NotContains(t, page, "https://")
+
+	// The state is served below /dashboard, not at the UI's path
+	assert.Equal(t, http.StatusOK, get(obs, "/dashboard/state").Code)
+	assert.Equal(t, http.StatusNotFound, get(obs, "/ui/state").Code)
+}
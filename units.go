@@ -0,0 +1,82 @@
+package autotune
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ByteUnit is the unit byte-valued metrics are reported in by the
+// human-facing JSON and CSV exports. Prometheus output always uses bytes.
+type ByteUnit string
+
+const (
+	// ByteUnitBytes reports raw bytes
+	ByteUnitBytes ByteUnit = "bytes"
+	// ByteUnitMiB reports mebibytes (2^20 bytes)
+	ByteUnitMiB ByteUnit = "mib"
+	// ByteUnitGiB reports gibibytes (2^30 bytes)
+	ByteUnitGiB ByteUnit = "gib"
+)
+
+// byteMetric is a byte-valued Metrics field
+type byteMetric struct {
+	field  string // Go field name, used as the JSON key
+	column string // snake_case name, used as the CSV column
+	value  uint64
+}
+
+// byteMetrics returns the byte-valued fields of metrics in a fixed order
+func byteMetrics(m Metrics) []byteMetric {
+	return []byteMetric{
+		{"HeapSize", "heap_size", m.HeapSize},
+		{"HeapAlloc", "heap_alloc", m.HeapAlloc},
+		{"HeapInuse", "heap_inuse", m.HeapInuse},
+		{"LiveHeap", "live_heap", m.LiveHeap},
+		{"NextGC", "next_gc", m.NextGC},
+		{"MemoryLimit", "memory_limit", m.MemoryLimit},
+		{"MemoryUsage", "memory_usage", m.MemoryUsage},
+		{"ContainerMemLimit", "container_memory_limit", m.ContainerMemLimit},
+	}
+}
+
+// validate reports whether the unit is known
+func (u ByteUnit) validate() error {
+	switch u {
+	case ByteUnitBytes, ByteUnitMiB, ByteUnitGiB:
+		return nil
+	}
+	return fmt.Errorf("unknown byte unit %q, expected bytes, mib or gib", u)
+}
+
+// convert converts a byte count to the unit
+func (u ByteUnit) convert(bytes uint64) float64 {
+	switch u {
+	case ByteUnitMiB:
+		return float64(bytes) / (1 << 20)
+	case ByteUnitGiB:
+		return float64(bytes) / (1 << 30)
+	}
+	return float64(bytes)
+}
+
+// metricsWithUnits renders metrics as a map in which every byte-valued field
+// is converted to the unit and its name suffixed with it, e.g. HeapAlloc
+// becomes HeapAlloc_mib
+func metricsWithUnits(metrics Metrics, unit ByteUnit) (map[string]interface{}, error) {
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for _, metric := range byteMetrics(metrics) {
+		delete(fields, metric.field)
+		fields[metric.field+"_"+string(unit)] = unit.convert(metric.value)
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,92 @@
+package autotune
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestByteUnitConversion tests conversion for each unit
+func TestByteUnitConversion(t *testing.T) {
+	tests := []struct {
+		unit     ByteUnit
+		bytes    uint64
+		expected float64
+	}{
+		{ByteUnitBytes, 1536, 1536},
+		{ByteUnitMiB, 3 << 20, 3},
+		{ByteUnitMiB, 1536 << 10, 1.5},
+		{ByteUnitGiB, 2 << 30, 2},
+		{ByteUnitGiB, 512 << 20, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.unit), func(t *testing.T) {
+			require.NoError(t, tt.unit.validate())
+			assert.Equal(t, tt.expected, tt.unit.convert(tt.bytes))
+		})
+	}
+
+	assert.Error(t, ByteUnit("kb").validate())
+}
+
+// TestMetricsWithUnits tests unit-labeled metrics fields
+func TestMetricsWithUnits(t *testing.T) {
+	metrics := Metrics{HeapAlloc: 256 << 20, ContainerMemLimit: 1 << 30, CurrentGOGC: 100}
+
+	fields, err := metricsWithUnits(metrics, ByteUnitMiB)
+	require.NoError(t, err)
+	assert.Equal(t, 256.0, fields["HeapAlloc_mib"])
+	assert.Equal(t, 1024.0, fields["ContainerMemLimit_mib"])
+	assert.NotContains(t, fields, "HeapAlloc")
+	assert.Equal(t, 100.0, fields["CurrentGOGC"], "non-byte fields are unchanged")
+
+	fields, err = metricsWithUnits(metrics, ByteUnitGiB)
+	require.NoError(t, err)
+	assert.Equal(t, 0.25, fields["HeapAlloc_gib"])
+	assert.Equal(t, 1.0, fields["ContainerMemLimit_gib"])
+}
+
+// TestExportUnits tests the JSON and CSV exports with each unit
+func TestExportUnits(t *testing.T) {
+	tuner, err := NewTuner(DefaultConfig())
+	require.NoError(t, err)
+	exporter := NewMetricsExporter(tuner)
+
+	assert.Error(t, exporter.SetUnits("kb"))
+
+	// Default CSV output is in bytes
+	data, err := exporter.ExportToCSV()
+	require.NoError(t, err)
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Contains(t, records[0], "heap_alloc_bytes")
+	assert.Equal(t, len(records[0]), len(records[1]))
+
+	for _, unit := range []ByteUnit{ByteUnitBytes, ByteUnitMiB, ByteUnitGiB} {
+		t.Run(string(unit), func(t *testing.T) {
+			require.NoError(t, exporter.SetUnits(unit))
+
+			data, err := exporter.ExportToJSON()
+			require.NoError(t, err)
+			var exported map[string]interface{}
+			require.NoError(t, json.Unmarshal(data, &exported))
+			assert.Equal(t, string(unit), exported["units"])
+			metrics := exported["metrics"].(map[string]interface{})
+			assert.Contains(t, metrics, "HeapAlloc_"+string(unit))
+			assert.Greater(t, metrics["HeapAlloc_"+string(unit)], 0.0)
+
+			data, err = exporter.ExportToCSV()
+			require.NoError(t, err)
+			records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+			require.NoError(t, err)
+			assert.Contains(t, records[0], "heap_alloc_"+string(unit))
+			assert.Contains(t, records[0], "gc_pause_time_ns", "durations stay in base units")
+		})
+	}
+}
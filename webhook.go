@@ -0,0 +1,186 @@
+package autotune
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// defaultWebhookTimeout bounds each webhook request
+	defaultWebhookTimeout = 5 * time.Second
+	// defaultWebhookAttempts is how often an alert is sent before it is
+	// dropped
+	defaultWebhookAttempts = 3
+	// defaultWebhookQueueSize is the number of alerts waiting for delivery
+	// beyond which new alerts are dropped
+	defaultWebhookQueueSize = 100
+)
+
+// WebhookAlertObserver POSTs alerts as JSON to an HTTP endpoint.
+//
+// Alerts are delivered by a background worker, so a slow or unreachable
+// endpoint never stalls the tuner's metrics callback. Requests failing with
+// a network error or a 5xx status are retried with jittered exponential
+// backoff; alerts rejected with another non-2xx status, failing every
+// attempt, or arriving while the queue is full are dropped with a warning.
+type WebhookAlertObserver struct {
+	url         string
+	headers     http.Header
+	timeout     time.Duration
+	maxAttempts int
+	queueSize   int
+	randSeed    int64
+	logger      Logger
+	client      *http.Client
+	queue       *pushQueue
+
+	// encode renders an alert as the request body
+	encode func(Alert) ([]byte, error)
+
+	// ctx is canceled by Stop to abort a request in flight
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// WebhookOption configures a WebhookAlertObserver
+type WebhookOption func(*WebhookAlertObserver)
+
+// WithWebhookTimeout sets the timeout of each request (default: 5s)
+func WithWebhookTimeout(timeout time.Duration) WebhookOption {
+	return func(w *WebhookAlertObserver) {
+		w.timeout = timeout
+	}
+}
+
+// WithWebhookHeader adds a header to every request, e.g. an Authorization
+// token
+func WithWebhookHeader(key, value string) WebhookOption {
+	return func(w *WebhookAlertObserver) {
+		w.headers.Add(key, value)
+	}
+}
+
+// WithWebhookAttempts sets how often an alert is sent before it is dropped
+// (default: 3)
+func WithWebhookAttempts(attempts int) WebhookOption {
+	return func(w *WebhookAlertObserver) {
+		w.maxAttempts = attempts
+	}
+}
+
+// WithWebhookQueueSize sets how many alerts may wait for delivery
+// (default: 100)
+func WithWebhookQueueSize(size int) WebhookOption {
+	return func(w *WebhookAlertObserver) {
+		w.queueSize = size
+	}
+}
+
+// WithWebhookRandSeed seeds the retry jitter, e.g. with the tuner's
+// Config.RandSeed for reproducible runs (default: 0, time-seeded)
+func WithWebhookRandSeed(seed int64) WebhookOption {
+	return func(w *WebhookAlertObserver) {
+		w.randSeed = seed
+	}
+}
+
+// WithWebhookLogger sets the logger for dropped alerts (default: the
+// standard logger)
+func WithWebhookLogger(logger Logger) WebhookOption {
+	return func(w *WebhookAlertObserver) {
+		w.logger = logger
+	}
+}
+
+// NewWebhookAlertObserver creates an observer POSTing alerts to the http or
+// https URL and starts its delivery worker. Call Stop to stop it.
+func NewWebhookAlertObserver(rawURL string, opts ...WebhookOption) (*WebhookAlertObserver, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("webhook URL must be an absolute http or https URL, got %q", rawURL)
+	}
+
+	w := &WebhookAlertObserver{
+		url:         rawURL,
+		headers:     make(http.Header),
+		timeout:     defaultWebhookTimeout,
+		maxAttempts: defaultWebhookAttempts,
+		queueSize:   defaultWebhookQueueSize,
+		logger:      &defaultLogger{},
+		encode:      func(alert Alert) ([]byte, error) { return json.Marshal(alert) },
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.timeout <= 0 {
+		return nil, fmt.Errorf("webhook timeout must be positive, got %v", w.timeout)
+	}
+
+	w.client = &http.Client{Timeout: w.timeout}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	w.queue = newPushQueue("Alert webhook", w.queueSize, w.maxAttempts, w.post, w.logger, newRand(w.randSeed))
+	w.queue.start()
+	return w, nil
+}
+
+// OnAlert queues the alert for delivery without blocking
+func (w *WebhookAlertObserver) OnAlert(alert Alert) {
+	payload, err := w.encode(alert)
+	if err != nil {
+		w.logger.Warn("Failed to encode alert for webhook: %v", err)
+		return
+	}
+	if !w.queue.enqueue(payload) {
+		w.logger.Warn("Alert webhook queue full, dropping alert: %s", alert.Message)
+	}
+}
+
+// Stop stops the delivery worker, aborting a request in flight. Alerts
+// still queued are discarded.
+func (w *WebhookAlertObserver) Stop() {
+	w.cancel()
+	w.queue.stop()
+}
+
+// Stats returns delivery counters
+func (w *WebhookAlertObserver) Stats() map[string]interface{} {
+	return w.queue.stats()
+}
+
+// post sends one encoded alert. 5xx responses are returned as retryable
+// errors, other non-2xx responses as permanent ones.
+func (w *WebhookAlertObserver) post(payload []byte) error {
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return &permanentPushError{err: err}
+	}
+	for key, values := range w.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	// Drain the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return &permanentPushError{err: fmt.Errorf("webhook returned %s", resp.Status)}
+	}
+	return nil
+}
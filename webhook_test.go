@@ -0,0 +1,112 @@
+package autotune
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWebhookAlertObserver tests that alerts are POSTed as JSON with the
+// configured headers
+func TestWebhookAlertObserver(t *testing.T) {
+	received := make(chan Alert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var alert Alert
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(body, &alert))
+		received <- alert
+	}))
+	defer server.Close()
+
+	observer, err := NewWebhookAlertObserver(server.URL, WithWebhookHeader("Authorization", "Bearer secret"))
+	require.NoError(t, err)
+	defer observer.Stop()
+
+	observer.OnAlert(Alert{Level: AlertLevelCritical, Message: "High GC pause time", Timestamp: time.Unix(1700000000, 0)})
+
+	select {
+	case alert := <-received:
+		assert.Equal(t, AlertLevelCritical, alert.Level)
+		assert.Equal(t, "High GC pause time", alert.Message)
+	case <-time.After(2 * time.Second):
+		t.Fatal("alert not delivered")
+	}
+	assert.Eventually(t, func() bool { return observer.Stats()["pushed"].(int64) == 1 }, time.Second, 10*time.Millisecond)
+}
+
+// TestWebhookAlertObserverRetries tests that 5xx responses are retried and
+// other failures dropped
+func TestWebhookAlertObserverRetries(t *testing.T) {
+	var requests int64
+	status := int64(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&requests, 1) > 1 {
+			w.WriteHeader(int(atomic.LoadInt64(&status)))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	observer, err := NewWebhookAlertObserver(server.URL, WithWebhookLogger(silentLogger{}))
+	require.NoError(t, err)
+	defer observer.Stop()
+
+	observer.OnAlert(Alert{Level: AlertLevelWarning, Message: "retried"})
+	assert.Eventually(t, func() bool { return observer.Stats()["pushed"].(int64) == 1 }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&requests))
+	assert.Equal(t, int64(1), observer.Stats()["failed"])
+
+	// A rejected alert isn't retried
+	atomic.StoreInt64(&status, http.StatusBadRequest)
+	observer.OnAlert(Alert{Level: AlertLevelWarning, Message: "rejected"})
+	assert.Eventually(t, func() bool { return observer.Stats()["dropped"].(int64) == 1 }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(3), atomic.LoadInt64(&requests))
+}
+
+// TestWebhookAlertObserverNonBlocking tests that a stalled endpoint doesn't
+// block OnAlert and that alerts beyond the queue are dropped
+func TestWebhookAlertObserverNonBlocking(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	// Deferred calls run in reverse, so the observer is stopped first and
+	// must abort the stalled request
+
+	observer, err := NewWebhookAlertObserver(server.URL,
+		WithWebhookQueueSize(2), WithWebhookTimeout(10*time.Second), WithWebhookLogger(silentLogger{}))
+	require.NoError(t, err)
+	defer observer.Stop()
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		observer.OnAlert(Alert{Level: AlertLevelInfo, Message: "stalled"})
+	}
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Eventually(t, func() bool { return observer.Stats()["dropped"].(int64) >= 7 }, time.Second, 10*time.Millisecond)
+}
+
+// TestNewWebhookAlertObserverValidation tests URL and option validation
+func TestNewWebhookAlertObserverValidation(t *testing.T) {
+	for _, rawURL := range []string{"", "ftp://example.com", "/alerts", "http://"} {
+		_, err := NewWebhookAlertObserver(rawURL)
+		assert.Error(t, err, rawURL)
+	}
+
+	_, err := NewWebhookAlertObserver("http://example.com", WithWebhookTimeout(-time.Second))
+	assert.Error(t, err)
+}
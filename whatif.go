@@ -0,0 +1,90 @@
+package autotune
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// whatIfMaxSamples bounds the recorded samples replayed by /whatif
+	whatIfMaxSamples = 1000
+	// whatIfMaxBodyBytes bounds the size of a /whatif config override
+	whatIfMaxBodyBytes = 64 << 10
+)
+
+// authorized reports whether the request carries the configured bearer
+// token. Without a configured token nothing is authorized.
+func (obs *ObservabilityServer) authorized(r *http.Request) bool {
+	if obs.config.AuthToken == "" {
+		return false
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(obs.config.AuthToken)) == 1
+}
+
+// handleWhatIf replays the recorded metrics under the live config with the
+// JSON fields in the request body overridden, for example
+// {"TargetLatency": 5000000, "TuningAggressiveness": 0.5}, and returns the
+// decisions Simulate would make next to those of the unchanged config
+func (obs *ObservabilityServer) handleWhatIf(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if obs.config.AuthToken == "" {
+		http.Error(w, "what-if simulation requires an auth token to be configured", http.StatusNotFound)
+		return
+	}
+	if !obs.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	obs.tuner.mu.RLock()
+	live := *obs.tuner.config
+	qosClass, memoryRequest := obs.tuner.qosClass, obs.tuner.memoryRequest
+	obs.tuner.mu.RUnlock()
+
+	override := live
+	if err := json.NewDecoder(io.LimitReader(r.Body, whatIfMaxBodyBytes)).Decode(&override); err != nil {
+		http.Error(w, fmt.Sprintf("invalid config override: %v", err), http.StatusBadRequest)
+		return
+	}
+	override.Logger = live.Logger
+
+	obs.mu.RLock()
+	recorded := obs.metricsHistory
+	if len(recorded) > whatIfMaxSamples {
+		recorded = recorded[len(recorded)-whatIfMaxSamples:]
+	}
+	history := make([]Metrics, len(recorded))
+	for i, sample := range recorded {
+		history[i] = sample.Metrics
+	}
+	obs.mu.RUnlock()
+
+	decisions, err := simulate(&override, history, qosClass, memoryRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	baseline, err := simulate(&live, history, qosClass, memoryRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"samples":   len(history),
+		"decisions": decisions,
+		"baseline":  baseline,
+		"timestamp": time.Now(),
+	})
+}